@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// withAccessHint adds advice to err when it is a write denied below dir, the
+// AddOns folder, instead of leaving the user with a bare "access is denied".
+func withAccessHint(err error, dir string) error {
+	if !errors.Is(err, fs.ErrPermission) {
+		return err
+	}
+	return fmt.Errorf("%w\n\n%s", err, accessHint(dir))
+}
+
+// accessHint explains how to make dir writable on this system.
+func accessHint(dir string) string {
+	switch runtime.GOOS {
+	case "windows":
+		var b strings.Builder
+		if underProgramFiles(dir) {
+			b.WriteString("WoW is installed under Program Files, where Windows only lets administrators change files.\n")
+		}
+		b.WriteString("To fix it, either:\n")
+		b.WriteString("  - run blink from a terminal started with \"Run as administrator\",\n")
+		fmt.Fprintf(&b, "  - give your user Modify rights on %s (Properties → Security → Edit), or\n", dir)
+		b.WriteString("  - move the game out of Program Files (Battle.net → Settings → Game Install/Update), e.g. to C:\\Games")
+		return b.String()
+	default:
+		return fmt.Sprintf("Your user can't write to %s. To fix it, either:\n"+
+			"  - take ownership of it, e.g. sudo chown -R \"$USER\" %q, or\n"+
+			"  - point wowPath at a WoW install your user owns", dir, dir)
+	}
+}
+
+// underProgramFiles reports whether dir is below one of the Program Files
+// folders.
+func underProgramFiles(dir string) bool {
+	for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)", "ProgramW6432"} {
+		root := os.Getenv(env)
+		if root == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(root, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
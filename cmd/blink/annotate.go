@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/byteorem/blink/internal/annotate"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/urfave/cli/v2"
+)
+
+func annotateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "annotate",
+		Usage: "Write a .luarc.json so the Lua language server sees what blink syncs",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "fetch",
+				Usage: "Download (or update) the community WoW API annotations",
+			},
+			&cli.StringSliceFlag{
+				Name:  "library",
+				Usage: "Additional annotation/library path to add to the workspace",
+			},
+		},
+		Action: runAnnotate,
+	}
+}
+
+func runAnnotate(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	srcDirs, _, err := findAddon(cfg)
+	if err != nil {
+		return err
+	}
+	srcDir := srcDirs[0]
+
+	libraries := c.StringSlice("library")
+
+	cacheDir, err := annotate.CacheDir()
+	if err != nil {
+		return err
+	}
+	if c.Bool("fetch") {
+		fmt.Printf("Fetching WoW API annotations into %s\n", cacheDir)
+		if err := annotate.Fetch(cacheDir); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(annotate.AnnotationsPath(cacheDir)); err == nil {
+		libraries = append(libraries, annotate.AnnotationsPath(cacheDir))
+	} else {
+		fmt.Println("No WoW API annotations found — run `blink annotate --fetch` to download them")
+	}
+
+	ig := copier.NewIgnorer(srcDir, cfg.IgnorePatterns(""), cfg.UseGitignore, cfg.UsePkgMeta, cfg.UseGitattributes)
+	ig.FollowSymlinks = cfg.FollowSymlinks
+	settings, err := annotate.Settings(srcDir, ig, libraries)
+	if err != nil {
+		return err
+	}
+
+	path, err := annotate.Write(srcDir, settings)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", path)
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/byteorem/blink/internal/audit"
+	"github.com/byteorem/blink/internal/budget"
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/ghactions"
+	"github.com/byteorem/blink/internal/workspace"
+	"github.com/urfave/cli/v2"
+)
+
+// auditFile returns the file keeping the last audit of each addon, next to
+// the release zips.
+func auditFile(cfg config.Config) string {
+	return filepath.Join(cfg.Package.Out, "audit.json")
+}
+
+func auditCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "audit",
+		Usage: "Break the synced files down by type and size, flag oversized or unused media, and compare with the last audit",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "max-media",
+				Usage: "Flag textures, sounds and fonts larger than this",
+				Value: "1MB",
+			},
+		},
+		Action: runAudit,
+	}
+}
+
+func runAudit(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	maxMedia, err := budget.ParseSize(c.String("max-media"))
+	if err != nil {
+		return fmt.Errorf("--max-media: %w", err)
+	}
+
+	addons, err := resolveAddons(cfg, "", "")
+	if err != nil {
+		return err
+	}
+	if addons, err = workspace.Filter(addons, c.StringSlice("addon")); err != nil {
+		return err
+	}
+
+	previous, err := audit.Read(auditFile(cfg))
+	if err != nil {
+		return fmt.Errorf("reading the last audit failed: %w", err)
+	}
+	reports := make(map[string]audit.Report, len(previous))
+	for name, r := range previous {
+		reports[name] = r
+	}
+
+	var warnings []string
+	for _, a := range addons {
+		r, err := audit.Run(a.Sources, maxMedia)
+		if err != nil {
+			return fmt.Errorf("%s: %w", a.Name, err)
+		}
+		last, compared := previous[a.Name]
+		printAudit(a.Name, r, last, compared)
+		reports[a.Name] = r
+
+		warn := func(f audit.File, msg string) {
+			warnings = append(warnings, msg)
+			ciAnnotate(c, ghactions.Annotation{Level: "warning", File: filepath.Join(a.Dir(), filepath.FromSlash(f.Path)), Message: msg})
+		}
+		for _, f := range r.Oversized {
+			warn(f, fmt.Sprintf("%s is %s, over %s", f.Path, budget.FormatSize(f.Size), budget.FormatSize(maxMedia)))
+		}
+		for _, f := range r.Unreferenced {
+			warn(f, fmt.Sprintf("no code mentions %s", f.Path))
+		}
+	}
+
+	if err := audit.Write(auditFile(cfg), reports); err != nil {
+		return err
+	}
+	if c.Bool("ci") && len(warnings) > 0 {
+		return cli.Exit(fmt.Sprintf("%d warning(s)", len(warnings)), exitWarnings)
+	}
+	return nil
+}
+
+// printAudit prints the report of an addon, with the changes since last
+// when compared is set.
+func printAudit(name string, r, last audit.Report, compared bool) {
+	change := func(now, before audit.Total) string {
+		if !compared {
+			return ""
+		}
+		return fmt.Sprintf("  (%+d files, %s)", now.Files-before.Files, sizeChange(now.Bytes-before.Bytes))
+	}
+
+	fmt.Printf("%s: %d files, %s%s\n", name, r.Total.Files, budget.FormatSize(r.Total.Bytes), change(r.Total, last.Total))
+	for _, k := range audit.Kinds {
+		t := r.Kinds[k]
+		if t.Files == 0 && last.Kinds[k].Files == 0 {
+			continue
+		}
+		fmt.Printf("  %-9s %5d files  %10s%s\n", k, t.Files, budget.FormatSize(t.Bytes), change(t, last.Kinds[k]))
+	}
+	if len(r.Oversized) > 0 {
+		fmt.Println("  oversized media:")
+		for _, f := range r.Oversized {
+			fmt.Printf("    %s  %s\n", f.Path, budget.FormatSize(f.Size))
+		}
+	}
+	if len(r.Unreferenced) > 0 {
+		fmt.Println("  media no code mentions:")
+		for _, f := range r.Unreferenced {
+			fmt.Printf("    %s  %s\n", f.Path, budget.FormatSize(f.Size))
+		}
+	}
+	fmt.Println()
+}
+
+// sizeChange renders a change in size with its sign, e.g. "+4.2 MB".
+func sizeChange(n int64) string {
+	if n < 0 {
+		return "-" + budget.FormatSize(-n)
+	}
+	return "+" + budget.FormatSize(n)
+}
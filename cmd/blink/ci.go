@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/byteorem/blink/internal/ghactions"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes under --ci, so a pipeline can tell failures from warnings.
+const (
+	exitFailed   = 1
+	exitWarnings = 2
+)
+
+// ciMode is set by --ci, for the error main prints after the command ends.
+var ciMode bool
+
+// ciGroup starts a collapsible group of log lines under --ci, and returns
+// the func that ends it.
+func ciGroup(c *cli.Context, title string) func() {
+	if !c.Bool("ci") {
+		return func() {}
+	}
+	return ghactions.Group(os.Stdout, title)
+}
+
+// ciAnnotate prints an annotation under --ci. File is made relative to the
+// repository root, GITHUB_WORKSPACE, or else the working directory.
+func ciAnnotate(c *cli.Context, a ghactions.Annotation) {
+	if !c.Bool("ci") {
+		return
+	}
+	if a.File != "" {
+		a.File = ciPath(a.File)
+	}
+	fmt.Println(a)
+}
+
+// ciPath returns path relative to the repository root, slash-separated.
+func ciPath(path string) string {
+	root := os.Getenv("GITHUB_WORKSPACE")
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	if rel, err := filepath.Rel(root, abs); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(abs)
+}
+
+// ciWarnings annotates warnings under --ci, where they fail the step with
+// exitWarnings; it returns that exit error, or nil.
+func ciWarnings(c *cli.Context, warnings []string) error {
+	if !c.Bool("ci") || len(warnings) == 0 {
+		return nil
+	}
+	for _, w := range warnings {
+		ciAnnotate(c, ghactions.Annotation{Level: "warning", Message: w})
+	}
+	return cli.Exit(fmt.Sprintf("%d warning(s)", len(warnings)), exitWarnings)
+}
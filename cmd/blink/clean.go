@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/state"
+	"github.com/byteorem/blink/internal/workspace"
+	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli/v2"
+)
+
+func cleanCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "clean",
+		Usage: "Remove the synced addon folder from Interface/AddOns",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Don't ask for confirmation",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Also remove folders blink didn't create (no " + copier.MarkerFile + " marker)",
+			},
+		},
+		Action: runClean,
+	}
+}
+
+func runClean(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	wowPath, err := resolveWowPath(cfg, c.StringSlice("flavor"), c.Bool("any-path"))
+	if err != nil {
+		return err
+	}
+	addons, err := resolveAddons(cfg, filepath.Join(wowPath, "Interface", "AddOns"), "")
+	if err != nil {
+		return err
+	}
+	addons = append(addons, uiPackFolders(cfg, wowPath, "")...)
+	if addons, err = workspace.Filter(addons, c.StringSlice("addon")); err != nil {
+		return err
+	}
+
+	// UI pack folders hold client files too: they aren't removed, but get
+	// back the files blink replaced in them.
+	var targets []string
+	var packs []*workspace.Addon
+	for _, a := range addons {
+		if _, err := os.Stat(a.Target); os.IsNotExist(err) {
+			continue
+		}
+		if a.Pack {
+			packs = append(packs, a)
+			continue
+		}
+		if _, ok := copier.LinkTarget(a.Target); ok {
+			return fmt.Errorf("%s is a link — remove it with blink unlink", a.Target)
+		}
+		// Only folders blink synced to are removed, never a release installed
+		// by an addon manager under the same name.
+		if !copier.HasMarker(a.Target) && !c.Bool("force") {
+			return fmt.Errorf("%s was not created by blink (no %s marker) — use --force to remove it anyway", a.Target, copier.MarkerFile)
+		}
+		targets = append(targets, a.Target)
+	}
+	if len(targets) == 0 && len(packs) == 0 {
+		fmt.Println("Nothing to clean")
+		return nil
+	}
+
+	if !c.Bool("yes") {
+		question := fmt.Sprintf("Remove %s?", strings.Join(targets, ", "))
+		if len(packs) > 0 {
+			dirs := make([]string, len(packs))
+			for i, a := range packs {
+				dirs[i] = a.Target
+			}
+			question = fmt.Sprintf("Restore the original files in %s?", strings.Join(dirs, ", "))
+			if len(targets) > 0 {
+				question = fmt.Sprintf("Remove %s and restore the original files in %s?", strings.Join(targets, ", "), strings.Join(dirs, ", "))
+			}
+		}
+		ok, err := confirm(question)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return cli.Exit("", 1)
+		}
+	}
+
+	for _, t := range targets {
+		if err := os.RemoveAll(t); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", t)
+	}
+	forgetTargets(targets)
+	for _, a := range packs {
+		n, err := copier.RestoreOriginals(a.Sources, a.Target)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restored %d file(s) in %s\n", n, a.Target)
+		forgetPack(a.Target)
+	}
+	return nil
+}
+
+// forgetTargets drops removed folders from the state store.
+func forgetTargets(targets []string) {
+	_ = state.Update(func(st *state.Store) {
+		for _, t := range targets {
+			st.Forget(t)
+		}
+	})
+}
+
+// forgetPack drops a restored UI pack folder from the state store.
+func forgetPack(target string) {
+	_ = state.Update(func(st *state.Store) { st.ForgetPack(target) })
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to no.
+func confirm(question string) (bool, error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		return false, errors.New("not a terminal — pass --yes to confirm")
+	}
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
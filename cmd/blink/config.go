@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/byteorem/blink/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Inspect blink.toml",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "validate",
+				Usage:     "Report unknown keys, type mismatches and invalid values, with line numbers",
+				ArgsUsage: "[file...]",
+				Action:    runConfigValidate,
+			},
+		},
+	}
+}
+
+func runConfigValidate(c *cli.Context) error {
+	var paths []string
+	for _, arg := range c.Args().Slice() {
+		paths = append(paths, userPath(arg))
+	}
+	if len(paths) == 0 {
+		paths = config.Files(configFile(c))
+		if len(paths) == 0 {
+			return fmt.Errorf("no blink.toml or %s in the current directory", config.LocalFile)
+		}
+	}
+
+	problems := 0
+	for _, path := range paths {
+		issues, err := config.Validate(path)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			if issue.Line > 0 {
+				fmt.Printf("%s:%d: %s\n", path, issue.Line, issue.Message)
+			} else {
+				fmt.Printf("%s: %s\n", path, issue.Message)
+			}
+		}
+		problems += len(issues)
+	}
+
+	if problems > 0 {
+		fmt.Printf("%d problem(s) found\n", problems)
+		return cli.Exit("", 1)
+	}
+	fmt.Println("Config OK")
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/detect"
+	"github.com/byteorem/blink/internal/flavor"
+	"github.com/byteorem/blink/internal/logging"
+	"github.com/byteorem/blink/internal/sync"
+	"github.com/byteorem/blink/internal/ui"
+	"github.com/byteorem/blink/internal/watcher"
+	"github.com/byteorem/blink/internal/workspace"
+)
+
+// sessionControls returns what the TUI's command palette may change in a
+// session syncing addons to the client at wowPath. Switching clients is left
+// out for pinned sessions — container targets and several targets at once —
+// and for two-way syncs, whose watches and mirror are tied to the first
+// target. watching, if set, is restarted with the new client's ignore rules.
+func sessionControls(cfg config.Config, wowPath string, addons []*workspace.Addon, engine *sync.Engine, watching *watches, delay *watcher.Delay, pinned bool) ui.Controls {
+	controls := ui.Controls{
+		SetDelay: func(ms int) {
+			delay.Set(ms)
+			slog.Debug("debounce delay changed", "delay", ms)
+		},
+		ToggleVerbose: func() bool {
+			if logging.Level() <= slog.LevelDebug {
+				logging.SetLevel(slog.LevelInfo)
+				return false
+			}
+			logging.SetLevel(slog.LevelDebug)
+			return true
+		},
+	}
+	if pinned || cfg.TwoWay {
+		return controls
+	}
+
+	// Retarget runs in the TUI's Update, where the addons are read, and
+	// changes them behind the engine's lock.
+	controls.Retarget = func(name string) (string, error) {
+		fl, ok := flavor.Lookup(name)
+		if !ok {
+			return "", fmt.Errorf("unknown flavor %q", name)
+		}
+		dir := filepath.Join(filepath.Dir(wowPath), fl.Dir)
+		if !detect.IsClientDir(dir) {
+			return "", fmt.Errorf("no %s client next to %s", fl.Name, wowPath)
+		}
+		addOnsDir := filepath.Join(dir, "Interface", "AddOns")
+		tf, err := targetTransform(cfg, fl)
+		if err != nil {
+			return "", err
+		}
+
+		packs := false
+		err = engine.Modify(func() error {
+			for _, a := range addons {
+				for i := range a.Sources {
+					a.Sources[i].Ignorer = sourceIgnorer(cfg, a.Sources[i].Dir, fl.Name)
+				}
+				if a.Pack {
+					a.Target = filepath.Join(dir, filepath.FromSlash(a.Name))
+					recordPack(a)
+					packs = true
+					continue
+				}
+				a.Target = filepath.Join(addOnsDir, filepath.Base(a.Target))
+				srcDirs := sourceDirs(a)
+				if err := copier.WriteMarker(a.Target, srcDirs); err != nil {
+					return withAccessHint(fmt.Errorf("marking %s failed: %w", a.Target, err), addOnsDir)
+				}
+				recordTarget(a.Target, srcDirs)
+			}
+			engine.SetTransform(tf)
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		wowPath = dir
+		slog.Debug("target", "wowPath", wowPath, "flavor", fl.Name)
+		if watching != nil {
+			if err := watching.restart(watchRoots(cfg, addons, fl.Name)); err != nil {
+				return "", err
+			}
+		}
+
+		switch {
+		case len(addons) > 1 && packs:
+			return dir, nil
+		case len(addons) > 1:
+			return addOnsDir, nil
+		}
+		return addons[0].Target, nil
+	}
+	return controls
+}
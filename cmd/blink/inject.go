@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/byteorem/blink/internal/savedvars"
+	"github.com/byteorem/blink/internal/wowproc"
+	"github.com/urfave/cli/v2"
+)
+
+func injectCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "inject",
+		Usage: "Put the WeakAuras export strings listed under [inject] into the client's SavedVariables, replacing auras with the same name",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "while-running",
+				Usage: "Inject even while WoW is running, which writes SavedVariables back over them at logout",
+			},
+		},
+		Action: runInject,
+	}
+}
+
+func runInject(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Inject.WeakAuras) == 0 {
+		return errors.New("nothing to inject — list WeakAuras export files under [inject] in blink.toml, e.g. weakAuras = [\"auras/*.txt\"]")
+	}
+	exports, err := readExports(cfg.Inject.WeakAuras)
+	if err != nil {
+		return err
+	}
+	wowPath, err := resolveWowPath(cfg, c.StringSlice("flavor"), c.Bool("any-path"))
+	if err != nil {
+		return err
+	}
+	dir, err := savedVariablesDir(wowPath, cfg.Inject.Account)
+	if err != nil {
+		return err
+	}
+
+	// The client writes SavedVariables back at logout and on /reload,
+	// over anything put there while it runs.
+	if running, err := wowproc.Running(c.Context); err == nil && running && !c.Bool("while-running") {
+		return errors.New("WoW is running and would overwrite the injected auras when you log out — exit the game first (or pass --while-running)")
+	}
+
+	path := filepath.Join(dir, savedvars.WeakAurasFile)
+	_, statErr := os.Stat(path)
+	ids, err := savedvars.InjectWeakAuras(path, exports)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Injected %d aura(s) into %s: %s\n", len(ids), path, strings.Join(ids, ", "))
+	if statErr == nil {
+		fmt.Printf("The previous file is in %s\n", path+savedvars.BackupSuffix)
+	}
+	return nil
+}
+
+// readExports returns the export strings in the files matching patterns, in
+// file name order.
+func readExports(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("inject: %w", err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("inject: no files match %q", pattern)
+		}
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+	files = slices.Compact(files)
+
+	var exports []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if s := strings.TrimSpace(string(data)); s != "" {
+			exports = append(exports, s)
+		}
+	}
+	return exports, nil
+}
+
+// savedVariablesDir returns the account's SavedVariables folder in the
+// client at wowPath. Without an account, the only one there is used.
+func savedVariablesDir(wowPath, account string) (string, error) {
+	root := filepath.Join(wowPath, "WTF", "Account")
+	if account == "" {
+		entries, err := os.ReadDir(root)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		var accounts []string
+		for _, e := range entries {
+			if e.IsDir() && e.Name() != "SavedVariables" {
+				accounts = append(accounts, e.Name())
+			}
+		}
+		switch len(accounts) {
+		case 0:
+			return "", fmt.Errorf("no accounts in %s — log in with this client once first", root)
+		case 1:
+			account = accounts[0]
+		default:
+			return "", fmt.Errorf("%s has several accounts (%s) — pick one with account under [inject] in blink.toml", root, strings.Join(accounts, ", "))
+		}
+	}
+	dir := filepath.Join(root, account, "SavedVariables")
+	if _, err := os.Stat(filepath.Dir(dir)); err != nil {
+		return "", fmt.Errorf("account %q not found in %s", account, root)
+	}
+	return dir, os.MkdirAll(dir, 0o755)
+}
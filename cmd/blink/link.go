@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/docker"
+	"github.com/byteorem/blink/internal/workspace"
+	"github.com/urfave/cli/v2"
+)
+
+func linkCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "link",
+		Usage: "Link the addon folder in Interface/AddOns to the source (a symlink, or a junction on Windows) instead of copying, so nothing needs watching",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "replace-unmarked",
+				Usage: "Also replace folders blink didn't create (no " + copier.MarkerFile + " marker)",
+			},
+		},
+		Action: runLink,
+	}
+}
+
+func unlinkCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "unlink",
+		Usage:  "Remove the links blink link made, leaving the source as it is",
+		Action: runUnlink,
+	}
+}
+
+// linkAddons returns the addons to link, in every client of targets or in
+// the one of wowPath.
+func linkAddons(c *cli.Context, cfg config.Config) ([]*workspace.Addon, error) {
+	if docker.IsURL(cfg.WowPath) {
+		return nil, errors.New("addons can't be linked into a container — sync them instead")
+	}
+	var dirs []string
+	if len(cfg.Targets) > 0 {
+		var err error
+		if dirs, err = resolveTargets(cfg, c.StringSlice("flavor"), c.Bool("any-path")); err != nil {
+			return nil, err
+		}
+	} else {
+		wowPath, err := resolveWowPath(cfg, c.StringSlice("flavor"), c.Bool("any-path"))
+		if err != nil {
+			return nil, err
+		}
+		dirs = []string{wowPath}
+	}
+
+	var addons []*workspace.Addon
+	for _, dir := range dirs {
+		found, err := resolveAddons(cfg, filepath.Join(dir, "Interface", "AddOns"), "")
+		if err != nil {
+			return nil, err
+		}
+		if found, err = workspace.Filter(found, c.StringSlice("addon")); err != nil {
+			return nil, err
+		}
+		addons = append(addons, found...)
+	}
+	return addons, nil
+}
+
+func runLink(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	addons, err := linkAddons(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Check every addon before touching any folder.
+	var todo []*workspace.Addon
+	for _, a := range addons {
+		switch {
+		case len(a.Sources) > 1:
+			return fmt.Errorf("%s overlays several sources, which a link can't — sync it instead", a.Name)
+		case a.Template != "":
+			return fmt.Errorf("%s generates its .toc files from %s, which a link can't — sync it instead", a.Name, a.Template)
+		case copier.LinksTo(a.Target, a.Dir()):
+			fmt.Printf("%s is already linked\n", a.Target)
+			continue
+		}
+		if target, ok := copier.LinkTarget(a.Target); ok {
+			return fmt.Errorf("%s already links to %s — remove it first", a.Target, target)
+		}
+		if _, err := os.Lstat(a.Target); err == nil && !copier.HasMarker(a.Target) && !c.Bool("replace-unmarked") {
+			return fmt.Errorf("%s was not created by blink (no %s marker) — use --replace-unmarked to replace it anyway", a.Target, copier.MarkerFile)
+		}
+		todo = append(todo, a)
+	}
+
+	for _, a := range todo {
+		addOnsDir := filepath.Dir(a.Target)
+		if err := os.RemoveAll(a.Target); err != nil {
+			return withAccessHint(fmt.Errorf("removing the copy in %s failed: %w", a.Target, err), addOnsDir)
+		}
+		if err := copier.Link(a.Dir(), a.Target); err != nil {
+			return withAccessHint(fmt.Errorf("linking %s failed: %w", a.Name, err), addOnsDir)
+		}
+		recordTarget(a.Target, sourceDirs(a))
+		fmt.Printf("Linked %s to %s\n", a.Target, a.Dir())
+	}
+	if len(todo) > 0 && (len(cfg.FlavorFiles) > 0 || cfg.Header.Text != "" || cfg.Header.File != "" || cfg.BuildInfo || cfg.Provenance) {
+		fmt.Println("note: linked folders hold the source as it is — flavorFiles, directives, the license header and build info don't apply to them")
+	}
+	return nil
+}
+
+func runUnlink(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	addons, err := linkAddons(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	var removed []string
+	for _, a := range addons {
+		if !copier.LinksTo(a.Target, a.Dir()) {
+			continue
+		}
+		// Removing the link itself never touches the files it links to.
+		if err := os.Remove(a.Target); err != nil {
+			return withAccessHint(fmt.Errorf("unlinking %s failed: %w", a.Target, err), filepath.Dir(a.Target))
+		}
+		removed = append(removed, a.Target)
+		fmt.Printf("Unlinked %s\n", a.Target)
+	}
+	forgetTargets(removed)
+	if len(removed) == 0 {
+		fmt.Println("Nothing to unlink")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/byteorem/blink/internal/budget"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/ghactions"
+	"github.com/byteorem/blink/internal/lint"
+	"github.com/urfave/cli/v2"
+)
+
+func lintCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "lint",
+		Usage:  "Check every synced Lua file for syntax errors (and selene diagnostics when enabled)",
+		Action: runLint,
+	}
+}
+
+func runLint(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	srcDirs, _, err := findAddon(cfg)
+	if err != nil {
+		return err
+	}
+	srcDir := srcDirs[0]
+
+	ig := copier.NewIgnorer(srcDir, cfg.IgnorePatterns(""), cfg.UseGitignore, cfg.UsePkgMeta, cfg.UseGitattributes)
+	ig.FollowSymlinks = cfg.FollowSymlinks
+	files, err := copier.ListFiles(srcDir, ig)
+	if err != nil {
+		return fmt.Errorf("listing files failed: %w", err)
+	}
+
+	var luaFiles []string
+	errorCount := 0
+	endGroup := ciGroup(c, "Syntax")
+	for _, rel := range files {
+		if !lint.IsLua(rel) {
+			continue
+		}
+		luaFiles = append(luaFiles, rel)
+		if err := lint.CheckSyntax(filepath.Join(srcDir, rel)); err != nil {
+			fmt.Printf("%s: %v\n", rel, err)
+			errorCount++
+			a := ghactions.Annotation{Level: "error", File: filepath.Join(srcDir, rel), Message: err.Error()}
+			var se *lint.SyntaxError
+			if errors.As(err, &se) {
+				a.Line, a.Column = se.Line, se.Column
+			}
+			ciAnnotate(c, a)
+		}
+	}
+	endGroup()
+
+	warningCount := 0
+	limits, err := cfg.Budget.Limits()
+	if err != nil {
+		return err
+	}
+	over, err := budget.Check([]copier.Source{{Dir: srcDir, Ignorer: ig}}, limits)
+	if err != nil {
+		return err
+	}
+	for _, w := range over {
+		fmt.Println("budget:", w)
+		warningCount++
+		ciAnnotate(c, ghactions.Annotation{Level: "warning", Message: "budget: " + w})
+	}
+
+	if cfg.Selene.Enabled {
+		endGroup := ciGroup(c, "selene")
+		s := &lint.Selene{Command: cfg.Selene.Command, Std: cfg.Selene.Std, Dir: srcDir}
+		diags, err := s.Run(c.Context, luaFiles...)
+		if err != nil {
+			endGroup()
+			return err
+		}
+		for _, d := range diags {
+			fmt.Println(d)
+			if d.Severity == lint.SeverityError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+			file := d.File
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(srcDir, file)
+			}
+			ciAnnotate(c, ghactions.Annotation{Level: d.Severity.String(), File: file, Line: d.Line, Column: d.Column, Message: fmt.Sprintf("[%s] %s", d.Code, d.Message)})
+		}
+		endGroup()
+	}
+
+	fmt.Printf("Checked %d Lua files: %d error(s), %d warning(s)\n", len(luaFiles), errorCount, warningCount)
+	if errorCount > 0 {
+		return cli.Exit("", exitFailed)
+	}
+	if warningCount > 0 && c.Bool("ci") {
+		return cli.Exit("", exitWarnings)
+	}
+	return nil
+}
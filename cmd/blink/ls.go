@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/flavor"
+	"github.com/byteorem/blink/internal/workspace"
+	"github.com/urfave/cli/v2"
+)
+
+func lsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ls",
+		Usage: "List the files blink would sync",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "stats",
+				Usage: "Show how many files and folders each ignore pattern excluded instead, to spot patterns that match nothing or too much",
+			},
+		},
+		Action: runLs,
+	}
+}
+
+func runLs(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	// Files of other flavors are left out only when listing for one flavor.
+	var targetFlavor string
+	if only := c.StringSlice("flavor"); len(only) == 1 {
+		f, ok := flavor.Lookup(only[0])
+		if !ok {
+			return fmt.Errorf("unknown flavor %q in --flavor", only[0])
+		}
+		targetFlavor = f.Name
+	}
+
+	addons, err := resolveAddons(cfg, "", targetFlavor)
+	if err != nil {
+		return err
+	}
+	if addons, err = workspace.Filter(addons, c.StringSlice("addon")); err != nil {
+		return err
+	}
+
+	for _, a := range addons {
+		for _, src := range a.Sources {
+			files, err := copier.ListFiles(src.Dir, src.Ignorer)
+			if err != nil {
+				return fmt.Errorf("listing files failed: %w", err)
+			}
+			if c.Bool("stats") {
+				printIgnoreStats(src, len(files))
+				continue
+			}
+			for _, rel := range files {
+				if len(addons) > 1 {
+					rel = filepath.Join(a.Name, rel)
+				}
+				fmt.Println(filepath.ToSlash(rel))
+			}
+		}
+	}
+	return nil
+}
+
+// printIgnoreStats prints how many paths each ignore pattern of src kept
+// out of a listing of synced files.
+func printIgnoreStats(src copier.Source, synced int) {
+	fmt.Printf("%s: %d file(s) synced\n", src.Dir, synced)
+	for _, st := range src.Ignorer.Stats() {
+		note := ""
+		if st.Excluded == 0 && st.Origin != copier.OriginBuiltin {
+			note = "  (matches nothing)"
+		}
+		fmt.Printf("  %6d  %-14s %s%s\n", st.Excluded, st.Origin, st.Pattern, note)
+	}
+	fmt.Println()
+}
@@ -3,19 +3,40 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/byteorem/blink/internal/budget"
 	"github.com/byteorem/blink/internal/config"
 	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/crash"
 	"github.com/byteorem/blink/internal/detect"
+	"github.com/byteorem/blink/internal/docker"
+	"github.com/byteorem/blink/internal/flavor"
+	"github.com/byteorem/blink/internal/ghactions"
+	"github.com/byteorem/blink/internal/gitinfo"
+	"github.com/byteorem/blink/internal/i18n"
+	"github.com/byteorem/blink/internal/lint"
+	"github.com/byteorem/blink/internal/logging"
+	"github.com/byteorem/blink/internal/sdnotify"
+	"github.com/byteorem/blink/internal/state"
+	"github.com/byteorem/blink/internal/status"
+	"github.com/byteorem/blink/internal/sync"
+	"github.com/byteorem/blink/internal/telemetry"
+	"github.com/byteorem/blink/internal/toc"
+	"github.com/byteorem/blink/internal/transform"
 	"github.com/byteorem/blink/internal/ui"
+	"github.com/byteorem/blink/internal/update"
 	"github.com/byteorem/blink/internal/watcher"
+	"github.com/byteorem/blink/internal/workspace"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
@@ -23,7 +44,15 @@ import (
 
 var version = "dev"
 
+// telemetryEndpoint receives the reports of users who ran `blink telemetry
+// enable`. It is set at release build time with -ldflags; builds without
+// one never send anything.
+var telemetryEndpoint = ""
+
 func main() {
+	defer crash.Recover()
+	crash.SetVersion(version)
+
 	app := &cli.App{
 		Name:    "blink",
 		Usage:   "Hot-reload for WoW addon development",
@@ -41,7 +70,7 @@ func main() {
 			},
 			&cli.BoolFlag{
 				Name:  "no-watch",
-				Usage: "One-time copy, don't watch for changes",
+				Usage: "One-time copy, don't watch for changes (same as blink sync)",
 			},
 			&cli.IntFlag{
 				Name:    "delay",
@@ -52,139 +81,1267 @@ func main() {
 				Name:  "verbose",
 				Usage: "Enable verbose logging",
 			},
+			&cli.StringSliceFlag{
+				Name:  "addon",
+				Usage: "When several addons are synced, only sync these, e.g. --addon MyAddon,MyAddon_Options",
+			},
+			&cli.StringSliceFlag{
+				Name:    "flavor",
+				Aliases: []string{"game-version"},
+				Usage:   "Only sync to these WoW flavors, e.g. --flavor retail,classic_era (syncs default to flavor in blink.toml)",
+			},
+			&cli.BoolFlag{
+				Name:  "fix",
+				Usage: "Name the addon folder so every .toc file loads, when the .toc names don't match it",
+			},
+			&cli.StringFlag{
+				Name:  "log-level",
+				Usage: "Log records at this level and above: debug, info, warn or error (default: info, debug with --verbose)",
+			},
+			&cli.BoolFlag{
+				Name:  "plain",
+				Usage: "Print timestamped lines instead of the interactive TUI, even on a terminal (e.g. for screen readers)",
+			},
+			&cli.BoolFlag{
+				Name:  "ci",
+				Usage: "Run for CI pipelines such as GitHub Actions: plain output, no watching, errors and warnings as annotations, and exit code 2 on warnings",
+			},
+			&cli.StringFlag{
+				Name:  "log-file",
+				Usage: "Append all output to this file instead of the terminal",
+			},
+			&cli.BoolFlag{
+				Name:  "low-power",
+				Usage: "Save battery: slower spinner, longer debounce, fewer background checks",
+			},
+			&cli.DurationFlag{
+				Name:  "until",
+				Usage: "Stop watching after this long, e.g. 2h",
+			},
+			&cli.BoolFlag{
+				Name:  "until-wow-exits",
+				Usage: "Stop watching once the WoW client exits (waiting for it to start if it isn't running)",
+			},
+			&cli.BoolFlag{
+				Name:  "any-path",
+				Usage: "Sync even when --wow-path doesn't look like a WoW client folder",
+			},
+			&cli.BoolFlag{
+				Name:  "create-target",
+				Usage: "Create the client's Interface/AddOns folder without asking when it doesn't exist yet",
+			},
+			&cli.StringFlag{
+				Name:  "events",
+				Usage: "Where changes come from: native (the OS's file watching) or stdin (paths written to standard input by an editor or build tool)",
+				Value: "native",
+			},
+			&cli.IntFlag{
+				Name:  "pprof",
+				Usage: "Serve runtime profiles on this localhost port and show memory stats in the TUI",
+			},
+			&cli.StringFlag{
+				Name:  "config",
+				Usage: "Use this config file (or the blink.toml in this folder) and run from its folder, instead of ./blink.toml",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			ciMode = c.Bool("ci")
+			if err := redirectOutput(c); err != nil {
+				return err
+			}
+			return useConfigDir(c)
 		},
 		Action: run,
+		Commands: []*cli.Command{
+			watchCommand(),
+			syncCommand(),
+			cleanCommand(),
+			uninstallCommand(),
+			pruneCommand(),
+			packageCommand(),
+			publishCommand(),
+			snapshotCommand(),
+			lsCommand(),
+			auditCommand(),
+			linkCommand(),
+			unlinkCommand(),
+			lintCommand(),
+			testCommand(),
+			annotateCommand(),
+			tocCommand(),
+			serviceCommand(),
+			telemetryCommand(),
+			configCommand(),
+			sandboxCommand(),
+			recordCommand(),
+			replayCommand(),
+			injectCommand(),
+		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, i18n.Tf("error: %v", err))
+		if ciMode {
+			fmt.Println(ghactions.Annotation{Level: "error", Message: err.Error()})
+		}
+		os.Exit(exitFailed)
 	}
 }
 
-func run(c *cli.Context) error {
-	cfg, err := config.Load()
+// programOptions returns the options of the TUI programs of a watch session.
+// With stdinEvents, keys are read from the terminal, as standard input
+// carries the changes.
+func programOptions(stdinEvents bool) []tea.ProgramOption {
+	opts := []tea.ProgramOption{tea.WithoutCatchPanics()}
+	if stdinEvents {
+		opts = append(opts, tea.WithInputTTY())
+	}
+	return opts
+}
+
+// runTUI runs a TUI program made for a ui.Guard model, giving the terminal
+// back first if a panic ends it in a crash report.
+func runTUI(p *tea.Program) error {
+	crash.OnCrash(func() { _ = p.ReleaseTerminal() })
+	defer crash.OnCrash(nil)
+	_, err := p.Run()
+	return err
+}
+
+// redirectOutput sends all output to the --log-file, if one is given. Output
+// then isn't a terminal, so blink runs in plain text mode.
+func redirectOutput(c *cli.Context) error {
+	path := c.String("log-file")
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("can't open log file: %w", err)
+	}
+	os.Stdout = f
+	os.Stderr = f
+	logging.SetOutput(f)
+	return nil
+}
+
+// startDir is the directory blink was started in, before useConfigDir moved
+// to the project folder.
+var startDir string
+
+// useConfigDir makes the folder of the config file the working directory, so
+// paths in the config and addon detection work as if blink had been started
+// there. The config file is --config, or else the nearest blink.toml in or
+// above the current directory. A relative --source is kept pointing where the
+// user meant.
+func useConfigDir(c *cli.Context) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	startDir = wd
+
+	path := c.String("config")
+	if path == "" {
+		found, ok := config.Find(wd)
+		if !ok || filepath.Dir(found) == wd {
+			return nil
+		}
+		path = found
+	}
+	path, err = filepath.Abs(path)
 	if err != nil {
 		return err
 	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "blink.toml")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("can't read config file: %w", err)
+	}
+	if src := c.String("source"); src != "" && src != "auto" && !filepath.IsAbs(src) {
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return err
+		}
+		if err := c.Set("source", abs); err != nil {
+			return err
+		}
+	}
+	if err := c.Set("config", path); err != nil {
+		return err
+	}
+	return os.Chdir(filepath.Dir(path))
+}
 
+// userPath resolves a path given on the command line against the directory
+// blink was started in.
+func userPath(path string) string {
+	if filepath.IsAbs(path) || startDir == "" {
+		return path
+	}
+	return filepath.Join(startDir, path)
+}
+
+// configFile returns the config file to read: --config, or ./blink.toml.
+func configFile(c *cli.Context) string {
+	if path := c.String("config"); path != "" {
+		return path
+	}
+	return "blink.toml"
+}
+
+// recordTarget adds a synced folder to the state store, so blink uninstall
+// can find it later. Failing to record is not worth stopping a sync for.
+func recordTarget(target string, srcDirs []string) {
+	err := state.Update(func(st *state.Store) { st.Record(target, srcDirs) })
+	if err != nil {
+		slog.Warn(fmt.Sprintf("can't record %s in blink's state", target), "err", err)
+	}
+}
+
+// recordPack adds a UI pack folder to the state store, so blink uninstall can
+// put back the client files it replaced.
+func recordPack(a *workspace.Addon) {
+	err := state.Update(func(st *state.Store) { st.RecordPack(a.Target, sourceDirs(a)) })
+	if err != nil {
+		slog.Warn(fmt.Sprintf("can't record %s in blink's state", a.Target), "err", err)
+	}
+}
+
+// claimTarget removes stale files from the target of a and marks it as
+// synced from a's sources.
+func claimTarget(a *workspace.Addon, addOnsDir string) error {
+	if target, ok := copier.LinkTarget(a.Target); ok {
+		return fmt.Errorf("%s links to %s (blink link) — run blink unlink to sync copies instead", a.Target, target)
+	}
+	cleaned, err := copier.CleanDestinationSources(a.Sources, a.Target)
+	if err != nil {
+		return withAccessHint(fmt.Errorf("cleanup failed: %w", err), addOnsDir)
+	}
+	if cleaned > 0 {
+		fmt.Println(i18n.Tf("Removed %d stale file(s) from %s", cleaned, a.Target))
+	}
+
+	srcDirs := sourceDirs(a)
+	if err := copier.WriteMarker(a.Target, srcDirs); err != nil {
+		return withAccessHint(fmt.Errorf("marking %s failed: %w", a.Target, err), addOnsDir)
+	}
+	recordTarget(a.Target, srcDirs)
+	return nil
+}
+
+// sourceDirs returns the directories of an addon's sources.
+func sourceDirs(a *workspace.Addon) []string {
+	dirs := make([]string, len(a.Sources))
+	for i, src := range a.Sources {
+		dirs[i] = src.Dir
+	}
+	return dirs
+}
+
+// loadConfig reads blink.toml (or --config) and applies command-line overrides.
+func loadConfig(c *cli.Context) (config.Config, error) {
+	cfg, err := config.LoadFile(configFile(c))
+	if err != nil {
+		return cfg, err
+	}
 	config.MergeFlags(&cfg, c.String("source"), c.String("wow-path"), c.Int("delay"), c.Bool("verbose"))
+	flavor.SetCustom(cfg.Flavors)
+	if c.Bool("low-power") {
+		cfg.LowPower = true
+	}
+	if cfg.LowPower {
+		cfg.Delay = max(cfg.Delay, config.LowPowerDelay)
+	}
+	if c.IsSet("log-level") {
+		cfg.LogLevel = c.String("log-level")
+	}
+	level, err := logging.ParseLevel(cfg.LogLevelName())
+	if err != nil {
+		return cfg, err
+	}
+	logging.Setup(level)
+	detectWorkspace(&cfg)
+	locale := cfg.Locale
+	if locale == "" {
+		locale = i18n.Detect(os.Getenv)
+	}
+	if err := i18n.Set(locale); err != nil {
+		return cfg, err
+	}
+	ui.SetTheme(cfg.Theme)
+	crash.SetConfig(cfg)
+	return cfg, nil
+}
+
+// watchOptions returns how to watch files under cfg.
+func watchOptions(cfg config.Config) watcher.Options {
+	opts := watcher.Options{Delay: watcher.NewDelay(cfg.Delay), Idle: cfg.IdleDuration()}
+	if cfg.LowPower {
+		opts.Audit = 10 * watcher.AuditInterval
+	}
+	return opts
+}
+
+// copyTransform returns the configured rewrites of copied files that don't
+// depend on the target (whitespace trimming, license header), or nil when
+// there are none.
+func copyTransform(cfg config.Config) (transform.Func, error) {
+	banner, err := cfg.Header.Banner()
+	if err != nil {
+		return nil, fmt.Errorf("blink.toml: %w", err)
+	}
+	return transform.Chain(transform.TrimWhitespace(cfg.TrimWhitespace), transform.Header(banner)), nil
+}
+
+// targetTransform returns how to tailor copied files to a client of flavor
+// fl, the zero Flavor when the target's flavor is unknown.
+func targetTransform(cfg config.Config, fl flavor.Flavor) (transform.Func, error) {
+	var tf transform.Func
+	if fl.Name != "" && !fl.Custom {
+		tf = transform.Directives(fl)
+	}
+	rewrite, err := copyTransform(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return transform.Chain(tf, rewrite), nil
+}
 
-	if cfg.Verbose {
-		log.Printf("[verbose] config: source=%q wowPath=%q delay=%dms gitignore=%v pkgmeta=%v ignore=%v",
-			cfg.Source, cfg.WowPath, cfg.Delay, cfg.UseGitignore, cfg.UsePkgMeta, cfg.Ignore)
+// sourceTransform returns how to rewrite the files of the source directory
+// dir after the target's transform: with provenance, they note the commit of
+// the checkout dir is in.
+func sourceTransform(cfg config.Config, dir string) transform.Func {
+	if !cfg.Provenance {
+		return nil
 	}
+	head := gitinfo.NewCache(dir, 2*time.Second)
+	return transform.Provenance(func() string { return head.Get().Commit }, time.Now)
+}
+
+// sourceIgnorer returns the ignorer of a source directory synced to a client
+// of targetFlavor.
+func sourceIgnorer(cfg config.Config, dir, targetFlavor string) *copier.Ignorer {
+	ig := copier.NewIgnorer(dir, cfg.IgnorePatterns(targetFlavor), cfg.UseGitignore, cfg.UsePkgMeta, cfg.UseGitattributes)
+	ig.FollowSymlinks = cfg.FollowSymlinks
+	return ig
+}
 
+// siblingAddons are the addon folders detectWorkspace found side by side,
+// for pickAddon to offer.
+var siblingAddons []string
+
+// detectWorkspace turns on workspace mode when the source is auto-detected
+// and the working directory has no .toc file of its own but several addon
+// folders side by side, so each of them is synced.
+func detectWorkspace(cfg *config.Config) {
+	if cfg.Source != "auto" || cfg.MultiAddon() {
+		return
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	if tocs, _ := detect.TocFiles(root); len(tocs) > 0 {
+		return
+	}
+	if dirs := detect.SiblingAddons(root); len(dirs) > 1 {
+		slog.Debug("several addons found, syncing them as a workspace", "root", root, "addons", len(dirs))
+		cfg.Workspace.Enabled = true
+		siblingAddons = dirs
+	}
+}
+
+// findAddon resolves the configured source directories and the addon name.
+// The addon's own source (the one with its .toc) comes first.
+func findAddon(cfg config.Config) ([]string, string, error) {
+	if cfg.SourceIsGlob() {
+		return nil, "", fmt.Errorf("source %q is a pattern for several addons — pick one with --source", cfg.Source)
+	}
+	if len(cfg.Sources) > 1 {
+		return detect.FindAddonSources(cfg.Sources)
+	}
 	srcDir, addonName, err := detect.FindAddon(cfg.Source)
 	if err != nil {
-		return err
+		return nil, "", err
+	}
+	return []string{srcDir}, addonName, nil
+}
+
+// resolveAddons returns the addons to sync: every addon below the working
+// directory in workspace mode, otherwise the single configured addon.
+func resolveAddons(cfg config.Config, addOnsDir, targetFlavor string) ([]*workspace.Addon, error) {
+	sourcesFor := func(dirs []string) []copier.Source {
+		sources := make([]copier.Source, len(dirs))
+		for i, dir := range dirs {
+			sources[i] = copier.Source{Dir: dir, Ignorer: sourceIgnorer(cfg, dir, targetFlavor), Transform: sourceTransform(cfg, dir)}
+		}
+		return sources
+	}
+
+	if !cfg.MultiAddon() {
+		srcDirs, addonName, err := findAddon(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return []*workspace.Addon{{
+			Name:     addonName,
+			Sources:  sourcesFor(srcDirs),
+			Target:   filepath.Join(addOnsDir, addonName),
+			Template: cfg.Toc.Template,
+		}}, nil
+	}
+
+	members, err := workspaceMembers(cfg)
+	if err != nil {
+		return nil, err
 	}
 
+	var addons []*workspace.Addon
+	for _, m := range members {
+		a := &workspace.Addon{Name: m.Name, Sources: sourcesFor([]string{m.Dir}), Target: filepath.Join(addOnsDir, m.Name)}
+		// The shared template only applies to the addons that have one.
+		if cfg.Toc.Template != "" {
+			if _, err := os.Stat(filepath.Join(m.Dir, cfg.Toc.Template)); err == nil {
+				a.Template = cfg.Toc.Template
+			}
+		}
+		addons = append(addons, a)
+	}
+	if err := workspace.CheckTargets(addons); err != nil {
+		return nil, err
+	}
+	return addons, nil
+}
+
+// workspaceMembers returns the addons below the working directory: those
+// matching the configured patterns, or else every addon found there.
+func workspaceMembers(cfg config.Config) ([]workspace.Member, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if patterns := cfg.AddonPatterns(); len(patterns) > 0 {
+		return workspace.Glob(root, patterns)
+	}
+	members, err := workspace.Discover(root, copier.NewIgnorer(root, cfg.Ignore, cfg.UseGitignore, false, false))
+	if err != nil {
+		return nil, fmt.Errorf("discovering workspace addons failed: %w", err)
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no addons found below %s — each addon needs its own .toc file", root)
+	}
+	return members, nil
+}
+
+// resolveWowPath returns the WoW client folder to sync to. wowPath may also be
+// the WoW install itself, with a folder per client; the client is then picked
+// from the flavors the addon's .toc files are made for, retail first. A
+// non-empty only (--flavor), or else the config's flavor, limits the flavors
+// that may be picked; when only picks several clients, the command is refused,
+// as it works on one. Unless anyPath is set, a folder that doesn't look like a
+// client is refused.
+func resolveWowPath(cfg config.Config, only []string, anyPath bool) (string, error) {
+	dirs, err := clientDirs(cfg, only, anyPath)
+	if err != nil {
+		return "", err
+	}
+	if len(only) > 1 && len(dirs) > 1 {
+		return "", fmt.Errorf("--flavor picks several clients (%s), but this command works on one — give one flavor", strings.Join(dirs, ", "))
+	}
+	noteOthers(dirs)
+	return dirs[0], nil
+}
+
+// resolveWowPaths is resolveWowPath for syncs, which can go to several
+// clients: when only (--flavor) names more than one flavor, every client of
+// them the addon supports is returned, the first to take wowPath's place.
+func resolveWowPaths(cfg config.Config, only []string, anyPath bool) ([]string, error) {
+	dirs, err := clientDirs(cfg, only, anyPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(only) > 1 {
+		return dirs, nil
+	}
+	noteOthers(dirs)
+	return dirs[:1], nil
+}
+
+// noteOthers tells about the clients besides the first that could be synced
+// to.
+func noteOthers(dirs []string) {
+	if len(dirs) < 2 {
+		return
+	}
+	others := make([]string, len(dirs)-1)
+	for i, d := range dirs[1:] {
+		others[i] = filepath.Base(d)
+	}
+	fmt.Fprintln(os.Stderr, i18n.Tf("note: syncing to %s; the addon also supports %s (pick with --flavor)", filepath.Base(dirs[0]), strings.Join(others, ", ")))
+}
+
+// clientDirs returns the client folders resolveWowPath may pick from, best
+// first: wowPath alone when it is a client.
+func clientDirs(cfg config.Config, only []string, anyPath bool) ([]string, error) {
 	wowPath, err := detect.FindWowPath(cfg.WowPath)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := allowedFlavors(only)
+	if err != nil {
+		return nil, err
+	}
+
+	if fl, ok := detect.ClientFlavor(wowPath); ok || len(detect.InstalledFlavors(wowPath)) == 0 {
+		// A folder whose flavor can't be told is taken at its word.
+		if ok && len(allowed) > 0 && !allowed[fl.Name] {
+			return nil, fmt.Errorf("%s is not a client of the flavors given with --flavor", wowPath)
+		}
+		if !anyPath && !detect.IsClientDir(wowPath) {
+			return nil, fmt.Errorf("%s doesn't look like a WoW client folder (no Interface, WTF or Wow.exe in it) — "+
+				"point wowPath at a version folder like .../World of Warcraft/_retail_, or pass --any-path to sync there anyway", wowPath)
+		}
+		return []string{wowPath}, nil
+	}
+
+	var dirs []string
+	if cfg.MultiAddon() {
+		members, err := workspaceMembers(cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			dirs = append(dirs, m.Dir)
+		}
+	} else {
+		srcDirs, _, err := findAddon(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dirs = srcDirs[:1]
+	}
+
+	if len(only) == 0 && cfg.Flavor != "" {
+		if allowed, err = allowedFlavors([]string{cfg.Flavor}); err != nil {
+			return nil, err
+		}
+	}
+	var clients []string
+	for _, f := range detect.PickFlavors(wowPath, dirs...) {
+		if len(allowed) == 0 || allowed[f.Name] {
+			clients = append(clients, filepath.Join(wowPath, f.Dir))
+		}
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("%s has none of the WoW clients the addon's .toc files (and --flavor) allow", wowPath)
+	}
+	return clients, nil
+}
+
+// allowedFlavors returns the names of the flavors given with --flavor.
+func allowedFlavors(only []string) (map[string]bool, error) {
+	allowed := make(map[string]bool, len(only))
+	for _, name := range only {
+		f, ok := flavor.Lookup(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown flavor %q in --flavor", name)
+		}
+		allowed[f.Name] = true
+	}
+	return allowed, nil
+}
+
+// resolveTargets returns the client folders of cfg.Targets to sync to, the
+// first taking wowPath's place. A flavor name ("classic_era") or a folder
+// name ("_ptr_") is a client in the WoW install wowPath is or is in; other
+// entries are paths. A non-empty only (--flavor) limits the clients to those
+// flavors. Unless anyPath is set, a folder that doesn't look like a client is
+// refused.
+func resolveTargets(cfg config.Config, only []string, anyPath bool) ([]string, error) {
+	allowed, err := allowedFlavors(only)
+	if err != nil {
+		return nil, err
+	}
+	var install string
+	var dirs []string
+	for _, t := range cfg.Targets {
+		dir := filepath.FromSlash(strings.TrimSpace(t))
+		if !filepath.IsAbs(dir) && !strings.ContainsRune(dir, filepath.Separator) {
+			if install == "" {
+				if install, err = detect.FindWowPath(cfg.WowPath); err != nil {
+					return nil, fmt.Errorf("targets: %w", err)
+				}
+				if _, ok := flavor.FromDir(filepath.Base(install)); ok || detect.IsClientDir(install) {
+					install = filepath.Dir(install)
+				}
+			}
+			if f, ok := flavor.Lookup(dir); ok {
+				dir = f.Dir
+			}
+			dir = filepath.Join(install, dir)
+		}
+		if fl, ok := detect.ClientFlavor(dir); len(allowed) > 0 && (!ok || !allowed[fl.Name]) {
+			continue
+		}
+		if slices.Contains(dirs, dir) {
+			continue
+		}
+		if !anyPath && !detect.IsClientDir(dir) {
+			return nil, fmt.Errorf("target %s doesn't look like a WoW client folder (no Interface, WTF or Wow.exe in it) — "+
+				"fix targets in blink.toml, or pass --any-path to sync there anyway", dir)
+		}
+		dirs = append(dirs, dir)
+	}
+	if len(dirs) == 0 {
+		return nil, errors.New("none of the targets are clients of the flavors given with --flavor")
+	}
+	return dirs, nil
+}
+
+// ensureAddOnsDir creates the client's AddOns folder, which a client that
+// never had addons lacks, asking first unless create (--create-target) is set.
+func ensureAddOnsDir(dir string, create bool) error {
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return nil
+	}
+	if !create {
+		if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("%s doesn't exist yet (no addons were ever installed there) — pass --create-target to create it", dir)
+		}
+		ok, err := confirm(i18n.Tf("%s doesn't exist yet. Create it?", dir))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return cli.Exit("", 1)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return withAccessHint(err, dir)
+	}
+	fmt.Println(i18n.Tf("Created %s", dir))
+	return nil
+}
+
+// dockerMirror sets up syncing into the container folder that wowPath names,
+// through a local copy kept in blink's state directory.
+func dockerMirror(ctx context.Context, cfg config.Config) (*docker.Mirror, error) {
+	t, err := docker.Parse(cfg.WowPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TwoWay {
+		return nil, fmt.Errorf("twoWay doesn't work with a %s wowPath: edits in the container can't be watched", docker.Scheme)
+	}
+	dir, err := state.Dir()
+	if err != nil {
+		return nil, err
+	}
+	local := filepath.Join(dir, "docker", t.Container, filepath.FromSlash(strings.TrimPrefix(t.Dir, "/")))
+	if err := os.MkdirAll(local, 0o755); err != nil {
+		return nil, err
+	}
+	m := docker.NewMirror(t, local)
+	if err := m.Check(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// run is the action of bare blink: watch, or sync once with --no-watch.
+func run(c *cli.Context) error {
+	return start(c, !c.Bool("no-watch"))
+}
+
+func watchCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "watch",
+		Usage: "Sync the addon and keep it in sync as files change (the default)",
+		Action: func(c *cli.Context) error {
+			return start(c, true)
+		},
+	}
+}
+
+func syncCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "Sync the addon once and exit",
+		Action: func(c *cli.Context) error {
+			return start(c, false)
+		},
+	}
+}
+
+// start syncs the configured addons to the WoW install and, if watch is
+// set, keeps them in sync until interrupted.
+func start(c *cli.Context, watch bool) error {
+	// A CI step has to end: --ci syncs once, whichever command asked to
+	// watch.
+	watch = watch && !c.Bool("ci")
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	if err := pickAddon(c, &cfg); err != nil {
+		return err
+	}
+
+	var stdinEvents bool
+	switch c.String("events") {
+	case "native":
+	case "stdin":
+		stdinEvents = true
+	default:
+		return fmt.Errorf("unknown --events %q: use native or stdin", c.String("events"))
+	}
+
+	if !watch {
+		defer ciGroup(c, "blink sync")()
+	}
+
+	slog.Debug("config", "source", cfg.SourceList(), "wowPath", cfg.WowPath, "delay", cfg.Delay,
+		"gitignore", cfg.UseGitignore, "pkgmeta", cfg.UsePkgMeta, "gitattributes", cfg.UseGitattributes, "ignore", cfg.Ignore)
+
+	// A container target is synced into a local copy of its AddOns folder,
+	// and every write is passed on to the container.
+	var mirror *docker.Mirror
+	var wowPath, addOnsDir string
+	var others []string
+	if docker.IsURL(cfg.WowPath) {
+		if len(cfg.Targets) > 0 {
+			return errors.New("targets can't be combined with a container wowPath")
+		}
+		if mirror, err = dockerMirror(c.Context, cfg); err != nil {
+			return err
+		}
+		addOnsDir = mirror.Local
+		wowPath = filepath.Dir(filepath.Dir(addOnsDir))
+		if err := checkSandbox(addOnsDir); err != nil {
+			return err
+		}
+	} else {
+		if len(cfg.Targets) > 0 {
+			dirs, err := resolveTargets(cfg, c.StringSlice("flavor"), c.Bool("any-path"))
+			if err != nil {
+				return err
+			}
+			wowPath, others = dirs[0], dirs[1:]
+		} else {
+			dirs, err := resolveWowPaths(cfg, c.StringSlice("flavor"), c.Bool("any-path"))
+			if err != nil {
+				return err
+			}
+			if len(dirs) > 1 && cfg.TwoWay {
+				return errors.New("twoWay syncs to a single client, not several — give one --flavor")
+			}
+			wowPath, others = dirs[0], dirs[1:]
+		}
+		if err := checkSandbox(append([]string{wowPath}, others...)...); err != nil {
+			return err
+		}
+		addOnsDir = filepath.Join(wowPath, "Interface", "AddOns")
+		if err := ensureAddOnsDir(addOnsDir, c.Bool("create-target")); err != nil {
+			return err
+		}
+	}
+
+	// Files are tailored to the target's flavor when it can be told from the path.
+	fl, ok := detect.ClientFlavor(wowPath)
+	if !ok && len(cfg.FlavorFiles) > 0 {
+		slog.Warn(fmt.Sprintf("can't tell the flavor of %s — syncing files of every flavor", wowPath))
+	}
+	targetFlavor := fl.Name
+	head := gitinfo.NewCache(".", 2*time.Second)
+	tf, err := targetTransform(cfg, fl)
 	if err != nil {
 		return err
 	}
 
-	if cfg.Verbose {
-		log.Printf("[verbose] detected addon %q at %s", addonName, srcDir)
-		log.Printf("[verbose] WoW path: %s", wowPath)
+	addons, err := resolveAddons(cfg, addOnsDir, targetFlavor)
+	if err != nil {
+		return err
+	}
+	if len(cfg.UIPack.Paths) > 0 {
+		if mirror != nil {
+			return errors.New("uiPack paths can't be synced into a container, only its AddOns folder")
+		}
+		addons = append(addons, uiPackFolders(cfg, wowPath, targetFlavor)...)
+	}
+	if addons, err = workspace.Filter(addons, c.StringSlice("addon")); err != nil {
+		return err
 	}
 
-	targetPath := filepath.Join(wowPath, "Interface", "AddOns", addonName)
-	ig := copier.NewIgnorer(srcDir, cfg.Ignore, cfg.UseGitignore, cfg.UsePkgMeta)
+	for _, a := range addons {
+		slog.Debug("detected addon", "name", a.Name, "sources", sourceDirs(a))
+	}
+	slog.Debug("target", "wowPath", wowPath, "flavor", targetFlavor)
 
-	cleaned, err := copier.CleanDestination(srcDir, targetPath, ig)
+	limits, err := cfg.Budget.Limits()
 	if err != nil {
-		return fmt.Errorf("cleanup failed: %w", err)
+		return err
 	}
-	if cleaned > 0 {
-		fmt.Printf("Removed %d stale file(s) from destination\n", cleaned)
+	var warnings []string
+	if w := wtfWarning(c.Context, addons); w != "" {
+		slog.Warn(w)
+		warnings = append(warnings, w)
 	}
+	for _, a := range addons {
+		if err := checkSandbox(a.Target); err != nil {
+			return err
+		}
+		if a.Pack {
+			// UI pack folders hold client files too: nothing is cleaned up
+			// or marked, and the files they replace are kept.
+			recordPack(a)
+			continue
+		}
+		warns, err := checkName(a, c.Bool("fix"), cfg.Toc.Variants())
+		if err != nil {
+			return err
+		}
+		over, err := budget.Check(a.Sources, limits)
+		if err != nil {
+			return fmt.Errorf("checking budget failed: %w", err)
+		}
+		for _, w := range over {
+			if len(addons) > 1 {
+				w = a.Name + ": " + w
+			}
+			warns = append(warns, w)
+		}
+		for _, w := range warns {
+			slog.Warn(w)
+		}
+		warnings = append(warnings, warns...)
 
-	isTTY := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+		if len(a.Sources) > 1 {
+			conflicts, err := copier.FindConflicts(a.Sources)
+			if err != nil {
+				return fmt.Errorf("checking sources failed: %w", err)
+			}
+			if len(conflicts) > 0 {
+				for _, cf := range conflicts {
+					fmt.Fprintln(os.Stderr, i18n.Tf("conflict: %s is provided by %s", cf.RelPath, strings.Join(cf.Dirs, i18n.T(" and "))))
+				}
+				return fmt.Errorf("%d file(s) are provided by more than one source — rename or ignore them in all but one", len(conflicts))
+			}
+		}
+
+		if err := claimTarget(a, addOnsDir); err != nil {
+			return err
+		}
+	}
+	extras, err := otherTargets(c, cfg, others)
+	if err != nil {
+		return err
+	}
+
+	// --plain takes the non-TTY path: no spinner, redraws or colors.
+	isTTY := !c.Bool("plain") && !c.Bool("ci") && (isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()))
 
 	var fileCount int
 
-	if isTTY && !c.Bool("no-watch") {
-		total, err := copier.CountFiles(srcDir, ig)
-		if err != nil {
-			return fmt.Errorf("counting files failed: %w", err)
+	if isTTY && watch {
+		total := 0
+		for _, a := range addons {
+			n, err := copier.CountFilesSources(a.Sources)
+			if err != nil {
+				return fmt.Errorf("counting files failed: %w", err)
+			}
+			total += n
 		}
 
 		syncModel := ui.NewSyncModel(total)
-		p := tea.NewProgram(syncModel)
+		if !cfg.Animations {
+			syncModel = syncModel.WithoutAnimation()
+		}
+		p := tea.NewProgram(ui.Guard(syncModel), programOptions(stdinEvents)...)
 
 		go func() {
-			_, _ = copier.InitialSyncWithProgress(srcDir, targetPath, ig, func(_ int) {
-				p.Send(ui.SyncFileMsg{})
-			})
+			defer crash.Recover()
+			for _, a := range addons {
+				_, _ = copier.InitialSyncSources(a.Sources, a.Target, tf, func(_ int) {
+					p.Send(ui.SyncFileMsg{})
+				})
+			}
 			p.Send(ui.SyncDoneMsg{Count: total})
 		}()
 
-		if _, err := p.Run(); err != nil {
+		if err := runTUI(p); err != nil {
 			return err
 		}
 
 		fileCount = total
 	} else {
-		var err error
-		fileCount, err = copier.InitialSync(srcDir, targetPath, ig)
+		for _, a := range addons {
+			n, err := copier.InitialSyncSources(a.Sources, a.Target, tf, nil)
+			if err != nil {
+				return withAccessHint(fmt.Errorf("initial sync failed: %w", err), addOnsDir)
+			}
+			fileCount += n
+		}
+	}
+
+	for _, a := range addons {
+		names, err := a.GenerateTocs(cfg.Toc.Variants())
 		if err != nil {
-			return fmt.Errorf("initial sync failed: %w", err)
+			return fmt.Errorf("toc generation failed: %w", err)
+		}
+		if len(names) > 0 {
+			fmt.Println(i18n.Tf("Generated %d .toc file(s) from %s", len(names), filepath.Join(a.Dir(), a.Template)))
+		}
+		if cfg.BuildInfo && !a.Pack {
+			if err := a.WriteBuildInfo(head.Get(), time.Now()); err != nil {
+				return fmt.Errorf("writing %s failed: %w", workspace.BuildInfoFile, err)
+			}
 		}
 	}
 
-	if c.Bool("no-watch") {
-		fmt.Printf("Synced %d files to %s\n", fileCount, targetPath)
-		return nil
+	extraCounts := make([]int, len(extras))
+	for i, t := range extras {
+		if extraCounts[i], err = t.sync(cfg, head); err != nil {
+			return err
+		}
+	}
+
+	if mirror != nil {
+		for _, a := range addons {
+			if err := mirror.Copy(a.Target); err != nil {
+				return fmt.Errorf("copying %s into the container failed: %w", a.Name, err)
+			}
+		}
+	}
+
+	targetPath := addons[0].Target
+	if len(addons) > 1 {
+		targetPath = addOnsDir
+		if slices.ContainsFunc(addons, func(a *workspace.Addon) bool { return a.Pack }) {
+			targetPath = wowPath
+		}
+	}
+	if mirror != nil {
+		targetPath = mirror.URL(targetPath)
+	}
+
+	if !watch {
+		fmt.Println(i18n.Tf("Synced %d files to %s", fileCount, targetPath))
+		for i, t := range extras {
+			fmt.Println(i18n.Tf("Synced %d files to %s", extraCounts[i], t.path()))
+		}
+		return ciWarnings(c, warnings)
+	}
+
+	// Destination files edited after this point are not overwritten silently.
+	writes := copier.NewTracker()
+	defer sendTelemetry(fileCount, time.Now())
+	for _, t := range extras {
+		for _, a := range t.addons {
+			if err := writes.Scan(a.Target); err != nil {
+				return fmt.Errorf("scanning %s failed: %w", a.Target, err)
+			}
+		}
+	}
+	for _, a := range addons {
+		if err := writes.Scan(a.Target); err != nil {
+			return fmt.Errorf("scanning %s failed: %w", a.Target, err)
+		}
+		if !cfg.TwoWay {
+			continue
+		}
+		// In two-way mode, source files changed since the sync make an
+		// edit to the same file in AddOns a conflict.
+		for _, src := range a.Sources {
+			files, err := copier.ListFiles(src.Dir, src.Ignorer)
+			if err != nil {
+				return fmt.Errorf("scanning %s failed: %w", src.Dir, err)
+			}
+			for _, rel := range files {
+				writes.Record(filepath.Join(src.Dir, rel))
+			}
+		}
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	ctx, stop := untilContext(ctx, c)
+	defer stop()
+	if port := c.Int("pprof"); port > 0 {
+		if err := servePprof(ctx, port); err != nil {
+			return err
+		}
+	}
 
-	eventCh, err := watcher.Watch(ctx, srcDir, ig, cfg.Delay, cfg.Verbose)
-	if err != nil {
-		return fmt.Errorf("failed to start watcher: %w", err)
+	// Other targets may be of other flavors, whose files must be seen too.
+	watchFlavor := targetFlavor
+	if len(extras) > 0 {
+		watchFlavor = ""
+	}
+	roots := watchRoots(cfg, addons, watchFlavor)
+	// The watches share one debounce delay, so the TUI can change it.
+	opts := watchOptions(cfg)
+	if session.recorder != nil {
+		opts.Trace = session.recorder.Trace
+	}
+	var eventCh <-chan watcher.Event
+	var watching *watches
+	switch {
+	case session.replay != nil:
+		if eventCh, err = session.replay(ctx, opts.Delay); err != nil {
+			return err
+		}
+	case stdinEvents:
+		// Paths are relative to where blink was started, not to blink.toml.
+		eventCh = watcher.Read(ctx, os.Stdin, userPath("."), roots)
+	default:
+		if watching, err = startWatches(ctx, roots, opts); err != nil {
+			return err
+		}
+		eventCh = watching.events()
+	}
+	if session.recorder != nil {
+		eventCh = session.recorder.Tee(ctx, eventCh)
+	}
+
+	names := make([]string, len(addons))
+	for i, a := range addons {
+		names[i] = a.Name
+	}
+
+	var st *status.Writer
+	if cfg.StatusFile != "" {
+		st = status.NewWriter(cfg.StatusFile, names)
+		defer st.Remove()
+		if err := st.Update(func(s *status.Status) { s.LastSync = time.Now() }); err != nil {
+			slog.Warn("can't write status file", "err", err)
+		}
+	}
+
+	// Under a systemd unit with Type=notify, report readiness and keep the
+	// watchdog fed while the watch loops are healthy.
+	if sent, err := sdnotify.Notify(sdnotify.Ready + "\n" + sdnotify.Status("watching "+strings.Join(names, ", "))); err != nil {
+		slog.Warn("systemd notification failed", "err", err)
+	} else if sent {
+		defer func() { _, _ = sdnotify.Notify(sdnotify.Stopping) }()
+		if timeout := sdnotify.WatchdogInterval(); timeout > 0 {
+			go feedWatchdog(ctx, timeout)
+		}
+	}
+
+	engine := sync.NewEngine(addons, cfg, tf, writes)
+	if mirror != nil {
+		engine = engine.WithMirror(mirror)
+	}
+	if cfg.BuildInfo {
+		engine = engine.WithBuildInfo(head.Get)
+	}
+	if !limits.IsZero() {
+		engine = engine.WithBudget(limits)
+	}
+	for _, t := range extras {
+		other := sync.NewEngine(t.addons, cfg, t.tf, writes).Named(filepath.Base(t.wowPath))
+		if cfg.BuildInfo {
+			other = other.WithBuildInfo(head.Get)
+		}
+		engine = engine.WithTargets(other)
 	}
 
 	if isTTY {
-		m := ui.NewModel(addonName, targetPath, srcDir, targetPath, fileCount, eventCh, ig)
-		p := tea.NewProgram(m)
-		if _, err := p.Run(); err != nil {
+		// Log output (e.g. --verbose) goes to a panel rather than over the screen.
+		logw := ui.NewLogWriter()
+		defer logging.SetOutput(logging.SetOutput(logw))
+
+		m := ui.NewModel(addons, targetPath, fileCount, eventCh, engine, cfg, st).WithWarnings(warnings).WithLog(logw)
+		m = m.WithControls(sessionControls(cfg, wowPath, addons, engine, watching, opts.Delay, mirror != nil || len(extras) > 0))
+		if c.Int("pprof") > 0 {
+			m = m.WithRuntimeStats()
+		}
+		if cfg.UpdateCheck && version != "dev" {
+			if dir, err := state.Dir(); err == nil {
+				checker := update.NewChecker(dir)
+				m = m.WithUpdateCheck(func() string { return updateNotice(ctx, checker) })
+			}
+		}
+		p := tea.NewProgram(ui.Guard(m), programOptions(stdinEvents)...)
+		if err := runTUI(p); err != nil {
 			return err
 		}
 	} else {
 		// Plain text mode for non-TTY
-		fmt.Printf("blink %s — watching %s\n", version, addonName)
-		fmt.Printf("target: %s\n", targetPath)
-		fmt.Printf("synced %d files\n", fileCount)
+		fmt.Println(i18n.Tf("blink %s — watching %s", version, strings.Join(names, ", ")))
+		fmt.Println(i18n.Tf("target: %s", targetPath))
+		for _, t := range extras {
+			fmt.Println(i18n.Tf("target: %s", t.path()))
+		}
+		fmt.Println(i18n.Tf("synced %d files", fileCount))
+
+		// The status file follows the outcome of each change.
+		synced := func() {
+			_ = st.Update(func(s *status.Status) {
+				s.State, s.Error, s.LastSync = status.StateWatching, "", time.Now()
+			})
+		}
+		failed := func(msg string) {
+			telemetry.RecordError(msg)
+			_ = st.Update(func(s *status.Status) { s.State, s.Error = status.StateError, msg })
+		}
 
 		for ev := range eventCh {
-			ts := time.Now().Format("15:04:05")
+			ts := cfg.FormatTime(time.Now())
+			_ = st.Update(func(s *status.Status) { s.Pending = len(eventCh) })
 
 			if ev.Err != nil {
-				fmt.Fprintf(os.Stderr, "%s  watcher error: %v\n", ts, ev.Err)
+				fmt.Fprintf(os.Stderr, "%s  %s\n", ts, i18n.Tf("watcher error: %v", ev.Err))
+				continue
+			}
+			if ev.Restarted {
+				fmt.Fprintf(os.Stderr, "%s  watcher → %s\n", ts, i18n.T("restarted, re-syncing"))
+				// Catch up on changes made while the watch was down.
+				if a, _, ok := workspace.Route(addons, ev.Root); ok {
+					n, err := engine.Resync(a)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "%s  %s → %s\n", ts, a.Name, i18n.Tf("error: %v", err))
+						failed(a.Name + ": " + err.Error())
+						continue
+					}
+					fmt.Printf("%s  %s → %s\n", ts, a.Name, i18n.Tf("synced %d files", n))
+					synced()
+				}
 				continue
 			}
 
-			dstPath := filepath.Join(targetPath, ev.RelPath)
-			srcPath := filepath.Join(srcDir, ev.RelPath)
-
-			switch ev.Op {
-			case watcher.OpRemove, watcher.OpRename:
-				if err := copier.DeleteFile(dstPath); err != nil {
-					fmt.Fprintf(os.Stderr, "%s  %s → error: %v\n", ts, ev.RelPath, err)
+			var results []sync.Result
+			for _, r := range engine.Handle(ev) {
+				if r.Kind == sync.Renaming {
+					results = append(results, engine.Rename(r)...)
 				} else {
-					fmt.Printf("%s  %s → removed\n", ts, ev.RelPath)
+					results = append(results, r)
 				}
-			default:
-				if err := copier.CopyFile(srcPath, dstPath); err != nil {
-					fmt.Fprintf(os.Stderr, "%s  %s → error: %v\n", ts, ev.RelPath, err)
-				} else {
-					fmt.Printf("%s  %s → copied\n", ts, ev.RelPath)
+			}
+			for _, r := range results {
+				switch r.Kind {
+				case sync.Synced:
+					fmt.Printf("%s  %s → %s\n", ts, r.Label, r.Text())
+					synced()
+				case sync.Warning:
+					fmt.Fprintf(os.Stderr, "%s  %s → %s\n", ts, r.Label, r.Text())
+					synced()
+				case sync.Held:
+					// Without a terminal there is no one to ask; the next
+					// blink sync overwrites the edit.
+					fmt.Fprintf(os.Stderr, "%s  %s → %s\n", ts, r.Label, r.Text())
+				case sync.Failed:
+					fmt.Fprintf(os.Stderr, "%s  %s → %s\n", ts, r.Label, r.Text())
+					failed(r.Label + ": " + r.Action())
+				case sync.Renamed:
+					fmt.Printf("%s  %s → %s\n", ts, r.Label, i18n.Tf("renamed to %s, now syncing to %s", r.Addon.Name, r.Addon.Target))
+					for i := range addons {
+						names[i] = addons[i].Name
+					}
+					_ = st.Update(func(s *status.Status) { s.Addons = names })
+				}
+			}
+
+			if _, _, ok := workspace.Route(addons, ev.Root); ok && len(results) > 0 && cfg.Selene.Enabled && lint.IsLua(ev.RelPath) {
+				if _, err := os.Stat(filepath.Join(ev.Root, ev.RelPath)); err != nil {
+					continue
+				}
+				s := &lint.Selene{Command: cfg.Selene.Command, Std: cfg.Selene.Std, Dir: ev.Root}
+				diags, err := s.Run(ctx, ev.RelPath)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%s  selene → %s\n", ts, i18n.Tf("error: %v", err))
+				}
+				for _, d := range diags {
+					fmt.Printf("%s  %s\n", ts, d)
 				}
 			}
 		}
 	}
 
+	var reason stopReason
+	if errors.As(context.Cause(ctx), &reason) {
+		fmt.Println(reason)
+	}
 	return nil
 }
+
+// sendTelemetry sends the session's anonymous report, if the user opted in.
+func sendTelemetry(fileCount int, start time.Time) {
+	dir, err := state.Dir()
+	if err != nil || !telemetry.Enabled(dir) {
+		return
+	}
+	r := telemetry.NewReport(version, watcher.Backend(), fileCount, start)
+	if err := telemetry.Send(context.Background(), telemetryEndpoint, r); err != nil {
+		slog.Debug("telemetry not sent", "err", err)
+	}
+}
+
+// updateNotice returns a line announcing a newer blink release, or "" when
+// this one is current or the check failed.
+func updateNotice(ctx context.Context, c *update.Checker) string {
+	rel, err := c.Latest(ctx)
+	if err != nil {
+		slog.Debug("update check failed", "err", err)
+		return ""
+	}
+	if !update.Newer(rel.Version, version) {
+		return ""
+	}
+	return i18n.Tf("blink %s is available (you have %s): %s", rel.Version, version, rel.URL)
+}
+
+// checkName returns warnings about .toc files the client won't load from the
+// addon's folder, e.g. MyAddon_Vanilla.toc next to MyAddon_Mainline.toc,
+// which names the folder MyAddon_Mainline. With fix, the addon is renamed so
+// they all load instead, moving a folder blink synced under the old name.
+func checkName(a *workspace.Addon, fix bool, variants []toc.Variant) ([]string, error) {
+	nc := detect.CheckName(a.Dir(), a.Name)
+	if nc.OK() {
+		return nil, nil
+	}
+	warning := fmt.Sprintf("WoW won't load %s from a folder named %s", strings.Join(nc.Unloaded, ", "), a.Name)
+	switch {
+	case nc.Fix == "":
+		return []string{warning, fmt.Sprintf("the .toc files in %s name different addons — give them one base name", a.Dir())}, nil
+	case !fix:
+		return []string{warning, fmt.Sprintf("rerun with --fix to sync to %s instead", nc.Fix)}, nil
+	}
+
+	a.FixName = true
+	oldTarget := a.Target
+	if !copier.HasMarker(oldTarget) {
+		a.Name, a.Target = nc.Fix, filepath.Join(filepath.Dir(oldTarget), nc.Fix)
+		return nil, nil
+	}
+	if err := a.Rename(nc.Fix, variants); err != nil {
+		return nil, err
+	}
+	forgetTargets([]string{oldTarget})
+	fmt.Println(i18n.Tf("Moved %s to %s", oldTarget, a.Target))
+	return nil, nil
+}
+
+// feedWatchdog pings the systemd watchdog at half its timeout for as long as
+// the watch loops keep going round. A wedged loop stops the pings, and
+// systemd restarts blink.
+func feedWatchdog(ctx context.Context, timeout time.Duration) {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+	maxAge := max(timeout/2, 3*time.Second)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if watcher.Healthy(maxAge) {
+				_, _ = sdnotify.Notify(sdnotify.Watchdog)
+			}
+		}
+	}
+}
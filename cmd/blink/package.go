@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/crash"
+	"github.com/byteorem/blink/internal/detect"
+	"github.com/byteorem/blink/internal/flavor"
+	"github.com/byteorem/blink/internal/packager"
+	"github.com/byteorem/blink/internal/publish"
+	"github.com/byteorem/blink/internal/transform"
+	"github.com/byteorem/blink/internal/workspace"
+	"github.com/urfave/cli/v2"
+)
+
+func packageCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "package",
+		Usage: "Build a release zip of the files blink would sync",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "out",
+				Usage: "Folder to write the zip files to (default: package.out in blink.toml, or dist)",
+			},
+			&cli.BoolFlag{
+				Name:  "rebuild",
+				Usage: "Build every zip, even those whose files and settings haven't changed since the last build",
+			},
+		},
+		Action: runPackage,
+		Subcommands: []*cli.Command{
+			{
+				Name:   "login",
+				Usage:  "Save the passphrase of the key in package.signKey in the system keyring",
+				Action: runPackageLogin,
+			},
+			{
+				Name:   "logout",
+				Usage:  "Remove the saved passphrase of the signing key",
+				Action: runPackageLogout,
+			},
+		},
+	}
+}
+
+func runPackage(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	out := cfg.Package.Out
+	if c.IsSet("out") {
+		out = userPath(c.String("out"))
+	}
+	outDir, err := filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+	// Keep earlier releases out of the package when they're written inside the source.
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, outDir); err == nil && !strings.HasPrefix(rel, "..") {
+			cfg.Ignore = append(cfg.Ignore, "/"+filepath.ToSlash(rel)+"/")
+		}
+	}
+
+	rewrite, err := copyTransform(cfg)
+	if err != nil {
+		return err
+	}
+	// Minify first, so the license header survives. The rewritten files
+	// are shared by the zips of every flavor.
+	rewrite = transform.Cache(transform.Chain(transform.Minify(cfg.Package.Minify), rewrite))
+
+	flavors := []flavor.Flavor{{}}
+	if names := c.StringSlice("flavor"); len(names) > 0 {
+		flavors = nil
+		for _, name := range names {
+			fl, ok := flavor.Lookup(name)
+			if !ok {
+				return fmt.Errorf("unknown flavor %q in --flavor", name)
+			}
+			flavors = append(flavors, fl)
+		}
+	}
+
+	staging, err := os.MkdirTemp("", "blink-package-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	var builds []*packageBuild
+	for _, fl := range flavors {
+		addons, err := resolveAddons(cfg, filepath.Join(staging, fl.Name), fl.Name)
+		if err != nil {
+			return err
+		}
+		if addons, err = workspace.Filter(addons, c.StringSlice("addon")); err != nil {
+			return err
+		}
+		for _, a := range addons {
+			builds = append(builds, &packageBuild{addon: a, flavor: fl})
+			if externals, ok := packager.NoLib(a.Dir()); ok {
+				builds = append(builds, &packageBuild{addon: noLibAddon(cfg, a, externals, filepath.Join(staging, fl.Name+"-nolib"), fl.Name), flavor: fl, nolib: true})
+			}
+		}
+	}
+
+	// Zips are only built again when something going into them changed.
+	settings, err := packageSettings(cfg)
+	if err != nil {
+		return err
+	}
+	cached := packager.ReadCache(outDir)
+	if c.Bool("rebuild") {
+		cached = nil
+	}
+
+	// Each build is staged in its own folder, so they run at once.
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for _, b := range builds {
+		wg.Add(1)
+		go func() {
+			defer crash.Recover()
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			b.err = b.run(cfg, outDir, rewrite, settings, cached)
+		}()
+	}
+	wg.Wait()
+
+	var releases []packager.Release
+	var released []string // files of the releases, for the checksums
+	cache := packager.ReadCache(outDir)
+	for _, b := range builds {
+		if b.err != nil {
+			return fmt.Errorf("%s: %w", b.label(), b.err)
+		}
+		if b.unchanged {
+			fmt.Printf("Unchanged since the last build: %s\n", b.zipPath)
+		} else {
+			fmt.Printf("Packaged %d files into %s\n", b.count, b.zipPath)
+		}
+		releases = append(releases, b.release)
+		released = append(released, filepath.Base(b.zipPath), filepath.Base(packager.ManifestName(b.zipPath)))
+		cache[filepath.Base(b.zipPath)] = packager.Cached{Inputs: b.inputs, Files: b.count, Release: b.release}
+	}
+	if err := packager.WriteCache(outDir, cache); err != nil {
+		return err
+	}
+
+	// Update clients like WowUp find the zips through release.json.
+	if err := packager.WriteReleaseFile(outDir, releases); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", filepath.Join(outDir, packager.ReleaseFile))
+
+	sums, err := packager.WriteChecksums(outDir, released)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", sums)
+	if cfg.Package.Sign == "" {
+		return nil
+	}
+	signer := packager.Signer{Tool: cfg.Package.Sign, Key: cfg.Package.SignKey, Passphrase: publish.SigningPassphrase(cfg.Package.SignKey)}
+	sig, err := signer.Sign(c.Context, sums)
+	if err != nil {
+		return fmt.Errorf("signing %s failed: %w", packager.ChecksumFile, err)
+	}
+	fmt.Printf("Signed it into %s\n", sig)
+	return nil
+}
+
+// packageSettings returns the settings a zip is built with, for its
+// fingerprint: the blink version, the config and the license header.
+func packageSettings(cfg config.Config) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	banner, err := cfg.Header.Banner()
+	if err != nil {
+		return "", err
+	}
+	return version + "\x00" + string(data) + "\x00" + banner, nil
+}
+
+// noLibAddon returns a copy of a staged into stagingDir that leaves out the
+// libraries at externals, for its nolib release.
+func noLibAddon(cfg config.Config, a *workspace.Addon, externals []string, stagingDir, targetFlavor string) *workspace.Addon {
+	cfg.Ignore = slices.Clone(cfg.Ignore)
+	for _, ext := range externals {
+		cfg.Ignore = append(cfg.Ignore, "/"+strings.Trim(filepath.ToSlash(ext), "/"))
+	}
+	nolib := *a
+	nolib.Target = filepath.Join(stagingDir, filepath.Base(a.Target))
+	nolib.Sources = slices.Clone(a.Sources)
+	for i, src := range nolib.Sources {
+		nolib.Sources[i].Ignorer = sourceIgnorer(cfg, src.Dir, targetFlavor)
+	}
+	return &nolib
+}
+
+// packageBuild is the release zip of an addon for one flavor, or for every
+// flavor when flavor is the zero Flavor. A nolib build leaves out the
+// libraries the addon's .pkgmeta fetches.
+type packageBuild struct {
+	addon  *workspace.Addon
+	flavor flavor.Flavor
+	nolib  bool
+
+	count     int
+	zipPath   string
+	release   packager.Release
+	inputs    string // fingerprint of what the zip is built from
+	unchanged bool   // the zip was kept from an earlier build
+	err       error
+}
+
+// label names the build in messages.
+func (b *packageBuild) label() string {
+	var variant []string
+	if b.flavor.Name != "" {
+		variant = append(variant, b.flavor.Name)
+	}
+	if b.nolib {
+		variant = append(variant, "nolib")
+	}
+	if len(variant) == 0 {
+		return b.addon.Name
+	}
+	return b.addon.Name + " (" + strings.Join(variant, ", ") + ")"
+}
+
+// run stages exactly what a sync to the build's flavor would produce,
+// rewritten by rewrite, and archives it into outDir with its manifest. A zip
+// in cached built from the same settings and files is kept instead.
+func (b *packageBuild) run(cfg config.Config, outDir string, rewrite transform.Func, settings string, cached map[string]packager.Cached) error {
+	a := b.addon
+	if len(a.Sources) > 1 {
+		conflicts, err := copier.FindConflicts(a.Sources)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("%d file(s) are provided by more than one source, e.g. %s", len(conflicts), conflicts[0].RelPath)
+		}
+	}
+
+	// The version is read from the .toc file the zip's flavor loads.
+	version := "dev"
+	if t, ok := detect.TocFor(a.Dir(), b.flavor); ok {
+		version = packager.Version(a.Dir(), filepath.Join(a.Dir(), t))
+	} else if tocs, _ := detect.TocFiles(a.Dir()); len(tocs) > 0 {
+		version = packager.Version(a.Dir(), filepath.Join(a.Dir(), tocs[0]))
+	}
+	name := version
+	if b.flavor.Name != "" {
+		name += "-" + b.flavor.Name
+	}
+	if b.nolib {
+		name += "-nolib"
+	}
+	b.zipPath = filepath.Join(outDir, packager.ArchiveName(a.Name, name))
+
+	inputs, err := b.fingerprint(settings, version)
+	if err != nil {
+		return err
+	}
+	b.inputs = inputs
+	if c, ok := cached[filepath.Base(b.zipPath)]; ok && c.Inputs == inputs && exists(b.zipPath) && exists(packager.ManifestName(b.zipPath)) {
+		b.count, b.release, b.unchanged = c.Files, c.Release, true
+		return nil
+	}
+
+	var directives []transform.Func
+	if b.flavor.Name != "" {
+		directives = append(directives, transform.Directives(b.flavor))
+	}
+	if b.nolib {
+		directives = append(directives, transform.NoLib())
+	}
+	tf := transform.Chain(append(directives, rewrite)...)
+	count, err := copier.InitialSyncSources(a.Sources, a.Target, tf, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := a.GenerateTocs(cfg.Toc.Variants()); err != nil {
+		return err
+	}
+	if err := packager.Zip(a.Target, b.zipPath, a.Name); err != nil {
+		return err
+	}
+
+	// The manifest lets the zip's contents be checked without trusting it.
+	files, err := packager.Manifest(a.Target, a.Name)
+	if err != nil {
+		return err
+	}
+	if err := packager.WriteManifest(packager.ManifestName(b.zipPath), files); err != nil {
+		return err
+	}
+
+	games, err := packager.Games(a.Target)
+	if err != nil {
+		return err
+	}
+	b.count = count
+	b.release = packager.Release{Name: a.Name, Version: version, Filename: filepath.Base(b.zipPath), Nolib: b.nolib, Metadata: games}
+	return nil
+}
+
+// fingerprint returns the sum of what the build's zip is made from: the
+// settings, version and flavor, and every file of the addon's sources.
+func (b *packageBuild) fingerprint(settings, version string) (string, error) {
+	a := b.addon
+	variant := b.flavor.Name
+	if b.nolib {
+		variant += "-nolib"
+	}
+	f := packager.NewFingerprint(settings, version, variant, a.Name)
+	for i, src := range a.Sources {
+		files, err := copier.ListFiles(src.Dir, src.Ignorer)
+		if err != nil {
+			return "", err
+		}
+		for _, rel := range files {
+			if err := f.AddFile(fmt.Sprintf("%d/%s", i, filepath.ToSlash(rel)), filepath.Join(src.Dir, rel)); err != nil {
+				return "", err
+			}
+		}
+	}
+	if a.Template != "" {
+		if err := f.AddFile("template", filepath.Join(a.Dir(), a.Template)); err != nil {
+			return "", err
+		}
+	}
+	return f.Sum(), nil
+}
+
+// exists reports whether there is a file at path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func runPackageLogin(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	if cfg.Package.Sign == "" {
+		return errors.New("no signing set up — set package.sign (and package.signKey) in blink.toml first")
+	}
+	pass, err := readSecret("Passphrase: ")
+	if pass == "" {
+		if err != nil {
+			return err
+		}
+		return errors.New("no passphrase given")
+	}
+	if err := publish.SaveSigningPassphrase(cfg.Package.SignKey, pass); err != nil {
+		return fmt.Errorf("saving the passphrase in the system keyring failed: %w", err)
+	}
+	fmt.Println("Saved the passphrase in the system keyring")
+	return nil
+}
+
+func runPackageLogout(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	if err := publish.DeleteSigningPassphrase(cfg.Package.SignKey); err != nil {
+		return err
+	}
+	fmt.Println("Removed the saved passphrase")
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/detect"
+	"github.com/byteorem/blink/internal/i18n"
+	"github.com/byteorem/blink/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli/v2"
+)
+
+// pickAddon asks which of the addons found side by side to sync, all of
+// them or one, when they were detected rather than configured. It doesn't
+// ask when --addon already picks, or without a terminal to ask on. The
+// choice is saved to blink.toml if asked to.
+func pickAddon(c *cli.Context, cfg *config.Config) error {
+	if len(siblingAddons) == 0 || c.IsSet("addon") || c.Bool("plain") || c.Bool("ci") || c.String("events") == "stdin" {
+		return nil
+	}
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		if !isatty.IsTerminal(f.Fd()) && !isatty.IsCygwinTerminal(f.Fd()) {
+			return nil
+		}
+	}
+
+	names := make([]string, len(siblingAddons))
+	dirs := make(map[string]string, len(siblingAddons))
+	for i, dir := range siblingAddons {
+		names[i], _ = detect.AddonName(dir)
+		dirs[names[i]] = dir
+	}
+	var picked ui.Picked
+	if err := runTUI(tea.NewProgram(ui.Guard(ui.NewPickModel(names, &picked)), programOptions(false)...)); err != nil {
+		return err
+	}
+	if picked.Cancelled {
+		return cli.Exit("", 1)
+	}
+
+	path := configFile(c)
+	if picked.Addon == "" {
+		if picked.Save {
+			if err := config.SaveWorkspace(path); err != nil {
+				return err
+			}
+			fmt.Println(i18n.Tf("Saved to %s: sync every addon", path))
+		}
+		return nil
+	}
+
+	dir := dirs[picked.Addon]
+	cfg.Source, cfg.Workspace.Enabled = dir, false
+	if picked.Save {
+		// The addons were found in the working directory, which source is
+		// relative to.
+		if err := config.SaveSource(path, filepath.Base(dir)); err != nil {
+			return err
+		}
+		fmt.Println(i18n.Tf("Saved to %s: sync %s", path, picked.Addon))
+	}
+	return nil
+}
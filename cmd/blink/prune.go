@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/state"
+	"github.com/urfave/cli/v2"
+)
+
+func pruneCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "prune",
+		Usage: "Remove addon folders blink synced whose source is gone, e.g. after renaming or deleting an addon",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Don't ask for confirmation",
+			},
+		},
+		Action: runPrune,
+	}
+}
+
+func runPrune(c *cli.Context) error {
+	stateDir, err := state.Dir()
+	if err != nil {
+		return err
+	}
+	st, err := state.Open(stateDir)
+	if err != nil {
+		return fmt.Errorf("reading blink's state: %w", err)
+	}
+
+	// Records of folders that are gone, or lost their marker to a release
+	// install, are dropped without touching the folder.
+	var targets []state.Target
+	var stale []string
+	for _, t := range st.Orphans() {
+		if copier.HasMarker(t.Path) {
+			targets = append(targets, t)
+		} else {
+			stale = append(stale, t.Path)
+		}
+	}
+	for _, t := range st.Targets {
+		if _, err := os.Stat(t.Path); os.IsNotExist(err) && !slices.Contains(stale, t.Path) {
+			stale = append(stale, t.Path)
+		}
+	}
+	if len(stale) > 0 {
+		for _, path := range stale {
+			st.Forget(path)
+		}
+		if err := st.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Forgot %d folder(s) that are gone or no longer synced by blink\n", len(stale))
+	}
+	if len(targets) == 0 {
+		fmt.Println("Nothing to prune")
+		return nil
+	}
+
+	fmt.Println("These folders were synced from addons that are gone:")
+	for _, t := range targets {
+		fmt.Printf("  %s (from %s)\n", t.Path, t.Sources[0])
+	}
+	if !c.Bool("yes") {
+		ok, err := confirm("Remove them?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return cli.Exit("", 1)
+		}
+	}
+
+	for _, t := range targets {
+		if err := os.RemoveAll(t.Path); err != nil {
+			_ = st.Save()
+			return withAccessHint(err, filepath.Dir(t.Path))
+		}
+		st.Forget(t.Path)
+		fmt.Printf("Removed %s\n", t.Path)
+	}
+	return st.Save()
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/byteorem/blink/internal/gitinfo"
+	"github.com/byteorem/blink/internal/packager"
+	"github.com/byteorem/blink/internal/publish"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func publishCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "publish",
+		Usage: "Upload the zips built by blink package",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "github",
+				Usage: "Create or update the GitHub Release of the current tag and upload the zips, their manifests, checksums and release.json to it",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "dir",
+						Usage: "Folder blink package wrote the zips to (default: package.out in blink.toml, or dist)",
+					},
+					&cli.StringFlag{
+						Name:  "tag",
+						Usage: "Tag to publish (default: the tag of the checked-out commit)",
+					},
+					&cli.StringFlag{
+						Name:  "repo",
+						Usage: "GitHub repository as owner/name (default: the origin remote)",
+					},
+					&cli.BoolFlag{
+						Name:  "draft",
+						Usage: "Create the release as a draft",
+					},
+					&cli.BoolFlag{
+						Name:  "prerelease",
+						Usage: "Mark a newly created release as a pre-release",
+					},
+				},
+				Action: runPublishGitHub,
+				Subcommands: []*cli.Command{
+					{
+						Name:   "login",
+						Usage:  "Save a GitHub token (with contents: write) in the system keyring",
+						Action: runPublishLogin,
+					},
+					{
+						Name:   "logout",
+						Usage:  "Remove the saved GitHub token",
+						Action: runPublishLogout,
+					},
+				},
+			},
+		},
+	}
+}
+
+func runPublishGitHub(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	dir := cfg.Package.Out
+	if c.IsSet("dir") {
+		dir = userPath(c.String("dir"))
+	}
+	releases, err := packager.ReadReleaseFile(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no %s in %s — run blink package first", packager.ReleaseFile, dir)
+	}
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, 2*len(releases)+1)
+	for _, r := range releases {
+		zipPath := filepath.Join(dir, r.Filename)
+		files = append(files, zipPath)
+		if _, err := os.Stat(packager.ManifestName(zipPath)); err == nil {
+			files = append(files, packager.ManifestName(zipPath))
+		}
+	}
+	files = append(files, filepath.Join(dir, packager.ReleaseFile))
+	for _, name := range []string{packager.ChecksumFile, packager.ChecksumFile + ".asc", packager.ChecksumFile + ".minisig"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+
+	tag := c.String("tag")
+	if tag == "" {
+		if tag = gitinfo.Tag("."); tag == "" {
+			return errors.New("the checked-out commit isn't tagged — tag the release or pass --tag")
+		}
+	}
+	repo := c.String("repo")
+	if repo == "" {
+		var ok bool
+		if repo, ok = publish.RepoFromRemote(gitinfo.Remote(".", "origin")); !ok {
+			return errors.New("can't tell the GitHub repository from the origin remote — pass --repo owner/name")
+		}
+	}
+	token, err := publish.GitHubToken()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Publishing %s to %s...\n", tag, repo)
+	opts := publish.Options{Draft: c.Bool("draft"), Prerelease: c.Bool("prerelease")}
+	page, err := publish.NewGitHub(repo, token).Publish(c.Context, tag, files, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Uploaded %d file(s) to %s\n", len(files), page)
+	return nil
+}
+
+func runPublishLogin(_ *cli.Context) error {
+	line, err := readSecret("GitHub token: ")
+	token := strings.TrimSpace(line)
+	if token == "" {
+		if err != nil {
+			return err
+		}
+		return errors.New("no token given")
+	}
+	if err := publish.SaveGitHubToken(token); err != nil {
+		return fmt.Errorf("saving the token in the system keyring failed: %w", err)
+	}
+	fmt.Println("Saved the token in the system keyring")
+	return nil
+}
+
+// readSecret asks for a token or passphrase on standard input, without
+// showing what is typed when it is a terminal. It returns the line without
+// its line break.
+func readSecret(prompt string) (string, error) {
+	fmt.Print(prompt)
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		secret, err := term.ReadPassword(fd)
+		fmt.Println()
+		return string(secret), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+func runPublishLogout(_ *cli.Context) error {
+	if err := publish.DeleteGitHubToken(); err != nil {
+		return err
+	}
+	fmt.Println("Removed the saved GitHub token")
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/byteorem/blink/internal/watcher"
+	"github.com/urfave/cli/v2"
+)
+
+// session changes where the events of a watch session come from and go,
+// for blink record and blink replay.
+var session struct {
+	recorder *watcher.Recorder
+	replay   func(ctx context.Context, delay *watcher.Delay) (<-chan watcher.Event, error)
+}
+
+func recordCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "record",
+		Usage:     "Watch and sync as usual, recording every change and when it happened to a file for blink replay, e.g. for bug reports",
+		ArgsUsage: "[file]",
+		Action:    runRecord,
+	}
+}
+
+func runRecord(c *cli.Context) error {
+	path := "blink-events.jsonl"
+	if c.Args().Present() {
+		path = userPath(c.Args().First())
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	session.recorder = watcher.NewRecorder(f)
+	err = start(c, true)
+	if cerr := errors.Join(session.recorder.Close(), f.Close()); err == nil && cerr != nil {
+		err = fmt.Errorf("writing %s failed: %w", path, cerr)
+	}
+	if err == nil {
+		fmt.Printf("Recorded the session to %s — replay it with blink replay %s\n", path, path)
+	}
+	return err
+}
+
+func replayCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "replay",
+		Usage:     "Feed the changes recorded by blink record through the sync engine again, into a throwaway WoW folder",
+		ArgsUsage: "<file>",
+		Flags: []cli.Flag{
+			&cli.Float64Flag{
+				Name:  "speed",
+				Usage: "Replay this many times faster than recorded; 0 replays without waiting",
+				Value: 1,
+			},
+			&cli.BoolFlag{
+				Name:  "keep",
+				Usage: "Keep the sandbox folder afterwards instead of removing it",
+			},
+		},
+		Action: runReplay,
+	}
+}
+
+func runReplay(c *cli.Context) error {
+	if !c.Args().Present() {
+		return errors.New("name the recording to replay, e.g. blink replay blink-events.jsonl")
+	}
+	if c.Float64("speed") < 0 {
+		return fmt.Errorf("--speed %v: must be 0 or more", c.Float64("speed"))
+	}
+	f, err := os.Open(userPath(c.Args().First()))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	session.replay = func(ctx context.Context, delay *watcher.Delay) (<-chan watcher.Event, error) {
+		return watcher.Replay(ctx, f, c.Float64("speed"), delay)
+	}
+	// The session ends with the recording, which the TUI doesn't.
+	if err := c.Set("plain", "true"); err != nil {
+		return err
+	}
+	return withSandbox(c, func() error {
+		return start(c, true)
+	})
+}
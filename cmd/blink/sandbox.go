@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/byteorem/blink/internal/sandbox"
+	"github.com/byteorem/blink/internal/state"
+	"github.com/urfave/cli/v2"
+)
+
+func sandboxCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sandbox",
+		Usage: "Sync into a throwaway WoW folder instead of the real install, e.g. for demos or trying out config changes",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "keep",
+				Usage: "Keep the sandbox folder afterwards instead of removing it",
+			},
+		},
+		Action: runSandbox,
+	}
+}
+
+func runSandbox(c *cli.Context) error {
+	return withSandbox(c, func() error {
+		return start(c, !c.Bool("no-watch"))
+	})
+}
+
+// sandboxed is the sandbox a blink sandbox or replay session syncs into.
+var sandboxed *sandbox.Sandbox
+
+// withSandbox runs fn with --wow-path set to a throwaway WoW folder, removed
+// afterwards unless --keep is set.
+func withSandbox(c *cli.Context, fn func() error) error {
+	sb, err := sandbox.New()
+	if err != nil {
+		return err
+	}
+	sandboxed = sb
+	defer func() {
+		sandboxed = nil
+		if c.Bool("keep") {
+			fmt.Printf("Kept the sandbox in %s\n", sb.WowPath)
+			return
+		}
+		forgetBelow(sb.Root)
+		_ = sb.Remove()
+	}()
+
+	fmt.Printf("Sandbox WoW folder: %s\n", sb.WowPath)
+	if err := c.Set("wow-path", sb.WowPath); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// checkSandbox refuses to write to dirs when they are outside the sandbox
+// of the session, e.g. targets in blink.toml naming the real install.
+func checkSandbox(dirs ...string) error {
+	if sandboxed == nil {
+		return nil
+	}
+	for _, dir := range dirs {
+		if err := sandboxed.Check(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forgetBelow drops the folders below dir from the state store.
+func forgetBelow(dir string) {
+	_ = state.Update(func(st *state.Store) {
+		for _, t := range append([]state.Target(nil), st.Targets...) {
+			if strings.HasPrefix(t.Path, dir+string(filepath.Separator)) {
+				st.Forget(t.Path)
+			}
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/byteorem/blink/internal/detect"
+	"github.com/byteorem/blink/internal/service"
+	"github.com/urfave/cli/v2"
+)
+
+func serviceCommand() *cli.Command {
+	flags := []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "systemd",
+			Usage: "Use a systemd user unit (default on Linux)",
+		},
+		&cli.BoolFlag{
+			Name:  "launchd",
+			Usage: "Use a launchd agent in ~/Library/LaunchAgents (default on macOS)",
+		},
+		&cli.BoolFlag{
+			Name:  "windows",
+			Usage: "Use a scheduled task started at logon (default on Windows)",
+		},
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "Service name (default: blink-<folder name>)",
+		},
+	}
+	return &cli.Command{
+		Name:  "service",
+		Usage: "Run blink in the background whenever you're logged in",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "install",
+				Usage:  "Install and start a service watching the addon or workspace in the current folder",
+				Flags:  flags,
+				Action: runServiceInstall,
+			},
+			{
+				Name:   "status",
+				Usage:  "Show whether the service is running",
+				Flags:  flags,
+				Action: runServiceStatus,
+			},
+			{
+				Name:   "uninstall",
+				Usage:  "Stop the service and remove it",
+				Flags:  flags,
+				Action: runServiceUninstall,
+			},
+		},
+	}
+}
+
+// serviceManager returns the service manager chosen by flag, or the
+// platform's default.
+func serviceManager(c *cli.Context) (service.Manager, error) {
+	switch {
+	case c.Bool("systemd"):
+		return service.NewSystemd()
+	case c.Bool("launchd"):
+		return service.NewLaunchd()
+	case c.Bool("windows"):
+		return service.NewSchtasks()
+	case runtime.GOOS == "linux":
+		return service.NewSystemd()
+	case runtime.GOOS == "darwin":
+		return service.NewLaunchd()
+	case runtime.GOOS == "windows":
+		return service.NewSchtasks()
+	}
+	return nil, errors.New("no service manager for this platform — pass --systemd, --launchd or --windows")
+}
+
+// serviceName returns the --name flag or the name derived from the working directory.
+func serviceName(c *cli.Context) (string, error) {
+	if name := c.String("name"); name != "" {
+		return name, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return service.NameFor(wd), nil
+}
+
+func runServiceInstall(c *cli.Context) error {
+	mgr, err := serviceManager(c)
+	if err != nil {
+		return err
+	}
+	name, err := serviceName(c)
+	if err != nil {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// Make sure the service would find something to watch before installing it.
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	if !cfg.MultiAddon() {
+		if _, _, err := findAddon(cfg); err != nil {
+			return err
+		}
+	}
+	if _, err := detect.FindWowPath(cfg.WowPath); err != nil {
+		return err
+	}
+
+	// Global flags given on this command line are passed on, with paths made
+	// absolute since the service doesn't share this shell's directory.
+	var args []string
+	for _, f := range []string{"source", "wow-path", "config"} {
+		if v := c.String(f); v != "" {
+			abs, err := filepath.Abs(v)
+			if err != nil {
+				return err
+			}
+			args = append(args, "--"+f, abs)
+		}
+	}
+	for _, a := range c.StringSlice("addon") {
+		args = append(args, "--addon", a)
+	}
+	for _, f := range c.StringSlice("flavor") {
+		args = append(args, "--flavor", f)
+	}
+
+	path, err := mgr.Install(service.Service{Name: name, Dir: wd, Exec: exe, Args: args})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Installed %s (%s)\n", name, path)
+	return nil
+}
+
+func runServiceStatus(c *cli.Context) error {
+	mgr, err := serviceManager(c)
+	if err != nil {
+		return err
+	}
+	name, err := serviceName(c)
+	if err != nil {
+		return err
+	}
+	out, err := mgr.Status(name)
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
+
+func runServiceUninstall(c *cli.Context) error {
+	mgr, err := serviceManager(c)
+	if err != nil {
+		return err
+	}
+	name, err := serviceName(c)
+	if err != nil {
+		return err
+	}
+	if err := mgr.Uninstall(name); err != nil {
+		return err
+	}
+	fmt.Printf("Uninstalled %s\n", name)
+	return nil
+}
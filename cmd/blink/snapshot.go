@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/byteorem/blink/internal/snapshot"
+	"github.com/byteorem/blink/internal/state"
+	"github.com/byteorem/blink/internal/workspace"
+	"github.com/urfave/cli/v2"
+)
+
+func snapshotCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "snapshot",
+		Usage: "Save and restore addon folders in Interface/AddOns, e.g. to switch between a release and a dev build",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "create",
+				Usage:     "Archive the addon folder (default name: the current time)",
+				ArgsUsage: "[name]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "Archive every folder blink has synced, not just this addon's",
+					},
+				},
+				Action: runSnapshotCreate,
+			},
+			{
+				Name:      "restore",
+				Usage:     "Replace the archived folders with their contents at snapshot time",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Don't ask for confirmation",
+					},
+				},
+				Action: runSnapshotRestore,
+			},
+			{
+				Name:   "list",
+				Usage:  "List snapshots",
+				Action: runSnapshotList,
+			},
+		},
+	}
+}
+
+// snapshotDir is where snapshots are kept, inside blink's state.
+func snapshotDir() (string, error) {
+	dir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snapshots"), nil
+}
+
+func runSnapshotCreate(c *cli.Context) error {
+	root, err := snapshotDir()
+	if err != nil {
+		return err
+	}
+	name := c.Args().First()
+	if name == "" {
+		name = time.Now().Format("20060102-150405")
+	}
+
+	var folders []string
+	if c.Bool("all") {
+		dir, err := state.Dir()
+		if err != nil {
+			return err
+		}
+		st, err := state.Open(dir)
+		if err != nil {
+			return fmt.Errorf("reading blink's state: %w", err)
+		}
+		for _, t := range st.Targets {
+			folders = append(folders, t.Path)
+		}
+		if len(folders) == 0 {
+			return errors.New("blink hasn't synced any folders yet")
+		}
+	} else {
+		cfg, err := loadConfig(c)
+		if err != nil {
+			return err
+		}
+		wowPath, err := resolveWowPath(cfg, c.StringSlice("flavor"), c.Bool("any-path"))
+		if err != nil {
+			return err
+		}
+		addons, err := resolveAddons(cfg, filepath.Join(wowPath, "Interface", "AddOns"), "")
+		if err != nil {
+			return err
+		}
+		if addons, err = workspace.Filter(addons, c.StringSlice("addon")); err != nil {
+			return err
+		}
+		for _, a := range addons {
+			folders = append(folders, a.Target)
+		}
+	}
+
+	snap, err := snapshot.Create(root, name, folders)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved snapshot %s:\n", snap.Name)
+	printSnapshotFolders(snap)
+	return nil
+}
+
+func runSnapshotRestore(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("usage: blink snapshot restore <name>")
+	}
+	root, err := snapshotDir()
+	if err != nil {
+		return err
+	}
+	snap, err := snapshot.Load(root, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if !c.Bool("yes") {
+		fmt.Printf("Restoring %s replaces:\n", snap.Name)
+		printSnapshotFolders(snap)
+		ok, err := confirm("Continue?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return cli.Exit("", 1)
+		}
+	}
+
+	if err := snapshot.Restore(root, snap); err != nil {
+		return err
+	}
+	fmt.Printf("Restored snapshot %s\n", snap.Name)
+	return nil
+}
+
+func runSnapshotList(c *cli.Context) error {
+	root, err := snapshotDir()
+	if err != nil {
+		return err
+	}
+	snaps, err := snapshot.List(root)
+	if err != nil {
+		return err
+	}
+	if len(snaps) == 0 {
+		fmt.Println("No snapshots")
+		return nil
+	}
+	for _, s := range snaps {
+		names := make([]string, len(s.Folders))
+		for i, f := range s.Folders {
+			names[i] = filepath.Base(f.Path)
+		}
+		fmt.Printf("%-20s %s  %s\n", s.Name, s.Created.Format("2006-01-02 15:04"), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func printSnapshotFolders(snap *snapshot.Snapshot) {
+	for _, f := range snap.Folders {
+		if f.Archive == "" {
+			fmt.Printf("  %s (didn't exist)\n", f.Path)
+		} else {
+			fmt.Printf("  %s\n", f.Path)
+		}
+	}
+}
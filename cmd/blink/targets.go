@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/detect"
+	"github.com/byteorem/blink/internal/gitinfo"
+	"github.com/byteorem/blink/internal/transform"
+	"github.com/byteorem/blink/internal/workspace"
+	"github.com/urfave/cli/v2"
+)
+
+// extraTarget is a client a session syncs to besides the first of targets,
+// with its own copies of the addons, tailored to its flavor.
+type extraTarget struct {
+	wowPath string
+	addons  []*workspace.Addon
+	tf      transform.Func
+}
+
+// otherTargets sets up syncing to the clients at dirs: the addons are
+// resolved for each, and their folders there cleaned and marked.
+func otherTargets(c *cli.Context, cfg config.Config, dirs []string) ([]extraTarget, error) {
+	var targets []extraTarget
+	for _, dir := range dirs {
+		addOnsDir := filepath.Join(dir, "Interface", "AddOns")
+		if err := ensureAddOnsDir(addOnsDir, c.Bool("create-target")); err != nil {
+			return nil, err
+		}
+		fl, _ := detect.ClientFlavor(dir)
+		tf, err := targetTransform(cfg, fl)
+		if err != nil {
+			return nil, err
+		}
+		addons, err := resolveAddons(cfg, addOnsDir, fl.Name)
+		if err != nil {
+			return nil, err
+		}
+		addons = append(addons, uiPackFolders(cfg, dir, fl.Name)...)
+		if addons, err = workspace.Filter(addons, c.StringSlice("addon")); err != nil {
+			return nil, err
+		}
+		for _, a := range addons {
+			if err := checkSandbox(a.Target); err != nil {
+				return nil, err
+			}
+			if a.Pack {
+				recordPack(a)
+				continue
+			}
+			if err := claimTarget(a, addOnsDir); err != nil {
+				return nil, err
+			}
+		}
+		targets = append(targets, extraTarget{wowPath: dir, addons: addons, tf: tf})
+		slog.Debug("target", "wowPath", dir, "flavor", fl.Name)
+	}
+	return targets, nil
+}
+
+// sync copies the addons into the client, as the first sync of a session
+// does, and returns the number of files synced.
+func (t extraTarget) sync(cfg config.Config, head *gitinfo.Cache) (int, error) {
+	total := 0
+	for _, a := range t.addons {
+		n, err := copier.InitialSyncSources(a.Sources, a.Target, t.tf, nil)
+		if err != nil {
+			return total, withAccessHint(fmt.Errorf("initial sync failed: %w", err), filepath.Dir(a.Target))
+		}
+		total += n
+		if _, err := a.GenerateTocs(cfg.Toc.Variants()); err != nil {
+			return total, fmt.Errorf("toc generation failed: %w", err)
+		}
+		if cfg.BuildInfo && !a.Pack {
+			if err := a.WriteBuildInfo(head.Get(), time.Now()); err != nil {
+				return total, fmt.Errorf("writing %s failed: %w", workspace.BuildInfoFile, err)
+			}
+		}
+	}
+	return total, nil
+}
+
+// path names the client's AddOns folder, or the client folder when UI pack
+// folders are synced too.
+func (t extraTarget) path() string {
+	for _, a := range t.addons {
+		if a.Pack {
+			return t.wowPath
+		}
+	}
+	if len(t.addons) == 1 {
+		return t.addons[0].Target
+	}
+	return filepath.Join(t.wowPath, "Interface", "AddOns")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/byteorem/blink/internal/state"
+	"github.com/byteorem/blink/internal/telemetry"
+	"github.com/byteorem/blink/internal/watcher"
+	"github.com/urfave/cli/v2"
+)
+
+func telemetryCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "telemetry",
+		Usage: "Opt in to (or out of) sending an anonymous usage report after each watch session",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "enable",
+				Usage:  "Send the report from now on",
+				Action: func(c *cli.Context) error { return setTelemetry(true) },
+			},
+			{
+				Name:   "disable",
+				Usage:  "Stop sending the report (the default)",
+				Action: func(c *cli.Context) error { return setTelemetry(false) },
+			},
+			{
+				Name:   "status",
+				Usage:  "Show whether the report is sent, and what it contains",
+				Action: runTelemetryStatus,
+			},
+		},
+	}
+}
+
+func setTelemetry(enabled bool) error {
+	dir, err := state.Dir()
+	if err != nil {
+		return err
+	}
+	if err := telemetry.SetEnabled(dir, enabled); err != nil {
+		return err
+	}
+	if enabled {
+		fmt.Println("Telemetry enabled. Run `blink telemetry status` to see what is sent; `blink telemetry disable` turns it off.")
+	} else {
+		fmt.Println("Telemetry disabled.")
+	}
+	return nil
+}
+
+func runTelemetryStatus(c *cli.Context) error {
+	dir, err := state.Dir()
+	if err != nil {
+		return err
+	}
+	switch {
+	case !telemetry.Enabled(dir):
+		fmt.Println("Telemetry is disabled.")
+	case telemetryEndpoint == "":
+		fmt.Println("Telemetry is enabled, but this build of blink has nowhere to send it.")
+	default:
+		fmt.Printf("Telemetry is enabled; reports go to %s.\n", telemetryEndpoint)
+	}
+
+	example := telemetry.NewReport(version, watcher.Backend(), 120, time.Now().Add(-45*time.Minute))
+	example.Errors = map[string]int{"permission": 2}
+	data, err := json.MarshalIndent(example, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nA report looks like this, sent once when a watch session ends:\n%s\n", data)
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/lint"
+	"github.com/byteorem/blink/internal/testrun"
+	"github.com/byteorem/blink/internal/watcher"
+	"github.com/urfave/cli/v2"
+)
+
+func testCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "test",
+		Usage: "Run the addon's busted/luaunit test suite",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "watch",
+				Usage: "Re-run affected tests whenever a Lua file changes",
+			},
+		},
+		Action: runTest,
+	}
+}
+
+func newTestRunner(cfg config.Config, srcDir string) *testrun.Runner {
+	return &testrun.Runner{Command: cfg.Test.Command, Mocks: cfg.Test.Mocks, Dir: srcDir}
+}
+
+func runTest(c *cli.Context) error {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	srcDirs, _, err := findAddon(cfg)
+	if err != nil {
+		return err
+	}
+	srcDir := srcDirs[0]
+
+	runner := newTestRunner(cfg, srcDir)
+
+	if !c.Bool("watch") {
+		return testExit(runner.Stream(c.Context, os.Stdout))
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Test directories are usually excluded from the sync set, so only
+	// .gitignore applies here.
+	ig := copier.NewIgnorer(srcDir, nil, cfg.UseGitignore, false, false)
+	ig.FollowSymlinks = cfg.FollowSymlinks
+	eventCh, err := watcher.Watch(ctx, srcDir, ig, watchOptions(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to start watcher: %w", err)
+	}
+
+	fmt.Printf("blink test — watching %s\n", srcDir)
+	for ev := range eventCh {
+		if ev.Err != nil {
+			fmt.Fprintf(os.Stderr, "watcher error: %v\n", ev.Err)
+			continue
+		}
+		if ev.Op == watcher.OpRemove || !lint.IsLua(ev.RelPath) {
+			continue
+		}
+
+		specs := testrun.Affected(srcDir, ev.RelPath)
+		ts := cfg.FormatTime(time.Now())
+		if len(specs) == 0 {
+			fmt.Printf("\n%s  %s changed → running all tests\n", ts, ev.RelPath)
+		} else {
+			fmt.Printf("\n%s  %s changed → running %d spec(s)\n", ts, ev.RelPath, len(specs))
+		}
+		if err := runner.Stream(ctx, os.Stdout, specs...); err != nil {
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// testExit converts a failing test command into the matching process exit code.
+func testExit(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return cli.Exit("", exitErr.ExitCode())
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/byteorem/blink/internal/detect"
+	"github.com/byteorem/blink/internal/flavor"
+	"github.com/byteorem/blink/internal/toc"
+	"github.com/urfave/cli/v2"
+)
+
+func tocCommand() *cli.Command {
+	fileFlag := &cli.StringFlag{
+		Name:  "file",
+		Usage: "Operate on this .toc file only (default: the addon's .toc files)",
+	}
+	return &cli.Command{
+		Name:  "toc",
+		Usage: "Read and modify .toc metadata",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "get",
+				Usage:     "Print the value of a metadata field",
+				ArgsUsage: "<field>",
+				Flags:     []cli.Flag{fileFlag},
+				Action:    runTocGet,
+			},
+			{
+				Name:      "set",
+				Usage:     "Set a metadata field, preserving the file's layout",
+				ArgsUsage: "<field> <value>",
+				Flags: []cli.Flag{fileFlag, &cli.BoolFlag{
+					Name:  "all",
+					Usage: "Set a flavor's field such as Interface in every .toc file, not just the one --flavor picks",
+				}},
+				Action: runTocSet,
+			},
+			{
+				Name:   "list",
+				Usage:  "Print all metadata fields",
+				Flags:  []cli.Flag{fileFlag},
+				Action: runTocList,
+			},
+		},
+	}
+}
+
+// tocPaths returns the .toc files a toc subcommand operates on, with the
+// file the client of the first --flavor loads first, or else the file
+// matching the addon name.
+func tocPaths(c *cli.Context) ([]string, error) {
+	if f := c.String("file"); f != "" {
+		return []string{f}, nil
+	}
+
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	srcDirs, addonName, err := findAddon(cfg)
+	if err != nil {
+		return nil, err
+	}
+	srcDir := srcDirs[0]
+	names, err := detect.TocFiles(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no .toc file found in %s", srcDir)
+	}
+
+	primary := addonName + ".toc"
+	if flavors := c.StringSlice("flavor"); len(flavors) > 0 {
+		if fl, ok := flavor.Lookup(flavors[0]); ok {
+			if t, ok := detect.TocFor(srcDir, fl); ok {
+				primary = t
+			}
+		}
+	}
+
+	var paths []string
+	for _, name := range names {
+		p := filepath.Join(srcDir, name)
+		if strings.EqualFold(name, primary) {
+			paths = append([]string{p}, paths...)
+		} else {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+func runTocGet(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("usage: blink toc get <field>")
+	}
+	paths, err := tocPaths(c)
+	if err != nil {
+		return err
+	}
+	f, err := toc.Read(paths[0])
+	if err != nil {
+		return err
+	}
+	value, ok := f.Get(c.Args().First())
+	if !ok {
+		return cli.Exit(fmt.Sprintf("%s has no %q field", filepath.Base(paths[0]), c.Args().First()), 1)
+	}
+	fmt.Println(value)
+	return nil
+}
+
+// flavorFields are the fields whose value differs between the .toc files of
+// each flavor, so blink toc set writes them to one file unless --all is set.
+var flavorFields = []string{"Interface", "AllowLoadGameType"}
+
+func runTocSet(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return errors.New("usage: blink toc set <field> <value>")
+	}
+	paths, err := tocPaths(c)
+	if err != nil {
+		return err
+	}
+	key, value := c.Args().Get(0), c.Args().Get(1)
+	if !c.Bool("all") && slices.ContainsFunc(flavorFields, func(k string) bool { return strings.EqualFold(k, key) }) {
+		// The other files are for other flavors, with values of their own.
+		paths = paths[:1]
+	}
+	for _, p := range paths {
+		f, err := toc.Read(p)
+		if err != nil {
+			return err
+		}
+		f.Set(key, value)
+		if err := f.WriteFile(p); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s = %s\n", filepath.Base(p), key, value)
+	}
+	return nil
+}
+
+func runTocList(c *cli.Context) error {
+	paths, err := tocPaths(c)
+	if err != nil {
+		return err
+	}
+	f, err := toc.Read(paths[0])
+	if err != nil {
+		return err
+	}
+	for _, field := range f.Fields() {
+		fmt.Printf("%s: %s\n", field.Key, field.Value)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/workspace"
+	"github.com/byteorem/blink/internal/wowproc"
+)
+
+// uiPackFolders returns the uiPack paths of cfg as folders to sync into the
+// client at wowPath. They keep the files they replace and are never cleaned
+// or marked like addon folders.
+func uiPackFolders(cfg config.Config, wowPath, targetFlavor string) []*workspace.Addon {
+	folders := make([]*workspace.Addon, len(cfg.UIPack.Paths))
+	for i, p := range cfg.UIPack.Paths {
+		folders[i] = &workspace.Addon{
+			Name: filepath.ToSlash(p.Target),
+			Sources: []copier.Source{{
+				Dir:           p.Source,
+				Ignorer:       sourceIgnorer(cfg, p.Source, targetFlavor),
+				KeepOriginals: true,
+				Transform:     sourceTransform(cfg, p.Source),
+			}},
+			Target: filepath.Join(wowPath, filepath.FromSlash(p.Target)),
+			Pack:   true,
+		}
+	}
+	return folders
+}
+
+// wtfWarning returns a warning when a folder syncs into WTF while the client
+// runs, as it writes its settings over them on reload and logout.
+func wtfWarning(ctx context.Context, addons []*workspace.Addon) string {
+	for _, a := range addons {
+		if !a.Pack || !strings.EqualFold(strings.SplitN(a.Name, "/", 2)[0], "WTF") {
+			continue
+		}
+		if running, err := wowproc.Running(ctx); err == nil && running {
+			return fmt.Sprintf("WoW is running: it overwrites files synced into %s on /reload and logout — sync them with the client closed", a.Name)
+		}
+		return ""
+	}
+	return ""
+}
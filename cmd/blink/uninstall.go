@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/state"
+	"github.com/urfave/cli/v2"
+)
+
+func uninstallCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "uninstall",
+		Usage: "Remove every addon folder blink has synced, and blink's caches and state",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Don't ask for confirmation",
+			},
+		},
+		Action: runUninstall,
+	}
+}
+
+func runUninstall(c *cli.Context) error {
+	stateDir, err := state.Dir()
+	if err != nil {
+		return err
+	}
+	st, err := state.Open(stateDir)
+	if err != nil {
+		return fmt.Errorf("reading blink's state: %w", err)
+	}
+	var cacheDir string
+	if dir, err := os.UserCacheDir(); err == nil {
+		cacheDir = filepath.Join(dir, "blink")
+	}
+
+	// Folders that lost their marker may have been replaced by a release
+	// install since; those are left alone. Links made by blink link are
+	// removed without touching what they link to.
+	var targets, links []string
+	for _, t := range st.Targets {
+		switch {
+		case linkedTo(t.Path, t.Sources):
+			links = append(links, t.Path)
+		case copier.HasMarker(t.Path):
+			targets = append(targets, t.Path)
+		}
+	}
+
+	fmt.Println("This removes:")
+	for _, t := range slices.Concat(targets, links) {
+		fmt.Printf("  %s\n", t)
+	}
+	for _, t := range st.Packs {
+		fmt.Printf("  %s: the client files blink replaced are put back\n", t.Path)
+	}
+	if cacheDir != "" {
+		fmt.Printf("  %s (caches)\n", cacheDir)
+	}
+	fmt.Printf("  %s (state)\n", stateDir)
+
+	if !c.Bool("yes") {
+		ok, err := confirm("Continue?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return cli.Exit("", 1)
+		}
+	}
+
+	for _, t := range targets {
+		if err := os.RemoveAll(t); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", t)
+	}
+	for _, t := range links {
+		if err := os.Remove(t); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", t)
+	}
+	// Without the config, only the files blink replaced in UI pack folders
+	// are known; the ones it added stay.
+	for _, t := range st.Packs {
+		n, err := copier.RestoreOriginals(nil, t.Path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restored %d file(s) in %s\n", n, t.Path)
+	}
+	for _, dir := range []string{cacheDir, stateDir} {
+		if dir == "" {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	fmt.Println("blink's files are gone; remove the blink executable itself to finish")
+	return nil
+}
+
+// linkedTo reports whether target is a link to the first of sources.
+func linkedTo(target string, sources []string) bool {
+	return len(sources) > 0 && copier.LinksTo(target, sources[0])
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/byteorem/blink/internal/i18n"
+	"github.com/byteorem/blink/internal/wowproc"
+	"github.com/urfave/cli/v2"
+)
+
+// stopReason ends watch mode on its own, e.g. after --until, and is shown
+// when blink exits.
+type stopReason string
+
+func (r stopReason) Error() string { return string(r) }
+
+// wowPollInterval is how often --until-wow-exits checks the client is running.
+const wowPollInterval = 5 * time.Second
+
+// untilContext returns a context that also ends after --until and, with
+// --until-wow-exits, once the WoW client that was running (or started later)
+// exits. Its cause is then a stopReason.
+func untilContext(ctx context.Context, c *cli.Context) (context.Context, context.CancelFunc) {
+	cancelTimeout := context.CancelFunc(func() {})
+	if d := c.Duration("until"); d > 0 {
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, d, stopReason(i18n.Tf("stopped after %s (--until)", d)))
+	}
+	ctx, stop := withWowExit(ctx, c)
+	return ctx, func() { stop(); cancelTimeout() }
+}
+
+// withWowExit returns a context ending once the WoW client exits, when
+// --until-wow-exits is set. A client that isn't running yet is waited for.
+func withWowExit(ctx context.Context, c *cli.Context) (context.Context, context.CancelFunc) {
+	if !c.Bool("until-wow-exits") {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		seen := false
+		t := time.NewTicker(wowPollInterval)
+		defer t.Stop()
+		for {
+			running, err := wowproc.Running(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				slog.Warn("can't tell whether WoW is running — ignoring --until-wow-exits", "err", err)
+				return
+			}
+			if running {
+				seen = true
+			} else if seen {
+				cancel(stopReason(i18n.T("stopped, WoW exited (--until-wow-exits)")))
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+	return ctx, func() { cancel(nil) }
+}
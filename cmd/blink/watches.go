@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	gosync "sync"
+
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/watcher"
+	"github.com/byteorem/blink/internal/workspace"
+)
+
+// watchRoots returns the folders to watch for changes to addons synced to a
+// client of targetFlavor, or of any flavor when it is empty. The watches
+// still report changes to templates so generated files can be refreshed.
+func watchRoots(cfg config.Config, addons []*workspace.Addon, targetFlavor string) []watcher.Root {
+	watchCfg := cfg
+	watchCfg.Toc.Template = ""
+	var roots []watcher.Root
+	for _, a := range addons {
+		for _, src := range a.Sources {
+			roots = append(roots, watcher.Root{Dir: src.Dir, Ignorer: sourceIgnorer(watchCfg, src.Dir, targetFlavor)})
+		}
+		if cfg.TwoWay && !a.Pack {
+			roots = append(roots, watcher.Root{Dir: a.Target, Ignorer: a.TargetIgnorer(cfg.Toc.Variants())})
+		}
+	}
+	return roots
+}
+
+// watches runs the file watches of a session and passes their events on
+// through one channel, so the session can swap them for watches with other
+// ignore rules, e.g. after switching to a client of another flavor.
+type watches struct {
+	ctx  context.Context
+	opts watcher.Options
+	out  chan watcher.Event
+
+	mu   gosync.Mutex
+	stop context.CancelFunc // stops the current watches
+	done chan struct{}      // closed once all their events are passed on
+}
+
+// startWatches watches roots until ctx is done, when the events channel is
+// closed.
+func startWatches(ctx context.Context, roots []watcher.Root, opts watcher.Options) (*watches, error) {
+	w := &watches{ctx: ctx, opts: opts, out: make(chan watcher.Event, 64)}
+	if err := w.start(roots); err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		<-w.done
+		close(w.out)
+	}()
+	return w, nil
+}
+
+// events returns the channel the changes come in on.
+func (w *watches) events() <-chan watcher.Event {
+	return w.out
+}
+
+// restart replaces the watches with watches of roots. Changes made while
+// they restart are missed; re-sync afterwards.
+func (w *watches) restart(roots []watcher.Root) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stop()
+	<-w.done
+	return w.start(roots)
+}
+
+// start watches roots, stopping the watches already started when one fails.
+func (w *watches) start(roots []watcher.Root) error {
+	ctx, stop := context.WithCancel(w.ctx)
+	var chs []<-chan watcher.Event
+	var err error
+	for _, r := range roots {
+		var ch <-chan watcher.Event
+		if ch, err = watcher.Watch(ctx, r.Dir, r.Ignorer, w.opts); err != nil {
+			err = fmt.Errorf("failed to start watcher: %w", err)
+			stop()
+			break
+		}
+		chs = append(chs, ch)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Drain the watches until they are closed, so none is left
+		// blocked when they are stopped.
+		for ev := range watcher.Merge(chs...) {
+			select {
+			case w.out <- ev:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	w.stop, w.done = stop, done
+	return err
+}
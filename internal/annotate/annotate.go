@@ -0,0 +1,119 @@
+// Package annotate generates Lua language server configuration for an addon.
+package annotate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/byteorem/blink/internal/copier"
+)
+
+// FileName is the LuaLS configuration file written to the addon root.
+const FileName = ".luarc.json"
+
+// WowAPIRepo is the community-maintained WoW API annotation repository.
+const WowAPIRepo = "https://github.com/Ketho/vscode-wow-api.git"
+
+const schemaURL = "https://raw.githubusercontent.com/LuaLS/vscode-lua/master/setting/schema.json"
+
+// libDirNames are folder names conventionally used for embedded libraries.
+var libDirNames = map[string]bool{"libs": true, "lib": true, "libraries": true}
+
+// Settings returns the LuaLS settings blink manages for the addon at srcDir.
+// Top-level paths excluded by ig are hidden from the workspace, embedded
+// library folders are treated as libraries, and extra library paths (such as
+// fetched WoW API annotations) are added to the workspace library.
+func Settings(srcDir string, ig *copier.Ignorer, libraries []string) (map[string]any, error) {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	library := append([]string{}, libraries...)
+	ignoreDir := []string{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if ig.ShouldIgnore(e.Name()) {
+			ignoreDir = append(ignoreDir, e.Name())
+			continue
+		}
+		if libDirNames[strings.ToLower(e.Name())] {
+			library = append(library, e.Name())
+		}
+	}
+	sort.Strings(ignoreDir)
+
+	return map[string]any{
+		"$schema":                   schemaURL,
+		"runtime.version":           "Lua 5.1",
+		"runtime.builtin":           map[string]string{"io": "disable", "os": "disable", "package": "disable"},
+		"workspace.library":         library,
+		"workspace.ignoreDir":       ignoreDir,
+		"workspace.checkThirdParty": false,
+		"diagnostics.libraryFiles":  "Disable",
+	}, nil
+}
+
+// Write merges settings into the .luarc.json in srcDir, keeping any keys
+// blink does not manage. It returns the path written.
+func Write(srcDir string, settings map[string]any) (string, error) {
+	path := filepath.Join(srcDir, FileName)
+
+	merged := map[string]any{}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &merged); err != nil {
+			return "", fmt.Errorf("existing %s is not valid JSON: %w", FileName, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	for k, v := range settings {
+		merged[k] = v
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// CacheDir returns the directory fetched WoW API annotations are kept in.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blink", "vscode-wow-api"), nil
+}
+
+// AnnotationsPath returns the annotation folder inside a vscode-wow-api checkout.
+func AnnotationsPath(checkout string) string {
+	return filepath.Join(checkout, "Annotations")
+}
+
+// Fetch clones (or updates) the community WoW API annotations into dir.
+func Fetch(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return git("-C", dir, "pull", "--ff-only", "--quiet")
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return err
+	}
+	return git("clone", "--depth", "1", "--quiet", WowAPIRepo, dir)
+}
+
+func git(args ...string) error {
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("fetching WoW API annotations failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
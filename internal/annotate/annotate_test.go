@@ -0,0 +1,67 @@
+package annotate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/byteorem/blink/internal/copier"
+)
+
+func TestSettings(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "Libs", "LibStub"), 0o755)
+	_ = os.MkdirAll(filepath.Join(dir, "tests"), 0o755)
+	_ = os.MkdirAll(filepath.Join(dir, "Modules"), 0o755)
+
+	ig := copier.NewIgnorer(dir, []string{"tests/"}, false, false, false)
+	settings, err := Settings(dir, ig, []string{"/cache/Annotations"})
+	if err != nil {
+		t.Fatalf("Settings() error = %v", err)
+	}
+
+	library := settings["workspace.library"].([]string)
+	if len(library) != 2 || library[0] != "/cache/Annotations" || library[1] != "Libs" {
+		t.Errorf("workspace.library = %v, want [/cache/Annotations Libs]", library)
+	}
+	ignoreDir := settings["workspace.ignoreDir"].([]string)
+	if len(ignoreDir) != 1 || ignoreDir[0] != "tests" {
+		t.Errorf("workspace.ignoreDir = %v, want [tests]", ignoreDir)
+	}
+	if settings["runtime.version"] != "Lua 5.1" {
+		t.Errorf("runtime.version = %v, want Lua 5.1", settings["runtime.version"])
+	}
+}
+
+func TestWrite_MergesExisting(t *testing.T) {
+	dir := t.TempDir()
+	existing := `{"diagnostics.globals": ["MyGlobal"], "runtime.version": "Lua 5.4"}`
+	_ = os.WriteFile(filepath.Join(dir, FileName), []byte(existing), 0o644)
+
+	path, err := Write(dir, map[string]any{"runtime.version": "Lua 5.1"})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("written file is not JSON: %v", err)
+	}
+	if got["runtime.version"] != "Lua 5.1" {
+		t.Errorf("runtime.version = %v, want Lua 5.1", got["runtime.version"])
+	}
+	if _, ok := got["diagnostics.globals"]; !ok {
+		t.Error("unmanaged key diagnostics.globals was dropped")
+	}
+}
+
+func TestWrite_InvalidExisting(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, FileName), []byte("{not json"), 0o644)
+
+	if _, err := Write(dir, map[string]any{}); err == nil {
+		t.Fatal("Write() expected error for invalid existing .luarc.json")
+	}
+}
@@ -0,0 +1,180 @@
+// Package audit reports what the synced files of an addon are made of, to
+// keep its download size in check.
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/byteorem/blink/internal/copier"
+)
+
+// Kind is a type of file in an addon.
+type Kind string
+
+// The kinds of files an audit tells apart.
+const (
+	Code     Kind = "code"
+	Textures Kind = "textures"
+	Sounds   Kind = "sounds"
+	Fonts    Kind = "fonts"
+	Other    Kind = "other"
+)
+
+// Kinds lists the kinds in the order reports show them.
+var Kinds = []Kind{Code, Textures, Sounds, Fonts, Other}
+
+// kinds maps file extensions to their kind; the rest are Other.
+var kinds = map[string]Kind{
+	".lua": Code, ".xml": Code, ".toc": Code,
+	".blp": Textures, ".tga": Textures, ".png": Textures, ".jpg": Textures, ".jpeg": Textures,
+	".ogg": Sounds, ".mp3": Sounds, ".wav": Sounds,
+	".ttf": Fonts, ".otf": Fonts,
+}
+
+// KindOf returns the kind of the file at rel.
+func KindOf(rel string) Kind {
+	if k, ok := kinds[strings.ToLower(filepath.Ext(rel))]; ok {
+		return k
+	}
+	return Other
+}
+
+// IsMedia reports whether files of kind k are loaded by path from the
+// addon's code.
+func (k Kind) IsMedia() bool {
+	return k == Textures || k == Sounds || k == Fonts
+}
+
+// File is a synced file.
+type File struct {
+	Path string `json:"path"` // relative to the addon, slash-separated
+	Kind Kind   `json:"kind"`
+	Size int64  `json:"size"`
+}
+
+// Total is the number and size of a set of files.
+type Total struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+// Report is the audit of an addon.
+type Report struct {
+	Total        Total          `json:"total"`
+	Kinds        map[Kind]Total `json:"kinds"`
+	Oversized    []File         `json:"oversized,omitempty"`    // media over the size limit, largest first
+	Unreferenced []File         `json:"unreferenced,omitempty"` // media no code file mentions
+}
+
+// Run audits the files synced from srcs. Media files larger than maxMedia
+// bytes, if it is above 0, are reported as oversized.
+func Run(srcs []copier.Source, maxMedia int64) (Report, error) {
+	r := Report{Kinds: make(map[Kind]Total)}
+	var media []File
+	var code strings.Builder
+	for _, s := range srcs {
+		rels, err := copier.ListFiles(s.Dir, s.Ignorer)
+		if err != nil {
+			return r, err
+		}
+		for _, rel := range rels {
+			info, err := os.Stat(filepath.Join(s.Dir, rel))
+			if err != nil {
+				return r, err
+			}
+			f := File{Path: filepath.ToSlash(rel), Kind: KindOf(rel), Size: info.Size()}
+			r.Total.Files++
+			r.Total.Bytes += f.Size
+			t := r.Kinds[f.Kind]
+			t.Files++
+			t.Bytes += f.Size
+			r.Kinds[f.Kind] = t
+
+			switch {
+			case f.Kind.IsMedia():
+				media = append(media, f)
+			case f.Kind == Code:
+				data, err := os.ReadFile(filepath.Join(s.Dir, rel))
+				if err != nil {
+					return r, err
+				}
+				code.WriteString(normalize(string(data)))
+				code.WriteByte('\n')
+			}
+		}
+	}
+
+	refs := code.String()
+	for _, f := range media {
+		if maxMedia > 0 && f.Size > maxMedia {
+			r.Oversized = append(r.Oversized, f)
+		}
+		if !referenced(f.Path, refs) {
+			r.Unreferenced = append(r.Unreferenced, f)
+		}
+	}
+	sortBySize(r.Oversized)
+	sortBySize(r.Unreferenced)
+	return r, nil
+}
+
+// normalize lowercases code and turns the backslashes of WoW paths, escaped
+// or not, into slashes, so paths can be looked up in it.
+func normalize(code string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(strings.ToLower(code), `\\`, "/"), `\`, "/")
+}
+
+// referenced reports whether the normalized code mentions the media file at
+// rel. Textures are often named without their extension, and paths are
+// often built from a folder variable, so the file's name without its
+// extension is enough.
+func referenced(rel, code string) bool {
+	name := strings.ToLower(path.Base(rel))
+	name = strings.TrimSuffix(name, path.Ext(name))
+	return strings.Contains(code, name)
+}
+
+// sortBySize sorts files largest first, then by path.
+func sortBySize(files []File) {
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+}
+
+// Read returns the reports saved with Write in file by addon name, or none
+// when there is no file yet.
+func Read(file string) (map[string]Report, error) {
+	reports := make(map[string]Report)
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return reports, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// Write saves reports by addon name in file, for the next audit to compare
+// against.
+func Write(file string, reports map[string]Report) error {
+	data, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
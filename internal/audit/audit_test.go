@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/byteorem/blink/internal/copier"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel string, size int) {
+		_ = os.MkdirAll(filepath.Join(dir, filepath.Dir(rel)), 0o755)
+		_ = os.WriteFile(filepath.Join(dir, rel), make([]byte, size), 0o644)
+	}
+	write("Media/Background.tga", 4096)
+	write("Media/Old.tga", 100)
+	write("Media/Alert.ogg", 200)
+	write("README.md", 10)
+	_ = os.WriteFile(filepath.Join(dir, "Core.lua"), []byte(`f:SetTexture("Interface\\AddOns\\MyAddon\\Media\\Background")
+PlaySoundFile(media .. "alert.ogg")
+`), 0o644)
+
+	r, err := Run([]copier.Source{{Dir: dir, Ignorer: copier.NewIgnorer(dir, nil, false, false, false)}}, 1024)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if r.Total.Files != 5 || r.Kinds[Textures] != (Total{2, 4196}) || r.Kinds[Sounds].Files != 1 || r.Kinds[Code].Files != 1 || r.Kinds[Other].Files != 1 {
+		t.Errorf("totals = %+v, kinds %+v", r.Total, r.Kinds)
+	}
+	if len(r.Oversized) != 1 || r.Oversized[0].Path != "Media/Background.tga" {
+		t.Errorf("Oversized = %+v", r.Oversized)
+	}
+	if len(r.Unreferenced) != 1 || r.Unreferenced[0].Path != "Media/Old.tga" {
+		t.Errorf("Unreferenced = %+v", r.Unreferenced)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out", "audit.json")
+	if reports, err := Read(file); err != nil || len(reports) != 0 {
+		t.Errorf("Read() without a file = %v, %v", reports, err)
+	}
+	want := Report{Total: Total{3, 300}, Kinds: map[Kind]Total{Code: {3, 300}}}
+	if err := Write(file, map[string]Report{"MyAddon": want}); err != nil {
+		t.Fatal(err)
+	}
+	reports, err := Read(file)
+	if err != nil || reports["MyAddon"].Total != want.Total || reports["MyAddon"].Kinds[Code] != want.Kinds[Code] {
+		t.Errorf("Read() = %+v, %v", reports, err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package budget warns when the files synced for an addon grow past
+// configured limits, e.g. a large image that landed in the addon by mistake.
+package budget
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/byteorem/blink/internal/copier"
+)
+
+// Limits are the thresholds checked. A zero value means no limit.
+type Limits struct {
+	Files      int   // number of synced files
+	TotalBytes int64 // size of all synced files together
+	FileBytes  int64 // size of any one file
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+var units = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as "300MB", "1.5GB", "512KB" or a plain number
+// of bytes. Units are powers of 1024; an empty string is 0.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	upper := strings.ToUpper(s)
+	mult := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(upper, u.suffix) {
+			upper, mult = strings.TrimSpace(strings.TrimSuffix(upper, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(upper, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 50MB)", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// FormatSize renders n bytes with the largest unit that fits, e.g. "4.2 MB".
+func FormatSize(n int64) string {
+	for _, u := range units[:len(units)-1] {
+		if n >= u.bytes {
+			return fmt.Sprintf("%.1f %s", float64(n)/float64(u.bytes), u.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// Overrun is a limit the files go over.
+type Overrun struct {
+	Limit string // "files", "total" or "file"
+	File  string // for "file", the file over the budget
+	Text  string
+}
+
+// Check returns a warning for every limit the files of srcs go over, largest
+// files first.
+func Check(srcs []copier.Source, l Limits) ([]string, error) {
+	over, err := Over(srcs, l)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for _, o := range over {
+		warnings = append(warnings, o.Text)
+	}
+	return warnings, nil
+}
+
+// Over returns every limit the files of srcs go over, largest files first.
+func Over(srcs []copier.Source, l Limits) ([]Overrun, error) {
+	if l.IsZero() {
+		return nil, nil
+	}
+	type file struct {
+		rel  string
+		size int64
+	}
+	var files []file
+	var total int64
+	for _, s := range srcs {
+		rels, err := copier.ListFiles(s.Dir, s.Ignorer)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range rels {
+			info, err := os.Stat(filepath.Join(s.Dir, rel))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file{rel, info.Size()})
+			total += info.Size()
+		}
+	}
+
+	var over []Overrun
+	if l.Files > 0 && len(files) > l.Files {
+		over = append(over, Overrun{Limit: "files", Text: fmt.Sprintf("%d files synced, over the budget of %d", len(files), l.Files)})
+	}
+	if l.TotalBytes > 0 && total > l.TotalBytes {
+		over = append(over, Overrun{Limit: "total", Text: fmt.Sprintf("%s synced, over the budget of %s", FormatSize(total), FormatSize(l.TotalBytes))})
+	}
+	if l.FileBytes > 0 {
+		sort.Slice(files, func(i, j int) bool { return files[i].size > files[j].size })
+		for _, f := range files {
+			if f.size <= l.FileBytes {
+				break
+			}
+			over = append(over, Overrun{Limit: "file", File: f.rel, Text: fmt.Sprintf("%s is %s, over the file budget of %s", f.rel, FormatSize(f.size), FormatSize(l.FileBytes))})
+		}
+	}
+	return over, nil
+}
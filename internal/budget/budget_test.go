@@ -0,0 +1,74 @@
+package budget
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/byteorem/blink/internal/copier"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"", 0},
+		{"1024", 1024},
+		{"512KB", 512 << 10},
+		{"50MB", 50 << 20},
+		{"50 mb", 50 << 20},
+		{"1.5GB", 3 << 29},
+		{"10B", 10},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"MB", "lots", "-5MB"} {
+		if _, err := ParseSize(bad); err == nil {
+			t.Errorf("ParseSize(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	if got := FormatSize(300 << 20); got != "300.0 MB" {
+		t.Errorf("FormatSize = %q", got)
+	}
+	if got := FormatSize(12); got != "12 B" {
+		t.Errorf("FormatSize = %q", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "Core.lua"), []byte("-- core"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "Art.psd"), make([]byte, 4096), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "Skip.psd"), make([]byte, 8192), 0o644)
+	srcs := []copier.Source{{Dir: dir, Ignorer: copier.NewIgnorer(dir, []string{"Skip.psd"}, false, false, false)}}
+
+	warns, err := Check(srcs, Limits{})
+	if err != nil || warns != nil {
+		t.Fatalf("no limits: %v, %v", warns, err)
+	}
+
+	warns, err = Check(srcs, Limits{Files: 1, TotalBytes: 2048, FileBytes: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warns) != 3 {
+		t.Fatalf("warnings = %q, want 3", warns)
+	}
+	if !strings.HasPrefix(warns[0], "2 files") || !strings.HasPrefix(warns[2], "Art.psd is 4.0 KB") {
+		t.Errorf("warnings = %q", warns)
+	}
+
+	warns, _ = Check(srcs, Limits{Files: 2, TotalBytes: 1 << 20})
+	if len(warns) != 0 {
+		t.Errorf("within budget: %q", warns)
+	}
+}
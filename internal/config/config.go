@@ -2,54 +2,609 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/byteorem/blink/internal/budget"
+	"github.com/byteorem/blink/internal/flavor"
+	"github.com/byteorem/blink/internal/toc"
 )
 
 // Config holds blink configuration from blink.toml and CLI flags.
 type Config struct {
-	Source       string   `toml:"source"`
-	WowPath      string   `toml:"wowPath"`
-	Ignore       []string `toml:"ignore"`
-	UseGitignore bool     `toml:"useGitignore"`
-	UsePkgMeta   bool     `toml:"usePkgMeta"`
-	Delay        int      `toml:"delay"` // debounce delay in milliseconds
-	Verbose      bool     `toml:"verbose"`
+	Source           string   `toml:"-"` // single source path or "auto"; see Load
+	Sources          []string `toml:"-"` // set instead of Source when "source" is a list
+	WowPath          string   `toml:"wowPath"`
+	Targets          []string `toml:"targets"` // client folders synced at once, e.g. "_retail_" and "_classic_era_" next to wowPath, or paths
+	Flavor           string   `toml:"flavor"`  // client picked when wowPath is the WoW folder, as with --flavor
+	Ignore           []string `toml:"ignore"`
+	UseGitignore     bool     `toml:"useGitignore"`
+	UsePkgMeta       bool     `toml:"usePkgMeta"`
+	UseGitattributes bool     `toml:"useGitattributes"` // honor export-ignore in .gitattributes
+	Delay            int      `toml:"delay"`            // debounce delay in milliseconds
+	IdleSuspend      string   `toml:"idleSuspend"`      // e.g. "15m": poll instead of holding file watches after this long without changes
+	LowPower         bool     `toml:"lowPower"`         // slower spinner, longer debounce and fewer background checks, for laptops on battery
+	Verbose          bool     `toml:"verbose"`
+	LogLevel         string   `toml:"logLevel"`       // debug, info, warn or error; verbose means debug
+	TwoWay           bool     `toml:"twoWay"`         // also copy edits made in the AddOns folder back into the source
+	FollowSymlinks   bool     `toml:"followSymlinks"` // sync and watch through symlinked directories
+	Locale           string   `toml:"locale"`         // language of messages, e.g. "deDE"; empty follows the environment
+	TimeFormat       string   `toml:"timeFormat"`     // change timestamps: "24h", "12h", "datetime" or a Go time layout
+	TimeZone         string   `toml:"timeZone"`       // "local", "UTC" or an IANA name like "Europe/Berlin"
+
+	loc *time.Location // TimeZone, resolved by Load
+
+	TerminalTitle bool   `toml:"terminalTitle"` // show sync status in the terminal/tab title
+	Animations    bool   `toml:"animations"`    // spinner and smooth progress bar; off redraws only on changes
+	UpdateCheck   bool   `toml:"updateCheck"`   // look for a newer blink release once a day
+	StatusFile    string `toml:"statusFile"`    // JSON status for prompts/status bars, with a .txt one-liner next to it
+
+	SyntaxCheck    bool `toml:"syntaxCheck"`    // parse changed .lua files before copying
+	SkipInvalidLua bool `toml:"skipInvalidLua"` // don't copy .lua files that fail to parse
+
+	TrimWhitespace []string `toml:"trimWhitespace"` // patterns of files to strip trailing whitespace from on copy
+	Provenance     bool     `toml:"provenance"`     // note the source file, commit and sync time at the top of synced .lua files
+	BuildInfo      bool     `toml:"buildInfo"`      // write BlinkBuildInfo.lua with the checkout's commit, branch and sync time
+
+	Selene SeleneConfig `toml:"selene"`
+	Test   TestConfig   `toml:"test"`
+	Toc    TocConfig    `toml:"toc"`
+
+	Workspace WorkspaceConfig `toml:"workspace"`
+	Budget    BudgetConfig    `toml:"budget"`
+	Header    HeaderConfig    `toml:"header"`
+	Package   PackageConfig   `toml:"package"`
+	Inject    InjectConfig    `toml:"inject"`
+	Theme     ThemeConfig     `toml:"theme"`
+	UIPack    UIPackConfig    `toml:"uiPack"`
+
+	// FlavorFiles lists patterns that only sync to targets of a given flavor,
+	// keyed by flavor name (e.g. "retail", "classic_era").
+	FlavorFiles map[string][]string `toml:"flavorFiles"`
+
+	// Flavors defines flavors blink doesn't know yet by their client folder,
+	// keyed by name, e.g. plunderstorm = "_plunderstorm_".
+	Flavors map[string]string `toml:"flavors"`
+}
+
+// SeleneConfig controls running the selene linter on changed files.
+type SeleneConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Command string `toml:"command"` // selene executable
+	Std     string `toml:"std"`     // used when the addon has no selene.toml
+}
+
+// TestConfig controls the Lua test runner used by blink test.
+type TestConfig struct {
+	Command  string `toml:"command"`  // e.g. "busted" or "lua tests/run.lua"
+	Mocks    string `toml:"mocks"`    // WoW API mocks loaded before the tests
+	OnChange bool   `toml:"onChange"` // run affected tests before syncing in watch mode
+}
+
+// WorkspaceConfig controls syncing every addon in a multi-addon repository.
+type WorkspaceConfig struct {
+	Enabled bool     `toml:"enabled"` // discover addons in subdirectories of the blink.toml folder
+	Members []string `toml:"members"` // glob patterns of addon folders, used instead of discovery
+}
+
+// BudgetConfig sets limits on what an addon syncs; going over one is a
+// warning, not an error. Sizes are strings like "50MB"; unset means no limit.
+type BudgetConfig struct {
+	MaxFiles     int    `toml:"maxFiles"`
+	MaxTotalSize string `toml:"maxTotalSize"`
+	MaxFileSize  string `toml:"maxFileSize"`
+}
+
+// Limits returns the budget as limits to check against.
+func (b BudgetConfig) Limits() (budget.Limits, error) {
+	total, err := budget.ParseSize(b.MaxTotalSize)
+	if err != nil {
+		return budget.Limits{}, fmt.Errorf("budget.maxTotalSize: %w", err)
+	}
+	file, err := budget.ParseSize(b.MaxFileSize)
+	if err != nil {
+		return budget.Limits{}, fmt.Errorf("budget.maxFileSize: %w", err)
+	}
+	return budget.Limits{Files: b.MaxFiles, TotalBytes: total, FileBytes: file}, nil
+}
+
+// HeaderConfig sets a license or copyright notice put at the top of the .lua
+// files blink syncs and packages; the files in the source stay as they are.
+type HeaderConfig struct {
+	Text string `toml:"text"`
+	File string `toml:"file"` // read the notice from this file, relative to blink.toml, instead
+}
+
+// Banner returns the notice, reading it from File when set.
+func (h HeaderConfig) Banner() (string, error) {
+	if h.File == "" {
+		return h.Text, nil
+	}
+	data, err := os.ReadFile(h.File)
+	if err != nil {
+		return "", fmt.Errorf("header.file: %w", err)
+	}
+	return string(data), nil
+}
+
+// PackageConfig controls the release zips built by blink package.
+type PackageConfig struct {
+	Minify  []string `toml:"minify"`  // patterns of .lua files to strip comments and whitespace from
+	Sign    string   `toml:"sign"`    // "gpg" or "minisign" to sign SHA256SUMS; empty doesn't sign
+	SignKey string   `toml:"signKey"` // gpg key ID or email, or minisign secret key file
+	Out     string   `toml:"out"`     // folder the zips are written to, e.g. "dist"
+}
+
+// InjectConfig lists addon export strings kept in the repository that blink
+// inject writes into the client's SavedVariables.
+type InjectConfig struct {
+	Account   string   `toml:"account"`   // folder in WTF/Account; may be left out when there is only one
+	WeakAuras []string `toml:"weakAuras"` // glob patterns of files holding a WeakAuras export string each
+}
+
+// ThemeConfig changes the look of the TUI, for terminal themes the default
+// colors clash with and fonts without emoji. Colors are ANSI numbers like
+// "212" or hex like "#ff87d7"; unset ones keep the default.
+type ThemeConfig struct {
+	Spinner string `toml:"spinner"` // one of SpinnerStyles
+	Emoji   bool   `toml:"emoji"`   // show ✨ in the header
+	Accent  string `toml:"accent"`  // header, spinner and progress bar
+	Success string `toml:"success"` // copied files and the header's dots
+	Warning string `toml:"warning"`
+	Error   string `toml:"error"` // errors and removed files
+	Path    string `toml:"path"`  // file paths
+}
+
+// SpinnerStyles are the spinners theme.spinner picks from.
+var SpinnerStyles = []string{"dot", "minidot", "line", "jump", "pulse", "points", "meter", "ellipsis", "hamburger", "globe", "moon"}
+
+// colorPattern matches the colors a theme accepts.
+var colorPattern = regexp.MustCompile(`^(\d{1,3}|#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6})$`)
+
+// colors returns the theme's colors by key, in key order.
+func (t ThemeConfig) colors() [][2]string {
+	return [][2]string{{"accent", t.Accent}, {"error", t.Error}, {"path", t.Path}, {"success", t.Success}, {"warning", t.Warning}}
+}
+
+// UIPackConfig lists folders of a UI pack synced outside the AddOns folder,
+// such as fonts and textures under Interface or profiles under WTF.
+type UIPackConfig struct {
+	Paths []UIPackPath `toml:"paths"`
 }
 
+// UIPackPath syncs the folder Source into Target, relative to the client
+// folder, e.g. "Interface/Fonts".
+type UIPackPath struct {
+	Source string `toml:"source"`
+	Target string `toml:"target"`
+}
+
+// uiPackRoots are the folders of the client UI pack paths may sync into.
+var uiPackRoots = []string{"Interface", "WTF"}
+
+// checkTarget returns why p can't be synced into, or nil.
+func (p UIPackPath) checkTarget() error {
+	target := filepath.ToSlash(p.Target)
+	parts := strings.Split(target, "/")
+	switch {
+	case p.Source == "":
+		return errors.New("source is missing")
+	case target == "" || path.IsAbs(target) || filepath.IsAbs(p.Target) || path.Clean(target) != target || slices.Contains(parts, ".."):
+		return fmt.Errorf("target %q is not a plain path relative to the client folder", p.Target)
+	case !slices.ContainsFunc(uiPackRoots, func(r string) bool { return strings.EqualFold(r, parts[0]) }):
+		return fmt.Errorf("target %q is not under Interface or WTF", p.Target)
+	case len(parts) == 1:
+		return fmt.Errorf("target %q is the whole %s folder; name a folder in it", p.Target, parts[0])
+	case strings.EqualFold(parts[0], "Interface") && strings.EqualFold(parts[1], "AddOns"):
+		return fmt.Errorf("target %q is in AddOns, which blink syncs as addons", p.Target)
+	}
+	return nil
+}
+
+// TocConfig controls generating flavor-specific .toc files from a template.
+type TocConfig struct {
+	Template string               `toml:"template"` // relative to the addon source
+	Flavors  map[string]TocFlavor `toml:"flavors"`  // keyed by .toc suffix, e.g. "Mainline"
+}
+
+// TocFlavor holds the per-flavor values of a generated .toc.
+type TocFlavor struct {
+	Interface string   `toml:"interface"`
+	Files     []string `toml:"files"`
+}
+
+// Variants returns the configured .toc variants sorted by suffix.
+func (t TocConfig) Variants() []toc.Variant {
+	variants := make([]toc.Variant, 0, len(t.Flavors))
+	for suffix, f := range t.Flavors {
+		variants = append(variants, toc.Variant{Suffix: suffix, Interface: f.Interface, Files: f.Files})
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Suffix < variants[j].Suffix })
+	return variants
+}
+
+// IgnorePatterns returns the patterns excluded from the sync set of a target
+// with the given flavor: the configured ignore list, files blink generates
+// output from, and files reserved for other flavors. An empty flavor (target
+// flavor unknown) keeps every flavor's files.
+func (c Config) IgnorePatterns(flavorName string) []string {
+	patterns := append([]string{}, c.Ignore...)
+	if c.Toc.Template != "" {
+		patterns = append(patterns, "/"+filepath.ToSlash(filepath.Clean(c.Toc.Template)))
+	}
+	// Release zips written inside the addon aren't part of it; dist/
+	// is always ignored.
+	if out := filepath.Clean(c.Package.Out); filepath.IsLocal(out) && out != "dist" {
+		patterns = append(patterns, "/"+filepath.ToSlash(out)+"/")
+	}
+	if flavorName == "" {
+		return patterns
+	}
+
+	// A test client also gets the files of the flavor it previews.
+	f, ok := flavor.Lookup(flavorName)
+	if !ok {
+		f = flavor.Flavor{Name: flavorName}
+	}
+	skip := make(map[string]bool) // the target's own files, and patterns already listed
+	for name, files := range c.FlavorFiles {
+		if f.Runs(name) {
+			for _, p := range files {
+				skip[p] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(c.FlavorFiles))
+	for name := range c.FlavorFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if f.Runs(name) {
+			continue
+		}
+		for _, p := range c.FlavorFiles[name] {
+			if !skip[p] {
+				patterns = append(patterns, p)
+				skip[p] = true
+			}
+		}
+	}
+	return patterns
+}
+
+// flavorName is what a custom flavor may be called.
+var flavorName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// checkFlavor reports what is wrong with the custom flavor name, whose
+// client folder is dir.
+func checkFlavor(name, dir string) error {
+	if !flavorName.MatchString(name) {
+		return fmt.Errorf("flavors.%s: flavor names are lowercase letters, digits and _", name)
+	}
+	if f, ok := flavor.Lookup(name); ok && !f.Custom {
+		return fmt.Errorf("flavors.%s: %s is built in", name, name)
+	}
+	if dir == "" || dir == "." || dir == ".." || strings.ContainsAny(dir, `/\`) {
+		return fmt.Errorf("flavors.%s: %q is not a client folder name like \"_%s_\"", name, dir, name)
+	}
+	if f, ok := flavor.FromDir(dir); ok && !f.Custom {
+		return fmt.Errorf("flavors.%s: %s is the folder of the built-in %s flavor", name, dir, f.Name)
+	}
+	return nil
+}
+
+// LowPowerDelay is the shortest debounce delay, in milliseconds, in low power
+// mode.
+const LowPowerDelay = 300
+
 // Defaults returns a Config with default values.
 func Defaults() Config {
 	return Config{
-		Source:       "auto",
-		WowPath:      "auto",
-		Ignore:       []string{},
-		UseGitignore: true,
-		UsePkgMeta:   true,
-		Delay:        50,
+		Source:           "auto",
+		WowPath:          "auto",
+		Ignore:           []string{},
+		UseGitignore:     true,
+		UsePkgMeta:       true,
+		UseGitattributes: true,
+		Delay:            50,
+		SyntaxCheck:      true,
+		TerminalTitle:    true,
+		Animations:       true,
+		UpdateCheck:      true,
+		Theme: ThemeConfig{
+			Spinner: "dot",
+			Emoji:   true,
+		},
+		Selene: SeleneConfig{
+			Command: "selene",
+			Std:     "lua51+wow",
+		},
+		Test: TestConfig{
+			Command: "busted",
+		},
+		Package: PackageConfig{
+			Out: "dist",
+		},
 	}
 }
 
-// Load reads blink.toml if present and returns the merged config.
+// LocalFile holds machine-specific overrides, e.g. a developer's own wowPath.
+// It is merged over blink.toml and is meant to stay out of version control.
+const LocalFile = "blink.local.toml"
+
+// Load reads blink.toml and then blink.local.toml, where present, and returns
+// the merged config. Keys set in blink.local.toml replace those in blink.toml.
 func Load() (Config, error) {
+	return LoadFile("blink.toml")
+}
+
+// LoadFile is Load with the config file at path instead of ./blink.toml. The
+// blink.local.toml next to it is merged over it.
+func LoadFile(path string) (Config, error) {
 	cfg := Defaults()
 
-	if _, err := os.Stat("blink.toml"); os.IsNotExist(err) {
-		return cfg, nil
+	for _, path := range Files(path) {
+		if err := decodeFile(path, &cfg); err != nil {
+			return cfg, err
+		}
 	}
 
-	if _, err := toml.DecodeFile("blink.toml", &cfg); err != nil {
-		return cfg, fmt.Errorf("failed to parse blink.toml: %w", err)
+	if errs := cfg.fieldErrors(); len(errs) > 0 {
+		return cfg, fmt.Errorf("blink.toml: %w", errs[0].err)
+	}
+	if cfg.TimeZone != "" && !strings.EqualFold(cfg.TimeZone, "local") {
+		cfg.loc, _ = time.LoadLocation(cfg.TimeZone)
 	}
 
 	return cfg, nil
 }
 
+// Find returns the blink.toml in dir or the nearest directory above it, the
+// way git finds .git, and false when there is none up to the root.
+func Find(dir string) (string, bool) {
+	for {
+		path := filepath.Join(dir, "blink.toml")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Files returns the config file at path and the blink.local.toml next to it,
+// leaving out those that don't exist.
+func Files(path string) []string {
+	var files []string
+	for _, p := range []string{path, filepath.Join(filepath.Dir(path), LocalFile)} {
+		if _, err := os.Stat(p); err == nil {
+			files = append(files, p)
+		}
+	}
+	return files
+}
+
+// fieldError is an invalid value, with the dotted key it was set under.
+type fieldError struct {
+	key string
+	err error
+}
+
+// fieldErrors returns the values Load rejects, in key order.
+func (c Config) fieldErrors() []fieldError {
+	var errs []fieldError
+	if _, ok := flavor.Lookup(c.Flavor); c.Flavor != "" && !ok && c.Flavors[c.Flavor] == "" {
+		errs = append(errs, fieldError{"flavor", fmt.Errorf("flavor: unknown flavor %q", c.Flavor)})
+	}
+	names := make([]string, 0, len(c.FlavorFiles))
+	for name := range c.FlavorFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := flavor.Lookup(name); !ok && c.Flavors[name] == "" {
+			errs = append(errs, fieldError{"flavorFiles." + name, fmt.Errorf("unknown flavor %q in flavorFiles", name)})
+		}
+	}
+	names = names[:0]
+	for name := range c.Flavors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := checkFlavor(name, c.Flavors[name]); err != nil {
+			errs = append(errs, fieldError{"flavors." + name, err})
+		}
+	}
+	if _, err := budget.ParseSize(c.Budget.MaxTotalSize); err != nil {
+		errs = append(errs, fieldError{"budget.maxTotalSize", fmt.Errorf("budget.maxTotalSize: %w", err)})
+	}
+	if _, err := budget.ParseSize(c.Budget.MaxFileSize); err != nil {
+		errs = append(errs, fieldError{"budget.maxFileSize", fmt.Errorf("budget.maxFileSize: %w", err)})
+	}
+	if c.IdleSuspend != "" {
+		if d, err := time.ParseDuration(c.IdleSuspend); err != nil || d < 0 {
+			errs = append(errs, fieldError{"idleSuspend", fmt.Errorf("idleSuspend: %q is not a duration like \"15m\"", c.IdleSuspend)})
+		}
+	}
+	for _, t := range c.Targets {
+		if strings.TrimSpace(t) == "" {
+			errs = append(errs, fieldError{"targets", errors.New("targets: empty entry")})
+			break
+		}
+	}
+	if len(c.Targets) > 1 && c.TwoWay {
+		errs = append(errs, fieldError{"targets", errors.New("targets: twoWay syncs to a single client, not several")})
+	}
+	if c.Header.Text != "" && c.Header.File != "" {
+		errs = append(errs, fieldError{"header.file", errors.New("header: set either text or file, not both")})
+	}
+	switch c.Package.Sign {
+	case "", "gpg":
+	case "minisign":
+		if c.Package.SignKey == "" {
+			errs = append(errs, fieldError{"package.signKey", errors.New("package.signKey: minisign needs the secret key file")})
+		}
+	default:
+		errs = append(errs, fieldError{"package.sign", fmt.Errorf("package.sign: unknown signing tool %q (gpg or minisign)", c.Package.Sign)})
+	}
+	if strings.TrimSpace(c.Package.Out) == "" {
+		errs = append(errs, fieldError{"package.out", errors.New("package.out: the zips need a folder")})
+	}
+	if !slices.Contains(SpinnerStyles, c.Theme.Spinner) {
+		errs = append(errs, fieldError{"theme.spinner", fmt.Errorf("theme.spinner: unknown spinner %q (one of %s)", c.Theme.Spinner, strings.Join(SpinnerStyles, ", "))})
+	}
+	for _, kv := range c.Theme.colors() {
+		if kv[1] == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(kv[1]); !colorPattern.MatchString(kv[1]) || err == nil && n > 255 {
+			errs = append(errs, fieldError{"theme." + kv[0], fmt.Errorf("theme.%s: %q is not a color like \"212\" or \"#ff87d7\"", kv[0], kv[1])})
+		}
+	}
+	if c.TimeZone != "" && !strings.EqualFold(c.TimeZone, "local") {
+		if _, err := time.LoadLocation(c.TimeZone); err != nil {
+			errs = append(errs, fieldError{"timeZone", fmt.Errorf("timeZone: %w", err)})
+		}
+	}
+	for _, p := range c.UIPack.Paths {
+		if err := p.checkTarget(); err != nil {
+			errs = append(errs, fieldError{"uiPack.paths", fmt.Errorf("uiPack.paths: %w", err)})
+		}
+	}
+	return errs
+}
+
+// decodeFile decodes the config file at path over cfg.
+func decodeFile(path string, cfg *Config) error {
+	// "source" is either a path or a list of paths, so it is decoded separately.
+	file := struct {
+		Config
+		Source toml.Primitive `toml:"source"`
+	}{Config: *cfg}
+	md, err := toml.DecodeFile(path, &file)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	*cfg = file.Config
+	if md.IsDefined("source") {
+		if err := decodeSource(md, file.Source, cfg); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func decodeSource(md toml.MetaData, prim toml.Primitive, cfg *Config) error {
+	var single string
+	if err := md.PrimitiveDecode(prim, &single); err == nil {
+		cfg.Source = single
+		return nil
+	}
+	var list []string
+	if err := md.PrimitiveDecode(prim, &list); err != nil || len(list) == 0 {
+		return fmt.Errorf("source must be a path or a non-empty list of paths")
+	}
+	if len(list) == 1 {
+		cfg.Source = list[0]
+	} else {
+		cfg.Source = ""
+		cfg.Sources = list
+	}
+	return nil
+}
+
+// SourceList returns the configured source paths: Sources when a list was
+// given, otherwise Source alone.
+func (c Config) SourceList() []string {
+	if len(c.Sources) > 0 {
+		return c.Sources
+	}
+	return []string{c.Source}
+}
+
+// MultiAddon reports whether several addons are synced at once: in a
+// workspace, or when the source is a glob pattern.
+func (c Config) MultiAddon() bool {
+	return c.Workspace.Enabled || len(c.AddonPatterns()) > 0
+}
+
+// AddonPatterns returns the glob patterns that pick the addon folders when
+// several addons are synced: workspace members, or a source with wildcards.
+// It returns nil when addons are discovered instead.
+func (c Config) AddonPatterns() []string {
+	if len(c.Workspace.Members) > 0 {
+		return c.Workspace.Members
+	}
+	if c.SourceIsGlob() {
+		return []string{c.Source}
+	}
+	return nil
+}
+
+// SourceIsGlob reports whether the source is a glob pattern, e.g.
+// "packages/Addon*", rather than a path.
+func (c Config) SourceIsGlob() bool {
+	return len(c.Sources) == 0 && strings.ContainsAny(c.Source, "*?[")
+}
+
+// LogLevelName returns the configured log level: logLevel if set, otherwise
+// debug when verbose and info when not.
+func (c Config) LogLevelName() string {
+	switch {
+	case c.LogLevel != "":
+		return c.LogLevel
+	case c.Verbose:
+		return "debug"
+	}
+	return "info"
+}
+
+// timeLayouts are the named timeFormat presets.
+var timeLayouts = map[string]string{
+	"":         "15:04:05",
+	"24h":      "15:04:05",
+	"12h":      "3:04:05 PM",
+	"datetime": "2006-01-02 15:04:05",
+}
+
+// FormatTime renders a change timestamp with timeFormat, in timeZone.
+func (c Config) FormatTime(t time.Time) string {
+	layout, ok := timeLayouts[c.TimeFormat]
+	if !ok {
+		layout = c.TimeFormat
+	}
+	if c.loc != nil {
+		t = t.In(c.loc)
+	}
+	return t.Format(layout)
+}
+
+// IdleDuration returns IdleSuspend as a duration, 0 when unset.
+func (c Config) IdleDuration() time.Duration {
+	d, _ := time.ParseDuration(c.IdleSuspend)
+	return d
+}
+
 // MergeFlags overrides config values with non-empty CLI flags.
 func MergeFlags(cfg *Config, source, wowPath string, delay int, verbose bool) {
 	if source != "" {
 		cfg.Source = source
+		cfg.Sources = nil
 	}
 	if wowPath != "" {
 		cfg.WowPath = wowPath
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestDefaults(t *testing.T) {
@@ -24,6 +26,18 @@ func TestDefaults(t *testing.T) {
 	if cfg.UsePkgMeta != true {
 		t.Error("UsePkgMeta = false, want true")
 	}
+	if !cfg.UseGitattributes {
+		t.Error("UseGitattributes = false, want true")
+	}
+	if cfg.SyntaxCheck != true {
+		t.Error("SyntaxCheck = false, want true")
+	}
+	if cfg.SkipInvalidLua != false {
+		t.Error("SkipInvalidLua = true, want false")
+	}
+	if cfg.TerminalTitle != true {
+		t.Error("TerminalTitle = false, want true")
+	}
 }
 
 func TestLoad_NoFile(t *testing.T) {
@@ -114,3 +128,503 @@ func TestMergeFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_TocFlavors(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	toml := `[toc]
+template = "MyAddon.toc.tmpl"
+
+[toc.flavors.Vanilla]
+interface = "11507"
+files = ["Classic/Init.lua"]
+
+[toc.flavors.Mainline]
+interface = "110200"
+`
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte(toml), 0o644)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	variants := cfg.Toc.Variants()
+	if len(variants) != 2 {
+		t.Fatalf("len(Variants()) = %d, want 2", len(variants))
+	}
+	if variants[0].Suffix != "Mainline" || variants[1].Suffix != "Vanilla" {
+		t.Errorf("Variants() order = %s, %s, want Mainline, Vanilla", variants[0].Suffix, variants[1].Suffix)
+	}
+	if len(variants[1].Files) != 1 || variants[1].Interface != "11507" {
+		t.Errorf("Vanilla variant = %+v", variants[1])
+	}
+
+	patterns := cfg.IgnorePatterns("")
+	if len(patterns) != 1 || patterns[0] != "/MyAddon.toc.tmpl" {
+		t.Errorf("IgnorePatterns() = %v, want [/MyAddon.toc.tmpl]", patterns)
+	}
+}
+
+func TestIgnorePatterns_FlavorFiles(t *testing.T) {
+	cfg := Defaults()
+	cfg.Ignore = []string{"*.bak"}
+	cfg.FlavorFiles = map[string][]string{
+		"retail":      {"Retail/"},
+		"classic":     {"Classic/", "Shared/Classic.lua"},
+		"classic_era": {"Classic/", "Era/"},
+	}
+
+	got := cfg.IgnorePatterns("classic")
+	want := []string{"*.bak", "Era/", "Retail/"}
+	if len(got) != len(want) {
+		t.Fatalf("IgnorePatterns(classic) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("IgnorePatterns(classic)[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if got := cfg.IgnorePatterns(""); len(got) != 1 {
+		t.Errorf("IgnorePatterns(\"\") = %v, want only the ignore list", got)
+	}
+
+	// A test client syncs its own files and those of the flavor it previews.
+	cfg.FlavorFiles["ptr"] = []string{"PTR/"}
+	if got, want := cfg.IgnorePatterns("ptr"), []string{"*.bak", "Classic/", "Shared/Classic.lua", "Era/"}; !slices.Equal(got, want) {
+		t.Errorf("IgnorePatterns(ptr) = %v, want %v", got, want)
+	}
+	if got, want := cfg.IgnorePatterns("retail"), []string{"*.bak", "Classic/", "Shared/Classic.lua", "Era/", "PTR/"}; !slices.Equal(got, want) {
+		t.Errorf("IgnorePatterns(retail) = %v, want %v", got, want)
+	}
+}
+
+func TestLoad_UnknownFlavorFiles(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[flavorFiles]\nwotlk = [\"Wrath/\"]\n"), 0o644)
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() expected error for unknown flavor in flavorFiles")
+	}
+}
+
+func TestLoad_Flavors(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("flavors = { plunderstorm = \"_plunderstorm_\" }\n\n[flavorFiles]\nplunderstorm = [\"Storm/\"]\n"), 0o644)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Flavors["plunderstorm"] != "_plunderstorm_" {
+		t.Errorf("Flavors = %v", cfg.Flavors)
+	}
+
+	for _, bad := range []string{
+		"flavors = { retail = \"_retail2_\" }\n",
+		"flavors = { Storm = \"_storm_\" }\n",
+		"flavors = { storm = \"../_storm_\" }\n",
+		"flavors = { storm = \"_classic_era_\" }\n",
+	} {
+		_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte(bad), 0o644)
+		if _, err := Load(); err == nil {
+			t.Errorf("Load() accepted %q", bad)
+		}
+	}
+}
+
+func TestLoad_Flavor(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("flavor = \"plunderstorm\"\nflavors = { plunderstorm = \"_plunderstorm_\" }\n"), 0o644)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Flavor != "plunderstorm" {
+		t.Errorf("Flavor = %q, want plunderstorm", cfg.Flavor)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("flavor = \"storm\"\n"), 0o644)
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted an unknown flavor")
+	}
+}
+
+func TestLoad_SourceList(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte(`source = ["../common", "."]`+"\n"), 0o644)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.SourceList(); len(got) != 2 || got[0] != "../common" || got[1] != "." {
+		t.Errorf("SourceList() = %v, want [../common .]", got)
+	}
+
+	MergeFlags(&cfg, "/other", "", 0, false)
+	if got := cfg.SourceList(); len(got) != 1 || got[0] != "/other" {
+		t.Errorf("SourceList() after --source = %v, want [/other]", got)
+	}
+}
+
+func TestLoad_SourceInvalid(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("source = 3\n"), 0o644)
+
+	if _, err := Load(); err == nil {
+		t.Error("expected error for non-path source")
+	}
+}
+
+func TestLoad_Workspace(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[workspace]\nenabled = true\n"), 0o644)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Workspace.Enabled {
+		t.Error("Workspace.Enabled = false, want true")
+	}
+}
+
+func TestLoad_Budget(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[budget]\nmaxFiles = 500\nmaxFileSize = \"5MB\"\n"), 0o644)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	l, err := cfg.Budget.Limits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Files != 500 || l.FileBytes != 5<<20 || l.TotalBytes != 0 {
+		t.Errorf("Limits() = %+v", l)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[budget]\nmaxTotalSize = \"huge\"\n"), 0o644)
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted an invalid size")
+	}
+}
+
+func TestLoad_Header(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "HEADER.txt"), []byte("Copyright (c) 2026 Jane Doe\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[header]\nfile = \"HEADER.txt\"\n"), 0o644)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if banner, err := cfg.Header.Banner(); err != nil || banner != "Copyright (c) 2026 Jane Doe\n" {
+		t.Errorf("Banner() = %q, %v", banner, err)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[header]\ntext = \"MIT\"\nfile = \"HEADER.txt\"\n"), 0o644)
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted both header.text and header.file")
+	}
+}
+
+func TestLoad_IdleSuspend(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("idleSuspend = \"15m\"\n"), 0o644)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.IdleDuration() != 15*time.Minute {
+		t.Errorf("IdleDuration() = %v, want 15m", cfg.IdleDuration())
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("idleSuspend = \"soon\"\n"), 0o644)
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted an invalid idleSuspend")
+	}
+}
+
+func TestLoad_Theme(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[theme]\nspinner = \"line\"\nemoji = false\naccent = \"#ff87d7\"\npath = \"33\"\n"), 0o644)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := ThemeConfig{Spinner: "line", Accent: "#ff87d7", Path: "33"}
+	if cfg.Theme != want {
+		t.Errorf("Theme = %+v, want %+v", cfg.Theme, want)
+	}
+
+	for _, bad := range []string{"spinner = \"wheel\"", "accent = \"pink\"", "error = \"300\"", "warning = \"#12345\""} {
+		_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[theme]\n"+bad+"\n"), 0o644)
+		if _, err := Load(); err == nil {
+			t.Errorf("Load() accepted %s", bad)
+		}
+	}
+}
+
+func TestLoad_UIPack(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[[uiPack.paths]]\nsource = \"fonts\"\ntarget = \"Interface/Fonts\"\n\n[[uiPack.paths]]\nsource = \"wtf\"\ntarget = \"wtf/Account/ME\"\n"), 0o644)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []UIPackPath{{Source: "fonts", Target: "Interface/Fonts"}, {Source: "wtf", Target: "wtf/Account/ME"}}
+	if !slices.Equal(cfg.UIPack.Paths, want) {
+		t.Errorf("UIPack.Paths = %+v, want %+v", cfg.UIPack.Paths, want)
+	}
+
+	for _, bad := range []string{"Interface/AddOns/MyAddon", "interface/addons", "Interface", "Fonts", "Interface/../WTF", "/Interface/Fonts", ""} {
+		_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[[uiPack.paths]]\nsource = \"fonts\"\ntarget = \""+bad+"\"\n"), 0o644)
+		if _, err := Load(); err == nil {
+			t.Errorf("Load() accepted target %q", bad)
+		}
+	}
+}
+
+func TestLoad_Targets(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("targets = [\"_retail_\", \"classic_era\", \"/games/wow/_ptr_\"]\n"), 0o644)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []string{"_retail_", "classic_era", "/games/wow/_ptr_"}; !slices.Equal(cfg.Targets, want) {
+		t.Errorf("Targets = %v, want %v", cfg.Targets, want)
+	}
+
+	for _, bad := range []string{"targets = [\"_retail_\", \" \"]\n", "targets = [\"_retail_\", \"_classic_\"]\ntwoWay = true\n"} {
+		_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte(bad), 0o644)
+		if _, err := Load(); err == nil {
+			t.Errorf("Load() accepted %q", bad)
+		}
+	}
+}
+
+func TestLoad_LocalOverrides(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	shared := "wowPath = \"/shared/_retail_\"\ndelay = 100\nignore = [\"*.md\"]\n\n[selene]\nenabled = true\n"
+	local := "wowPath = \"/mine/_retail_\"\n\n[selene]\ncommand = \"/opt/selene\"\n"
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte(shared), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, LocalFile), []byte(local), 0o644)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.WowPath != "/mine/_retail_" {
+		t.Errorf("WowPath = %q, want the local one", cfg.WowPath)
+	}
+	if cfg.Delay != 100 || len(cfg.Ignore) != 1 {
+		t.Errorf("shared settings lost: delay=%d ignore=%v", cfg.Delay, cfg.Ignore)
+	}
+	if !cfg.Selene.Enabled || cfg.Selene.Command != "/opt/selene" {
+		t.Errorf("Selene = %+v, want enabled with the local command", cfg.Selene)
+	}
+}
+
+func TestLoad_LocalOnly(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_ = os.WriteFile(filepath.Join(dir, LocalFile), []byte("delay = 10\n"), 0o644)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Delay != 10 || !cfg.UseGitignore {
+		t.Errorf("Load() = delay %d, useGitignore %v; want local delay over defaults", cfg.Delay, cfg.UseGitignore)
+	}
+}
+
+func TestAddonPatterns(t *testing.T) {
+	cfg := Defaults()
+	if cfg.MultiAddon() || cfg.AddonPatterns() != nil {
+		t.Error("default config should sync a single addon")
+	}
+
+	cfg.Source = "packages/Addon*"
+	if !cfg.SourceIsGlob() || !cfg.MultiAddon() || len(cfg.AddonPatterns()) != 1 {
+		t.Errorf("glob source: patterns = %v", cfg.AddonPatterns())
+	}
+
+	cfg.Source = "auto"
+	cfg.Workspace.Members = []string{"a/*", "b/*"}
+	if !cfg.MultiAddon() || len(cfg.AddonPatterns()) != 2 {
+		t.Errorf("workspace members: patterns = %v", cfg.AddonPatterns())
+	}
+}
+
+func TestLogLevelName(t *testing.T) {
+	cfg := Defaults()
+	if got := cfg.LogLevelName(); got != "info" {
+		t.Errorf("default LogLevelName() = %q, want info", got)
+	}
+	cfg.Verbose = true
+	if got := cfg.LogLevelName(); got != "debug" {
+		t.Errorf("verbose LogLevelName() = %q, want debug", got)
+	}
+	cfg.LogLevel = "warn"
+	if got := cfg.LogLevelName(); got != "warn" {
+		t.Errorf("LogLevelName() = %q, want the configured warn", got)
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	ts := time.Date(2025, 3, 9, 14, 2, 11, 0, time.UTC)
+	if got := Defaults().FormatTime(ts.Local()); got != ts.Local().Format("15:04:05") {
+		t.Errorf("default FormatTime = %q", got)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("timeFormat = \"12h\"\ntimeZone = \"UTC\"\n"), 0o644)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.FormatTime(ts.Local()); got != "2:02:11 PM" {
+		t.Errorf("12h UTC FormatTime = %q, want 2:02:11 PM", got)
+	}
+
+	cfg.TimeFormat = "datetime"
+	if got := cfg.FormatTime(ts); got != "2025-03-09 14:02:11" {
+		t.Errorf("datetime FormatTime = %q", got)
+	}
+	cfg.TimeFormat = "15:04"
+	if got := cfg.FormatTime(ts); got != "14:02" {
+		t.Errorf("custom FormatTime = %q", got)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("timeZone = \"Mars/Olympus\"\n"), 0o644)
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted an unknown time zone")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dev.toml")
+	_ = os.WriteFile(path, []byte("source = \"./MyAddon\"\ndelay = 80\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, LocalFile), []byte("delay = 120\n"), 0o644)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.Source != "./MyAddon" {
+		t.Errorf("Source = %q, want %q", cfg.Source, "./MyAddon")
+	}
+	if cfg.Delay != 120 {
+		t.Errorf("Delay = %d, want 120 from %s", cfg.Delay, LocalFile)
+	}
+	if got := Files(filepath.Join(dir, "missing.toml")); len(got) != 1 || got[0] != filepath.Join(dir, LocalFile) {
+		t.Errorf("Files() = %v, want only %s", got, LocalFile)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "MyAddon", "Modules")
+	_ = os.MkdirAll(sub, 0o755)
+	_ = os.WriteFile(filepath.Join(root, "blink.toml"), []byte(""), 0o644)
+
+	for _, dir := range []string{root, sub} {
+		got, ok := Find(dir)
+		if want := filepath.Join(root, "blink.toml"); !ok || got != want {
+			t.Errorf("Find(%s) = %q, %v, want %q", dir, got, ok, want)
+		}
+	}
+	if got, ok := Find(t.TempDir()); ok {
+		t.Errorf("Find() without blink.toml = %q", got)
+	}
+}
+
+func TestLoad_PackageOut(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[package]\nout = \"build\"\n"), 0o644)
+
+	cfg, err := LoadFile(filepath.Join(dir, "blink.toml"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.Package.Out != "build" {
+		t.Errorf("Package.Out = %q, want build", cfg.Package.Out)
+	}
+	if got := cfg.IgnorePatterns(""); !slices.Contains(got, "/build/") {
+		t.Errorf("IgnorePatterns() = %v, want the zips' folder ignored", got)
+	}
+	if got := Defaults().IgnorePatterns(""); len(got) != 0 {
+		t.Errorf("IgnorePatterns() by default = %v, want none (dist/ is built in)", got)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "blink.toml"), []byte("[package]\nout = \"\"\n"), 0o644)
+	if _, err := LoadFile(filepath.Join(dir, "blink.toml")); err == nil {
+		t.Error("LoadFile() with an empty package.out: want error")
+	}
+}
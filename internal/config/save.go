@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+)
+
+// SaveSource sets source in the config file at path to dir, creating the
+// file if there is none. The rest of the file is left as it is.
+func SaveSource(path, dir string) error {
+	data, err := readForSave(path)
+	if err != nil {
+		return err
+	}
+	var line bytes.Buffer
+	if err := toml.NewEncoder(&line).Encode(map[string]string{"source": dir}); err != nil {
+		return err
+	}
+	// Keys at the top of the file can't end up inside a table.
+	return os.WriteFile(path, append(line.Bytes(), data...), 0o644)
+}
+
+// SaveWorkspace turns on workspace mode in the config file at path, creating
+// the file if there is none. The rest of the file is left as it is.
+func SaveWorkspace(path string) error {
+	data, err := readForSave(path)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		if !bytes.HasSuffix(data, []byte("\n")) {
+			data = append(data, '\n')
+		}
+		data = append(data, '\n')
+	}
+	data = append(data, "[workspace]\nenabled = true\n"...)
+	return os.WriteFile(path, data, 0o644)
+}
+
+// readForSave returns the config file at path, or nothing when it doesn't
+// exist yet. A file that already picks the addons to sync is not changed.
+func readForSave(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file map[string]any
+	md, err := toml.Decode(string(data), &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, key := range []string{"source", "workspace"} {
+		if md.IsDefined(key) {
+			return nil, fmt.Errorf("%s already sets %s", path, key)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blink.toml")
+	_ = os.WriteFile(path, []byte("# my addons\n[toc]\ntemplate = \"Addon.toc.tmpl\"\n"), 0o644)
+
+	if err := SaveSource(path, "AddonB"); err != nil {
+		t.Fatalf("SaveSource() error = %v", err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.Source != "AddonB" || cfg.Toc.Template != "Addon.toc.tmpl" {
+		t.Errorf("Source = %q, template = %q after saving", cfg.Source, cfg.Toc.Template)
+	}
+
+	if err := SaveSource(path, "AddonA"); err == nil {
+		t.Error("SaveSource() over a saved source: want error")
+	}
+	if err := SaveWorkspace(path); err == nil {
+		t.Error("SaveWorkspace() with a saved source: want error")
+	}
+}
+
+func TestSaveWorkspace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blink.toml")
+
+	if err := SaveWorkspace(path); err != nil {
+		t.Fatalf("SaveWorkspace() error = %v", err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if !cfg.Workspace.Enabled {
+		t.Error("Workspace.Enabled = false after saving")
+	}
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/byteorem/blink/internal/i18n"
+	"github.com/byteorem/blink/internal/logging"
+)
+
+// Issue is a problem found in a config file. Line is 0 when it isn't known.
+type Issue struct {
+	Line    int
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return i.Message
+	}
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+// decodeLine matches the position the toml package puts in front of a
+// decoding error, e.g. a string where a number was expected.
+var decodeLine = regexp.MustCompile(`^toml: line (\d+)(?: \(last key "[^"]*"\))?: `)
+
+// Validate checks the config file at path on its own, more strictly than
+// Load: besides syntax errors, type mismatches and values Load rejects, it
+// reports keys blink doesn't know (which Load ignores) and log levels and
+// locales that would only fail at startup.
+func Validate(path string) ([]Issue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+
+	cfg := Defaults()
+	file := struct {
+		Config
+		Source toml.Primitive `toml:"source"`
+	}{Config: cfg}
+	md, err := toml.Decode(text, &file)
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return []Issue{{Line: perr.Position.Line, Message: perr.Message}}, nil
+		}
+		msg := err.Error()
+		if m := decodeLine.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return []Issue{{Line: line, Message: msg[len(m[0]):]}}, nil
+		}
+		return []Issue{{Message: msg}}, nil
+	}
+	cfg = file.Config
+
+	var issues []Issue
+	if md.IsDefined("source") {
+		if err := decodeSource(md, file.Source, &cfg); err != nil {
+			issues = append(issues, Issue{keyLine(text, toml.Key{"source"}), err.Error()})
+		}
+	}
+
+	var unknown []toml.Key
+	for _, key := range md.Undecoded() {
+		if len(unknown) > 0 && hasPrefix(key, unknown[len(unknown)-1]) {
+			continue // inside an unknown table already reported
+		}
+		if key[0] == "source" {
+			continue // decoded by decodeSource
+		}
+		unknown = append(unknown, key)
+		issues = append(issues, Issue{keyLine(text, key), fmt.Sprintf("unknown key %q", key.String())})
+	}
+
+	// The toml package matches keys case-insensitively, so "wowpath" works
+	// but reads like a typo; point at the spelling the docs use.
+	for _, key := range md.Keys() {
+		if len(key) == 1 && key[0] == "source" {
+			continue
+		}
+		if want, ok := canonicalKey(reflect.TypeOf(Config{}), key); ok && want.String() != key.String() {
+			issues = append(issues, Issue{keyLine(text, key), fmt.Sprintf("key %q should be spelled %q", key.String(), want.String())})
+		}
+	}
+
+	for _, fe := range cfg.fieldErrors() {
+		issues = append(issues, Issue{keyLine(text, parseKey(fe.key)), fe.err.Error()})
+	}
+	if cfg.Delay < 0 {
+		issues = append(issues, Issue{keyLine(text, toml.Key{"delay"}), "delay must not be negative"})
+	}
+	if _, err := logging.ParseLevel(cfg.LogLevel); err != nil {
+		issues = append(issues, Issue{keyLine(text, toml.Key{"logLevel"}), err.Error()})
+	}
+	if cfg.Locale != "" {
+		if _, ok := i18n.Normalize(cfg.Locale); !ok {
+			issues = append(issues, Issue{keyLine(text, toml.Key{"locale"}),
+				fmt.Sprintf("unsupported locale %q (supported: %s)", cfg.Locale, strings.Join(i18n.Locales(), ", "))})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues, nil
+}
+
+// canonicalKey returns key with each part spelled as in the toml tags of t,
+// or false when some part doesn't name a field. Map keys are kept as given.
+func canonicalKey(t reflect.Type, key toml.Key) (toml.Key, bool) {
+	out := make(toml.Key, 0, len(key))
+	for _, part := range key {
+		switch t.Kind() {
+		case reflect.Map:
+			out = append(out, part)
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+		default:
+			return nil, false
+		}
+		field, ok := tomlField(t, part)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, field.Tag.Get("toml"))
+		t = field.Type
+		if t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+	}
+	return out, true
+}
+
+// tomlField finds the field of struct type t whose toml tag matches name,
+// ignoring case.
+func tomlField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if tag := f.Tag.Get("toml"); tag != "" && tag != "-" && strings.EqualFold(tag, name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func hasPrefix(key, prefix toml.Key) bool {
+	if len(key) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if key[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyLine returns the line where key is set or its table starts, or 0 when
+// it can't be found. It understands table headers and dotted keys, which is
+// all blink.toml needs.
+func keyLine(text string, key toml.Key) int {
+	want := strings.Join(key, ".")
+	var table toml.Key
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "["):
+			end := strings.LastIndex(line, "]")
+			if end < 0 {
+				continue
+			}
+			table = parseKey(strings.Trim(line[:end], "[]"))
+			if strings.Join(table, ".") == want {
+				return i + 1
+			}
+		default:
+			name, _, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			full := append(append(toml.Key{}, table...), parseKey(name)...)
+			if strings.Join(full, ".") == want {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// parseKey splits a dotted TOML key, dropping quotes around its parts.
+func parseKey(s string) toml.Key {
+	var key toml.Key
+	for _, part := range strings.Split(s, ".") {
+		key = append(key, strings.Trim(strings.TrimSpace(part), `"'`))
+	}
+	return key
+}
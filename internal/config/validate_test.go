@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func validate(t *testing.T, content string) []string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "blink.toml")
+	_ = os.WriteFile(path, []byte(content), 0o644)
+	issues, err := Validate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.String())
+	}
+	return got
+}
+
+func TestValidate_Valid(t *testing.T) {
+	got := validate(t, `source = ["../Common", "./MyAddon"]
+wowPath = "auto"
+logLevel = "debug"
+locale = "de_DE.UTF-8"
+
+[toc.flavors.Mainline]
+interface = "110200"
+
+[flavorFiles]
+retail = ["Retail/"]
+`)
+	if len(got) != 0 {
+		t.Errorf("issues = %q, want none", got)
+	}
+}
+
+func TestValidate_UnknownKeys(t *testing.T) {
+	got := validate(t, `wowpath = "/mnt/c/WoW"
+delay = 80
+
+[selene]
+enabled = true
+standard = "lua51"
+
+[hooks]
+before = "make"
+after = "true"
+`)
+	want := []string{
+		`line 1: key "wowpath" should be spelled "wowPath"`,
+		`line 6: unknown key "selene.standard"`,
+		`line 8: unknown key "hooks"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("issues = %q, want %q", got, want)
+	}
+}
+
+func TestValidate_TypeMismatch(t *testing.T) {
+	got := validate(t, "delay = 50\n\n[selene]\nenabled = \"yes\"\n")
+	if len(got) != 1 || got[0][:7] != "line 4:" {
+		t.Errorf("issues = %q, want one on line 4", got)
+	}
+}
+
+func TestValidate_SyntaxError(t *testing.T) {
+	got := validate(t, "delay = 50\nignore = [\n")
+	if len(got) != 1 || got[0][:7] != "line 2:" {
+		t.Errorf("issues = %q, want one on line 2", got)
+	}
+}
+
+func TestValidate_InvalidValues(t *testing.T) {
+	got := validate(t, `logLevel = "loud"
+locale = "xxXX"
+timeZone = "Mars/Olympus"
+delay = -5
+
+[budget]
+maxFileSize = "huge"
+
+[flavorFiles]
+wotlk = ["Wrath/"]
+`)
+	lines := []string{"line 1:", "line 2:", "line 3:", "line 4:", "line 7:", "line 10:"}
+	if len(got) != len(lines) {
+		t.Fatalf("issues = %q, want %d", got, len(lines))
+	}
+	for i, prefix := range lines {
+		if got[i][:len(prefix)] != prefix {
+			t.Errorf("issue %d = %q, want %s", i, got[i], prefix)
+		}
+	}
+}
@@ -3,22 +3,52 @@ package copier
 
 import (
 	"bufio"
+	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
-	cp "github.com/otiai10/copy"
+	"github.com/byteorem/blink/internal/transform"
 	ignore "github.com/sabhiram/go-gitignore"
 )
 
 // Ignorer determines which files should be excluded from syncing.
 type Ignorer struct {
-	gi *ignore.GitIgnore
+	gi      *ignore.GitIgnore
+	lines   []string // the patterns gi was compiled from
+	origins []string // where each line comes from
+
+	// FollowSymlinks makes walks descend into symlinked directories, so
+	// their files sync like any other.
+	FollowSymlinks bool
+
+	statsMu  sync.Mutex
+	excluded map[string]int // paths walks skipped, with the line that excluded each
 }
 
-// NewIgnorer creates an Ignorer from .gitignore, .pkgmeta (if enabled), and extra patterns.
-func NewIgnorer(srcDir string, extraPatterns []string, useGitignore bool, usePkgMeta bool) *Ignorer {
-	patterns := []string{"blink.toml", ".git"}
+// Where ignore patterns come from, as reported by Stats.
+const (
+	OriginBuiltin       = "built-in"
+	OriginGitignore     = ".gitignore"
+	OriginPkgMeta       = ".pkgmeta"
+	OriginGitattributes = ".gitattributes"
+	OriginConfig        = "blink.toml"
+)
+
+// NewIgnorer creates an Ignorer from .gitignore, .pkgmeta and .gitattributes
+// (each if enabled), and extra patterns.
+func NewIgnorer(srcDir string, extraPatterns []string, useGitignore bool, usePkgMeta bool, useGitattributes bool) *Ignorer {
+	ig := &Ignorer{excluded: make(map[string]int)}
+	add := func(origin string, patterns ...string) {
+		for _, p := range patterns {
+			ig.lines = append(ig.lines, p)
+			ig.origins = append(ig.origins, origin)
+		}
+	}
+	add(OriginBuiltin, "blink.toml", "blink.local.toml", ".git", "/dist/") // dist/ holds blink package output
 
 	if useGitignore {
 		gitignorePath := filepath.Join(srcDir, ".gitignore")
@@ -30,18 +60,23 @@ func NewIgnorer(srcDir string, extraPatterns []string, useGitignore bool, usePkg
 				if line == "" || strings.HasPrefix(line, "#") {
 					continue
 				}
-				patterns = append(patterns, line)
+				add(OriginGitignore, line)
 			}
 		}
 	}
 
 	if usePkgMeta {
-		patterns = append(patterns, parsePkgMetaIgnore(srcDir)...)
+		add(OriginPkgMeta, parsePkgMetaIgnore(srcDir)...)
+	}
+
+	if useGitattributes {
+		add(OriginGitattributes, parseExportIgnore(srcDir)...)
 	}
 
-	patterns = append(patterns, extraPatterns...)
+	add(OriginConfig, extraPatterns...)
 
-	return &Ignorer{gi: ignore.CompileIgnoreLines(patterns...)}
+	ig.gi = ignore.CompileIgnoreLines(ig.lines...)
+	return ig
 }
 
 // parsePkgMetaIgnore reads .pkgmeta and extracts patterns from the ignore: block.
@@ -76,105 +111,253 @@ func parsePkgMetaIgnore(srcDir string) []string {
 	return patterns
 }
 
+// parseExportIgnore reads .gitattributes and returns the patterns marked
+// export-ignore, i.e. the files git archive leaves out of a release.
+func parseExportIgnore(srcDir string) []string {
+	f, err := os.Open(filepath.Join(srcDir, ".gitattributes"))
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Skip comments, macro definitions ([attr]name) and negative
+		// patterns, which gitattributes doesn't allow.
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[attr]") || strings.HasPrefix(fields[0], "!") {
+			continue
+		}
+		// Later attributes on the line override earlier ones, so
+		// "export-ignore -export-ignore" leaves the file in.
+		ignored := false
+		for _, attr := range fields[1:] {
+			switch attr {
+			case "export-ignore":
+				ignored = true
+			case "-export-ignore", "!export-ignore":
+				ignored = false
+			}
+		}
+		if ignored {
+			patterns = append(patterns, strings.Trim(fields[0], `"`))
+		}
+	}
+	return patterns
+}
+
 // ShouldIgnore reports whether the given relative path should be excluded.
 func (ig *Ignorer) ShouldIgnore(relPath string) bool {
-	if ig.gi.MatchesPath(relPath) {
-		return true
+	_, ok := ig.match(relPath)
+	return ok
+}
+
+// match returns the index of the line that excludes relPath, if any.
+func (ig *Ignorer) match(relPath string) (int, bool) {
+	if ok, p := ig.gi.MatchesPathHow(relPath); ok {
+		return p.LineNo - 1, true
 	}
 	// Also check with trailing slash so directory-only patterns (e.g. "node_modules/")
 	// match the directory path itself, not just its children.
 	if !strings.HasSuffix(relPath, "/") {
-		return ig.gi.MatchesPath(relPath + "/")
+		if ok, p := ig.gi.MatchesPathHow(relPath + "/"); ok {
+			return p.LineNo - 1, true
+		}
 	}
-	return false
+	return 0, false
+}
+
+// skip is ShouldIgnore for walks: it also notes which pattern excluded the
+// path, for Stats.
+func (ig *Ignorer) skip(relPath string) bool {
+	line, ok := ig.match(relPath)
+	if ok {
+		ig.statsMu.Lock()
+		ig.excluded[filepath.ToSlash(relPath)] = line
+		ig.statsMu.Unlock()
+	}
+	return ok
+}
+
+// PatternStat is how many paths an ignore pattern kept out of walks.
+type PatternStat struct {
+	Origin   string // one of the Origin constants
+	Pattern  string
+	Excluded int // files and folders; a folder's contents aren't counted
+}
+
+// Stats returns the patterns that exclude paths, in the order they apply,
+// with the number of paths each kept out of the walks so far. A path walked
+// several times counts once; one matched by several patterns counts for the
+// last, which is the one that decides.
+func (ig *Ignorer) Stats() []PatternStat {
+	counts := make([]int, len(ig.lines))
+	ig.statsMu.Lock()
+	for _, line := range ig.excluded {
+		counts[line]++
+	}
+	ig.statsMu.Unlock()
+
+	var stats []PatternStat
+	for i, line := range ig.lines {
+		if strings.HasPrefix(line, "!") || strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue // negations re-include paths rather than exclude them
+		}
+		stats = append(stats, PatternStat{Origin: ig.origins[i], Pattern: line, Excluded: counts[i]})
+	}
+	return stats
 }
 
 // CountFiles returns the number of non-ignored files under src.
 func CountFiles(src string, ig *Ignorer) (int, error) {
-	count := 0
-	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
+	files, err := ListFiles(src, ig)
+	return len(files), err
+}
+
+// ListFiles returns the relative paths of all non-ignored files under src.
+func ListFiles(src string, ig *Ignorer) ([]string, error) {
+	var files []string
+	err := Walk(src, src, ig, func(_, relPath string, isDir bool) error {
+		if !isDir {
+			files = append(files, relPath)
 		}
-		relPath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
+		return nil
+	})
+	return files, err
+}
+
+// InitialSyncWithProgress copies files from src to dst, calling onFile after
+// each file. A non-nil tf rewrites file contents on the way.
+func InitialSyncWithProgress(src, dst string, ig *Ignorer, tf transform.Func, onFile func(copied int)) (int, error) {
+	return initialSync(src, dst, ig, tf, false, onFile)
+}
+
+// initialSync is InitialSyncWithProgress, keeping the originals of the files
+// it overwrites when keep is set.
+func initialSync(src, dst string, ig *Ignorer, tf transform.Func, keep bool, onFile func(copied int)) (int, error) {
+	count := 0
+	err := Walk(src, src, ig, func(srcPath, rel string, isDir bool) error {
+		dstPath := filepath.Join(dst, rel)
+		if isDir {
+			return os.MkdirAll(dstPath, 0o755)
 		}
-		if relPath == "." {
-			return nil
+		count++
+		if onFile != nil {
+			onFile(count)
 		}
-		if ig.ShouldIgnore(relPath) {
-			if d.IsDir() {
-				return filepath.SkipDir
+		if keep {
+			if err := KeepOriginal(dstPath); err != nil {
+				return err
 			}
-			return nil
 		}
-		if !d.IsDir() {
-			count++
+		if !ig.FollowSymlinks && isSymlink(srcPath) {
+			return copyLink(srcPath, dstPath)
 		}
-		return nil
+		return CopyFileWith(srcPath, dstPath, rel, tf)
 	})
 	return count, err
 }
 
-// InitialSyncWithProgress copies files from src to dst, calling onFile after each file.
-func InitialSyncWithProgress(src, dst string, ig *Ignorer, onFile func(copied int)) (int, error) {
-	count := 0
-	err := cp.Copy(src, dst, cp.Options{
-		Skip: func(info os.FileInfo, srcPath, _ string) (bool, error) {
-			rel, err := filepath.Rel(src, srcPath)
-			if err != nil || rel == "." {
-				return false, nil
-			}
-			if ig.ShouldIgnore(rel) {
-				return true, nil
-			}
-			if !info.IsDir() {
-				count++
-				if onFile != nil {
-					onFile(count)
-				}
-			}
-			return false, nil
-		},
-		OnDirExists: func(_, _ string) cp.DirExistsAction {
-			return cp.Merge
-		},
-	})
-	return count, err
+// OriginalSuffix is added to the name of a file blink overwrote outside an
+// addon folder, e.g. a font of the client, for the copy of what was there
+// before.
+const OriginalSuffix = ".blink.orig"
+
+// KeepOriginal copies the file at dst to dst+OriginalSuffix before blink
+// first overwrites it. It does nothing when the copy exists already, so it
+// holds the file from before blink, or when there is no file at dst.
+func KeepOriginal(dst string) error {
+	if _, err := os.Lstat(dst + OriginalSuffix); err == nil {
+		return nil
+	}
+	if _, err := os.Lstat(dst); os.IsNotExist(err) {
+		return nil
+	}
+	return CopyFile(dst, dst+OriginalSuffix)
 }
 
-// InitialSync copies all non-ignored files from src to dst.
-func InitialSync(src, dst string, ig *Ignorer) (int, error) {
+// RestoreOriginal undoes syncing a file to dst: the copy KeepOriginal kept is
+// put back, or dst is removed when there was none, as blink added the file.
+func RestoreOriginal(dst string) error {
+	if _, err := os.Lstat(dst + OriginalSuffix); err == nil {
+		return os.Rename(dst+OriginalSuffix, dst)
+	}
+	return DeleteFile(dst)
+}
+
+// RestoreOriginals undoes syncing srcs to dst, a folder whose originals were
+// kept: each file a source provides is put back with RestoreOriginal, then
+// any copy left below dst, from a file no source provides any more, is put
+// back too. Without srcs, only the kept copies come back. It returns the
+// number of files restored or removed.
+func RestoreOriginals(srcs []Source, dst string) (int, error) {
 	count := 0
-	err := cp.Copy(src, dst, cp.Options{
-		Skip: func(info os.FileInfo, srcPath, _ string) (bool, error) {
-			rel, err := filepath.Rel(src, srcPath)
-			if err != nil || rel == "." {
-				return false, nil
+	for _, s := range srcs {
+		files, err := ListFiles(s.Dir, s.Ignorer)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return count, err
+		}
+		for _, rel := range files {
+			path := filepath.Join(dst, rel)
+			if _, err := os.Lstat(path); os.IsNotExist(err) {
+				continue
 			}
-			if ig.ShouldIgnore(rel) {
-				return true, nil
+			if err := RestoreOriginal(path); err != nil {
+				return count, err
 			}
-			if !info.IsDir() {
-				count++
+			count++
+		}
+	}
+	err := filepath.WalkDir(dst, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dst {
+				return filepath.SkipDir
 			}
-			return false, nil
-		},
-		OnDirExists: func(_, _ string) cp.DirExistsAction {
-			return cp.Merge
-		},
+			return err
+		}
+		orig, ok := strings.CutSuffix(path, OriginalSuffix)
+		if d.IsDir() || !ok {
+			return nil
+		}
+		if err := os.Rename(path, orig); err != nil {
+			return err
+		}
+		count++
+		return nil
 	})
 	return count, err
 }
 
+// InitialSync copies all non-ignored files from src to dst. A non-nil tf
+// rewrites file contents on the way.
+func InitialSync(src, dst string, ig *Ignorer, tf transform.Func) (int, error) {
+	return InitialSyncWithProgress(src, dst, ig, tf, nil)
+}
+
 // CopyFile copies a single file from src to dst, creating directories as needed.
 func CopyFile(src, dst string) error {
+	return CopyFileWith(src, dst, "", nil)
+}
+
+// CopyFileWith copies a single file from src to dst like CopyFile, passing its
+// contents through tf (if non-nil). relPath is the file's path relative to the
+// addon root, as seen by tf.
+func CopyFileWith(src, dst, relPath string, tf transform.Func) error {
 	data, err := os.ReadFile(src)
 	if err != nil {
 		return err
 	}
 
+	if tf != nil {
+		if data, err = tf(relPath, data); err != nil {
+			return fmt.Errorf("%s: %w", relPath, err)
+		}
+	}
+
 	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
 		return err
 	}
@@ -185,6 +368,13 @@ func CopyFile(src, dst string) error {
 // CleanDestination removes files from dst that don't exist in src or match
 // ignore rules. Returns the count of removed files.
 func CleanDestination(src, dst string, ig *Ignorer) (int, error) {
+	return CleanDestinationSources([]Source{{Dir: src, Ignorer: ig}}, dst)
+}
+
+// CleanDestinationSources removes files from dst that no source provides. A
+// file only counts as provided by a source whose ignore rules let it through.
+// Returns the count of removed files.
+func CleanDestinationSources(srcs []Source, dst string) (int, error) {
 	if _, err := os.Stat(dst); os.IsNotExist(err) {
 		return 0, nil
 	}
@@ -203,19 +393,14 @@ func CleanDestination(src, dst string, ig *Ignorer) (int, error) {
 		if relPath == "." || d.IsDir() {
 			return nil
 		}
-		shouldRemove := false
-		if ig != nil && ig.ShouldIgnore(relPath) {
-			shouldRemove = true
-		} else {
-			srcPath := filepath.Join(src, relPath)
-			if _, err := os.Stat(srcPath); os.IsNotExist(err) {
-				shouldRemove = true
-			}
+		if relPath == MarkerFile {
+			return nil
 		}
-		if shouldRemove {
+		if !provided(srcs, relPath) {
 			if err := os.Remove(path); err != nil {
 				return err
 			}
+			slog.Debug("removed stale file", "path", path)
 			removed++
 		}
 		return nil
@@ -243,6 +428,28 @@ func CleanDestination(src, dst string, ig *Ignorer) (int, error) {
 	return removed, nil
 }
 
+// MarkerFile is written into every destination blink syncs to, marking the
+// folder as one blink manages and may delete.
+const MarkerFile = ".blink"
+
+// WriteMarker marks dst as managed by blink, recording where its files come from.
+func WriteMarker(dst string, srcDirs []string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	content := "# This folder is synced by blink (https://github.com/byteorem/blink).\n"
+	for _, dir := range srcDirs {
+		content += "source: " + dir + "\n"
+	}
+	return os.WriteFile(filepath.Join(dst, MarkerFile), []byte(content), 0o644)
+}
+
+// HasMarker reports whether dst carries blink's ownership marker.
+func HasMarker(dst string) bool {
+	_, err := os.Stat(filepath.Join(dst, MarkerFile))
+	return err == nil
+}
+
 // DeleteFile removes the file at dst, returning nil if it does not exist.
 func DeleteFile(dst string) error {
 	err := os.Remove(dst)
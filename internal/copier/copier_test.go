@@ -3,13 +3,14 @@ package copier
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestShouldIgnore_AlwaysIgnored(t *testing.T) {
-	ig := NewIgnorer(t.TempDir(), nil, false, false)
+	ig := NewIgnorer(t.TempDir(), nil, false, false, false)
 
-	alwaysIgnored := []string{"blink.toml", ".git", ".git/config", ".git/HEAD"}
+	alwaysIgnored := []string{"blink.toml", "blink.local.toml", ".git", ".git/config", ".git/HEAD", "dist/MyAddon-1.0.zip"}
 	for _, p := range alwaysIgnored {
 		if !ig.ShouldIgnore(p) {
 			t.Errorf("ShouldIgnore(%q) = false, want true", p)
@@ -18,7 +19,7 @@ func TestShouldIgnore_AlwaysIgnored(t *testing.T) {
 }
 
 func TestShouldIgnore_GlobPatterns(t *testing.T) {
-	ig := NewIgnorer(t.TempDir(), []string{"*.bak", "*.log"}, false, false)
+	ig := NewIgnorer(t.TempDir(), []string{"*.bak", "*.log"}, false, false, false)
 
 	if !ig.ShouldIgnore("test.bak") {
 		t.Error("ShouldIgnore(test.bak) = false, want true")
@@ -32,7 +33,7 @@ func TestShouldIgnore_GlobPatterns(t *testing.T) {
 }
 
 func TestShouldIgnore_DirPatterns(t *testing.T) {
-	ig := NewIgnorer(t.TempDir(), []string{"node_modules/"}, false, false)
+	ig := NewIgnorer(t.TempDir(), []string{"node_modules/"}, false, false, false)
 
 	if !ig.ShouldIgnore("node_modules") {
 		t.Error("ShouldIgnore(node_modules) = false, want true")
@@ -49,7 +50,7 @@ func TestNewIgnorer_GitignorePatterns(t *testing.T) {
 	dir := t.TempDir()
 	_ = os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.tmp\n# comment\n\nbuild/\n"), 0o644)
 
-	ig := NewIgnorer(dir, nil, true, false)
+	ig := NewIgnorer(dir, nil, true, false, false)
 
 	if !ig.ShouldIgnore("foo.tmp") {
 		t.Error("should ignore *.tmp from .gitignore")
@@ -63,7 +64,7 @@ func TestNewIgnorer_NoGitignore(t *testing.T) {
 	dir := t.TempDir()
 	_ = os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.tmp\n"), 0o644)
 
-	ig := NewIgnorer(dir, []string{"*.bak"}, false, false)
+	ig := NewIgnorer(dir, []string{"*.bak"}, false, false, false)
 
 	if ig.ShouldIgnore("foo.tmp") {
 		t.Error("should not ignore *.tmp when useGitignore=false")
@@ -85,8 +86,8 @@ func TestInitialSync(t *testing.T) {
 	_ = os.MkdirAll(filepath.Join(src, ".git"), 0o755)
 	_ = os.WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref"), 0o644)
 
-	ig := NewIgnorer(src, nil, false, false)
-	count, err := InitialSync(src, dst, ig)
+	ig := NewIgnorer(src, nil, false, false, false)
+	count, err := InitialSync(src, dst, ig, nil)
 	if err != nil {
 		t.Fatalf("InitialSync() error = %v", err)
 	}
@@ -107,6 +108,57 @@ func TestInitialSync(t *testing.T) {
 	}
 }
 
+func TestListFiles(t *testing.T) {
+	src := t.TempDir()
+	_ = os.WriteFile(filepath.Join(src, "main.lua"), []byte("print('hi')"), 0o644)
+	_ = os.MkdirAll(filepath.Join(src, "libs"), 0o755)
+	_ = os.WriteFile(filepath.Join(src, "libs", "helper.lua"), []byte("-- help"), 0o644)
+	_ = os.WriteFile(filepath.Join(src, "notes.bak"), []byte("ignored"), 0o644)
+
+	ig := NewIgnorer(src, []string{"*.bak"}, false, false, false)
+	files, err := ListFiles(src, ig)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	want := []string{filepath.Join("libs", "helper.lua"), "main.lua"}
+	if len(files) != len(want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("files[%d] = %q, want %q", i, files[i], want[i])
+		}
+	}
+}
+
+func TestInitialSync_Transform(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	_ = os.WriteFile(filepath.Join(src, "main.lua"), []byte("print('hi')"), 0o644)
+	_ = os.WriteFile(filepath.Join(src, "logo.tga"), []byte("binary"), 0o644)
+
+	upper := func(relPath string, data []byte) ([]byte, error) {
+		if filepath.Ext(relPath) != ".lua" {
+			return data, nil
+		}
+		return []byte(strings.ToUpper(string(data))), nil
+	}
+
+	count, err := InitialSync(src, dst, NewIgnorer(src, nil, false, false, false), upper)
+	if err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "main.lua")); string(data) != "PRINT('HI')" {
+		t.Errorf("main.lua = %q, want transformed contents", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "logo.tga")); string(data) != "binary" {
+		t.Errorf("logo.tga = %q, want original contents", data)
+	}
+}
+
 func TestCopyFile(t *testing.T) {
 	src := t.TempDir()
 	dst := t.TempDir()
@@ -155,7 +207,7 @@ manual-changelog:
 `
 	_ = os.WriteFile(filepath.Join(dir, ".pkgmeta"), []byte(pkgmeta), 0o644)
 
-	ig := NewIgnorer(dir, nil, false, true)
+	ig := NewIgnorer(dir, nil, false, true, false)
 
 	if !ig.ShouldIgnore("README.md") {
 		t.Error("should ignore README.md from .pkgmeta")
@@ -175,7 +227,7 @@ func TestNewIgnorer_PkgMetaDisabled(t *testing.T) {
 	dir := t.TempDir()
 	_ = os.WriteFile(filepath.Join(dir, ".pkgmeta"), []byte("ignore:\n  - README.md\n"), 0o644)
 
-	ig := NewIgnorer(dir, nil, false, false)
+	ig := NewIgnorer(dir, nil, false, false, false)
 
 	if ig.ShouldIgnore("README.md") {
 		t.Error("should not ignore README.md when usePkgMeta=false")
@@ -186,13 +238,90 @@ func TestNewIgnorer_PkgMetaNoIgnoreBlock(t *testing.T) {
 	dir := t.TempDir()
 	_ = os.WriteFile(filepath.Join(dir, ".pkgmeta"), []byte("package-as: MyAddon\n"), 0o644)
 
-	ig := NewIgnorer(dir, nil, false, true)
+	ig := NewIgnorer(dir, nil, false, true, false)
 
 	if ig.ShouldIgnore("main.lua") {
 		t.Error("should not ignore main.lua with no pkgmeta ignore block")
 	}
 }
 
+func TestNewIgnorer_GitattributesExportIgnore(t *testing.T) {
+	dir := t.TempDir()
+	gitattributes := `# Leave dev files out of releases
+* text=auto eol=lf
+/.github export-ignore
+tests/ export-ignore
+*.psd binary export-ignore
+Docs.md export-ignore -export-ignore
+[attr]dev export-ignore
+`
+	_ = os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(gitattributes), 0o644)
+
+	ig := NewIgnorer(dir, nil, false, false, true)
+
+	for _, path := range []string{".github", ".github/workflows/ci.yml", "tests/foo.lua", "Art/Logo.psd"} {
+		if !ig.ShouldIgnore(path) {
+			t.Errorf("should ignore %s from .gitattributes", path)
+		}
+	}
+	for _, path := range []string{"main.lua", "Docs.md", "dev"} {
+		if ig.ShouldIgnore(path) {
+			t.Errorf("should not ignore %s", path)
+		}
+	}
+}
+
+func TestNewIgnorer_GitattributesDisabled(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("tests/ export-ignore\n"), 0o644)
+
+	ig := NewIgnorer(dir, nil, false, false, false)
+
+	if ig.ShouldIgnore("tests/foo.lua") {
+		t.Error("should not ignore tests/ when useGitattributes=false")
+	}
+}
+
+func TestIgnorer_Stats(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n!keep.log\n"), 0o644)
+	for _, name := range []string{"main.lua", "a.log", "b.log", "keep.log", "notes.md", "blink.toml"} {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644)
+	}
+	_ = os.MkdirAll(filepath.Join(dir, "tests", "unit"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "tests", "unit", "a_spec.lua"), []byte("x"), 0o644)
+
+	ig := NewIgnorer(dir, []string{"tests/", "*.mdd"}, true, false, false)
+	// A second walk doesn't count the same paths again.
+	for range 2 {
+		if _, err := ListFiles(dir, ig); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := make(map[string]int)
+	for _, st := range ig.Stats() {
+		got[st.Origin+" "+st.Pattern] = st.Excluded
+	}
+	want := map[string]int{
+		"built-in blink.toml":       1,
+		"built-in blink.local.toml": 0,
+		"built-in .git":             0,
+		"built-in /dist/":           0,
+		".gitignore *.log":          2,
+		"blink.toml tests/":         1,
+		"blink.toml *.mdd":          0,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Stats() = %v, want %v", got, want)
+	}
+	for k, n := range want {
+		if got[k] != n {
+			t.Errorf("%s excluded %d, want %d", k, got[k], n)
+		}
+	}
+}
+
 func TestCleanDestination_RemovesStaleFiles(t *testing.T) {
 	src := t.TempDir()
 	dst := t.TempDir()
@@ -268,7 +397,7 @@ func TestCleanDestination_RemovesIgnoredFiles(t *testing.T) {
 	_ = os.WriteFile(filepath.Join(src, ".github", "workflows", "ci.yml"), []byte("ci"), 0o644)
 	_ = os.WriteFile(filepath.Join(src, "README.md"), []byte("readme"), 0o644)
 
-	ig := NewIgnorer(src, []string{".github/", "README.md"}, false, false)
+	ig := NewIgnorer(src, []string{".github/", "README.md"}, false, false, false)
 
 	removed, err := CleanDestination(src, dst, ig)
 	if err != nil {
@@ -293,3 +422,72 @@ func TestDeleteFile_NonExistent(t *testing.T) {
 		t.Errorf("DeleteFile() non-existent should return nil, got %v", err)
 	}
 }
+
+func TestMarker(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "MyAddon")
+
+	if HasMarker(dst) {
+		t.Fatal("HasMarker() = true before WriteMarker")
+	}
+	if err := WriteMarker(dst, []string{src}); err != nil {
+		t.Fatalf("WriteMarker() error = %v", err)
+	}
+	if !HasMarker(dst) {
+		t.Fatal("HasMarker() = false after WriteMarker")
+	}
+
+	// The marker isn't part of the source but must survive cleanup.
+	if _, err := CleanDestination(src, dst, nil); err != nil {
+		t.Fatalf("CleanDestination() error = %v", err)
+	}
+	if !HasMarker(dst) {
+		t.Error("CleanDestination() removed the marker")
+	}
+}
+
+func TestRestoreOriginals(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(src, "FRIZQT__.TTF"):           "pack",
+		filepath.Join(src, "Extra.ttf"):              "pack",
+		filepath.Join(dst, "FRIZQT__.TTF"):           "client",
+		filepath.Join(dst, "Other.ttf"):              "client",
+		filepath.Join(dst, "Old.ttf"):                "pack, since removed from the source",
+		filepath.Join(dst, "Old.ttf"+OriginalSuffix): "client",
+	}
+	for path, data := range files {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srcs := []Source{{Dir: src, Ignorer: NewIgnorer(src, nil, false, false, false), KeepOriginals: true}}
+	if _, err := InitialSyncSources(srcs, dst, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "FRIZQT__.TTF")); string(data) != "pack" {
+		t.Fatalf("synced FRIZQT__.TTF = %q", data)
+	}
+
+	n, err := RestoreOriginals(srcs, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("RestoreOriginals() = %d, want 3", n)
+	}
+	for name, want := range map[string]string{"FRIZQT__.TTF": "client", "Other.ttf": "client", "Old.ttf": "client"} {
+		if data, err := os.ReadFile(filepath.Join(dst, name)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, want)
+		}
+	}
+	for _, name := range []string{"Extra.ttf", "FRIZQT__.TTF" + OriginalSuffix, "Old.ttf" + OriginalSuffix} {
+		if _, err := os.Lstat(filepath.Join(dst, name)); !os.IsNotExist(err) {
+			t.Errorf("%s is still there", name)
+		}
+	}
+
+	if n, err := RestoreOriginals(srcs, filepath.Join(dst, "missing")); n != 0 || err != nil {
+		t.Errorf("RestoreOriginals(missing folder) = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,61 @@
+package copier
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Link makes dst a link to the folder src, so the folder is never copied: a
+// symlink, or on Windows, where symlinks need developer mode or an
+// administrator, an NTFS junction when a symlink can't be made.
+func Link(src, dst string) error {
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+	err = os.Symlink(src, dst)
+	if err == nil || runtime.GOOS != "windows" {
+		return err
+	}
+	out, jerr := exec.Command("cmd", "/c", "mklink", "/J", dst, src).CombinedOutput()
+	if jerr != nil {
+		return fmt.Errorf("%w; making a junction failed too: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// LinkTarget returns the folder dst links to, if dst is a symlink or a
+// junction.
+func LinkTarget(dst string) (string, bool) {
+	info, err := os.Lstat(dst)
+	if err != nil || info.Mode()&(fs.ModeSymlink|fs.ModeIrregular) == 0 {
+		return "", false
+	}
+	target, err := os.Readlink(dst)
+	if err != nil {
+		return "", false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(dst), target)
+	}
+	return filepath.Clean(target), true
+}
+
+// LinksTo reports whether dst is a link to the folder src.
+func LinksTo(dst, src string) bool {
+	target, ok := LinkTarget(dst)
+	if !ok {
+		return false
+	}
+	a, err := os.Stat(target)
+	if err != nil {
+		return false
+	}
+	b, err := os.Stat(src)
+	return err == nil && os.SameFile(a, b)
+}
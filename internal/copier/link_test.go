@@ -0,0 +1,34 @@
+package copier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLink(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "MyAddon")
+	_ = os.MkdirAll(src, 0o755)
+	_ = os.WriteFile(filepath.Join(src, "Core.lua"), []byte("print(1)"), 0o644)
+	addOns := t.TempDir()
+	dst := filepath.Join(addOns, "MyAddon")
+
+	if err := Link(src, dst); err != nil {
+		t.Skipf("can't make links here: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "Core.lua")); err != nil || string(data) != "print(1)" {
+		t.Errorf("Core.lua through the link = %q, %v", data, err)
+	}
+	if target, ok := LinkTarget(dst); !ok || target != src {
+		t.Errorf("LinkTarget() = %q, %v, want %q", target, ok, src)
+	}
+	if !LinksTo(dst, src) {
+		t.Error("LinksTo(dst, src) = false")
+	}
+	if LinksTo(dst, addOns) {
+		t.Error("LinksTo() is true for another folder")
+	}
+	if _, ok := LinkTarget(src); ok {
+		t.Error("LinkTarget() of a plain folder reports a link")
+	}
+}
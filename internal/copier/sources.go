@@ -0,0 +1,137 @@
+package copier
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/byteorem/blink/internal/transform"
+)
+
+// Source is one source directory synced into a target, together with the
+// rules deciding which of its files are synced. Several sources may be
+// overlaid into the same target.
+type Source struct {
+	Dir     string
+	Ignorer *Ignorer
+
+	// KeepOriginals makes syncs keep a copy of each file in the target
+	// before first overwriting it; see KeepOriginal.
+	KeepOriginals bool
+
+	// Transform, if non-nil, rewrites the source's files after the
+	// target's transform, e.g. to note the commit the source is at.
+	Transform transform.Func
+}
+
+// Conflict is a relative path that more than one source would sync.
+type Conflict struct {
+	RelPath string
+	Dirs    []string // the source directories providing it, in source order
+}
+
+// provided reports whether any source syncs relPath.
+func provided(srcs []Source, relPath string) bool {
+	for _, s := range srcs {
+		if s.Ignorer != nil && s.Ignorer.ShouldIgnore(relPath) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.Dir, relPath)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// FindConflicts returns the relative paths provided by more than one source,
+// sorted by path.
+func FindConflicts(srcs []Source) ([]Conflict, error) {
+	owners := map[string][]string{}
+	for _, s := range srcs {
+		files, err := ListFiles(s.Dir, s.Ignorer)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			owners[f] = append(owners[f], s.Dir)
+		}
+	}
+
+	var conflicts []Conflict
+	for rel, dirs := range owners {
+		if len(dirs) > 1 {
+			conflicts = append(conflicts, Conflict{RelPath: rel, Dirs: dirs})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].RelPath < conflicts[j].RelPath })
+	return conflicts, nil
+}
+
+// OtherProviders returns the sources other than dir that also provide relPath.
+func OtherProviders(srcs []Source, dir, relPath string) []string {
+	var dirs []string
+	for _, s := range srcs {
+		if s.Dir == dir {
+			continue
+		}
+		if provided([]Source{s}, relPath) {
+			dirs = append(dirs, s.Dir)
+		}
+	}
+	return dirs
+}
+
+// CountFilesSources returns the number of non-ignored files across srcs.
+func CountFilesSources(srcs []Source) (int, error) {
+	total := 0
+	for _, s := range srcs {
+		n, err := CountFiles(s.Dir, s.Ignorer)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// InitialSyncSources copies every source into dst in order, calling onFile
+// after each file with the running total.
+func InitialSyncSources(srcs []Source, dst string, tf transform.Func, onFile func(copied int)) (int, error) {
+	total := 0
+	for _, s := range srcs {
+		base := total
+		n, err := initialSync(s.Dir, dst, s.Ignorer, transform.Chain(tf, s.Transform), s.KeepOriginals, func(copied int) {
+			if onFile != nil {
+				onFile(base + copied)
+			}
+		})
+		total += n
+		if err != nil {
+			return total, err
+		}
+		slog.Debug("synced source", "src", s.Dir, "dst", dst, "files", n)
+	}
+	return total, nil
+}
+
+// SourceOf returns the source that srcPath, the file relPath of a source, is
+// in.
+func SourceOf(srcs []Source, srcPath, relPath string) (Source, bool) {
+	for _, s := range srcs {
+		if filepath.Join(s.Dir, relPath) == srcPath {
+			return s, true
+		}
+	}
+	return Source{}, false
+}
+
+// SourceFor returns the source whose directory is dir.
+func SourceFor(srcs []Source, dir string) (Source, bool) {
+	for _, s := range srcs {
+		if s.Dir == dir {
+			return s, true
+		}
+	}
+	return Source{}, false
+}
@@ -0,0 +1,90 @@
+package copier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitialSyncSources(t *testing.T) {
+	common := t.TempDir()
+	addon := t.TempDir()
+	dst := t.TempDir()
+
+	_ = os.MkdirAll(filepath.Join(common, "Libs"), 0o755)
+	_ = os.WriteFile(filepath.Join(common, "Libs", "Shared.lua"), []byte("shared"), 0o644)
+	_ = os.WriteFile(filepath.Join(addon, "MyAddon.toc"), []byte("## Title: x"), 0o644)
+	_ = os.WriteFile(filepath.Join(addon, "main.lua"), []byte("main"), 0o644)
+
+	srcs := []Source{{Dir: addon, Ignorer: NewIgnorer(addon, nil, false, false, false)}, {Dir: common, Ignorer: NewIgnorer(common, nil, false, false, false)}}
+
+	var last int
+	count, err := InitialSyncSources(srcs, dst, nil, func(copied int) { last = copied })
+	if err != nil {
+		t.Fatalf("InitialSyncSources() error = %v", err)
+	}
+	if count != 3 || last != 3 {
+		t.Errorf("count = %d, last progress = %d, want 3", count, last)
+	}
+	for _, f := range []string{"MyAddon.toc", "main.lua", filepath.Join("Libs", "Shared.lua")} {
+		if _, err := os.Stat(filepath.Join(dst, f)); err != nil {
+			t.Errorf("%s was not synced", f)
+		}
+	}
+	if n, _ := CountFilesSources(srcs); n != 3 {
+		t.Errorf("CountFilesSources() = %d, want 3", n)
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	a := t.TempDir()
+	b := t.TempDir()
+	_ = os.WriteFile(filepath.Join(a, "util.lua"), []byte("a"), 0o644)
+	_ = os.WriteFile(filepath.Join(b, "util.lua"), []byte("b"), 0o644)
+	_ = os.WriteFile(filepath.Join(a, "only-a.lua"), []byte("a"), 0o644)
+	_ = os.WriteFile(filepath.Join(b, "notes.md"), []byte("b"), 0o644)
+	_ = os.WriteFile(filepath.Join(a, "notes.md"), []byte("a"), 0o644)
+
+	srcs := []Source{
+		{Dir: a, Ignorer: NewIgnorer(a, []string{"*.md"}, false, false, false)},
+		{Dir: b, Ignorer: NewIgnorer(b, nil, false, false, false)},
+	}
+	conflicts, err := FindConflicts(srcs)
+	if err != nil {
+		t.Fatalf("FindConflicts() error = %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].RelPath != "util.lua" {
+		t.Fatalf("conflicts = %+v, want only util.lua", conflicts)
+	}
+	if len(conflicts[0].Dirs) != 2 || conflicts[0].Dirs[0] != a || conflicts[0].Dirs[1] != b {
+		t.Errorf("Dirs = %v, want [%s %s]", conflicts[0].Dirs, a, b)
+	}
+	if got := OtherProviders(srcs, a, "util.lua"); len(got) != 1 || got[0] != b {
+		t.Errorf("OtherProviders() = %v, want [%s]", got, b)
+	}
+	if got := OtherProviders(srcs, b, "notes.md"); len(got) != 0 {
+		t.Errorf("OtherProviders() = %v, want none (ignored in a)", got)
+	}
+}
+
+func TestCleanDestinationSources(t *testing.T) {
+	a := t.TempDir()
+	b := t.TempDir()
+	dst := t.TempDir()
+	_ = os.WriteFile(filepath.Join(a, "main.lua"), []byte("a"), 0o644)
+	_ = os.WriteFile(filepath.Join(b, "shared.lua"), []byte("b"), 0o644)
+	for _, f := range []string{"main.lua", "shared.lua", "stale.lua"} {
+		_ = os.WriteFile(filepath.Join(dst, f), []byte("x"), 0o644)
+	}
+
+	removed, err := CleanDestinationSources([]Source{{Dir: a}, {Dir: b}}, dst)
+	if err != nil {
+		t.Fatalf("CleanDestinationSources() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "shared.lua")); err != nil {
+		t.Error("shared.lua should still exist")
+	}
+}
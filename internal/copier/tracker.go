@@ -0,0 +1,103 @@
+package copier
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/byteorem/blink/internal/transform"
+)
+
+// Tracker remembers the files blink wrote into a destination, so a file
+// changed there by something else (an in-game editor, a quick manual tweak)
+// can be told apart before it is overwritten. It is safe for concurrent use.
+type Tracker struct {
+	mu     sync.Mutex
+	writes map[string]written
+}
+
+type written struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewTracker returns an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{writes: make(map[string]written)}
+}
+
+// Record notes the current state of path as written by blink. A missing file
+// is forgotten.
+func (t *Tracker) Record(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	info, err := os.Stat(path)
+	if err != nil {
+		delete(t.writes, path)
+		return
+	}
+	t.writes[path] = written{modTime: info.ModTime(), size: info.Size()}
+}
+
+// Scan records every file below dir, e.g. after a full sync.
+func (t *Tracker) Scan(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			t.Record(path)
+		}
+		return nil
+	})
+}
+
+// Changed reports whether path was modified since blink last wrote it. Files
+// blink never wrote, and files that are gone, are not considered changed.
+func (t *Tracker) Changed(path string) bool {
+	t.mu.Lock()
+	w, ok := t.writes[path]
+	t.mu.Unlock()
+	if !ok {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Equal(w.modTime) || info.Size() != w.size
+}
+
+// Written reports whether path is exactly as blink last wrote it, i.e. a
+// change to it seen by a watcher was blink's own.
+func (t *Tracker) Written(path string) bool {
+	t.mu.Lock()
+	_, ok := t.writes[path]
+	t.mu.Unlock()
+	return ok && !t.Changed(path)
+}
+
+// PullBack copies a destination file that was changed in place back over its
+// source, and records both so neither counts as changed. It refuses when
+// tf rewrites the source file, since the destination then holds the
+// rewritten output (flavor directives, license header, trimmed whitespace)
+// rather than what the source should contain.
+func (t *Tracker) PullBack(dst, src, relPath string, tf transform.Func) error {
+	if tf != nil {
+		orig, err := os.ReadFile(src)
+		if err == nil {
+			out, err := tf(relPath, orig)
+			if err != nil || string(out) != string(orig) {
+				return errors.New("the synced copy was rewritten on the way (flavor directives or license header) — copy the change into the source by hand")
+			}
+		}
+	}
+	if err := CopyFile(dst, src); err != nil {
+		return err
+	}
+	t.Record(dst)
+	t.Record(src)
+	return nil
+}
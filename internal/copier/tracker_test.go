@@ -0,0 +1,78 @@
+package copier
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "Core.lua")
+	_ = os.WriteFile(dst, []byte("print(1)"), 0o644)
+
+	tr := NewTracker()
+	if tr.Changed(dst) {
+		t.Error("untracked file reported as changed")
+	}
+	if err := tr.Scan(dir); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if tr.Changed(dst) {
+		t.Error("file reported as changed right after Scan")
+	}
+
+	_ = os.WriteFile(dst, []byte("print(2) -- edited in game"), 0o644)
+	if !tr.Changed(dst) {
+		t.Error("edited file not reported as changed")
+	}
+
+	if tr.Written(dst) {
+		t.Error("edited file reported as written by blink")
+	}
+
+	tr.Record(dst)
+	if tr.Changed(dst) {
+		t.Error("file reported as changed after Record")
+	}
+	if !tr.Written(dst) {
+		t.Error("recorded file not reported as written by blink")
+	}
+	if tr.Written(filepath.Join(dir, "New.lua")) {
+		t.Error("untracked file reported as written by blink")
+	}
+
+	// Same size, newer mtime.
+	_ = os.WriteFile(dst, []byte("print(3) -- edited in game"), 0o644)
+	_ = os.Chtimes(dst, time.Now(), time.Now().Add(time.Hour))
+	if !tr.Changed(dst) {
+		t.Error("touched file not reported as changed")
+	}
+}
+
+func TestTracker_PullBack(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	src := filepath.Join(srcDir, "Core.lua")
+	dst := filepath.Join(dstDir, "Core.lua")
+	_ = os.WriteFile(src, []byte("print(1)"), 0o644)
+	_ = os.WriteFile(dst, []byte("print(2)"), 0o644)
+
+	tr := NewTracker()
+	if err := tr.PullBack(dst, src, "Core.lua", nil); err != nil {
+		t.Fatalf("PullBack() error = %v", err)
+	}
+	if data, _ := os.ReadFile(src); string(data) != "print(2)" {
+		t.Errorf("source = %q, want print(2)", data)
+	}
+	if tr.Changed(dst) || tr.Changed(src) {
+		t.Error("pulled-back file still reported as changed")
+	}
+
+	upper := func(_ string, data []byte) ([]byte, error) { return []byte(strings.ToUpper(string(data))), nil }
+	if err := tr.PullBack(dst, src, "Core.lua", upper); err == nil {
+		t.Error("expected error pulling back a file the transform rewrites")
+	}
+}
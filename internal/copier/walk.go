@@ -0,0 +1,114 @@
+package copier
+
+import (
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Walk calls fn for dir and every file and directory below it that ig lets
+// through, in lexical order. dir is root or a directory below it; the relative
+// paths passed to fn, and the ignore rules, are relative to root. Returning
+// filepath.SkipDir from fn for a directory skips its contents.
+//
+// When ig follows symlinks, a symlinked directory is walked like a real one,
+// except that a link back to one of its own parents is skipped. Otherwise
+// symlinks are passed to fn as files. Broken symlinks are skipped with a
+// warning rather than failing the walk.
+func Walk(root, dir string, ig *Ignorer, fn func(path, rel string, isDir bool) error) error {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if err := fn(dir, rel, true); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	return walk(dir, rel, ig, []string{real}, fn)
+}
+
+// walk walks the contents of dir. parents holds the resolved paths of dir and
+// the directories above it, to notice symlink cycles.
+func walk(dir, relDir string, ig *Ignorer, parents []string, fn func(path, rel string, isDir bool) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		rel := filepath.Join(relDir, e.Name())
+		if ig != nil && ig.skip(rel) {
+			continue
+		}
+		isDir := e.IsDir()
+		real := filepath.Join(parents[len(parents)-1], e.Name())
+		if e.Type()&fs.ModeSymlink != 0 && BrokenLink(path) {
+			slog.Warn("skipped broken symlink", "path", path)
+			continue
+		}
+		if e.Type()&fs.ModeSymlink != 0 && ig != nil && ig.FollowSymlinks {
+			if real, err = filepath.EvalSymlinks(path); err != nil {
+				return err
+			}
+			info, err := os.Stat(real)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() && slices.Contains(parents, real) {
+				slog.Warn("skipped symlink back to a parent directory", "path", path, "target", real)
+				continue
+			}
+			isDir = info.IsDir()
+		}
+		if err := fn(path, rel, isDir); err != nil {
+			if isDir && err == filepath.SkipDir {
+				continue
+			}
+			return err
+		}
+		if isDir {
+			if err := walk(path, rel, ig, append(parents, real), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// BrokenLink reports whether path is a symlink whose target doesn't exist.
+func BrokenLink(path string) bool {
+	if !isSymlink(path) {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err != nil
+}
+
+// isSymlink reports whether path is a symbolic link.
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&fs.ModeSymlink != 0
+}
+
+// copyLink recreates the symlink src at dst.
+func copyLink(src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return os.Symlink(target, dst)
+}
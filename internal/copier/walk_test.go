@@ -0,0 +1,99 @@
+package copier
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// symlinkTree makes an addon whose Libs folder links to a shared checkout,
+// which itself links back to the addon.
+func symlinkTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	src := filepath.Join(root, "MyAddon")
+	shared := filepath.Join(root, "SharedLibs")
+	_ = os.MkdirAll(filepath.Join(shared, "LibStub"), 0o755)
+	_ = os.WriteFile(filepath.Join(shared, "LibStub", "LibStub.lua"), []byte("-- lib"), 0o644)
+	_ = os.MkdirAll(src, 0o755)
+	_ = os.WriteFile(filepath.Join(src, "Core.lua"), []byte("-- core"), 0o644)
+	if err := os.Symlink(shared, filepath.Join(src, "Libs")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	_ = os.Symlink(src, filepath.Join(shared, "Back"))
+	return src
+}
+
+func TestListFiles_FollowSymlinks(t *testing.T) {
+	src := symlinkTree(t)
+	ig := NewIgnorer(src, nil, false, false, false)
+	ig.FollowSymlinks = true
+
+	files, err := ListFiles(src, ig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Core.lua", filepath.Join("Libs", "LibStub", "LibStub.lua")}
+	if !slices.Equal(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+}
+
+func TestListFiles_SymlinkNotFollowed(t *testing.T) {
+	src := symlinkTree(t)
+
+	files, err := ListFiles(src, NewIgnorer(src, nil, false, false, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Core.lua", "Libs"}
+	if !slices.Equal(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+}
+
+func TestInitialSync_FollowSymlinks(t *testing.T) {
+	src := symlinkTree(t)
+	dst := t.TempDir()
+	ig := NewIgnorer(src, nil, false, false, false)
+	ig.FollowSymlinks = true
+
+	if _, err := InitialSync(src, dst, ig, nil); err != nil {
+		t.Fatal(err)
+	}
+	lib := filepath.Join(dst, "Libs", "LibStub", "LibStub.lua")
+	if isSymlink(filepath.Join(dst, "Libs")) {
+		t.Error("Libs was copied as a symlink")
+	}
+	if data, err := os.ReadFile(lib); err != nil || string(data) != "-- lib" {
+		t.Errorf("LibStub.lua = %q, %v", data, err)
+	}
+}
+
+func TestInitialSync_BrokenSymlink(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	_ = os.WriteFile(filepath.Join(src, "Core.lua"), []byte("-- core"), 0o644)
+	if err := os.Symlink(filepath.Join(src, "missing.lua"), filepath.Join(src, "Dangling.lua")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	for _, follow := range []bool{false, true} {
+		ig := NewIgnorer(src, nil, false, false, false)
+		ig.FollowSymlinks = follow
+		n, err := InitialSync(src, dst, ig, nil)
+		if err != nil {
+			t.Fatalf("follow=%v: %v", follow, err)
+		}
+		if n != 1 {
+			t.Errorf("follow=%v: synced %d files, want 1", follow, n)
+		}
+	}
+	if !BrokenLink(filepath.Join(src, "Dangling.lua")) {
+		t.Error("BrokenLink(Dangling.lua) = false")
+	}
+	if BrokenLink(filepath.Join(src, "Core.lua")) {
+		t.Error("BrokenLink(Core.lua) = true")
+	}
+}
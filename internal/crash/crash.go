@@ -0,0 +1,171 @@
+// Package crash writes a report when blink panics: the panic and its stack,
+// the config in use and the last changes handled, with the home folder and
+// anything that looks like a secret left out. The user gets the report's path
+// instead of a Go panic over a half-drawn TUI.
+package crash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/byteorem/blink/internal/state"
+)
+
+// maxEvents is how many recent events a report holds.
+const maxEvents = 50
+
+var (
+	mu      sync.Mutex
+	version string
+	config  string
+	events  []string
+	onCrash func()
+
+	crashing atomic.Bool
+)
+
+// SetVersion records the blink version for reports.
+func SetVersion(v string) {
+	mu.Lock()
+	defer mu.Unlock()
+	version = v
+}
+
+// SetConfig records the config in use for reports.
+func SetConfig(cfg any) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+		buf.Reset()
+		fmt.Fprintf(&buf, "(can't encode: %v)", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	config = buf.String()
+}
+
+// OnCrash sets a function run before the report is written, e.g. to give the
+// terminal back from the TUI. nil removes it.
+func OnCrash(f func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	onCrash = f
+}
+
+// Note records an event, such as a change being handled, for reports.
+func Note(format string, args ...any) {
+	line := time.Now().Format("15:04:05.000") + "  " + fmt.Sprintf(format, args...)
+	mu.Lock()
+	defer mu.Unlock()
+	events = append(events, line)
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+}
+
+// Recover is deferred at the top of main and of blink's goroutines. On a
+// panic it writes a report, tells the user where, and exits with status 2.
+func Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+	if !crashing.CompareAndSwap(false, true) {
+		// Another goroutine is already reporting its panic.
+		select {}
+	}
+	mu.Lock()
+	hook := onCrash
+	mu.Unlock()
+	if hook != nil {
+		hook()
+	}
+
+	path, err := writeReport(r, stack)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "blink crashed: %v\n\n%s\n(can't write a crash report: %v)\n", r, stack, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "blink crashed: %v\nThe details are in %s — please attach it when you report the bug.\n", r, path)
+	}
+	os.Exit(2)
+}
+
+// writeReport writes a report to the crashes folder of blink's state
+// directory and returns its path.
+func writeReport(r any, stack []byte) (string, error) {
+	dir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	home, _ := os.UserHomeDir()
+	return Write(filepath.Join(dir, "crashes"), Report(r, stack, home, time.Now()))
+}
+
+// Report returns the text of a report on the panic r with the given stack.
+// Paths below home are shortened to ~.
+func Report(r any, stack []byte, home string, now time.Time) string {
+	mu.Lock()
+	defer mu.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, "blink %s crashed at %s\n", version, now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "%s/%s, %s\n\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+	fmt.Fprintf(&b, "panic: %v\n\n%s\n", r, stack)
+	b.WriteString("Recent events, oldest first:\n")
+	if len(events) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for _, e := range events {
+		b.WriteString("  " + e + "\n")
+	}
+	b.WriteString("\nConfig:\n")
+	if config == "" {
+		b.WriteString("(not loaded)\n")
+	}
+	b.WriteString(config)
+	return redact(b.String(), home)
+}
+
+// Write saves report as a new file in dir and returns its path.
+func Write(dir, report string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, "crash-"+time.Now().Format("20060102-150405")+"-*.txt")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(report); err != nil {
+		return "", errors.Join(err, f.Close())
+	}
+	return f.Name(), f.Close()
+}
+
+// secretLine matches a config value under a key that names a secret.
+var secretLine = regexp.MustCompile(`(?im)^(\s*[\w.]*(?:token|secret|password|passwd|apikey|api_key|auth)[\w.]*\s*=\s*).+$`)
+
+// urlCredentials matches the user:password part of a URL.
+var urlCredentials = regexp.MustCompile(`://[^/@\s"']+@`)
+
+// redact shortens paths below home to ~ and blanks out secrets.
+func redact(s, home string) string {
+	if home != "" && home != "/" {
+		s = strings.ReplaceAll(s, home, "~")
+		if runtime.GOOS == "windows" {
+			// The config may spell the path with either separator.
+			s = strings.ReplaceAll(s, filepath.ToSlash(home), "~")
+		}
+	}
+	s = secretLine.ReplaceAllString(s, `${1}"(redacted)"`)
+	return urlCredentials.ReplaceAllString(s, "://(redacted)@")
+}
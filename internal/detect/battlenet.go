@@ -0,0 +1,161 @@
+package detect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/byteorem/blink/internal/flavor"
+)
+
+// productDB is where the Battle.net agent keeps what it installed, below
+// the ProgramData folder (on macOS, /Users/Shared).
+var productDB = filepath.Join("Battle.net", "Agent", "product.db")
+
+// FlavorInfoFile names the flavor a client folder holds, e.g.
+// _classic_era_/.flavor.info.
+const FlavorInfoFile = ".flavor.info"
+
+// Product is a game install recorded in Battle.net's product.db.
+type Product struct {
+	Code        string // e.g. "wow" or "wow_classic_era"
+	InstallPath string // as Battle.net wrote it, e.g. "C:/Program Files (x86)/World of Warcraft"
+}
+
+// ReadProducts returns the WoW installs recorded in the product.db at path.
+func ReadProducts(path string) ([]Product, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseProducts(data)
+}
+
+// parseProducts reads the WoW installs from product.db, a protobuf message
+// whose field 1 holds the installs: the product code in field 2, and in
+// field 3 the settings, with the install path in their field 1.
+func parseProducts(data []byte) ([]Product, error) {
+	var products []Product
+	err := protoFields(data, func(num int, value []byte) {
+		if num != 1 {
+			return
+		}
+		var p Product
+		_ = protoFields(value, func(num int, value []byte) {
+			switch num {
+			case 2:
+				p.Code = string(value)
+			case 3:
+				_ = protoFields(value, func(num int, value []byte) {
+					if num == 1 {
+						p.InstallPath = string(value)
+					}
+				})
+			}
+		})
+		if strings.HasPrefix(p.Code, "wow") && p.InstallPath != "" {
+			products = append(products, p)
+		}
+	})
+	return products, err
+}
+
+var errProto = errors.New("not a protobuf message")
+
+// protoFields calls fn with the number and bytes of each length-delimited
+// field of the protobuf message in data, skipping the other fields.
+func protoFields(data []byte, fn func(num int, value []byte)) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errProto
+		}
+		data = data[n:]
+		switch key & 7 {
+		case 0: // varint
+			if _, n = binary.Uvarint(data); n <= 0 {
+				return errProto
+			}
+			data = data[n:]
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return errProto
+			}
+			data = data[8:]
+		case 2: // length-delimited
+			size, n := binary.Uvarint(data)
+			if n <= 0 || size > uint64(len(data)-n) {
+				return errProto
+			}
+			fn(int(key>>3), data[n:n+int(size)])
+			data = data[n+int(size):]
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return errProto
+			}
+			data = data[4:]
+		default:
+			return errProto
+		}
+	}
+	return nil
+}
+
+// productInstalls returns the install folders the product.db below
+// programData records, with their Windows paths made local by drive, which
+// returns the folder a drive letter is at, or false when it isn't reachable.
+func productInstalls(programData string, drive func(letter byte) (string, bool)) []string {
+	products, err := ReadProducts(filepath.Join(programData, productDB))
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, p := range products {
+		dir := filepath.FromSlash(p.InstallPath)
+		if len(p.InstallPath) >= 2 && p.InstallPath[1] == ':' {
+			root, ok := drive(p.InstallPath[0])
+			if !ok {
+				continue
+			}
+			dir = filepath.Join(root, filepath.FromSlash(strings.TrimLeft(p.InstallPath[2:], `/\`)))
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// ClientFlavor returns the flavor of the client folder dir, from its name or
+// else its FlavorInfoFile, so a client in a renamed folder is still told.
+func ClientFlavor(dir string) (flavor.Flavor, bool) {
+	if f, ok := flavor.FromDir(filepath.Base(dir)); ok {
+		return f, true
+	}
+	data, err := os.ReadFile(filepath.Join(dir, FlavorInfoFile))
+	if err != nil {
+		return flavor.Flavor{}, false
+	}
+	return flavor.FromProduct(parseFlavorInfo(data))
+}
+
+// parseFlavorInfo returns the product code in a FlavorInfoFile: a header
+// line like "Product Flavor!STRING:0", then the value.
+func parseFlavorInfo(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	header := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if header {
+			header = false
+			continue
+		}
+		return strings.SplitN(line, "|", 2)[0]
+	}
+	return ""
+}
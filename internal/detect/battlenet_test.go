@@ -0,0 +1,85 @@
+package detect
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// field encodes a length-delimited protobuf field.
+func field(num int, value []byte) []byte {
+	b := binary.AppendUvarint(nil, uint64(num)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+// install encodes an install as product.db records it, with a varint field
+// in between as the real file has.
+func install(code, path string) []byte {
+	settings := field(1, []byte(path))
+	p := slices.Concat(field(1, []byte(code+"_uid")), field(2, []byte(code)), []byte{0x20, 0x01}, field(3, settings))
+	return field(1, p)
+}
+
+func TestParseProducts(t *testing.T) {
+	data := slices.Concat(
+		install("wow", "C:/Program Files (x86)/World of Warcraft"),
+		install("agent", "C:/ProgramData/Battle.net/Agent"),
+		install("wow_classic_era", "D:/Games/World of Warcraft"),
+		field(2, []byte("settings")),
+	)
+	got, err := parseProducts(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Product{
+		{Code: "wow", InstallPath: "C:/Program Files (x86)/World of Warcraft"},
+		{Code: "wow_classic_era", InstallPath: "D:/Games/World of Warcraft"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseProducts() = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseProducts([]byte{0x0a, 0x7f}); err == nil {
+		t.Error("parseProducts() accepted a truncated file")
+	}
+}
+
+func TestProductInstalls(t *testing.T) {
+	driveC := t.TempDir()
+	db := filepath.Join(driveC, "ProgramData", productDB)
+	_ = os.MkdirAll(filepath.Dir(db), 0o755)
+	data := slices.Concat(install("wow", "C:/Program Files (x86)/World of Warcraft"), install("wowt", "E:/WoW"))
+	if err := os.WriteFile(db, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// In a Wine prefix only C: is reachable.
+	got := productInstalls(filepath.Join(driveC, "ProgramData"), func(letter byte) (string, bool) {
+		return driveC, letter == 'C'
+	})
+	want := []string{filepath.Join(driveC, "Program Files (x86)", "World of Warcraft")}
+	if !slices.Equal(got, want) {
+		t.Errorf("productInstalls() = %v, want %v", got, want)
+	}
+}
+
+func TestClientFlavor(t *testing.T) {
+	root := t.TempDir()
+	era := filepath.Join(root, "_classic_era_")
+	renamed := filepath.Join(root, "Classic Era")
+	_ = os.MkdirAll(era, 0o755)
+	_ = os.MkdirAll(renamed, 0o755)
+	_ = os.WriteFile(filepath.Join(renamed, FlavorInfoFile), []byte("Product Flavor!STRING:0\nwow_classic_era\n"), 0o644)
+
+	for _, dir := range []string{era, renamed} {
+		if f, ok := ClientFlavor(dir); !ok || f.Name != "classic_era" {
+			t.Errorf("ClientFlavor(%q) = %q, %v, want classic_era", dir, f.Name, ok)
+		}
+	}
+	if f, ok := ClientFlavor(root); ok {
+		t.Errorf("ClientFlavor(%q) = %q, want none", root, f.Name)
+	}
+}
@@ -3,6 +3,7 @@ package detect
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,15 +16,14 @@ func FindAddon(sourceFlag string) (srcDir string, addonName string, err error) {
 		if err != nil {
 			return "", "", fmt.Errorf("invalid source path: %w", err)
 		}
+		if info, err := os.Stat(srcDir); err != nil || !info.IsDir() {
+			return "", "", missingSource(sourceFlag)
+		}
 		addonName = filepath.Base(srcDir)
 
 		// Try to derive addon name from .toc file in the source dir
-		entries, _ := os.ReadDir(srcDir)
-		for _, e := range entries {
-			if !e.IsDir() && strings.HasSuffix(strings.ToLower(e.Name()), ".toc") {
-				addonName = strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
-				break
-			}
+		if name, ok := AddonName(srcDir); ok {
+			addonName = name
 		}
 		return srcDir, addonName, nil
 	}
@@ -35,47 +35,143 @@ func FindAddon(sourceFlag string) (srcDir string, addonName string, err error) {
 	}
 
 	// Check root for .toc files
-	entries, err := os.ReadDir(cwd)
+	tocs, err := TocFiles(cwd)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to read directory: %w", err)
 	}
+	if len(tocs) > 0 {
+		name := tocAddonName(tocs)
+		slog.Debug("found addon", "name", name, "dir", cwd)
+		return cwd, name, nil
+	}
 
-	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(strings.ToLower(e.Name()), ".toc") {
-			name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
-			return cwd, name, nil
+	// Check subfolders for .toc files
+	dirs := SiblingAddons(cwd)
+	switch {
+	case len(dirs) == 1:
+		name, _ := AddonName(dirs[0])
+		slog.Debug("found addon", "name", name, "dir", dirs[0])
+		return dirs[0], name, nil
+	case len(dirs) > 1:
+		names := make([]string, len(dirs))
+		for i, d := range dirs {
+			names[i] = filepath.Base(d)
 		}
+		return "", "", fmt.Errorf("found several addons (%s) — enable [workspace] in blink.toml to sync them all, or pick one with --source",
+			strings.Join(names, ", "))
 	}
 
-	// Check subfolders for .toc files
+	return "", "", fmt.Errorf("no .toc file found — set source in blink.toml or use --source")
+}
+
+// SiblingAddons returns the folders directly inside root that hold a .toc
+// file, sorted, as in a repository of several addons side by side. Hidden
+// folders are skipped.
+func SiblingAddons(root string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
 	for _, e := range entries {
-		if !e.IsDir() {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
 			continue
 		}
-		subEntries, err := os.ReadDir(filepath.Join(cwd, e.Name()))
+		dir := filepath.Join(root, e.Name())
+		if _, ok := AddonName(dir); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// FindAddonSources resolves several source directories overlaid into one
+// addon. The addon is named after the .toc file of the first source that has
+// one, and that source is returned first.
+func FindAddonSources(paths []string) (srcDirs []string, addonName string, err error) {
+	primary := -1
+	for i, p := range paths {
+		dir, err := filepath.Abs(p)
 		if err != nil {
-			continue
+			return nil, "", fmt.Errorf("invalid source path: %w", err)
+		}
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			return nil, "", missingSource(p)
 		}
-		for _, se := range subEntries {
-			if !se.IsDir() && strings.HasSuffix(strings.ToLower(se.Name()), ".toc") {
-				name := strings.TrimSuffix(se.Name(), filepath.Ext(se.Name()))
-				return filepath.Join(cwd, e.Name()), name, nil
+		srcDirs = append(srcDirs, dir)
+		if primary < 0 {
+			if tocs, _ := TocFiles(dir); len(tocs) > 0 {
+				primary = i
+				addonName = tocAddonName(tocs)
 			}
 		}
 	}
+	if primary < 0 {
+		return nil, "", fmt.Errorf("none of the sources contains a .toc file")
+	}
+	srcDirs[0], srcDirs[primary] = srcDirs[primary], srcDirs[0]
+	return srcDirs, addonName, nil
+}
 
-	return "", "", fmt.Errorf("no .toc file found — set source in blink.toml or use --source")
+// AddonName returns the addon name given by the .toc files in dir, without
+// a flavor suffix: MyAddon for MyAddon_Mainline.toc and MyAddon_Vanilla.toc.
+func AddonName(dir string) (string, bool) {
+	tocs, err := TocFiles(dir)
+	if err != nil || len(tocs) == 0 {
+		return "", false
+	}
+	return tocAddonName(tocs), true
+}
+
+// TocFiles returns the names of the .toc files directly inside dir, sorted.
+func TocFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(strings.ToLower(e.Name()), ".toc") {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
 }
 
-// FindWowPath resolves the WoW version directory from a flag or auto-detection.
+// FindWowPath resolves the WoW version directory from a flag, or with "auto"
+// or an empty flag the install FindInstall finds.
+// A path to the client's Interface or Interface/AddOns folder is taken as the
+// client folder itself, so AddOns isn't nested inside AddOns.
 func FindWowPath(wowPathFlag string) (string, error) {
 	if wowPathFlag != "" && wowPathFlag != "auto" {
 		info, err := os.Stat(wowPathFlag)
 		if err != nil || !info.IsDir() {
 			return "", fmt.Errorf("wow-path %q does not exist or is not a directory", wowPathFlag)
 		}
+		if dir, ok := clientDir(wowPathFlag); ok {
+			slog.Debug("wow-path names a folder inside the client, using the client folder", "wowPath", wowPathFlag, "client", dir)
+			return dir, nil
+		}
 		return wowPathFlag, nil
 	}
 
-	return "", fmt.Errorf("wowPath is required — set wowPath in blink.toml or use --wow-path")
+	if dir, ok := FindInstall(); ok {
+		slog.Debug("found WoW", "wowPath", dir)
+		return dir, nil
+	}
+	return "", fmt.Errorf("WoW wasn't found where it is usually installed — set wowPath in blink.toml or use --wow-path")
+}
+
+// clientDir returns the client folder that dir is the Interface or
+// Interface/AddOns folder of.
+func clientDir(dir string) (string, bool) {
+	dir = filepath.Clean(dir)
+	if strings.EqualFold(filepath.Base(dir), "AddOns") {
+		dir = filepath.Dir(dir)
+	}
+	if strings.EqualFold(filepath.Base(dir), "Interface") {
+		return filepath.Dir(dir), true
+	}
+	return "", false
 }
@@ -3,6 +3,8 @@ package detect
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -77,6 +79,31 @@ func TestFindAddon_AutoDetectSubdir(t *testing.T) {
 	}
 }
 
+func TestFindAddon_SeveralSubdirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"AddonB", "AddonA", ".hidden", "docs"} {
+		_ = os.MkdirAll(filepath.Join(dir, name), 0o755)
+		if name != "docs" {
+			_ = os.WriteFile(filepath.Join(dir, name, name+".toc"), []byte(""), 0o644)
+		}
+	}
+
+	got := SiblingAddons(dir)
+	want := []string{filepath.Join(dir, "AddonA"), filepath.Join(dir, "AddonB")}
+	if !slices.Equal(got, want) {
+		t.Errorf("SiblingAddons() = %q, want %q", got, want)
+	}
+
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	_, _, err := FindAddon("auto")
+	if err == nil || !strings.Contains(err.Error(), "AddonA, AddonB") {
+		t.Errorf("FindAddon() error = %v, want one naming both addons", err)
+	}
+}
+
 func TestFindAddon_NoTocError(t *testing.T) {
 	dir := t.TempDir()
 	orig, _ := os.Getwd()
@@ -100,6 +127,23 @@ func TestFindWowPath_Explicit(t *testing.T) {
 	}
 }
 
+func TestFindWowPath_AddOnsFolder(t *testing.T) {
+	client := filepath.Join(t.TempDir(), "_retail_")
+	addOns := filepath.Join(client, "Interface", "AddOns")
+	if err := os.MkdirAll(addOns, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{addOns, addOns + string(filepath.Separator), filepath.Join(client, "Interface")} {
+		path, err := FindWowPath(dir)
+		if err != nil {
+			t.Fatalf("FindWowPath(%q) error = %v", dir, err)
+		}
+		if path != client {
+			t.Errorf("FindWowPath(%q) = %q, want %q", dir, path, client)
+		}
+	}
+}
+
 func TestFindWowPath_InvalidPath(t *testing.T) {
 	_, err := FindWowPath("/nonexistent/path/that/doesnt/exist")
 	if err == nil {
@@ -107,7 +151,16 @@ func TestFindWowPath_InvalidPath(t *testing.T) {
 	}
 }
 
+// noInstall makes the test run as if WoW weren't installed anywhere.
+func noInstall(t *testing.T) {
+	t.Helper()
+	orig := installCandidates
+	installCandidates = func() []string { return nil }
+	t.Cleanup(func() { installCandidates = orig })
+}
+
 func TestFindWowPath_AutoReturnsError(t *testing.T) {
+	noInstall(t)
 	_, err := FindWowPath("auto")
 	if err == nil {
 		t.Fatal("FindWowPath(\"auto\") should return error requiring explicit path")
@@ -115,8 +168,98 @@ func TestFindWowPath_AutoReturnsError(t *testing.T) {
 }
 
 func TestFindWowPath_EmptyReturnsError(t *testing.T) {
+	noInstall(t)
 	_, err := FindWowPath("")
 	if err == nil {
 		t.Fatal("FindWowPath(\"\") should return error requiring explicit path")
 	}
 }
+
+func TestTocFiles(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "MyAddon_Vanilla.toc"), []byte(""), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "MyAddon.toc"), []byte(""), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "Core.lua"), []byte(""), 0o644)
+
+	names, err := TocFiles(dir)
+	if err != nil {
+		t.Fatalf("TocFiles() error = %v", err)
+	}
+	if len(names) != 2 || names[0] != "MyAddon.toc" || names[1] != "MyAddon_Vanilla.toc" {
+		t.Errorf("TocFiles() = %v, want [MyAddon.toc MyAddon_Vanilla.toc]", names)
+	}
+}
+
+func TestFindAddonSources(t *testing.T) {
+	common := t.TempDir()
+	addon := t.TempDir()
+	_ = os.WriteFile(filepath.Join(addon, "MyAddon.toc"), []byte("## Title: My Addon"), 0o644)
+
+	dirs, name, err := FindAddonSources([]string{common, addon})
+	if err != nil {
+		t.Fatalf("FindAddonSources() error = %v", err)
+	}
+	if name != "MyAddon" {
+		t.Errorf("name = %q, want %q", name, "MyAddon")
+	}
+	if len(dirs) != 2 || dirs[0] != addon || dirs[1] != common {
+		t.Errorf("dirs = %v, want [%s %s]", dirs, addon, common)
+	}
+}
+
+func TestFindAddonSources_Errors(t *testing.T) {
+	if _, _, err := FindAddonSources([]string{t.TempDir(), t.TempDir()}); err == nil {
+		t.Error("expected error when no source has a .toc")
+	}
+	if _, _, err := FindAddonSources([]string{t.TempDir(), "/nonexistent/path/xyz"}); err == nil {
+		t.Error("expected error for missing source")
+	}
+}
+
+func TestAddonName(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := AddonName(dir); ok {
+		t.Error("AddonName() found a name without a .toc")
+	}
+	_ = os.WriteFile(filepath.Join(dir, "Renamed.toc"), []byte("## Title: x"), 0o644)
+	if name, ok := AddonName(dir); !ok || name != "Renamed" {
+		t.Errorf("AddonName() = %q, %v, want Renamed", name, ok)
+	}
+}
+
+func TestFindAddon_MissingSourceSuggests(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "MyAddOn"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "MyAddOn", "MyAddOn.toc"), []byte("## Title: x\n"), 0o644)
+	_ = os.MkdirAll(filepath.Join(dir, "Other"), 0o755)
+
+	_, _, err := FindAddon(filepath.Join(dir, "MyAdon"))
+	if err == nil || !strings.Contains(err.Error(), "did you mean "+filepath.Join(dir, "MyAddOn")+"?") {
+		t.Errorf("FindAddon() error = %v, want a suggestion of MyAddOn", err)
+	}
+}
+
+func TestSuggestSources(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"Libs", "Addon_Core", "Addon_Options"} {
+		_ = os.MkdirAll(filepath.Join(dir, name), 0o755)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "Addon_Core", "Addon_Core.toc"), []byte(""), 0o644)
+
+	orig, _ := os.Getwd()
+	defer func() { _ = os.Chdir(orig) }()
+	_ = os.Chdir(dir)
+
+	sep := string(filepath.Separator)
+	if got := SuggestSources("addon_cor"); len(got) != 1 || got[0] != "."+sep+"Addon_Core" {
+		t.Errorf("SuggestSources(addon_cor) = %v", got)
+	}
+	// Nothing close: the addon folders there.
+	if got := SuggestSources("Source"); len(got) != 1 || got[0] != "."+sep+"Addon_Core" {
+		t.Errorf("SuggestSources(Source) = %v", got)
+	}
+	// A typo further up: folders next to the first missing one.
+	if got := SuggestSources(filepath.Join("Lbs", "LibStub")); len(got) != 1 || got[0] != "."+sep+"Libs" {
+		t.Errorf("SuggestSources(Lbs/LibStub) = %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/byteorem/blink/internal/flavor"
+)
+
+// TocFlavors returns the flavors the addons in dirs are made for, judging by
+// the suffixes of their .toc files, in flavor order. It returns nil when any
+// flavor will do: a .toc without a flavor suffix loads on every client.
+func TocFlavors(dirs ...string) []flavor.Flavor {
+	supported := make(map[string]bool)
+	for _, dir := range dirs {
+		tocs, _ := TocFiles(dir)
+		for _, t := range tocs {
+			base := strings.TrimSuffix(t, filepath.Ext(t))
+			i := strings.LastIndex(base, "_")
+			if i <= 0 {
+				return nil
+			}
+			f, ok := flavor.FromTocSuffix(base[i+1:])
+			if !ok {
+				return nil
+			}
+			supported[f.Name] = true
+		}
+	}
+	if len(supported) == 0 {
+		return nil
+	}
+	var flavors []flavor.Flavor
+	for _, f := range flavor.All() {
+		if supported[f.Name] {
+			flavors = append(flavors, f)
+		}
+	}
+	return flavors
+}
+
+// InstalledFlavors returns the flavors whose client folder exists in the WoW
+// install at root, e.g. _retail_ and _classic_era_.
+func InstalledFlavors(root string) []flavor.Flavor {
+	var flavors []flavor.Flavor
+	for _, f := range flavor.All() {
+		if info, err := os.Stat(filepath.Join(root, f.Dir)); err == nil && info.IsDir() {
+			flavors = append(flavors, f)
+		}
+	}
+	return flavors
+}
+
+// IsClientDir reports whether dir looks like a WoW client folder: one holding
+// Interface or WTF, or the game executable (Wow.exe, WowClassic.exe, or World
+// of Warcraft.app on macOS).
+func IsClientDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		name := strings.ToLower(e.Name())
+		switch {
+		case e.IsDir() && (name == "interface" || name == "wtf"):
+			return true
+		case strings.HasPrefix(name, "wow") && strings.HasSuffix(name, ".exe"):
+			return true
+		case strings.HasPrefix(name, "world of warcraft") && strings.HasSuffix(name, ".app"):
+			return true
+		}
+	}
+	return false
+}
+
+// PickFlavors returns the flavors installed at root that the addons in dirs
+// are made for, in flavor order (retail first, test clients last).
+func PickFlavors(root string, dirs ...string) []flavor.Flavor {
+	installed := InstalledFlavors(root)
+	want := TocFlavors(dirs...)
+	if want == nil {
+		return installed
+	}
+	var picked []flavor.Flavor
+	for _, f := range installed {
+		if f.Custom {
+			// There is no telling which .toc files its client loads.
+			picked = append(picked, f)
+			continue
+		}
+		for _, w := range want {
+			if f.Runs(w.Name) {
+				picked = append(picked, f)
+			}
+		}
+	}
+	return picked
+}
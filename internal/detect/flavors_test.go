@@ -0,0 +1,61 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPickFlavors(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"_retail_", "_classic_era_"} {
+		_ = os.MkdirAll(filepath.Join(root, dir), 0o755)
+	}
+	names := func(dirs ...string) []string {
+		var got []string
+		for _, f := range PickFlavors(root, dirs...) {
+			got = append(got, f.Name)
+		}
+		return got
+	}
+
+	vanilla := t.TempDir()
+	_ = os.WriteFile(filepath.Join(vanilla, "MyAddon_Vanilla.toc"), nil, 0o644)
+	_ = os.WriteFile(filepath.Join(vanilla, "MyAddon_Mists.toc"), nil, 0o644)
+	if got := names(vanilla); !slices.Equal(got, []string{"classic_era"}) {
+		t.Errorf("PickFlavors(Vanilla, Mists) = %v, want [classic_era]", got)
+	}
+
+	plain := t.TempDir()
+	_ = os.WriteFile(filepath.Join(plain, "MyAddon.toc"), nil, 0o644)
+	if got := names(plain); !slices.Equal(got, []string{"retail", "classic_era"}) {
+		t.Errorf("PickFlavors(unsuffixed) = %v, want every installed flavor", got)
+	}
+
+	mists := t.TempDir()
+	_ = os.WriteFile(filepath.Join(mists, "MyAddon_Mists.toc"), nil, 0o644)
+	if got := names(mists); len(got) != 0 {
+		t.Errorf("PickFlavors(Mists) = %v, want none installed", got)
+	}
+}
+
+func TestIsClientDir(t *testing.T) {
+	tests := map[string]func(dir string){
+		"Interface":  func(dir string) { _ = os.Mkdir(filepath.Join(dir, "Interface"), 0o755) },
+		"WTF":        func(dir string) { _ = os.Mkdir(filepath.Join(dir, "WTF"), 0o755) },
+		"Wow.exe":    func(dir string) { _ = os.WriteFile(filepath.Join(dir, "Wow.exe"), nil, 0o644) },
+		"classic":    func(dir string) { _ = os.WriteFile(filepath.Join(dir, "WowClassic.exe"), nil, 0o644) },
+		"macOS":      func(dir string) { _ = os.Mkdir(filepath.Join(dir, "World of Warcraft.app"), 0o755) },
+		"empty":      func(dir string) {},
+		"WoW folder": func(dir string) { _ = os.Mkdir(filepath.Join(dir, "_retail_"), 0o755) },
+	}
+	for name, setup := range tests {
+		dir := t.TempDir()
+		setup(dir)
+		want := name != "empty" && name != "WoW folder"
+		if got := IsClientDir(dir); got != want {
+			t.Errorf("IsClientDir(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
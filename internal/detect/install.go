@@ -0,0 +1,117 @@
+package detect
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// registryKey is where the Battle.net installer records the WoW install.
+const registryKey = `HKLM\SOFTWARE\WOW6432Node\Blizzard Entertainment\World of Warcraft`
+
+// drives are the Windows drives searched for a WoW install.
+const drives = "CDEFGH"
+
+// installDirs are where WoW is usually installed below a drive's root.
+var installDirs = []string{
+	"Program Files (x86)/World of Warcraft",
+	"Program Files/World of Warcraft",
+	"World of Warcraft",
+	"Games/World of Warcraft",
+	"Program Files (x86)/Battle.net/World of Warcraft",
+	"Blizzard/World of Warcraft",
+}
+
+// installCandidates returns the folders a WoW install is looked for in, most
+// likely first. Tests replace it.
+var installCandidates = func() []string {
+	var dirs []string
+	switch runtime.GOOS {
+	case "windows":
+		dirs = append(dirs, productInstalls(os.Getenv("ProgramData"), func(letter byte) (string, bool) {
+			return string(letter) + `:\`, true
+		})...)
+		if out, err := exec.Command("reg", "query", registryKey, "/v", "InstallPath").Output(); err == nil {
+			if dir, ok := parseRegQuery(string(out), "InstallPath"); ok {
+				dirs = append(dirs, dir)
+			}
+		}
+		for _, d := range drives {
+			for _, rel := range installDirs {
+				dirs = append(dirs, filepath.Join(string(d)+`:\`, filepath.FromSlash(rel)))
+			}
+		}
+	case "linux":
+		// Under WSL, the Windows drives are mounted at /mnt/c, /mnt/d, …;
+		// elsewhere WoW runs in a Wine prefix with a C: drive of its own.
+		roots := make([]string, 0, len(drives))
+		for _, d := range strings.ToLower(drives) {
+			roots = append(roots, filepath.Join("/mnt", string(d)))
+		}
+		home, _ := os.UserHomeDir()
+		roots = append(roots, wineDrives(home, os.Getenv("WINEPREFIX"))...)
+		for _, root := range roots[:len(drives)] {
+			dirs = append(dirs, productInstalls(filepath.Join(root, "ProgramData"), func(letter byte) (string, bool) {
+				return filepath.Join("/mnt", strings.ToLower(string(letter))), true
+			})...)
+		}
+		for _, root := range roots[len(drives):] {
+			dirs = append(dirs, productInstalls(filepath.Join(root, "ProgramData"), func(letter byte) (string, bool) {
+				return root, letter == 'C' || letter == 'c'
+			})...)
+		}
+		for _, root := range roots {
+			for _, rel := range installDirs {
+				dirs = append(dirs, filepath.Join(root, rel))
+			}
+		}
+	case "darwin":
+		dirs = append(dirs, productInstalls("/Users/Shared", func(byte) (string, bool) { return "", false })...)
+		dirs = append(dirs, "/Applications/World of Warcraft")
+	}
+	return dirs
+}
+
+// FindInstall looks for WoW where Battle.net's product.db says it installed
+// it, then where it is usually installed: on Windows where the registry says
+// and in the usual folders of each drive, on Linux on the
+// Windows drives WSL mounts at /mnt and in the C: drives of Wine, Lutris,
+// Bottles and Proton prefixes. It returns the install folder holding
+// _retail_, _classic_era_, …, or a client folder when that is all there is.
+func FindInstall() (string, bool) {
+	for _, dir := range installCandidates() {
+		if len(InstalledFlavors(dir)) > 0 {
+			return dir, true
+		}
+		if IsClientDir(dir) {
+			if _, ok := ClientFlavor(dir); ok && len(InstalledFlavors(filepath.Dir(dir))) > 0 {
+				return filepath.Dir(dir), true
+			}
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// parseRegQuery returns the data of the value name in the output of reg
+// query, e.g. from the line "    InstallPath    REG_SZ    C:\...\_retail_\".
+func parseRegQuery(out, name string) (string, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || !strings.EqualFold(fields[0], name) || !strings.HasPrefix(fields[1], "REG_") {
+			continue
+		}
+		// The data may hold spaces; it starts after the type.
+		line := scanner.Text()
+		data := strings.TrimSpace(line[strings.Index(line, fields[1])+len(fields[1]):])
+		if data == "" {
+			return "", false
+		}
+		return filepath.Clean(strings.TrimRight(data, `\/`)), true
+	}
+	return "", false
+}
@@ -0,0 +1,47 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindInstall(t *testing.T) {
+	root := t.TempDir()
+	missing := filepath.Join(root, "C", "World of Warcraft")
+	install := filepath.Join(root, "D", "World of Warcraft")
+	_ = os.MkdirAll(filepath.Join(install, "_classic_era_", "Interface"), 0o755)
+	orig := installCandidates
+	defer func() { installCandidates = orig }()
+
+	installCandidates = func() []string { return []string{missing, install} }
+	if dir, ok := FindInstall(); !ok || dir != install {
+		t.Errorf("FindInstall() = %q, %v, want %q", dir, ok, install)
+	}
+	if dir, err := FindWowPath("auto"); err != nil || dir != install {
+		t.Errorf("FindWowPath(\"auto\") = %q, %v, want %q", dir, err, install)
+	}
+
+	// The registry names the client folder; its install is returned.
+	installCandidates = func() []string { return []string{filepath.Join(install, "_classic_era_")} }
+	if dir, ok := FindInstall(); !ok || dir != install {
+		t.Errorf("FindInstall() from a client folder = %q, %v, want %q", dir, ok, install)
+	}
+
+	installCandidates = func() []string { return []string{missing} }
+	if dir, ok := FindInstall(); ok {
+		t.Errorf("FindInstall() = %q, want none", dir)
+	}
+}
+
+func TestParseRegQuery(t *testing.T) {
+	out := "\r\nHKEY_LOCAL_MACHINE\\SOFTWARE\\WOW6432Node\\Blizzard Entertainment\\World of Warcraft\r\n" +
+		"    InstallPath    REG_SZ    C:\\Program Files (x86)\\World of Warcraft\\_retail_\\\r\n\r\n"
+	dir, ok := parseRegQuery(out, "InstallPath")
+	if want := `C:\Program Files (x86)\World of Warcraft\_retail_`; !ok || dir != filepath.Clean(want) {
+		t.Errorf("parseRegQuery() = %q, %v, want %q", dir, ok, want)
+	}
+	if _, ok := parseRegQuery("ERROR: The system was unable to find the specified registry key or value.\r\n", "InstallPath"); ok {
+		t.Error("parseRegQuery() found a value in an error")
+	}
+}
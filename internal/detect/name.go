@@ -0,0 +1,95 @@
+package detect
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/byteorem/blink/internal/flavor"
+)
+
+// NameCheck is the result of checking an addon name against its .toc files.
+type NameCheck struct {
+	Unloaded []string // .toc files the client won't load from a folder with the name
+	Fix      string   // the folder name that loads every .toc file, or "" if there is none
+}
+
+// OK reports whether every .toc file loads.
+func (nc NameCheck) OK() bool {
+	return len(nc.Unloaded) == 0
+}
+
+// TocBase returns the folder name a .toc file loads from: its base name
+// without a flavor suffix, e.g. "MyAddon" for MyAddon_Mainline.toc.
+func TocBase(tocName string) string {
+	base := strings.TrimSuffix(tocName, filepath.Ext(tocName))
+	if i := strings.LastIndex(base, "_"); i > 0 {
+		if _, ok := flavor.FromTocSuffix(base[i+1:]); ok {
+			return base[:i]
+		}
+	}
+	return base
+}
+
+// tocAddonName returns the addon name the .toc files tocs give: the name of
+// one without a flavor suffix, or else the first one's without its suffix.
+func tocAddonName(tocs []string) string {
+	for _, t := range tocs {
+		if base := strings.TrimSuffix(t, filepath.Ext(t)); TocBase(t) == base {
+			return base
+		}
+	}
+	return TocBase(tocs[0])
+}
+
+// TocFor returns the .toc file in dir a client of flavor fl loads: the one
+// with fl's own suffix, then one with another suffix fl runs (e.g. _Cata on
+// a classic client), then the one without a suffix. It returns false when
+// the client loads none of them.
+func TocFor(dir string, fl flavor.Flavor) (string, bool) {
+	tocs, _ := TocFiles(dir)
+	if len(tocs) == 0 {
+		return "", false
+	}
+	name := tocAddonName(tocs)
+	var other, plain string
+	for _, t := range tocs {
+		base := strings.TrimSuffix(t, filepath.Ext(t))
+		switch {
+		case strings.EqualFold(base, name):
+			plain = t
+		case TocBase(t) != name:
+		case fl.TocSuffix != "" && strings.EqualFold(base, name+"_"+fl.TocSuffix):
+			return t, true
+		case other == "":
+			if f, ok := flavor.FromTocSuffix(base[len(name)+1:]); ok && fl.Runs(f.Name) {
+				other = t
+			}
+		}
+	}
+	if other != "" {
+		return other, true
+	}
+	return plain, plain != ""
+}
+
+// CheckName reports which .toc files in dir the client won't load when the
+// addon's folder is called name. The client only loads <folder>.toc and
+// <folder>_<flavor suffix>.toc.
+func CheckName(dir, name string) NameCheck {
+	tocs, _ := TocFiles(dir)
+	var nc NameCheck
+	for _, t := range tocs {
+		if TocBase(t) != name && strings.TrimSuffix(t, filepath.Ext(t)) != name {
+			nc.Unloaded = append(nc.Unloaded, t)
+		}
+	}
+	for i, t := range tocs {
+		if i == 0 {
+			nc.Fix = TocBase(t)
+		} else if TocBase(t) != nc.Fix {
+			nc.Fix = ""
+			break
+		}
+	}
+	return nc
+}
@@ -0,0 +1,77 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/byteorem/blink/internal/flavor"
+)
+
+func TestTocBase(t *testing.T) {
+	tests := map[string]string{
+		"MyAddon.toc":          "MyAddon",
+		"MyAddon_Mainline.toc": "MyAddon",
+		"MyAddon_Vanilla.toc":  "MyAddon",
+		"My_Addon.toc":         "My_Addon",
+		"My_Addon_Cata.toc":    "My_Addon",
+	}
+	for in, want := range tests {
+		if got := TocBase(in); got != want {
+			t.Errorf("TocBase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"MyAddon_Mainline.toc", "MyAddon_Vanilla.toc"} {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte("## Title: x"), 0o644)
+	}
+
+	nc := CheckName(dir, "MyAddon_Mainline")
+	if nc.OK() || !slices.Equal(nc.Unloaded, []string{"MyAddon_Vanilla.toc"}) || nc.Fix != "MyAddon" {
+		t.Errorf("CheckName(MyAddon_Mainline) = %+v", nc)
+	}
+	if nc := CheckName(dir, "MyAddon"); !nc.OK() {
+		t.Errorf("CheckName(MyAddon) = %+v, want OK", nc)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "Other.toc"), []byte("## Title: x"), 0o644)
+	if nc := CheckName(dir, "MyAddon"); nc.OK() || nc.Fix != "" {
+		t.Errorf("CheckName() with two addon names = %+v, want no fix", nc)
+	}
+}
+
+func TestTocFor(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"MyAddon.toc", "MyAddon_Cata.toc", "MyAddon_Vanilla.toc"} {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte("## Title: x"), 0o644)
+	}
+
+	if name, ok := AddonName(dir); !ok || name != "MyAddon" {
+		t.Errorf("AddonName() = %q, %v, want MyAddon", name, ok)
+	}
+	tests := map[string]string{
+		"retail":      "MyAddon.toc",
+		"classic":     "MyAddon_Cata.toc",
+		"classic_ptr": "MyAddon_Cata.toc",
+		"classic_era": "MyAddon_Vanilla.toc",
+	}
+	for name, want := range tests {
+		fl, _ := flavor.Lookup(name)
+		if got, ok := TocFor(dir, fl); !ok || got != want {
+			t.Errorf("TocFor(%s) = %q, %v, want %q", name, got, ok, want)
+		}
+	}
+
+	_ = os.Remove(filepath.Join(dir, "MyAddon.toc"))
+	if name, _ := AddonName(dir); name != "MyAddon" {
+		t.Errorf("AddonName() with suffixed .toc files only = %q, want MyAddon", name)
+	}
+	retail, _ := flavor.Lookup("retail")
+	if got, ok := TocFor(dir, retail); ok {
+		t.Errorf("TocFor(retail) = %q, want none", got)
+	}
+}
@@ -0,0 +1,120 @@
+package detect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// missingSource returns the error for a source path that doesn't exist, with
+// the nearby folders it might have meant.
+func missingSource(path string) error {
+	if s := SuggestSources(path); len(s) > 0 {
+		return fmt.Errorf("source %q does not exist — did you mean %s?", path, strings.Join(s, " or "))
+	}
+	return fmt.Errorf("source %q does not exist or is not a directory", path)
+}
+
+// SuggestSources returns folders near the missing path that it might have
+// meant: folders next to it with a similar name, best first, or otherwise the
+// addon folders (those with a .toc file) there. Paths are given relative to
+// the working directory when below it.
+func SuggestSources(path string) []string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	parent, base := filepath.Dir(abs), filepath.Base(abs)
+	// Walk up to the nearest folder that exists, for typos further up.
+	for {
+		if info, err := os.Stat(parent); err == nil && info.IsDir() {
+			break
+		}
+		next := filepath.Dir(parent)
+		if next == parent {
+			return nil
+		}
+		base, parent = filepath.Base(parent), next
+	}
+
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return nil
+	}
+	type candidate struct {
+		dir   string
+		dist  int
+		isToc bool
+	}
+	var close, addons []candidate
+	limit := max(2, len(base)/3)
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(parent, e.Name())
+		tocs, _ := TocFiles(dir)
+		c := candidate{dir: dir, dist: distance(strings.ToLower(base), strings.ToLower(e.Name())), isToc: len(tocs) > 0}
+		if c.dist <= limit {
+			close = append(close, c)
+		} else if c.isToc {
+			addons = append(addons, c)
+		}
+	}
+	if len(close) == 0 {
+		close = addons
+	}
+	sort.SliceStable(close, func(i, j int) bool {
+		if close[i].dist != close[j].dist {
+			return close[i].dist < close[j].dist
+		}
+		return close[i].isToc && !close[j].isToc
+	})
+
+	var out []string
+	for i, c := range close {
+		if i == 3 {
+			break
+		}
+		out = append(out, displayPath(c.dir))
+	}
+	return out
+}
+
+// displayPath shortens dir to a ./relative path when it is below the working
+// directory.
+func displayPath(dir string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return dir
+	}
+	rel, err := filepath.Rel(cwd, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return dir
+	}
+	return "." + string(filepath.Separator) + rel
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,43 @@
+package detect
+
+import (
+	"path/filepath"
+	"slices"
+)
+
+// winePrefixGlobs are where Wine, Lutris, Bottles and Steam's Proton keep
+// their prefixes, relative to the home folder.
+var winePrefixGlobs = []string{
+	".wine",
+	// Lutris, now and in older releases
+	"Games/*",
+	".local/share/lutris/prefixes/*",
+	// Bottles, and Bottles from Flathub
+	".local/share/bottles/bottles/*",
+	".var/app/com.usebottles.bottles/data/bottles/bottles/*",
+	// Proton, per Steam game, and Steam from Flathub
+	".steam/steam/steamapps/compatdata/*/pfx",
+	".local/share/Steam/steamapps/compatdata/*/pfx",
+	".var/app/com.valvesoftware.Steam/.local/share/Steam/steamapps/compatdata/*/pfx",
+}
+
+// wineDrives returns the C: drives of the Wine prefixes under home, the one
+// WINEPREFIX names (winePrefix) first.
+func wineDrives(home, winePrefix string) []string {
+	var drives []string
+	if winePrefix != "" {
+		drives = append(drives, filepath.Join(winePrefix, "drive_c"))
+	}
+	if home == "" {
+		return drives
+	}
+	for _, pattern := range winePrefixGlobs {
+		matches, _ := filepath.Glob(filepath.Join(home, filepath.FromSlash(pattern), "drive_c"))
+		for _, m := range matches {
+			if !slices.Contains(drives, m) {
+				drives = append(drives, m)
+			}
+		}
+	}
+	return drives
+}
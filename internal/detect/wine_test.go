@@ -0,0 +1,32 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWineDrives(t *testing.T) {
+	home := t.TempDir()
+	for _, dir := range []string{
+		"Games/battlenet/drive_c",
+		".local/share/bottles/bottles/WoW/drive_c",
+		".steam/steam/steamapps/compatdata/4711/pfx/drive_c",
+		"Games/no-prefix",
+	} {
+		_ = os.MkdirAll(filepath.Join(home, filepath.FromSlash(dir)), 0o755)
+	}
+	prefix := filepath.Join(t.TempDir(), "wow")
+
+	got := wineDrives(home, prefix)
+	want := []string{
+		filepath.Join(prefix, "drive_c"),
+		filepath.Join(home, "Games", "battlenet", "drive_c"),
+		filepath.Join(home, ".local", "share", "bottles", "bottles", "WoW", "drive_c"),
+		filepath.Join(home, ".steam", "steam", "steamapps", "compatdata", "4711", "pfx", "drive_c"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("wineDrives() = %v, want %v", got, want)
+	}
+}
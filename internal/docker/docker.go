@@ -0,0 +1,147 @@
+// Package docker mirrors synced addon folders into a Docker container, for
+// testing against containerized private servers.
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Scheme prefixes a wowPath naming a folder in a container.
+const Scheme = "docker://"
+
+// IsURL reports whether s names a container path, e.g.
+// "docker://wow-server:/srv/wow/Interface/AddOns".
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, Scheme)
+}
+
+// Target is an AddOns folder inside a container.
+type Target struct {
+	Container string
+	Dir       string // absolute path in the container
+}
+
+// Parse reads a docker:// URL. The path is taken as the AddOns folder when it
+// ends in AddOns, and as the WoW folder holding Interface/AddOns otherwise.
+func Parse(s string) (Target, error) {
+	rest, ok := strings.CutPrefix(s, Scheme)
+	if !ok {
+		return Target{}, fmt.Errorf("%q is not a %s URL", s, Scheme)
+	}
+	container, dir, ok := strings.Cut(rest, ":")
+	if !ok || container == "" || !path.IsAbs(dir) {
+		return Target{}, fmt.Errorf("%q should look like %scontainer:/path/to/AddOns", s, Scheme)
+	}
+	dir = path.Clean(dir)
+	if !strings.EqualFold(path.Base(dir), "AddOns") {
+		dir = path.Join(dir, "Interface", "AddOns")
+	}
+	return Target{Container: container, Dir: dir}, nil
+}
+
+func (t Target) String() string {
+	return Scheme + t.Container + ":" + t.Dir
+}
+
+// Mirror copies files written below a local folder to the same place below
+// the target's folder in the container, using the docker CLI.
+type Mirror struct {
+	Target  Target
+	Local   string // local stand-in for the AddOns folder that blink syncs into
+	Command string // docker executable, defaults to "docker"
+
+	run func(ctx context.Context, args ...string) error // runs the docker CLI; swapped in tests
+}
+
+// NewMirror returns a mirror of local into t.
+func NewMirror(t Target, local string) *Mirror {
+	return &Mirror{Target: t, Local: local}
+}
+
+// Check makes sure the container is running.
+func (m *Mirror) Check(ctx context.Context) error {
+	if err := m.docker(ctx, "exec", m.Target.Container, "true"); err != nil {
+		return fmt.Errorf("container %s is not reachable: %w", m.Target.Container, err)
+	}
+	return nil
+}
+
+// Copy copies the local file or folder at p into the container, replacing
+// what is there.
+func (m *Mirror) Copy(p string) error {
+	ctx := context.Background()
+	remote, err := m.remote(p)
+	if err != nil {
+		return err
+	}
+	if err := m.docker(ctx, "exec", m.Target.Container, "mkdir", "-p", path.Dir(remote)); err != nil {
+		return err
+	}
+	if info, err := os.Stat(p); err == nil && info.IsDir() {
+		// docker cp copies a folder into an existing one, so start afresh.
+		if err := m.docker(ctx, "exec", m.Target.Container, "rm", "-rf", remote); err != nil {
+			return err
+		}
+	}
+	return m.docker(ctx, "cp", p, m.Target.Container+":"+remote)
+}
+
+// Remove removes the container's copy of the local file or folder at p.
+func (m *Mirror) Remove(p string) error {
+	remote, err := m.remote(p)
+	if err != nil {
+		return err
+	}
+	return m.docker(context.Background(), "exec", m.Target.Container, "rm", "-rf", remote)
+}
+
+// URL returns the docker:// URL of the container's copy of the local path p.
+func (m *Mirror) URL(p string) string {
+	remote, err := m.remote(p)
+	if err != nil {
+		return p
+	}
+	return Scheme + m.Target.Container + ":" + remote
+}
+
+// remote maps a path below Local to the container.
+func (m *Mirror) remote(p string) (string, error) {
+	rel, err := filepath.Rel(m.Local, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside %s", p, m.Local)
+	}
+	return path.Join(m.Target.Dir, filepath.ToSlash(rel)), nil
+}
+
+func (m *Mirror) docker(ctx context.Context, args ...string) error {
+	if m.run != nil {
+		return m.run(ctx, args...)
+	}
+	command := m.Command
+	if command == "" {
+		command = "docker"
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var execErr *exec.Error
+	if errors.As(err, &execErr) {
+		return fmt.Errorf("docker not found — install it to sync into a container: %w", err)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("docker %s: %s", args[0], msg)
+		}
+		return fmt.Errorf("docker %s: %w", args[0], err)
+	}
+	return nil
+}
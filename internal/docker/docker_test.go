@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Target
+	}{
+		{"docker://wow-server:/srv/wow/_retail_/Interface/AddOns", Target{"wow-server", "/srv/wow/_retail_/Interface/AddOns"}},
+		{"docker://wow-server:/srv/wow/_retail_/", Target{"wow-server", "/srv/wow/_retail_/Interface/AddOns"}},
+		{"docker://ac:/azerothcore/client/addons", Target{"ac", "/azerothcore/client/addons"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"/srv/wow", "docker://wow-server", "docker://:/srv", "docker://wow-server:srv/wow"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+}
+
+// fakeMirror returns a mirror of a temporary folder that records the docker
+// commands it would run.
+func fakeMirror(t *testing.T) (*Mirror, *[]string) {
+	t.Helper()
+	var calls []string
+	m := NewMirror(Target{"wow", "/wow/Interface/AddOns"}, t.TempDir())
+	m.run = func(_ context.Context, args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return nil
+	}
+	return m, &calls
+}
+
+func TestMirror_CopyFile(t *testing.T) {
+	m, calls := fakeMirror(t)
+	file := filepath.Join(m.Local, "MyAddon", "Core.lua")
+
+	if err := m.Copy(file); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"exec wow mkdir -p /wow/Interface/AddOns/MyAddon",
+		"cp " + file + " wow:/wow/Interface/AddOns/MyAddon/Core.lua",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Errorf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestMirror_CopyFolderReplaces(t *testing.T) {
+	m, calls := fakeMirror(t)
+	dir := filepath.Join(m.Local, "MyAddon")
+	_ = os.MkdirAll(dir, 0o755)
+
+	if err := m.Copy(dir); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"exec wow mkdir -p /wow/Interface/AddOns",
+		"exec wow rm -rf /wow/Interface/AddOns/MyAddon",
+		"cp " + dir + " wow:/wow/Interface/AddOns/MyAddon",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Errorf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestMirror_OutsideLocal(t *testing.T) {
+	m, calls := fakeMirror(t)
+	if err := m.Remove(filepath.Join(filepath.Dir(m.Local), "elsewhere")); err == nil {
+		t.Error("Remove outside the local folder succeeded")
+	}
+	if len(*calls) != 0 {
+		t.Errorf("calls = %q, want none", *calls)
+	}
+	if got := m.URL(filepath.Join(m.Local, "MyAddon")); got != "docker://wow:/wow/Interface/AddOns/MyAddon" {
+		t.Errorf("URL = %q", got)
+	}
+}
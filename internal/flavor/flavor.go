@@ -0,0 +1,109 @@
+// Package flavor describes the WoW client flavors blink knows how to target.
+package flavor
+
+import (
+	"sort"
+	"strings"
+)
+
+// Flavor is a WoW client flavor.
+type Flavor struct {
+	Name      string // name used in blink.toml, e.g. "retail"
+	Dir       string // client folder inside the WoW install, e.g. "_retail_"
+	TocSuffix string // preferred suffix for flavor-specific .toc files
+	Version   string // game version in packager directives, e.g. "classic" for @version-classic@
+	Product   string // Battle.net product code, e.g. "wow_classic_era"
+	Live      string // for test clients, the flavor they preview, e.g. "retail" for "ptr"
+	Custom    bool   // defined in blink.toml rather than built in, see SetCustom
+}
+
+var known = []Flavor{
+	{Name: "retail", Dir: "_retail_", TocSuffix: "Mainline", Version: "retail", Product: "wow"},
+	{Name: "classic", Dir: "_classic_", TocSuffix: "Mists", Version: "mists", Product: "wow_classic"},
+	{Name: "classic_era", Dir: "_classic_era_", TocSuffix: "Vanilla", Version: "classic", Product: "wow_classic_era"},
+
+	// Test clients load the .toc files and directives of the flavor they preview.
+	{Name: "ptr", Dir: "_ptr_", TocSuffix: "Mainline", Version: "retail", Product: "wowt", Live: "retail"},
+	{Name: "xptr", Dir: "_xptr_", TocSuffix: "Mainline", Version: "retail", Product: "wowxptr", Live: "retail"},
+	{Name: "beta", Dir: "_beta_", TocSuffix: "Mainline", Version: "retail", Product: "wow_beta", Live: "retail"},
+	{Name: "classic_ptr", Dir: "_classic_ptr_", TocSuffix: "Mists", Version: "mists", Product: "wow_classic_ptr", Live: "classic"},
+	{Name: "classic_beta", Dir: "_classic_beta_", TocSuffix: "Mists", Version: "mists", Product: "wow_classic_beta", Live: "classic"},
+}
+
+// tocSuffixes maps every .toc suffix the clients recognise to a flavor name.
+var tocSuffixes = map[string]string{
+	"mainline": "retail",
+	"vanilla":  "classic_era",
+	"classic":  "classic",
+	"tbc":      "classic",
+	"bcc":      "classic",
+	"wrath":    "classic",
+	"wotlkc":   "classic",
+	"cata":     "classic",
+	"mists":    "classic",
+}
+
+// Runs reports whether a client of flavor f runs addons made for the flavor
+// named name: its own, or for a test client, the one it previews.
+func (f Flavor) Runs(name string) bool {
+	return f.Name == name || (f.Live != "" && f.Live == name)
+}
+
+// custom are the flavors blink.toml defines, set with SetCustom.
+var custom []Flavor
+
+// SetCustom defines more flavors by name and client folder, e.g.
+// plunderstorm = "_plunderstorm_", replacing those defined before. Blink
+// doesn't know which .toc files or packager directives their clients take,
+// so they get neither; they sync their own flavorFiles like any flavor.
+func SetCustom(dirs map[string]string) {
+	custom = custom[:0]
+	for name, dir := range dirs {
+		custom = append(custom, Flavor{Name: name, Dir: dir, Custom: true})
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i].Name < custom[j].Name })
+}
+
+// All returns the known flavors, the built-in ones first.
+func All() []Flavor {
+	return append(append([]Flavor(nil), known...), custom...)
+}
+
+// Lookup returns the flavor with the given name.
+func Lookup(name string) (Flavor, bool) {
+	for _, f := range All() {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Flavor{}, false
+}
+
+// FromDir returns the flavor whose client folder is dir (e.g. "_retail_").
+func FromDir(dir string) (Flavor, bool) {
+	for _, f := range All() {
+		if strings.EqualFold(f.Dir, dir) {
+			return f, true
+		}
+	}
+	return Flavor{}, false
+}
+
+// FromProduct returns the flavor with the Battle.net product code code.
+func FromProduct(code string) (Flavor, bool) {
+	for _, f := range All() {
+		if f.Product != "" && strings.EqualFold(f.Product, code) {
+			return f, true
+		}
+	}
+	return Flavor{}, false
+}
+
+// FromTocSuffix returns the flavor a .toc suffix (e.g. "Vanilla") targets.
+func FromTocSuffix(suffix string) (Flavor, bool) {
+	name, ok := tocSuffixes[strings.ToLower(suffix)]
+	if !ok {
+		return Flavor{}, false
+	}
+	return Lookup(name)
+}
@@ -0,0 +1,91 @@
+package flavor
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	f, ok := Lookup("classic_era")
+	if !ok || f.Dir != "_classic_era_" {
+		t.Errorf("Lookup(classic_era) = %+v, %v", f, ok)
+	}
+	if _, ok := Lookup("plunderstorm"); ok {
+		t.Error("Lookup(plunderstorm) found an unknown flavor")
+	}
+}
+
+func TestRuns(t *testing.T) {
+	ptr, ok := FromDir("_ptr_")
+	if !ok {
+		t.Fatal("FromDir(_ptr_) found no flavor")
+	}
+	if !ptr.Runs("ptr") || !ptr.Runs("retail") || ptr.Runs("classic") {
+		t.Errorf("ptr runs ptr %v, retail %v, classic %v; want true, true, false", ptr.Runs("ptr"), ptr.Runs("retail"), ptr.Runs("classic"))
+	}
+	if retail, _ := Lookup("retail"); retail.Runs("ptr") {
+		t.Error("retail runs ptr addons")
+	}
+}
+
+func TestFromProduct(t *testing.T) {
+	f, ok := FromProduct("wow_classic_era")
+	if !ok || f.Name != "classic_era" {
+		t.Errorf("FromProduct(wow_classic_era) = %+v, %v", f, ok)
+	}
+	if _, ok := FromProduct("agent"); ok {
+		t.Error("FromProduct(agent) found a flavor")
+	}
+}
+
+func TestFromTocSuffix(t *testing.T) {
+	tests := []struct {
+		suffix string
+		want   string
+	}{
+		{"Mainline", "retail"},
+		{"Vanilla", "classic_era"},
+		{"Cata", "classic"},
+		{"mists", "classic"},
+	}
+	for _, tt := range tests {
+		f, ok := FromTocSuffix(tt.suffix)
+		if !ok || f.Name != tt.want {
+			t.Errorf("FromTocSuffix(%q) = %q, %v, want %q", tt.suffix, f.Name, ok, tt.want)
+		}
+	}
+	if _, ok := FromTocSuffix("Retail"); ok {
+		t.Error("FromTocSuffix(Retail) should not be a recognised suffix")
+	}
+}
+
+func TestFromDir(t *testing.T) {
+	f, ok := FromDir("_classic_")
+	if !ok || f.Name != "classic" {
+		t.Errorf("FromDir(_classic_) = %+v, %v", f, ok)
+	}
+	if _, ok := FromDir("World of Warcraft"); ok {
+		t.Error("FromDir(World of Warcraft) should not match a flavor")
+	}
+}
+
+func TestSetCustom(t *testing.T) {
+	SetCustom(map[string]string{"plunderstorm": "_plunderstorm_"})
+	defer SetCustom(nil)
+
+	f, ok := Lookup("plunderstorm")
+	if !ok || f.Dir != "_plunderstorm_" || !f.Custom {
+		t.Errorf("Lookup(plunderstorm) = %+v, %v", f, ok)
+	}
+	if f, ok := FromDir("_plunderstorm_"); !ok || f.Name != "plunderstorm" {
+		t.Errorf("FromDir(_plunderstorm_) = %+v, %v", f, ok)
+	}
+	if all := All(); all[len(all)-1].Name != "plunderstorm" {
+		t.Errorf("All() ends with %q, want the custom flavor last", all[len(all)-1].Name)
+	}
+	if _, ok := FromProduct(""); ok {
+		t.Error("FromProduct(\"\") found a custom flavor")
+	}
+
+	SetCustom(nil)
+	if _, ok := Lookup("plunderstorm"); ok {
+		t.Error("Lookup(plunderstorm) still finds the flavor after SetCustom(nil)")
+	}
+}
@@ -0,0 +1,58 @@
+// Package ghactions writes GitHub Actions workflow commands, which the runner
+// turns into annotations on the run and pull request, and into collapsible
+// groups of log lines.
+package ghactions
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Annotation is an error or warning of a workflow step, shown on the line of
+// File in the pull request's diff when one is given.
+type Annotation struct {
+	Level   string // "error", "warning" or "notice"
+	File    string // relative to the repository root, slash-separated
+	Line    int    // 1-based; 0 for none
+	Column  int
+	Message string
+}
+
+// String returns the annotation as a workflow command, e.g.
+// "::error file=Core.lua,line=3::unexpected symbol".
+func (a Annotation) String() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(a.File))
+	}
+	if a.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", a.Line))
+	}
+	if a.Column > 0 {
+		props = append(props, fmt.Sprintf("col=%d", a.Column))
+	}
+	cmd := "::" + a.Level
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	return cmd + "::" + escapeData(a.Message)
+}
+
+// Group starts a collapsible group of log lines titled title, and returns
+// the func that ends it.
+func Group(w io.Writer, title string) func() {
+	fmt.Fprintf(w, "::group::%s\n", escapeData(title))
+	return func() { fmt.Fprintln(w, "::endgroup::") }
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command, which also
+// can't hold the separators of the properties.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
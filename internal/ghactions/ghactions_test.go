@@ -0,0 +1,32 @@
+package ghactions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnnotation_String(t *testing.T) {
+	tests := []struct {
+		a    Annotation
+		want string
+	}{
+		{Annotation{Level: "error", Message: "disk full"}, "::error::disk full"},
+		{Annotation{Level: "error", File: "src/Core.lua", Line: 3, Column: 7, Message: "unexpected symbol"}, "::error file=src/Core.lua,line=3,col=7::unexpected symbol"},
+		{Annotation{Level: "warning", File: "a,b:c.lua", Message: "100% over\nbudget"}, "::warning file=a%2Cb%3Ac.lua::100%25 over%0Abudget"},
+	}
+	for _, tt := range tests {
+		if got := tt.a.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var b strings.Builder
+	end := Group(&b, "Lint")
+	b.WriteString("ok\n")
+	end()
+	if want := "::group::Lint\nok\n::endgroup::\n"; b.String() != want {
+		t.Errorf("Group wrote %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,75 @@
+// Package gitinfo reads the state of the git checkout an addon is synced from.
+package gitinfo
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Info describes a checkout. Fields are empty outside a git repository.
+type Info struct {
+	Commit string // abbreviated hash of HEAD
+	Branch string // "HEAD" when detached
+	Dirty  bool   // tracked files have uncommitted changes
+}
+
+// Read returns the state of the checkout dir is in.
+func Read(dir string) Info {
+	info := Info{Commit: git(dir, "rev-parse", "--short", "HEAD")}
+	if info.Commit == "" {
+		return Info{}
+	}
+	info.Branch = git(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	info.Dirty = git(dir, "status", "--porcelain", "--untracked-files=no") != ""
+	return info
+}
+
+// Tag returns the tag pointing at HEAD of the checkout dir is in, or "" when
+// HEAD isn't tagged.
+func Tag(dir string) string {
+	return git(dir, "describe", "--tags", "--exact-match", "HEAD")
+}
+
+// Remote returns the URL of the named remote, or "" when there is none.
+func Remote(dir, name string) string {
+	return git(dir, "remote", "get-url", name)
+}
+
+// git runs a git command in dir and returns its trimmed output, or "" when it
+// fails.
+func git(dir string, args ...string) string {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Cache reads a checkout at most once per ttl, for callers that want its
+// state for every synced file. It is safe for concurrent use.
+type Cache struct {
+	dir string
+	ttl time.Duration
+
+	mu   sync.Mutex
+	at   time.Time
+	info Info
+}
+
+// NewCache returns a Cache of the checkout dir is in.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// Get returns the state of the checkout, reading it again once the last read
+// is older than the cache's ttl.
+func (c *Cache) Get() Info {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.at.IsZero() || time.Since(c.at) > c.ttl {
+		c.info, c.at = Read(c.dir), time.Now()
+	}
+	return c.info
+}
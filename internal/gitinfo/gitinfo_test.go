@@ -0,0 +1,72 @@
+package gitinfo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// initRepo returns a git repository with one commit, skipping the test when
+// git isn't installed.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "Core.lua"), []byte("print(1)\n"), 0o644)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestRead(t *testing.T) {
+	dir := initRepo(t)
+	if info := Read(dir); len(info.Commit) < 7 || info.Branch != "main" || info.Dirty {
+		t.Errorf("Read() = %+v, want a clean checkout of main", info)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "Core.lua"), []byte("print(2)\n"), 0o644)
+	if info := Read(dir); !info.Dirty {
+		t.Errorf("Read() = %+v after an edit, want dirty", info)
+	}
+	if info := Read(t.TempDir()); info != (Info{}) {
+		t.Errorf("Read() outside a repository = %+v, want empty", info)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := initRepo(t)
+	c := NewCache(dir, time.Hour)
+	first := c.Get()
+	_ = os.RemoveAll(filepath.Join(dir, ".git"))
+	if got := c.Get(); got != first {
+		t.Errorf("Get() = %+v after the checkout went away, want the cached %+v", got, first)
+	}
+}
+
+func TestTagAndRemote(t *testing.T) {
+	dir := initRepo(t)
+	if tag := Tag(dir); tag != "" {
+		t.Errorf("Tag() = %q before tagging", tag)
+	}
+	for _, args := range [][]string{{"tag", "v1.0"}, {"remote", "add", "origin", "git@github.com:me/MyAddon.git"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if tag := Tag(dir); tag != "v1.0" {
+		t.Errorf("Tag() = %q, want v1.0", tag)
+	}
+	if url := Remote(dir, "origin"); url != "git@github.com:me/MyAddon.git" {
+		t.Errorf("Remote() = %q", url)
+	}
+}
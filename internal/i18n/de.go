@@ -0,0 +1,110 @@
+package i18n
+
+var deDE = map[string]string{
+	// TUI
+	"Watching":                "Überwacht",
+	"Target":                  "Ziel",
+	"Files":                   "Dateien",
+	"Tests":                   "Tests",
+	"%d synced":               "%d synchronisiert",
+	"(off)":                   "(aus)",
+	"Watching for changes...": "Warte auf Änderungen...",
+	"(×%d since %s)":          "(×%d seit %s)",
+	"%d file(s) edited in AddOns: p to pull back into source, o to overwrite": "%d Datei(en) in AddOns bearbeitet: p übernimmt sie in die Quelle, o überschreibt sie",
+	"%d change(s) failed to sync: t to retry":                                 "%d Änderung(en) nicht synchronisiert: t für einen neuen Versuch",
+	"1-9 to toggle an addon":                                                  "1-9 schaltet ein Addon um",
+	"l to show the log (%d)":                                                  "l zeigt das Log (%d)",
+	"l to hide the log (%d)":                                                  "l blendet das Log aus (%d)",
+	"r to re-sync":                                                            "r synchronisiert neu",
+	"q to quit":                                                               "q beendet",
+	"Press %s":                                                                "Tasten: %s",
+	"Log":                                                                     "Log",
+	"(empty)":                                                                 "(leer)",
+	"… %d more":                                                               "… %d weitere",
+	"running…":                                                                "läuft…",
+	"waiting for changes":                                                     "wartet auf Änderungen",
+	"re-sync":                                                                 "Neusynchronisierung",
+	"synced %d files":                                                         "%d Dateien synchronisiert",
+	"Syncing files... %d/%d":                                                  "Synchronisiere Dateien... %d/%d",
+	"%d addons":                                                               "%d Addons",
+
+	// Changes
+	"copied":                  "kopiert",
+	"copied from %s":          "aus %s kopiert",
+	"removed":                 "entfernt",
+	"pulled back into source": "in die Quelle übernommen",
+	"not pulled back: %v":     "nicht übernommen: %v",
+	"edited in AddOns since the last sync, not overwritten": "seit der letzten Synchronisierung in AddOns bearbeitet, nicht überschrieben",
+	"changed in both the source and AddOns, not synced":     "in Quelle und AddOns geändert, nicht synchronisiert",
+	"skipped, broken symlink":                               "übersprungen, defekter Symlink",
+	"skipped, %v":                                           "übersprungen, %v",
+	"copied, %v":                                            "kopiert, %v",
+	"conflict, also provided by %s":                         "Konflikt, kommt auch aus %s",
+	"checking budget failed: %v":                            "Budgetprüfung fehlgeschlagen: %v",
+	"generated %d .toc file(s)":                             "%d .toc-Datei(en) erzeugt",
+	"renamed to %s":                                         "umbenannt in %s",
+	"renamed to %s, now syncing to %s":                      "umbenannt in %s, synchronisiere jetzt nach %s",
+	"error: %v":                                             "Fehler: %v",
+	"watcher error: %v":                                     "Fehler der Dateiüberwachung: %v",
+	"restarted, re-syncing":                                 "neu gestartet, synchronisiere erneut",
+	"access denied: run blink as administrator or make %s writable": "Zugriff verweigert: blink als Administrator starten oder %s beschreibbar machen",
+
+	// CLI
+	"note: syncing to %s; the addon also supports %s (pick with --flavor)": "Hinweis: synchronisiere nach %s; das Addon unterstützt auch %s (Auswahl mit --flavor)",
+	"conflict: %s is provided by %s":                                       "Konflikt: %s kommt aus %s",
+	" and ":                                                                " und ",
+	"Removed %d stale file(s) from %s":                                     "%d veraltete Datei(en) aus %s entfernt",
+	"Generated %d .toc file(s) from %s":                                    "%d .toc-Datei(en) aus %s erzeugt",
+	"Synced %d files to %s":                                                "%d Dateien nach %s synchronisiert",
+	"blink %s — watching %s":                                               "blink %s — überwacht %s",
+	"target: %s":                                                           "Ziel: %s",
+	"blink %s is available (you have %s): %s":                              "blink %s ist verfügbar (installiert: %s): %s",
+	"Moved %s to %s":                                                       "%s nach %s verschoben",
+	"stopped after %s (--until)":                                           "gestoppt nach %s (--until)",
+	"stopped, WoW exited (--until-wow-exits)":                              "gestoppt, WoW wurde beendet (--until-wow-exits)",
+
+	// runtime stats (--pprof)
+	"Runtime":                                "Laufzeit",
+	"%s heap, %s from the OS, %d goroutines": "%s Heap, %s vom System, %d Goroutinen",
+
+	// missing AddOns folder
+	"%s doesn't exist yet. Create it?": "%s existiert noch nicht. Anlegen?",
+	"Created %s":                       "%s angelegt",
+
+	// file churn panel
+	"Most synced files":               "Am häufigsten synchronisierte Dateien",
+	"c to hide the most synced files": "c blendet die häufigsten Dateien aus",
+	"c to show the most synced files": "c zeigt die häufigsten Dateien",
+
+	// command palette
+	": for commands":                           ": für Befehle",
+	"enter to run, esc to cancel":              "Enter führt aus, Esc bricht ab",
+	"unknown command — type help for the list": "unbekannter Befehl — help zeigt die Liste",
+	"usage: %s %s":                             "Aufruf: %s %s",
+	"no addon named %s":                        "kein Addon namens %s",
+	"not available in this session":            "in dieser Sitzung nicht verfügbar",
+	"paused %s":                                "%s pausiert",
+	"resumed %s":                               "%s fortgesetzt",
+	"switched to %s":                           "zu %s gewechselt",
+	"opened %s":                                "%s geöffnet",
+	"changes now wait %d ms before syncing":    "Änderungen warten jetzt %d ms vor dem Synchronisieren",
+	"debug logging on":                         "Debug-Logging an",
+	"debug logging off":                        "Debug-Logging aus",
+
+	// ignore pattern panel
+	"Ignore patterns":               "Ignore-Muster",
+	"matches nothing":               "trifft nichts",
+	"i to show the ignore patterns": "i zeigt die Ignore-Muster",
+	"i to hide the ignore patterns": "i blendet die Ignore-Muster aus",
+
+	// UI packs
+	"restored the original": "Original wiederhergestellt",
+
+	// addon picker
+	"Several addons were found. Which should be synced?": "Mehrere Addons gefunden. Welche sollen synchronisiert werden?",
+	"All of them":                               "Alle",
+	"s to remember the choice in blink.toml":    "s merkt sich die Wahl in blink.toml",
+	"↑/↓ to choose, enter to sync, esc to quit": "↑/↓ wählt, Enter synchronisiert, Esc beendet",
+	"Saved to %s: sync every addon":             "Gespeichert in %s: alle Addons synchronisieren",
+	"Saved to %s: sync %s":                      "Gespeichert in %s: %s synchronisieren",
+}
@@ -0,0 +1,110 @@
+package i18n
+
+var frFR = map[string]string{
+	// TUI
+	"Watching":                "Surveillé",
+	"Target":                  "Cible",
+	"Files":                   "Fichiers",
+	"Tests":                   "Tests",
+	"%d synced":               "%d synchronisés",
+	"(off)":                   "(désactivé)",
+	"Watching for changes...": "En attente de modifications...",
+	"(×%d since %s)":          "(×%d depuis %s)",
+	"%d file(s) edited in AddOns: p to pull back into source, o to overwrite": "%d fichier(s) modifié(s) dans AddOns : p pour les reprendre dans la source, o pour les écraser",
+	"%d change(s) failed to sync: t to retry":                                 "%d modification(s) non synchronisée(s) : t pour réessayer",
+	"1-9 to toggle an addon":                                                  "1-9 pour activer ou désactiver un addon",
+	"l to show the log (%d)":                                                  "l pour afficher le journal (%d)",
+	"l to hide the log (%d)":                                                  "l pour masquer le journal (%d)",
+	"r to re-sync":                                                            "r pour resynchroniser",
+	"q to quit":                                                               "q pour quitter",
+	"Press %s":                                                                "Touches : %s",
+	"Log":                                                                     "Journal",
+	"(empty)":                                                                 "(vide)",
+	"… %d more":                                                               "… %d de plus",
+	"running…":                                                                "en cours…",
+	"waiting for changes":                                                     "en attente de modifications",
+	"re-sync":                                                                 "resynchronisation",
+	"synced %d files":                                                         "%d fichiers synchronisés",
+	"Syncing files... %d/%d":                                                  "Synchronisation des fichiers... %d/%d",
+	"%d addons":                                                               "%d addons",
+
+	// Changes
+	"copied":                  "copié",
+	"copied from %s":          "copié depuis %s",
+	"removed":                 "supprimé",
+	"pulled back into source": "repris dans la source",
+	"not pulled back: %v":     "non repris : %v",
+	"edited in AddOns since the last sync, not overwritten": "modifié dans AddOns depuis la dernière synchronisation, non écrasé",
+	"changed in both the source and AddOns, not synced":     "modifié dans la source et dans AddOns, non synchronisé",
+	"skipped, broken symlink":                               "ignoré, lien symbolique cassé",
+	"skipped, %v":                                           "ignoré, %v",
+	"copied, %v":                                            "copié, %v",
+	"conflict, also provided by %s":                         "conflit, également fourni par %s",
+	"checking budget failed: %v":                            "échec de la vérification du budget : %v",
+	"generated %d .toc file(s)":                             "%d fichier(s) .toc généré(s)",
+	"renamed to %s":                                         "renommé en %s",
+	"renamed to %s, now syncing to %s":                      "renommé en %s, synchronisé désormais vers %s",
+	"error: %v":                                             "erreur : %v",
+	"watcher error: %v":                                     "erreur de surveillance : %v",
+	"restarted, re-syncing":                                 "redémarrée, nouvelle synchronisation",
+	"access denied: run blink as administrator or make %s writable": "accès refusé : lancez blink en administrateur ou rendez %s accessible en écriture",
+
+	// CLI
+	"note: syncing to %s; the addon also supports %s (pick with --flavor)": "remarque : synchronisation vers %s ; l'addon prend aussi en charge %s (choisir avec --flavor)",
+	"conflict: %s is provided by %s":                                       "conflit : %s est fourni par %s",
+	" and ":                                                                " et ",
+	"Removed %d stale file(s) from %s":                                     "%d fichier(s) obsolète(s) supprimé(s) de %s",
+	"Generated %d .toc file(s) from %s":                                    "%d fichier(s) .toc généré(s) à partir de %s",
+	"Synced %d files to %s":                                                "%d fichiers synchronisés vers %s",
+	"blink %s — watching %s":                                               "blink %s — surveille %s",
+	"target: %s":                                                           "cible : %s",
+	"blink %s is available (you have %s): %s":                              "blink %s est disponible (version installée : %s) : %s",
+	"Moved %s to %s":                                                       "%s déplacé vers %s",
+	"stopped after %s (--until)":                                           "arrêté après %s (--until)",
+	"stopped, WoW exited (--until-wow-exits)":                              "arrêté, WoW a été fermé (--until-wow-exits)",
+
+	// runtime stats (--pprof)
+	"Runtime":                                "Exécution",
+	"%s heap, %s from the OS, %d goroutines": "%s de tas, %s du système, %d goroutines",
+
+	// missing AddOns folder
+	"%s doesn't exist yet. Create it?": "%s n'existe pas encore. Le créer ?",
+	"Created %s":                       "%s créé",
+
+	// file churn panel
+	"Most synced files":               "Fichiers les plus synchronisés",
+	"c to hide the most synced files": "c pour masquer les fichiers les plus synchronisés",
+	"c to show the most synced files": "c pour afficher les fichiers les plus synchronisés",
+
+	// command palette
+	": for commands":                           ": pour les commandes",
+	"enter to run, esc to cancel":              "Entrée pour exécuter, Échap pour annuler",
+	"unknown command — type help for the list": "commande inconnue — tapez help pour la liste",
+	"usage: %s %s":                             "utilisation : %s %s",
+	"no addon named %s":                        "aucun addon nommé %s",
+	"not available in this session":            "indisponible dans cette session",
+	"paused %s":                                "%s en pause",
+	"resumed %s":                               "%s repris",
+	"switched to %s":                           "passé à %s",
+	"opened %s":                                "%s ouvert",
+	"changes now wait %d ms before syncing":    "les modifications attendent désormais %d ms avant la synchronisation",
+	"debug logging on":                         "journalisation de débogage activée",
+	"debug logging off":                        "journalisation de débogage désactivée",
+
+	// ignore pattern panel
+	"Ignore patterns":               "Motifs ignorés",
+	"matches nothing":               "ne correspond à rien",
+	"i to show the ignore patterns": "i pour afficher les motifs ignorés",
+	"i to hide the ignore patterns": "i pour masquer les motifs ignorés",
+
+	// UI packs
+	"restored the original": "original restauré",
+
+	// addon picker
+	"Several addons were found. Which should be synced?": "Plusieurs addons trouvés. Lesquels synchroniser ?",
+	"All of them":                               "Tous",
+	"s to remember the choice in blink.toml":    "s retient le choix dans blink.toml",
+	"↑/↓ to choose, enter to sync, esc to quit": "↑/↓ pour choisir, Entrée pour synchroniser, Échap pour quitter",
+	"Saved to %s: sync every addon":             "Enregistré dans %s : synchroniser tous les addons",
+	"Saved to %s: sync %s":                      "Enregistré dans %s : synchroniser %s",
+}
@@ -0,0 +1,95 @@
+// Package i18n translates blink's user-facing messages. Messages are looked
+// up by their English text, which is also what is shown when the current
+// locale has no translation for one.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Default is the locale of the messages in the source.
+const Default = "enUS"
+
+// catalogs holds the translations of each locale, keyed by the English
+// message. Locales are named like the WoW client's (deDE, frFR, ...).
+var catalogs = map[string]map[string]string{
+	"deDE": deDE,
+	"frFR": frFR,
+	"zhCN": zhCN,
+}
+
+var current map[string]string
+
+// Locales returns the supported locales, Default first.
+func Locales() []string {
+	names := []string{Default}
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// Set switches messages to locale, which is normalized first (see Normalize).
+// An empty locale means Default.
+func Set(locale string) error {
+	if locale == "" {
+		current = nil
+		return nil
+	}
+	name, ok := Normalize(locale)
+	if !ok {
+		return fmt.Errorf("unsupported locale %q (supported: %s)", locale, strings.Join(Locales(), ", "))
+	}
+	current = catalogs[name]
+	return nil
+}
+
+// Normalize maps a WoW-style locale ("deDE"), a POSIX one ("de_DE.UTF-8") or
+// a bare language ("de") to a supported locale name.
+func Normalize(locale string) (string, bool) {
+	s := locale
+	if i := strings.IndexAny(s, ".@"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.NewReplacer("_", "", "-", "").Replace(s)
+	if s == "C" || s == "POSIX" {
+		return Default, true
+	}
+	for _, name := range Locales() {
+		if strings.EqualFold(s, name) || strings.EqualFold(s, name[:2]) && len(s) == 2 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Detect returns the locale asked for by the environment: BLINK_LOCALE, else
+// the usual LC_ALL, LC_MESSAGES and LANG. An unsupported or unset locale
+// gives Default.
+func Detect(getenv func(string) string) string {
+	for _, key := range []string{"BLINK_LOCALE", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := getenv(key); v != "" {
+			if name, ok := Normalize(v); ok {
+				return name
+			}
+			return Default
+		}
+	}
+	return Default
+}
+
+// T returns the translation of msg in the current locale.
+func T(msg string) string {
+	if t, ok := current[msg]; ok {
+		return t
+	}
+	return msg
+}
+
+// Tf formats args with the translation of format.
+func Tf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
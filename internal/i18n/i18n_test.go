@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"deDE", "deDE", true},
+		{"de_DE.UTF-8", "deDE", true},
+		{"fr-FR", "frFR", true},
+		{"zh_CN.utf8", "zhCN", true},
+		{"de", "deDE", true},
+		{"en_US.UTF-8", "enUS", true},
+		{"C", "enUS", true},
+		{"koKR", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Normalize(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Normalize(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	env := map[string]string{"LANG": "fr_FR.UTF-8"}
+	if got := Detect(func(k string) string { return env[k] }); got != "frFR" {
+		t.Errorf("Detect(LANG) = %q, want frFR", got)
+	}
+	env["BLINK_LOCALE"] = "zhCN"
+	if got := Detect(func(k string) string { return env[k] }); got != "zhCN" {
+		t.Errorf("Detect(BLINK_LOCALE) = %q, want zhCN", got)
+	}
+	env = map[string]string{"LANG": "ko_KR.UTF-8"}
+	if got := Detect(func(k string) string { return env[k] }); got != Default {
+		t.Errorf("Detect(unsupported) = %q, want %q", got, Default)
+	}
+}
+
+func TestT(t *testing.T) {
+	defer func() { _ = Set("") }()
+
+	if err := Set("de_DE.UTF-8"); err != nil {
+		t.Fatal(err)
+	}
+	if got := T("copied"); got != "kopiert" {
+		t.Errorf("T(copied) = %q", got)
+	}
+	if got := T("not in the catalog"); got != "not in the catalog" {
+		t.Errorf("untranslated message = %q", got)
+	}
+	if got := Tf("Synced %d files to %s", 3, "AddOns"); got != "3 Dateien nach AddOns synchronisiert" {
+		t.Errorf("Tf = %q", got)
+	}
+	if err := Set("xxXX"); err == nil {
+		t.Error("Set(xxXX) succeeded, want an error")
+	}
+}
+
+var verbRe = regexp.MustCompile(`%[a-z]`)
+
+// A translation must take the same arguments as its message.
+func TestCatalogVerbs(t *testing.T) {
+	for name, cat := range catalogs {
+		for msg, tr := range cat {
+			if want, got := verbRe.FindAllString(msg, -1), verbRe.FindAllString(tr, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %q has verbs %v, want %v", name, tr, got, want)
+			}
+		}
+	}
+}
+
+// Every locale translates the same messages.
+func TestCatalogsComplete(t *testing.T) {
+	for name, cat := range catalogs {
+		for msg := range deDE {
+			if _, ok := cat[msg]; !ok {
+				t.Errorf("%s: no translation for %q", name, msg)
+			}
+		}
+		if len(cat) != len(deDE) {
+			t.Errorf("%s has %d messages, deDE has %d", name, len(cat), len(deDE))
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package i18n
+
+var zhCN = map[string]string{
+	// TUI
+	"Watching":                "监视",
+	"Target":                  "目标",
+	"Files":                   "文件",
+	"Tests":                   "测试",
+	"%d synced":               "已同步 %d 个",
+	"(off)":                   "(已关闭)",
+	"Watching for changes...": "正在监视更改...",
+	"(×%d since %s)":          "(×%d，自 %s 起)",
+	"%d file(s) edited in AddOns: p to pull back into source, o to overwrite": "%d 个文件在 AddOns 中被修改：按 p 取回到源码，按 o 覆盖",
+	"%d change(s) failed to sync: t to retry":                                 "%d 个更改同步失败：按 t 重试",
+	"1-9 to toggle an addon":                                                  "1-9 开关插件",
+	"l to show the log (%d)":                                                  "l 显示日志 (%d)",
+	"l to hide the log (%d)":                                                  "l 隐藏日志 (%d)",
+	"r to re-sync":                                                            "r 重新同步",
+	"q to quit":                                                               "q 退出",
+	"Press %s":                                                                "按键：%s",
+	"Log":                                                                     "日志",
+	"(empty)":                                                                 "(空)",
+	"… %d more":                                                               "… 还有 %d 条",
+	"running…":                                                                "运行中…",
+	"waiting for changes":                                                     "等待更改",
+	"re-sync":                                                                 "重新同步",
+	"synced %d files":                                                         "已同步 %d 个文件",
+	"Syncing files... %d/%d":                                                  "正在同步文件... %d/%d",
+	"%d addons":                                                               "%d 个插件",
+
+	// Changes
+	"copied":                  "已复制",
+	"copied from %s":          "已从 %s 复制",
+	"removed":                 "已删除",
+	"pulled back into source": "已取回到源码",
+	"not pulled back: %v":     "未取回：%v",
+	"edited in AddOns since the last sync, not overwritten": "上次同步后在 AddOns 中被修改，未覆盖",
+	"changed in both the source and AddOns, not synced":     "源码和 AddOns 中都被修改，未同步",
+	"skipped, broken symlink":                               "已跳过，符号链接已失效",
+	"skipped, %v":                                           "已跳过，%v",
+	"copied, %v":                                            "已复制，%v",
+	"conflict, also provided by %s":                         "冲突，%s 也提供此文件",
+	"checking budget failed: %v":                            "检查预算失败：%v",
+	"generated %d .toc file(s)":                             "已生成 %d 个 .toc 文件",
+	"renamed to %s":                                         "已重命名为 %s",
+	"renamed to %s, now syncing to %s":                      "已重命名为 %s，现在同步到 %s",
+	"error: %v":                                             "错误：%v",
+	"watcher error: %v":                                     "文件监视错误：%v",
+	"restarted, re-syncing":                                 "已重启，正在重新同步",
+	"access denied: run blink as administrator or make %s writable": "拒绝访问：请以管理员身份运行 blink，或让 %s 可写",
+
+	// CLI
+	"note: syncing to %s; the addon also supports %s (pick with --flavor)": "提示：同步到 %s；该插件还支持 %s（用 --flavor 选择）",
+	"conflict: %s is provided by %s":                                       "冲突：%s 由 %s 提供",
+	" and ":                                                                " 和 ",
+	"Removed %d stale file(s) from %s":                                     "已删除 %d 个过期文件（%s）",
+	"Generated %d .toc file(s) from %s":                                    "已生成 %d 个 .toc 文件（来自 %s）",
+	"Synced %d files to %s":                                                "已同步 %d 个文件到 %s",
+	"blink %s — watching %s":                                               "blink %s — 正在监视 %s",
+	"target: %s":                                                           "目标：%s",
+	"blink %s is available (you have %s): %s":                              "blink %s 已发布（当前版本 %s）：%s",
+	"Moved %s to %s":                                                       "已将 %s 移动到 %s",
+	"stopped after %s (--until)":                                           "已在 %s 后停止（--until）",
+	"stopped, WoW exited (--until-wow-exits)":                              "WoW 已退出，已停止（--until-wow-exits）",
+
+	// runtime stats (--pprof)
+	"Runtime":                                "运行时",
+	"%s heap, %s from the OS, %d goroutines": "堆 %s，系统占用 %s，%d 个 goroutine",
+
+	// missing AddOns folder
+	"%s doesn't exist yet. Create it?": "%s 尚不存在。要创建吗？",
+	"Created %s":                       "已创建 %s",
+
+	// file churn panel
+	"Most synced files":               "同步最频繁的文件",
+	"c to hide the most synced files": "c 隐藏最常同步的文件",
+	"c to show the most synced files": "c 显示最常同步的文件",
+
+	// command palette
+	": for commands":                           ": 输入命令",
+	"enter to run, esc to cancel":              "回车执行，Esc 取消",
+	"unknown command — type help for the list": "未知命令 — 输入 help 查看列表",
+	"usage: %s %s":                             "用法：%s %s",
+	"no addon named %s":                        "没有名为 %s 的插件",
+	"not available in this session":            "本次会话不可用",
+	"paused %s":                                "已暂停 %s",
+	"resumed %s":                               "已恢复 %s",
+	"switched to %s":                           "已切换到 %s",
+	"opened %s":                                "已打开 %s",
+	"changes now wait %d ms before syncing":    "更改现在等待 %d 毫秒后同步",
+	"debug logging on":                         "调试日志已开启",
+	"debug logging off":                        "调试日志已关闭",
+
+	// ignore pattern panel
+	"Ignore patterns":               "忽略规则",
+	"matches nothing":               "没有匹配",
+	"i to show the ignore patterns": "i 显示忽略规则",
+	"i to hide the ignore patterns": "i 隐藏忽略规则",
+
+	// UI packs
+	"restored the original": "已恢复原文件",
+
+	// addon picker
+	"Several addons were found. Which should be synced?": "找到多个插件。要同步哪个？",
+	"All of them":                               "全部",
+	"s to remember the choice in blink.toml":    "s 将选择保存到 blink.toml",
+	"↑/↓ to choose, enter to sync, esc to quit": "↑/↓ 选择，Enter 同步，Esc 退出",
+	"Saved to %s: sync every addon":             "已保存到 %s：同步所有插件",
+	"Saved to %s: sync %s":                      "已保存到 %s：同步 %s",
+}
@@ -0,0 +1,165 @@
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Severity ranks a linter diagnostic.
+type Severity int
+
+// Diagnostic severities.
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic is a single linter finding.
+type Diagnostic struct {
+	File     string // as reported by the linter, usually relative to the addon root
+	Line     int    // 1-based
+	Column   int    // 1-based
+	Severity Severity
+	Code     string
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s[%s] %s", d.File, d.Line, d.Column, d.Severity, d.Code, d.Message)
+}
+
+// Selene runs the selene linter (https://github.com/Kampfkarren/selene).
+type Selene struct {
+	Command string // executable, defaults to "selene"
+	Std     string // standard library used when the addon has no selene.toml
+	Dir     string // addon source directory selene runs in
+}
+
+// seleneJSON is one line of selene's json2 display style.
+type seleneJSON struct {
+	Type         string `json:"type"`
+	Severity     string `json:"severity"`
+	Code         string `json:"code"`
+	Message      string `json:"message"`
+	PrimaryLabel struct {
+		Filename string `json:"filename"`
+		Span     struct {
+			StartLine   int `json:"start_line"`
+			StartColumn int `json:"start_column"`
+		} `json:"span"`
+	} `json:"primary_label"`
+}
+
+// Run lints the given files (relative to s.Dir) and returns selene's diagnostics.
+func (s *Selene) Run(ctx context.Context, files ...string) ([]Diagnostic, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	command := s.Command
+	if command == "" {
+		command = "selene"
+	}
+
+	args := []string{"--display-style", "json2"}
+	if s.Std != "" {
+		if _, err := os.Stat(filepath.Join(s.Dir, "selene.toml")); os.IsNotExist(err) {
+			cfgPath, err := writeSeleneConfig(s.Std)
+			if err != nil {
+				return nil, err
+			}
+			defer func() { _ = os.Remove(cfgPath) }()
+			args = append(args, "--config", cfgPath)
+		}
+	}
+	args = append(args, files...)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = s.Dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var execErr *exec.Error
+	if errors.As(runErr, &execErr) {
+		return nil, fmt.Errorf("selene not found — install it or set selene.command in blink.toml: %w", runErr)
+	}
+
+	diags, err := parseSeleneOutput(stdout.Bytes(), files)
+	if err != nil {
+		return nil, err
+	}
+
+	// selene exits non-zero whenever it reports errors; only fail when it
+	// produced no diagnostics to explain why.
+	if runErr != nil && len(diags) == 0 {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = runErr.Error()
+		}
+		return nil, fmt.Errorf("selene failed: %s", msg)
+	}
+	return diags, nil
+}
+
+func writeSeleneConfig(std string) (string, error) {
+	f, err := os.CreateTemp("", "blink-selene-*.toml")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := fmt.Fprintf(f, "std = %q\n", std); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func parseSeleneOutput(out []byte, files []string) ([]Diagnostic, error) {
+	var diags []Diagnostic
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var sj seleneJSON
+		if err := json.Unmarshal(line, &sj); err != nil {
+			return nil, fmt.Errorf("failed to parse selene output: %w", err)
+		}
+		if sj.Type != "Diagnostic" {
+			continue
+		}
+		d := Diagnostic{
+			File:    sj.PrimaryLabel.Filename,
+			Line:    sj.PrimaryLabel.Span.StartLine + 1,
+			Column:  sj.PrimaryLabel.Span.StartColumn + 1,
+			Code:    sj.Code,
+			Message: sj.Message,
+		}
+		if d.File == "" && len(files) == 1 {
+			d.File = files[0]
+		}
+		if strings.EqualFold(sj.Severity, "error") {
+			d.Severity = SeverityError
+		}
+		diags = append(diags, d)
+	}
+	return diags, scanner.Err()
+}
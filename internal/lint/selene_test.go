@@ -0,0 +1,49 @@
+package lint
+
+import "testing"
+
+func TestParseSeleneOutput(t *testing.T) {
+	out := []byte(`{"type":"Diagnostic","severity":"Warning","code":"unused_variable","message":"x is defined, but never used","primary_label":{"filename":"Core.lua","span":{"start":6,"start_line":0,"start_column":6,"end":7,"end_line":0,"end_column":7},"message":""},"notes":[],"secondary_labels":[]}
+{"type":"Diagnostic","severity":"Error","code":"undefined_variable","message":"UnitNam is not defined","primary_label":{"filename":"Core.lua","span":{"start":20,"start_line":2,"start_column":4,"end":27,"end_line":2,"end_column":11},"message":""},"notes":[],"secondary_labels":[]}
+{"type":"Summary","errors":1,"warnings":1,"parse_errors":0}
+`)
+
+	diags, err := parseSeleneOutput(out, []string{"Core.lua"})
+	if err != nil {
+		t.Fatalf("parseSeleneOutput() error = %v", err)
+	}
+	if len(diags) != 2 {
+		t.Fatalf("len(diags) = %d, want 2", len(diags))
+	}
+
+	if diags[0].Severity != SeverityWarning || diags[0].Code != "unused_variable" {
+		t.Errorf("diags[0] = %+v, want unused_variable warning", diags[0])
+	}
+	if diags[0].Line != 1 || diags[0].Column != 7 {
+		t.Errorf("diags[0] position = %d:%d, want 1:7", diags[0].Line, diags[0].Column)
+	}
+	if diags[1].Severity != SeverityError || diags[1].Line != 3 {
+		t.Errorf("diags[1] = %+v, want error at line 3", diags[1])
+	}
+	if diags[1].File != "Core.lua" {
+		t.Errorf("diags[1].File = %q, want %q", diags[1].File, "Core.lua")
+	}
+}
+
+func TestParseSeleneOutput_MissingFilename(t *testing.T) {
+	out := []byte(`{"type":"Diagnostic","severity":"Warning","code":"shadowing","message":"shadowing variable","primary_label":{"span":{"start_line":4,"start_column":0}}}`)
+
+	diags, err := parseSeleneOutput(out, []string{"Options.lua"})
+	if err != nil {
+		t.Fatalf("parseSeleneOutput() error = %v", err)
+	}
+	if len(diags) != 1 || diags[0].File != "Options.lua" {
+		t.Errorf("diags = %+v, want one diagnostic for Options.lua", diags)
+	}
+}
+
+func TestParseSeleneOutput_Invalid(t *testing.T) {
+	if _, err := parseSeleneOutput([]byte("{not json"), nil); err == nil {
+		t.Fatal("parseSeleneOutput() expected error for malformed JSON")
+	}
+}
@@ -0,0 +1,61 @@
+// Package lint checks addon Lua sources for problems before they are synced.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// SyntaxError describes a Lua file that failed to parse.
+type SyntaxError struct {
+	Line    int // 0 when the error is at end of file
+	Column  int
+	Near    string
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("syntax error at end of file: %s", e.Message)
+	}
+	if e.Near != "" {
+		return fmt.Sprintf("syntax error at line %d near '%s': %s", e.Line, e.Near, e.Message)
+	}
+	return fmt.Sprintf("syntax error at line %d: %s", e.Line, e.Message)
+}
+
+// IsLua reports whether path names a Lua source file.
+func IsLua(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".lua")
+}
+
+// CheckSyntax parses the Lua file at path. It returns a *SyntaxError if the
+// file does not parse, or the underlying error if it cannot be read.
+func CheckSyntax(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	_, err = parse.Parse(f, filepath.Base(path))
+	if err == nil {
+		return nil
+	}
+
+	var perr *parse.Error
+	if !errors.As(err, &perr) {
+		return &SyntaxError{Message: err.Error()}
+	}
+	se := &SyntaxError{Near: perr.Token, Message: perr.Message}
+	if perr.Pos.Line != parse.EOF {
+		se.Line = perr.Pos.Line
+		se.Column = perr.Pos.Column
+	}
+	return se
+}
@@ -0,0 +1,76 @@
+package lint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsLua(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"main.lua", true},
+		{"Libs/LibStub/LibStub.LUA", true},
+		{"MyAddon.toc", false},
+		{"frames.xml", false},
+	}
+	for _, tt := range tests {
+		if got := IsLua(tt.path); got != tt.want {
+			t.Errorf("IsLua(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCheckSyntax_Valid(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "ok.lua")
+	_ = os.WriteFile(f, []byte("local x = 1\nif x then\n  print(x)\nend\n"), 0o644)
+
+	if err := CheckSyntax(f); err != nil {
+		t.Errorf("CheckSyntax() error = %v, want nil", err)
+	}
+}
+
+func TestCheckSyntax_Invalid(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "bad.lua")
+	_ = os.WriteFile(f, []byte("local x = 1\nlocal y = x +* 2\n"), 0o644)
+
+	err := CheckSyntax(f)
+	var se *SyntaxError
+	if !errors.As(err, &se) {
+		t.Fatalf("CheckSyntax() error = %v, want *SyntaxError", err)
+	}
+	if se.Line != 2 {
+		t.Errorf("Line = %d, want 2", se.Line)
+	}
+	if se.Near != "*" {
+		t.Errorf("Near = %q, want %q", se.Near, "*")
+	}
+}
+
+func TestCheckSyntax_UnexpectedEOF(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "eof.lua")
+	_ = os.WriteFile(f, []byte("function foo()\n  return 1\n"), 0o644)
+
+	err := CheckSyntax(f)
+	var se *SyntaxError
+	if !errors.As(err, &se) {
+		t.Fatalf("CheckSyntax() error = %v, want *SyntaxError", err)
+	}
+	if se.Line != 0 {
+		t.Errorf("Line = %d, want 0 for end of file", se.Line)
+	}
+}
+
+func TestCheckSyntax_MissingFile(t *testing.T) {
+	err := CheckSyntax(filepath.Join(t.TempDir(), "missing.lua"))
+	if err == nil {
+		t.Fatal("CheckSyntax() expected error for missing file")
+	}
+	var se *SyntaxError
+	if errors.As(err, &se) {
+		t.Error("missing file should not be reported as a syntax error")
+	}
+}
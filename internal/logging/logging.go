@@ -0,0 +1,139 @@
+// Package logging sets up blink's leveled logger.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	mu    sync.Mutex
+	out   io.Writer = os.Stderr
+	level slog.LevelVar
+)
+
+// ParseLevel returns the level named debug, info, warn or error.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+}
+
+// Setup makes slog's default logger, and with it the standard log package,
+// write records at l and above to the current output. Warnings and errors
+// are meant for the user and read "warning: ..." as they always did; debug
+// and info records are written as key=value text.
+func Setup(l slog.Level) {
+	level.Set(l)
+	h := slog.NewTextHandler(writer{}, &slog.HandlerOptions{
+		Level: &level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.String(slog.TimeKey, a.Value.Time().Format("15:04:05.000"))
+			}
+			return a
+		},
+	})
+	slog.SetDefault(slog.New(plainHandler{text: h}))
+}
+
+// plainHandler writes warnings and errors as plain lines, "warning: msg" and
+// "error: msg", followed by their attributes and then the "err" attribute
+// after a colon. Other records go to text.
+type plainHandler struct {
+	text  slog.Handler
+	attrs []slog.Attr
+}
+
+func (h plainHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.text.Enabled(ctx, l)
+}
+
+func (h plainHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn {
+		return h.text.Handle(ctx, r)
+	}
+	prefix := "warning: "
+	if r.Level >= slog.LevelError {
+		prefix = "error: "
+	}
+	var b strings.Builder
+	b.WriteString(prefix + r.Message)
+	var errText string
+	add := func(a slog.Attr) bool {
+		switch {
+		case a.Equal(slog.Attr{}):
+		case a.Key == "err":
+			errText = a.Value.String()
+		default:
+			v := a.Value.Resolve().String()
+			if v == "" || strings.ContainsAny(v, " \t\"=") {
+				v = strconv.Quote(v)
+			}
+			fmt.Fprintf(&b, " %s=%s", a.Key, v)
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(add)
+	if errText != "" {
+		b.WriteString(": " + errText)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(writer{}, b.String())
+	return err
+}
+
+func (h plainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return plainHandler{text: h.text.WithAttrs(attrs), attrs: append(slices.Clip(h.attrs), attrs...)}
+}
+
+func (h plainHandler) WithGroup(name string) slog.Handler {
+	return plainHandler{text: h.text.WithGroup(name), attrs: h.attrs}
+}
+
+// SetLevel changes the level of the logger Setup made.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Level returns the level records are logged at.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetOutput sends log records to w from now on, e.g. a --log-file or the
+// TUI's log panel. It returns the previous output.
+func SetOutput(w io.Writer) io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	prev := out
+	out = w
+	return prev
+}
+
+// writer writes to the current output.
+type writer struct{}
+
+func (writer) Write(p []byte) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return out.Write(p)
+}
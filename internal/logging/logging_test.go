@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"":      slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+	for in, want := range tests {
+		got, err := ParseLevel(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	var buf bytes.Buffer
+	defer SetOutput(SetOutput(&buf))
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+
+	Setup(slog.LevelWarn)
+	slog.Info("hidden")
+	slog.Warn("shown", "path", "Core.lua")
+	slog.Error("can't write status file", "err", errors.New("disk full"))
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Errorf("info record written at warn level: %q", out)
+	}
+	want := "warning: shown path=Core.lua\nerror: can't write status file: disk full\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	defer SetOutput(SetOutput(&buf))
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+
+	Setup(slog.LevelInfo)
+	slog.Debug("before")
+	SetLevel(slog.LevelDebug)
+	slog.Debug("after")
+
+	if out := buf.String(); strings.Contains(out, "before") || !strings.Contains(out, "after") {
+		t.Errorf("output = %q", out)
+	}
+	if Level() != slog.LevelDebug {
+		t.Errorf("Level() = %v", Level())
+	}
+}
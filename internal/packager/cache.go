@@ -0,0 +1,87 @@
+package packager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CacheFile records what each zip in a release folder was built from, so
+// zips whose inputs haven't changed aren't built again.
+const CacheFile = ".blink-package-cache.json"
+
+// Cached is a zip as recorded in CacheFile.
+type Cached struct {
+	Inputs  string  `json:"inputs"` // Fingerprint sum of what the zip was built from
+	Files   int     `json:"files"`
+	Release Release `json:"release"`
+}
+
+// ReadCache returns the zips recorded in the CacheFile in dir by file name.
+// A missing or unreadable cache is empty, so everything is built.
+func ReadCache(dir string) map[string]Cached {
+	cache := make(map[string]Cached)
+	data, err := os.ReadFile(filepath.Join(dir, CacheFile))
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return make(map[string]Cached)
+	}
+	return cache
+}
+
+// WriteCache writes cache as the CacheFile in dir.
+func WriteCache(dir string, cache map[string]Cached) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, CacheFile), append(data, '\n'), 0o644)
+}
+
+// Fingerprint hashes the inputs of a zip: the settings it was built with and
+// the path and contents of every file going into it.
+type Fingerprint struct {
+	h hash.Hash
+}
+
+// NewFingerprint returns a Fingerprint of settings, which it covers in order.
+func NewFingerprint(settings ...string) *Fingerprint {
+	f := &Fingerprint{h: sha256.New()}
+	for _, s := range settings {
+		f.write(s)
+	}
+	return f
+}
+
+// AddFile adds the file at path, known as name in the build.
+func (f *Fingerprint) AddFile(name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return err
+	}
+	f.write(name)
+	f.write(hex.EncodeToString(sum.Sum(nil)))
+	return nil
+}
+
+// Sum returns the fingerprint in hex.
+func (f *Fingerprint) Sum() string {
+	return hex.EncodeToString(f.h.Sum(nil))
+}
+
+// write adds s, ended by a NUL so neighbouring values can't run together.
+func (f *Fingerprint) write(s string) {
+	_, _ = io.WriteString(f.h, s)
+	_, _ = f.h.Write([]byte{0})
+}
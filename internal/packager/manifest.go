@@ -0,0 +1,64 @@
+package packager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestFile is a file in a release zip as listed in its manifest.
+type ManifestFile struct {
+	Path   string `json:"path"` // as in the zip, e.g. "MyAddon/Core.lua"
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ManifestName returns the name of the manifest written next to the zip at
+// zipPath, e.g. MyAddon-v1.0.manifest.json for MyAddon-v1.0.zip.
+func ManifestName(zipPath string) string {
+	return strings.TrimSuffix(zipPath, filepath.Ext(zipPath)) + ".manifest.json"
+}
+
+// Manifest lists every file below dir the way Zip archives it under prefix,
+// with its size and SHA-256, sorted by path so the same files always give the
+// same manifest.
+func Manifest(dir, prefix string) ([]ManifestFile, error) {
+	var files []ManifestFile
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, ManifestFile{Path: prefix + "/" + filepath.ToSlash(rel), Size: info.Size(), SHA256: sum})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// WriteManifest writes files as the manifest at path.
+func WriteManifest(path string, files []ManifestFile) error {
+	data, err := json.MarshalIndent(struct {
+		Files []ManifestFile `json:"files"`
+	}{files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
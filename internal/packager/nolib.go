@@ -0,0 +1,54 @@
+package packager
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NoLib reports whether the .pkgmeta in dir asks for nolib releases
+// (enable-nolib-creation: yes), which leave out the libraries its externals
+// fetch, and returns the paths of those externals.
+func NoLib(dir string) (externals []string, ok bool) {
+	f, err := os.Open(filepath.Join(dir, ".pkgmeta"))
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = f.Close() }()
+
+	inExternals := false
+	indent := "" // of the paths in the externals block
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			key, value, _ := strings.Cut(trimmed, ":")
+			inExternals = key == "externals"
+			if key == "enable-nolib-creation" {
+				value = strings.ToLower(strings.TrimSpace(value))
+				ok = value == "yes" || value == "true"
+			}
+			continue
+		}
+		if !inExternals {
+			continue
+		}
+		lead := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if indent == "" {
+			indent = lead
+		}
+		if lead != indent {
+			continue // the url or tag of an external
+		}
+		path, _, _ := strings.Cut(trimmed, ":")
+		if path = strings.Trim(strings.TrimSpace(path), `"'`); path != "" {
+			externals = append(externals, path)
+		}
+	}
+	return externals, ok
+}
@@ -0,0 +1,89 @@
+// Package packager builds release archives of an addon.
+package packager
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/byteorem/blink/internal/toc"
+)
+
+// Zip writes every file below dir to a zip archive at zipPath, placing them
+// under prefix (the addon folder name) so the archive extracts straight into
+// Interface/AddOns.
+func Zip(dir, zipPath, prefix string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(zipPath), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	zw := zip.NewWriter(out)
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		hdr.Name = prefix + "/" + filepath.ToSlash(rel)
+		hdr.Method = zip.Deflate
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		_, err = io.Copy(w, f)
+		return err
+	})
+	if err != nil {
+		_ = zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// ArchiveName returns the file name of an addon's release archive.
+func ArchiveName(addonName, version string) string {
+	return addonName + "-" + strings.ReplaceAll(version, "/", "-") + ".zip"
+}
+
+// Version returns the version to name a release of the addon at dir by: the
+// .toc's Version field, or `git describe` when the field is missing or still
+// a packager token such as @project-version@, or "dev".
+func Version(dir, tocPath string) string {
+	if f, err := toc.Read(tocPath); err == nil {
+		if v, ok := f.Get("Version"); ok && v != "" && !strings.Contains(v, "@") {
+			return v
+		}
+	}
+	out, err := exec.Command("git", "-C", dir, "describe", "--tags", "--always").Output()
+	if v := strings.TrimSpace(string(out)); err == nil && v != "" {
+		return v
+	}
+	return "dev"
+}
@@ -0,0 +1,245 @@
+package packager
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestZip(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "Libs"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "MyAddon.toc"), []byte("## Title: x\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "Libs", "Lib.lua"), []byte("-- lib\n"), 0o644)
+
+	zipPath := filepath.Join(t.TempDir(), "out", "MyAddon-1.0.zip")
+	if err := Zip(dir, zipPath, "MyAddon"); err != nil {
+		t.Fatalf("Zip() error = %v", err)
+	}
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	want := []string{"MyAddon/Libs/Lib.lua", "MyAddon/MyAddon.toc"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("entries = %v, want %v", names, want)
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	if got := ArchiveName("MyAddon", "v1.2/beta"); got != "MyAddon-v1.2-beta.zip" {
+		t.Errorf("ArchiveName() = %q", got)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	dir := t.TempDir()
+	tocPath := filepath.Join(dir, "MyAddon.toc")
+
+	_ = os.WriteFile(tocPath, []byte("## Title: x\n## Version: 2.4.0\n"), 0o644)
+	if got := Version(dir, tocPath); got != "2.4.0" {
+		t.Errorf("Version() = %q, want 2.4.0", got)
+	}
+
+	// Not a git repository, so an unexpanded token falls back to "dev".
+	_ = os.WriteFile(tocPath, []byte("## Version: @project-version@\n"), 0o644)
+	if got := Version(dir, tocPath); got != "dev" {
+		t.Errorf("Version() = %q, want dev", got)
+	}
+}
+
+func TestGames(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "MyAddon.toc"), []byte("## Interface: 110002, 11505\n## Title: x\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "MyAddon_Mists.toc"), []byte("## Interface: 50500\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "MyAddon_Vanilla.toc"), []byte("## Interface: 11507\n"), 0o644)
+
+	games, err := Games(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GameInfo{{"mainline", 110002}, {"mists", 50500}, {"classic", 11507}}
+	if len(games) != len(want) {
+		t.Fatalf("Games() = %v, want %v", games, want)
+	}
+	for i := range want {
+		if games[i] != want[i] {
+			t.Errorf("Games()[%d] = %v, want %v", i, games[i], want[i])
+		}
+	}
+}
+
+func TestWriteReleaseFile(t *testing.T) {
+	dir := t.TempDir()
+	releases := []Release{{Name: "MyAddon", Version: "v1.0", Filename: "MyAddon-v1.0.zip", Metadata: []GameInfo{{"mainline", 110002}}}}
+	if err := WriteReleaseFile(dir, releases); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ReleaseFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "releases": [
+    {
+      "name": "MyAddon",
+      "version": "v1.0",
+      "filename": "MyAddon-v1.0.zip",
+      "nolib": false,
+      "metadata": [
+        {
+          "flavor": "mainline",
+          "interface": 110002
+        }
+      ]
+    }
+  ]
+}
+`
+	if string(data) != want {
+		t.Errorf("%s = %s, want %s", ReleaseFile, data, want)
+	}
+
+	read, err := ReadReleaseFile(dir)
+	if err != nil || len(read) != 1 || read[0].Filename != "MyAddon-v1.0.zip" || len(read[0].Metadata) != 1 {
+		t.Errorf("ReadReleaseFile() = %+v, %v", read, err)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "Libs"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "MyAddon.toc"), []byte("## Title: x\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "Libs", "Lib.lua"), []byte("-- lib\n"), 0o644)
+
+	files, err := Manifest(dir, "MyAddon")
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+	want := []ManifestFile{
+		{Path: "MyAddon/Libs/Lib.lua", Size: 7, SHA256: "68c3de7d24b82ea53ed30ccc11b7cfa9b5dc3db93c6a078bcc86827704ed1736"},
+		{Path: "MyAddon/MyAddon.toc", Size: 12},
+	}
+	if len(files) != 2 || files[0].Path != want[0].Path || files[0].Size != want[0].Size || files[1].Path != want[1].Path || files[1].Size != want[1].Size {
+		t.Fatalf("Manifest() = %+v, want %+v", files, want)
+	}
+	if files[0].SHA256 != want[0].SHA256 {
+		t.Errorf("SHA256 = %s, want %s", files[0].SHA256, want[0].SHA256)
+	}
+
+	if got := ManifestName(filepath.Join("out", "MyAddon-v1.0.zip")); got != filepath.Join("out", "MyAddon-v1.0.manifest.json") {
+		t.Errorf("ManifestName() = %q", got)
+	}
+}
+
+func TestWriteChecksums(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "MyAddon-v1.0.zip"), []byte("-- lib\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "A-v1.0.zip"), nil, 0o644)
+
+	path, err := WriteChecksums(dir, []string{"MyAddon-v1.0.zip", "A-v1.0.zip"})
+	if err != nil {
+		t.Fatalf("WriteChecksums() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  A-v1.0.zip\n" +
+		"68c3de7d24b82ea53ed30ccc11b7cfa9b5dc3db93c6a078bcc86827704ed1736  MyAddon-v1.0.zip\n"
+	if string(data) != want {
+		t.Errorf("%s = %q, want %q", ChecksumFile, data, want)
+	}
+}
+
+func TestSigner_Command(t *testing.T) {
+	sig, args := Signer{Tool: "gpg", Key: "me@example.com", Passphrase: "secret"}.command("SHA256SUMS")
+	want := "--batch --yes --armor --detach-sign --output SHA256SUMS.asc --local-user me@example.com --pinentry-mode loopback --passphrase-fd 0 SHA256SUMS"
+	if sig != "SHA256SUMS.asc" || strings.Join(args, " ") != want {
+		t.Errorf("gpg command = %q, %q", sig, args)
+	}
+	sig, args = Signer{Tool: "minisign", Key: "blink.key"}.command("SHA256SUMS")
+	if sig != "SHA256SUMS.minisig" || strings.Join(args, " ") != "-S -m SHA256SUMS -x SHA256SUMS.minisig -s blink.key" {
+		t.Errorf("minisign command = %q, %q", sig, args)
+	}
+	if sig, _ := (Signer{Tool: "openssl"}).command("SHA256SUMS"); sig != "" {
+		t.Errorf("unknown tool gave %q", sig)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Core.lua")
+	_ = os.WriteFile(path, []byte("print(1)\n"), 0o644)
+	sum := func(settings, name string) string {
+		f := NewFingerprint(settings)
+		if err := f.AddFile(name, path); err != nil {
+			t.Fatal(err)
+		}
+		return f.Sum()
+	}
+
+	first := sum("retail", "Core.lua")
+	if sum("retail", "Core.lua") != first {
+		t.Error("same inputs gave different fingerprints")
+	}
+	if sum("classic", "Core.lua") == first || sum("retail", "Util.lua") == first {
+		t.Error("other settings or file name gave the same fingerprint")
+	}
+	_ = os.WriteFile(path, []byte("print(2)\n"), 0o644)
+	if sum("retail", "Core.lua") == first {
+		t.Error("changed file gave the same fingerprint")
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	if c := ReadCache(dir); len(c) != 0 {
+		t.Errorf("ReadCache() without a cache = %v", c)
+	}
+	cache := map[string]Cached{"MyAddon-v1.0.zip": {Inputs: "abc", Files: 3, Release: Release{Name: "MyAddon", Filename: "MyAddon-v1.0.zip"}}}
+	if err := WriteCache(dir, cache); err != nil {
+		t.Fatal(err)
+	}
+	if c := ReadCache(dir)["MyAddon-v1.0.zip"]; c.Inputs != "abc" || c.Files != 3 || c.Release.Name != "MyAddon" {
+		t.Errorf("ReadCache() = %+v", c)
+	}
+}
+
+func TestNoLib(t *testing.T) {
+	dir := t.TempDir()
+	pkgmeta := `package-as: MyAddon
+enable-nolib-creation: yes
+
+externals:
+  Libs/LibStub: https://repos.wowace.com/wow/libstub/trunk
+  "Libs/AceAddon-3.0":
+    url: https://repos.wowace.com/wow/ace3/trunk/AceAddon-3.0
+    tag: latest
+
+ignore:
+  - README.md
+`
+	if err := os.WriteFile(filepath.Join(dir, ".pkgmeta"), []byte(pkgmeta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	externals, ok := NoLib(dir)
+	if !ok || strings.Join(externals, ",") != "Libs/LibStub,Libs/AceAddon-3.0" {
+		t.Errorf("NoLib() = %v, %v", externals, ok)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".pkgmeta"), []byte("externals:\n  Libs/LibStub: https://example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := NoLib(dir); ok {
+		t.Error("NoLib() = true without enable-nolib-creation")
+	}
+}
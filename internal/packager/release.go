@@ -0,0 +1,102 @@
+package packager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/byteorem/blink/internal/toc"
+)
+
+// ReleaseFile is the metadata file update clients such as WowUp read next to
+// the zips, as written by the BigWigs packager.
+const ReleaseFile = "release.json"
+
+// Release describes one zip in ReleaseFile.
+type Release struct {
+	Name     string     `json:"name"`
+	Version  string     `json:"version"`
+	Filename string     `json:"filename"`
+	Nolib    bool       `json:"nolib"`
+	Metadata []GameInfo `json:"metadata"`
+}
+
+// GameInfo is a client flavor a release supports, with its interface version.
+type GameInfo struct {
+	Flavor    string `json:"flavor"` // packager game type, e.g. "mainline" or "classic"
+	Interface int    `json:"interface"`
+}
+
+// gameTypes names the packager game type of each interface major version;
+// 10 and up are retail.
+var gameTypes = map[int]string{1: "classic", 2: "bcc", 3: "wrath", 4: "cata", 5: "mists"}
+
+// Games returns the flavors the .toc files in dir support, newest first, read
+// from their Interface fields. A flavor listed more than once keeps its
+// highest interface version.
+func Games(dir string) ([]GameInfo, error) {
+	tocs, err := filepath.Glob(filepath.Join(dir, "*.toc"))
+	if err != nil {
+		return nil, err
+	}
+	best := make(map[string]int)
+	for _, path := range tocs {
+		f, err := toc.Read(path)
+		if err != nil {
+			return nil, err
+		}
+		v, _ := f.Get("Interface")
+		for _, s := range strings.Split(v, ",") {
+			iface, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || iface <= 0 {
+				continue
+			}
+			flavor := gameTypes[iface/10000]
+			if iface >= 100000 {
+				flavor = "mainline"
+			}
+			if flavor != "" && iface > best[flavor] {
+				best[flavor] = iface
+			}
+		}
+	}
+	games := make([]GameInfo, 0, len(best))
+	for flavor, iface := range best {
+		games = append(games, GameInfo{Flavor: flavor, Interface: iface})
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].Interface > games[j].Interface })
+	return games, nil
+}
+
+// ReadReleaseFile returns the releases listed in the ReleaseFile in dir.
+func ReadReleaseFile(dir string) ([]Release, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ReleaseFile))
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Releases []Release `json:"releases"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", ReleaseFile, err)
+	}
+	return file.Releases, nil
+}
+
+// WriteReleaseFile writes ReleaseFile describing releases into dir.
+func WriteReleaseFile(dir string, releases []Release) error {
+	data, err := json.MarshalIndent(struct {
+		Releases []Release `json:"releases"`
+	}{releases}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ReleaseFile), append(data, '\n'), 0o644)
+}
@@ -0,0 +1,114 @@
+package packager
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChecksumFile lists the SHA-256 of the files of a release in the format of
+// sha256sum, so `sha256sum -c SHA256SUMS` checks them.
+const ChecksumFile = "SHA256SUMS"
+
+// Signers are the tools a checksum file can be signed with.
+var Signers = []string{"gpg", "minisign"}
+
+// WriteChecksums writes ChecksumFile into dir, listing the files in it named
+// in names.
+func WriteChecksums(dir string, names []string) (string, error) {
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		sum, err := fileSHA256(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s  %s\n", sum, name)
+	}
+	path := filepath.Join(dir, ChecksumFile)
+	return path, os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Signer signs release files with gpg or minisign.
+type Signer struct {
+	Tool       string // one of Signers
+	Key        string // gpg key ID or email, or the minisign secret key file
+	Passphrase string // of the key; empty when it has none or gpg-agent has it
+}
+
+// Sign writes a detached signature of the file at path next to it, path.asc
+// for gpg and path.minisig for minisign, and returns its path.
+func (s Signer) Sign(ctx context.Context, path string) (string, error) {
+	sig, args := s.command(path)
+	if sig == "" {
+		return "", fmt.Errorf("unknown signing tool %q (one of %s)", s.Tool, strings.Join(Signers, ", "))
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.Tool, args...)
+	if s.Passphrase != "" {
+		cmd.Stdin = strings.NewReader(s.Passphrase + "\n")
+	}
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var execErr *exec.Error
+	if errors.As(err, &execErr) {
+		return "", fmt.Errorf("%s not found — install it to sign releases: %w", s.Tool, err)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", s.Tool, msg)
+		}
+		return "", fmt.Errorf("%s: %w", s.Tool, err)
+	}
+	return sig, nil
+}
+
+// command returns the signature Sign writes for path and the arguments of
+// the tool writing it, or an empty signature for an unknown tool.
+func (s Signer) command(path string) (string, []string) {
+	switch s.Tool {
+	case "gpg":
+		sig := path + ".asc"
+		args := []string{"--batch", "--yes", "--armor", "--detach-sign", "--output", sig}
+		if s.Key != "" {
+			args = append(args, "--local-user", s.Key)
+		}
+		if s.Passphrase != "" {
+			args = append(args, "--pinentry-mode", "loopback", "--passphrase-fd", "0")
+		}
+		return sig, append(args, path)
+	case "minisign":
+		sig := path + ".minisig"
+		args := []string{"-S", "-m", path, "-x", sig}
+		if s.Key != "" {
+			args = append(args, "-s", s.Key)
+		}
+		return sig, args
+	}
+	return "", nil
+}
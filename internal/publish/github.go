@@ -0,0 +1,204 @@
+// Package publish uploads packaged releases to where players download them.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// GitHubAPI is the base URL of the GitHub REST API.
+const GitHubAPI = "https://api.github.com"
+
+// GitHub publishes releases to a GitHub repository.
+type GitHub struct {
+	API    string
+	Repo   string // owner/name
+	Token  string
+	Client *http.Client
+}
+
+// NewGitHub returns a GitHub publisher for repo authenticating with token.
+func NewGitHub(repo, token string) *GitHub {
+	return &GitHub{
+		API:    GitHubAPI,
+		Repo:   repo,
+		Token:  token,
+		Client: &http.Client{Timeout: 5 * time.Minute}, // zips can take a while on slow uplinks
+	}
+}
+
+// Options controls how a missing release is created.
+type Options struct {
+	Draft      bool
+	Prerelease bool
+}
+
+type ghRelease struct {
+	HTMLURL   string    `json:"html_url"`
+	UploadURL string    `json:"upload_url"` // a URI template ending in {?name,label}
+	Assets    []ghAsset `json:"assets"`
+}
+
+type ghAsset struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Publish uploads files to the release of tag, creating it when there is
+// none yet, and returns the release's page. Assets already there under the
+// same name are replaced, so publishing again after a fix updates them.
+func (g *GitHub) Publish(ctx context.Context, tag string, files []string, opts Options) (string, error) {
+	rel, err := g.release(ctx, tag, opts)
+	if err != nil {
+		return "", err
+	}
+	existing := make(map[string]int64, len(rel.Assets))
+	for _, a := range rel.Assets {
+		existing[a.Name] = a.ID
+	}
+	for _, path := range files {
+		name := filepath.Base(path)
+		id, ok := existing[name]
+		if !ok {
+			if _, err := g.upload(ctx, rel.UploadURL, path, name); err != nil {
+				return "", fmt.Errorf("uploading %s: %w", name, err)
+			}
+			continue
+		}
+		// The new file goes up under another name, and the old one is only
+		// deleted once it is there, so a failed upload leaves the release
+		// with the old file rather than none.
+		tmp := "blink-new-" + name
+		if tmpID, ok := existing[tmp]; ok {
+			// Left over from a run that failed half-way.
+			if err := g.deleteAsset(ctx, tmpID); err != nil {
+				return "", fmt.Errorf("replacing %s: %w", name, err)
+			}
+		}
+		asset, err := g.upload(ctx, rel.UploadURL, path, tmp)
+		if err != nil {
+			return "", fmt.Errorf("uploading %s: %w", name, err)
+		}
+		if err := g.deleteAsset(ctx, id); err != nil {
+			return "", fmt.Errorf("replacing %s: %w", name, err)
+		}
+		body, _ := json.Marshal(map[string]string{"name": name})
+		if err := g.do(ctx, http.MethodPatch, g.assetURL(asset.ID), bytes.NewReader(body), "application/json", nil); err != nil {
+			return "", fmt.Errorf("renaming %s to %s: %w", tmp, name, err)
+		}
+	}
+	return rel.HTMLURL, nil
+}
+
+// release returns the release of tag, creating it when there is none.
+func (g *GitHub) release(ctx context.Context, tag string, opts Options) (ghRelease, error) {
+	var rel ghRelease
+	err := g.do(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.API, g.Repo, url.PathEscape(tag)), nil, "", &rel)
+	var se *statusError
+	if !errors.As(err, &se) || se.code != http.StatusNotFound {
+		return rel, err
+	}
+	body, _ := json.Marshal(map[string]any{"tag_name": tag, "name": tag, "draft": opts.Draft, "prerelease": opts.Prerelease})
+	err = g.do(ctx, http.MethodPost, fmt.Sprintf("%s/repos/%s/releases", g.API, g.Repo), bytes.NewReader(body), "application/json", &rel)
+	return rel, err
+}
+
+// assetURL returns the API URL of the release asset id.
+func (g *GitHub) assetURL(id int64) string {
+	return fmt.Sprintf("%s/repos/%s/releases/assets/%d", g.API, g.Repo, id)
+}
+
+func (g *GitHub) deleteAsset(ctx context.Context, id int64) error {
+	return g.do(ctx, http.MethodDelete, g.assetURL(id), nil, "", nil)
+}
+
+// upload adds the file at path to a release as an asset called name.
+func (g *GitHub) upload(ctx context.Context, uploadURL, path, name string) (ghAsset, error) {
+	var asset ghAsset
+	f, err := os.Open(path)
+	if err != nil {
+		return asset, err
+	}
+	defer func() { _ = f.Close() }()
+	base, _, _ := strings.Cut(uploadURL, "{")
+	contentType := "application/octet-stream"
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		contentType = "application/zip"
+	case ".json":
+		contentType = "application/json"
+	}
+	err = g.do(ctx, http.MethodPost, base+"?name="+url.QueryEscape(name), f, contentType, &asset)
+	return asset, err
+}
+
+// statusError is a response with an unexpected status.
+type statusError struct {
+	code    int
+	status  string
+	message string
+}
+
+func (e *statusError) Error() string {
+	if e.message != "" {
+		return fmt.Sprintf("GitHub: %s (%s)", e.message, e.status)
+	}
+	return "GitHub: " + e.status
+}
+
+// do sends a request and decodes the JSON response into out, if non-nil.
+func (g *GitHub) do(ctx context.Context, method, u string, body io.Reader, contentType string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if f, ok := body.(*os.File); ok {
+		if info, err := f.Stat(); err == nil {
+			req.ContentLength = info.Size()
+		}
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&msg)
+		return &statusError{code: resp.StatusCode, status: resp.Status, message: msg.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+var repoURL = regexp.MustCompile(`github\.com[:/]([\w.-]+/[\w.-]+?)(?:\.git)?/?$`)
+
+// RepoFromRemote returns the owner/name of a GitHub remote URL, such as
+// git@github.com:owner/name.git or https://github.com/owner/name.
+func RepoFromRemote(remote string) (string, bool) {
+	m := repoURL.FindStringSubmatch(strings.TrimSpace(remote))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
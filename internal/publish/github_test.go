@@ -0,0 +1,148 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fakeGitHub serves the release endpoints Publish uses, with an existing
+// release for v1.0 holding MyAddon-v1.0.zip, and records the calls made.
+// Uploads fail while *failUpload is set.
+func fakeGitHub(t *testing.T) (g *GitHub, calls *[]string, failUpload *bool) {
+	t.Helper()
+	calls, failUpload = new([]string), new(bool)
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		call := r.Method + " " + r.URL.Path
+		name := r.URL.Query().Get("name")
+		if name != "" {
+			body, _ := io.ReadAll(r.Body)
+			call += " " + name + "=" + string(body)
+		}
+		if r.Method == http.MethodPatch {
+			body, _ := io.ReadAll(r.Body)
+			call += " " + string(body)
+		}
+		*calls = append(*calls, call)
+		release := map[string]any{"html_url": "https://github.com/me/MyAddon/releases/tag/v1.0", "upload_url": srv.URL + "/upload/1{?name,label}"}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/me/MyAddon/releases/tags/v1.0":
+			release["assets"] = []map[string]any{{"id": 7, "name": "MyAddon-v1.0.zip"}}
+			_ = json.NewEncoder(w).Encode(release)
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/me/MyAddon/releases":
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(release)
+		case r.Method == http.MethodPost && *failUpload:
+			w.WriteHeader(http.StatusBadGateway)
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 9, "name": name})
+		default:
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("{}"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	g = NewGitHub("me/MyAddon", "secret")
+	g.API = srv.URL
+	return g, calls, failUpload
+}
+
+func writeFiles(t *testing.T) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"MyAddon-v1.0.zip", "release.json"} {
+		path := filepath.Join(dir, name)
+		_ = os.WriteFile(path, []byte(name), 0o644)
+		files = append(files, path)
+	}
+	return files
+}
+
+func TestPublish_ReplacesAssets(t *testing.T) {
+	g, calls, _ := fakeGitHub(t)
+	page, err := g.Publish(context.Background(), "v1.0", writeFiles(t), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"GET /repos/me/MyAddon/releases/tags/v1.0",
+		"POST /upload/1 blink-new-MyAddon-v1.0.zip=MyAddon-v1.0.zip",
+		"DELETE /repos/me/MyAddon/releases/assets/7",
+		`PATCH /repos/me/MyAddon/releases/assets/9 {"name":"MyAddon-v1.0.zip"}`,
+		"POST /upload/1 release.json=release.json",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Errorf("calls = %q, want %q", *calls, want)
+	}
+	if page != "https://github.com/me/MyAddon/releases/tag/v1.0" {
+		t.Errorf("page = %q", page)
+	}
+}
+
+func TestPublish_FailedUploadKeepsAsset(t *testing.T) {
+	g, calls, failUpload := fakeGitHub(t)
+	*failUpload = true
+	if _, err := g.Publish(context.Background(), "v1.0", writeFiles(t)[:1], Options{}); err == nil {
+		t.Fatal("Publish() with a failing upload succeeded")
+	}
+	for _, call := range *calls {
+		if call == "DELETE /repos/me/MyAddon/releases/assets/7" {
+			t.Errorf("deleted the old asset although the upload failed: %q", *calls)
+		}
+	}
+}
+
+func TestPublish_CreatesRelease(t *testing.T) {
+	g, calls, _ := fakeGitHub(t)
+	if _, err := g.Publish(context.Background(), "v2.0", writeFiles(t)[1:], Options{Draft: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"GET /repos/me/MyAddon/releases/tags/v2.0",
+		"POST /repos/me/MyAddon/releases",
+		"POST /upload/1 release.json=release.json",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Errorf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestPublish_Unauthorized(t *testing.T) {
+	g, _, _ := fakeGitHub(t)
+	g.Token = "wrong"
+	if _, err := g.Publish(context.Background(), "v1.0", nil, Options{}); err == nil {
+		t.Error("Publish() with a wrong token succeeded")
+	}
+}
+
+func TestRepoFromRemote(t *testing.T) {
+	for remote, want := range map[string]string{
+		"git@github.com:me/MyAddon.git":       "me/MyAddon",
+		"https://github.com/me/My.Addon":      "me/My.Addon",
+		"https://github.com/me/MyAddon.git\n": "me/MyAddon",
+		"ssh://git@github.com/me/MyAddon":     "me/MyAddon",
+	} {
+		if got, ok := RepoFromRemote(remote); !ok || got != want {
+			t.Errorf("RepoFromRemote(%q) = %q, %v, want %q", remote, got, ok, want)
+		}
+	}
+	if _, ok := RepoFromRemote("https://gitlab.com/me/MyAddon.git"); ok {
+		t.Error("RepoFromRemote accepted a GitLab remote")
+	}
+}
@@ -0,0 +1,75 @@
+package publish
+
+import (
+	"errors"
+	"os"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the name blink's secrets are kept under in the system
+// keyring.
+const keyringService = "blink"
+
+const githubTokenKey = "github-token"
+
+// GitHubToken returns the GitHub token saved with SaveGitHubToken, or the
+// GITHUB_TOKEN environment variable (e.g. in CI) when none is saved.
+func GitHubToken() (string, error) {
+	token, err := keyring.Get(keyringService, githubTokenKey)
+	if err == nil && token != "" {
+		return token, nil
+	}
+	if env := os.Getenv("GITHUB_TOKEN"); env != "" {
+		return env, nil
+	}
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return "", errors.New("no GitHub token: the system keyring isn't available (" + err.Error() + ") and GITHUB_TOKEN isn't set")
+	}
+	return "", errors.New("no GitHub token — save one with `blink publish github login` or set GITHUB_TOKEN")
+}
+
+// SaveGitHubToken keeps token in the system keyring.
+func SaveGitHubToken(token string) error {
+	return keyring.Set(keyringService, githubTokenKey, token)
+}
+
+// DeleteGitHubToken removes the saved token, if any.
+func DeleteGitHubToken() error {
+	if err := keyring.Delete(keyringService, githubTokenKey); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// signingKey is the keyring entry holding the passphrase of the signing key
+// named key, so each key keeps its own.
+func signingKey(key string) string {
+	return "signing-passphrase:" + key
+}
+
+// SigningPassphrase returns the passphrase of the release signing key saved
+// with SaveSigningPassphrase, or the BLINK_SIGNING_PASSPHRASE environment
+// variable (e.g. in CI) when none is saved. It is empty for keys without a
+// passphrase, or when gpg-agent holds it.
+func SigningPassphrase(key string) string {
+	if pass, err := keyring.Get(keyringService, signingKey(key)); err == nil && pass != "" {
+		return pass
+	}
+	return os.Getenv("BLINK_SIGNING_PASSPHRASE")
+}
+
+// SaveSigningPassphrase keeps the passphrase of the signing key in the
+// system keyring.
+func SaveSigningPassphrase(key, pass string) error {
+	return keyring.Set(keyringService, signingKey(key), pass)
+}
+
+// DeleteSigningPassphrase removes the saved passphrase of the signing key,
+// if any.
+func DeleteSigningPassphrase(key string) error {
+	if err := keyring.Delete(keyringService, signingKey(key)); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
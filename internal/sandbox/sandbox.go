@@ -0,0 +1,88 @@
+// Package sandbox makes the throwaway WoW install blink sandbox syncs into,
+// and keeps a session's writes inside it.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/byteorem/blink/internal/flavor"
+)
+
+// Sandbox is a throwaway WoW install in the temp directory.
+type Sandbox struct {
+	Root    string // temp folder everything is written below
+	WowPath string // the install's World of Warcraft folder
+}
+
+// New makes a sandbox in the temp directory. Every live client gets an
+// AddOns folder, so an addon's .toc files pick one just like in a real
+// install.
+func New() (*Sandbox, error) {
+	dir, err := os.MkdirTemp("", "blink-sandbox-*")
+	if err != nil {
+		return nil, err
+	}
+	// The temp directory may be behind a symlink, e.g. /tmp on macOS; Check
+	// compares resolved paths.
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	s := &Sandbox{Root: root, WowPath: filepath.Join(root, "World of Warcraft")}
+	for _, f := range flavor.All() {
+		if f.Live != "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Join(s.WowPath, f.Dir, "Interface", "AddOns"), 0o755); err != nil {
+			_ = s.Remove()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Check returns an error unless path is inside the sandbox once symlinks
+// along it are resolved, so a target in blink.toml, or a folder linked out
+// of the sandbox, can't have a session write to the real install.
+func (s *Sandbox) Check(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	resolved, err := resolve(abs)
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(s.Root, resolved); err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%s is outside the sandbox %s — refusing to write there", path, s.Root)
+	}
+	return nil
+}
+
+// Remove deletes the sandbox.
+func (s *Sandbox) Remove() error {
+	return os.RemoveAll(s.Root)
+}
+
+// resolve returns path with the symlinks along it resolved. The part that
+// doesn't exist yet is kept as it is.
+func resolve(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	parent := filepath.Dir(path)
+	if !errors.Is(err, fs.ErrNotExist) || parent == path {
+		return "", err
+	}
+	resolved, err = resolve(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolved, filepath.Base(path)), nil
+}
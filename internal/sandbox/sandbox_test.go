@@ -0,0 +1,70 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newSandbox(t *testing.T) *Sandbox {
+	t.Helper()
+	s, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Remove() })
+	return s
+}
+
+func TestNew(t *testing.T) {
+	s := newSandbox(t)
+	if _, err := os.Stat(filepath.Join(s.WowPath, "_retail_", "Interface", "AddOns")); err != nil {
+		t.Errorf("no retail AddOns folder: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.WowPath, "_ptr_")); err == nil {
+		t.Error("made a folder for a test client")
+	}
+	if err := s.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.Root); !os.IsNotExist(err) {
+		t.Errorf("sandbox still there after Remove: %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	s := newSandbox(t)
+	addOns := filepath.Join(s.WowPath, "_retail_", "Interface", "AddOns")
+	for _, path := range []string{
+		addOns,
+		filepath.Join(addOns, "MyAddon"),
+		filepath.Join(s.WowPath, "_retail_", "Fonts"),
+	} {
+		if err := s.Check(path); err != nil {
+			t.Errorf("Check(%s) = %v, want nil", path, err)
+		}
+	}
+
+	outside := t.TempDir()
+	for _, path := range []string{
+		outside,
+		filepath.Join(outside, "_retail_", "Interface", "AddOns"),
+		filepath.Join(addOns, "..", "..", "..", "..", "..", "MyAddon"),
+		filepath.Dir(s.Root),
+	} {
+		if err := s.Check(path); err == nil {
+			t.Errorf("Check(%s) = nil, want the write outside the sandbox refused", path)
+		}
+	}
+}
+
+func TestCheck_Symlink(t *testing.T) {
+	s := newSandbox(t)
+	link := filepath.Join(s.WowPath, "_retail_", "Interface", "AddOns", "MyAddon")
+	if err := os.Symlink(t.TempDir(), link); err != nil {
+		t.Skipf("can't make symlinks: %v", err)
+	}
+	if err := s.Check(filepath.Join(link, "Core.lua")); err == nil {
+		t.Error("Check() = nil for a folder linked out of the sandbox, want it refused")
+	}
+}
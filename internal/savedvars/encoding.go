@@ -0,0 +1,44 @@
+package savedvars
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// printAlphabet is the alphabet of LibDeflate's EncodeForPrint, which addons
+// use to make compressed data safe to copy and paste.
+const printAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789()"
+
+// decodeForPrint reverses LibDeflate:EncodeForPrint: every character carries
+// six bits, least significant first.
+func decodeForPrint(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	out := make([]byte, 0, len(s)*3/4)
+	var cache uint32
+	var bits uint
+	for i := range len(s) {
+		v := strings.IndexByte(printAlphabet, s[i])
+		if v < 0 {
+			return nil, fmt.Errorf("unexpected character %q at %d", s[i], i)
+		}
+		cache |= uint32(v) << bits
+		bits += 6
+		for bits >= 8 {
+			out = append(out, byte(cache))
+			cache >>= 8
+			bits -= 8
+		}
+	}
+	return out, nil
+}
+
+// inflate decompresses raw DEFLATE data, as written by
+// LibDeflate:CompressDeflate.
+func inflate(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	return io.ReadAll(r)
+}
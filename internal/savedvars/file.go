@@ -0,0 +1,220 @@
+// Package savedvars edits WoW SavedVariables files, the Lua files a client
+// loads addon data from at login and writes back at logout, and puts addon
+// export strings (WeakAuras) into them.
+package savedvars
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// BackupSuffix is added to the name of a SavedVariables file for the copy
+// saved before blink first changes it in a run.
+const BackupSuffix = ".blink.bak"
+
+// File is a SavedVariables file: the globals it assigns.
+type File struct {
+	L     *lua.LState
+	names []string
+	vars  map[string]lua.LValue
+}
+
+// Load reads the SavedVariables file at path. A missing file is an empty one,
+// as for an addon that never saved anything.
+func Load(path string) (*File, error) {
+	f := &File{L: lua.NewState(lua.Options{SkipOpenLibs: true}), vars: make(map[string]lua.LValue)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fn, err := f.L.LoadString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	// The file is only assignments; run it with no libraries in reach.
+	env := f.L.NewTable()
+	f.L.SetFEnv(fn, env)
+	f.L.Push(fn)
+	if err := f.L.PCall(0, 0, nil); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	env.ForEach(func(k, v lua.LValue) {
+		if name, ok := k.(lua.LString); ok {
+			f.Set(string(name), v)
+		}
+	})
+	slices.Sort(f.names)
+	return f, nil
+}
+
+// Close releases the Lua state holding the file's values.
+func (f *File) Close() {
+	f.L.Close()
+}
+
+// Get returns the global name, or LNil.
+func (f *File) Get(name string) lua.LValue {
+	if v, ok := f.vars[name]; ok {
+		return v
+	}
+	return lua.LNil
+}
+
+// Set assigns the global name.
+func (f *File) Set(name string, v lua.LValue) {
+	if _, ok := f.vars[name]; !ok {
+		f.names = append(f.names, name)
+	}
+	f.vars[name] = v
+}
+
+// Table returns the table in the global name, creating it (and any missing
+// tables along keys) if needed.
+func (f *File) Table(name string, keys ...string) *lua.LTable {
+	t, ok := f.Get(name).(*lua.LTable)
+	if !ok {
+		t = f.L.NewTable()
+		f.Set(name, t)
+	}
+	for _, k := range keys {
+		sub, ok := t.RawGetString(k).(*lua.LTable)
+		if !ok {
+			sub = f.L.NewTable()
+			t.RawSetString(k, sub)
+		}
+		t = sub
+	}
+	return t
+}
+
+// Save writes the file to path in the layout the client writes, first copying
+// what was there to path+BackupSuffix.
+func (f *File) Save(path string) error {
+	if old, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(path+BackupSuffix, old, 0o644); err != nil {
+			return fmt.Errorf("backing up %s: %w", path, err)
+		}
+	}
+	var b bytes.Buffer
+	b.WriteString("\n")
+	for _, name := range f.names {
+		if v := f.vars[name]; v != lua.LNil {
+			b.WriteString(name + " = ")
+			writeValue(&b, v, 0)
+			b.WriteString("\n")
+		}
+	}
+	return os.WriteFile(path, b.Bytes(), 0o644)
+}
+
+// writeValue writes v as a Lua literal, tables over several lines indented
+// by depth.
+func writeValue(b *bytes.Buffer, v lua.LValue, depth int) {
+	switch v := v.(type) {
+	case lua.LString:
+		b.WriteString(quote(string(v)))
+	case lua.LNumber:
+		b.WriteString(formatNumber(float64(v)))
+	case lua.LBool:
+		b.WriteString(v.String())
+	case *lua.LTable:
+		b.WriteString("{\n")
+		indent := strings.Repeat("\t", depth+1)
+		for _, k := range sortedKeys(v) {
+			b.WriteString(indent + "[")
+			writeValue(b, k, depth+1)
+			b.WriteString("] = ")
+			writeValue(b, v.RawGet(k), depth+1)
+			b.WriteString(",\n")
+		}
+		b.WriteString(strings.Repeat("\t", depth) + "}")
+	default:
+		// Functions and the like can't be saved; neither does the client.
+		b.WriteString("nil")
+	}
+}
+
+// sortedKeys returns t's keys that can be written, numbers first.
+func sortedKeys(t *lua.LTable) []lua.LValue {
+	var keys []lua.LValue
+	t.ForEach(func(k, _ lua.LValue) {
+		switch k.(type) {
+		case lua.LNumber, lua.LString, lua.LBool:
+			keys = append(keys, k)
+		}
+	})
+	rank := func(k lua.LValue) int {
+		switch k.(type) {
+		case lua.LNumber:
+			return 0
+		case lua.LString:
+			return 1
+		}
+		return 2
+	}
+	slices.SortFunc(keys, func(a, b lua.LValue) int {
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra - rb
+		}
+		if na, ok := a.(lua.LNumber); ok {
+			nb := b.(lua.LNumber)
+			return cmpFloat(float64(na), float64(nb))
+		}
+		return strings.Compare(a.String(), b.String())
+	})
+	return keys
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// formatNumber writes whole numbers without a fraction, like the client.
+func formatNumber(f float64) string {
+	switch {
+	case math.IsNaN(f) || math.IsInf(f, 0):
+		return "0"
+	case f == math.Trunc(f) && math.Abs(f) < 1<<53:
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// quote returns s as a Lua 5.1 string literal.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := range len(s) {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\%03d`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
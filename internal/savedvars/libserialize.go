@@ -0,0 +1,292 @@
+package savedvars
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// libSerializeVersion is the newest LibSerialize format understood.
+const libSerializeVersion = 1
+
+// LibSerialize type indexes for values whose first byte is a 5-bit type.
+const (
+	lsNil = iota
+	lsNum16Pos
+	lsNum16Neg
+	lsNum24Pos
+	lsNum24Neg
+	lsNum32Pos
+	lsNum32Neg
+	lsNum64Pos
+	lsNum64Neg
+	lsFloat
+	lsFloatStrPos
+	lsFloatStrNeg
+	lsTrue
+	lsFalse
+	lsStr8
+	lsStr16
+	lsStr24
+	lsTable8
+	lsTable16
+	lsTable24
+	lsArray8
+	lsArray16
+	lsArray24
+	lsMixed8
+	lsMixed16
+	lsMixed24
+	lsStrRef8
+	lsStrRef16
+	lsStrRef24
+	lsTableRef8
+	lsTableRef16
+	lsTableRef24
+)
+
+// LibSerialize types whose first byte also holds a count of up to 15.
+const (
+	lsEmbeddedString = iota
+	lsEmbeddedTable
+	lsEmbeddedArray
+	lsEmbeddedMixed
+)
+
+var errTruncated = errors.New("data ends early")
+
+// deserializer reads values written by LibSerialize:Serialize into Lua values
+// of L.
+type deserializer struct {
+	L         *lua.LState
+	data      []byte
+	pos       int
+	strRefs   []lua.LString
+	tableRefs []*lua.LTable
+}
+
+// deserialize returns the values LibSerialize serialized into data.
+func deserialize(L *lua.LState, data []byte) ([]lua.LValue, error) {
+	d := &deserializer{L: L, data: data}
+	version, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	if version > libSerializeVersion {
+		return nil, fmt.Errorf("LibSerialize format %d is newer than this blink understands", version)
+	}
+	var values []lua.LValue
+	for d.pos < len(d.data) {
+		v, err := d.object()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (d *deserializer) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errTruncated
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *deserializer) bytes(n int) ([]byte, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// int reads an unsigned big-endian integer of n bytes.
+func (d *deserializer) int(n int) (int, error) {
+	b, err := d.bytes(n)
+	if err != nil {
+		return 0, err
+	}
+	v := 0
+	for _, c := range b {
+		v = v<<8 | int(c)
+	}
+	return v, nil
+}
+
+func (d *deserializer) object() (lua.LValue, error) {
+	b, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b&1 == 1:
+		// NNNN NNN1: a 7-bit non-negative integer.
+		return lua.LNumber(b >> 1), nil
+	case b&3 == 2:
+		// CCCC TT10: a type with a 4-bit count.
+		count := int(b >> 4)
+		switch (b >> 2) & 3 {
+		case lsEmbeddedString:
+			return d.string(count)
+		case lsEmbeddedTable:
+			return d.table(0, count)
+		case lsEmbeddedArray:
+			return d.table(count, 0)
+		default:
+			// Two 2-bit counts, each one less than the true count.
+			return d.table(count%4+1, count/4+1)
+		}
+	case b&7 == 4:
+		// NNNN S100: a 12-bit integer, its upper bits in the next byte.
+		hi, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := lua.LNumber(int(b>>4) | int(hi)<<4)
+		if b&8 != 0 {
+			n = -n
+		}
+		return n, nil
+	}
+
+	switch typ := b >> 3; typ {
+	case lsNil:
+		return lua.LNil, nil
+	case lsNum16Pos, lsNum16Neg, lsNum24Pos, lsNum24Neg, lsNum32Pos, lsNum32Neg, lsNum64Pos, lsNum64Neg:
+		// Positive and negative alternate, for 2, 3, 4 and 7 bytes.
+		i := typ - lsNum16Pos
+		n, err := d.int([...]int{2, 3, 4, 7}[i/2])
+		if err != nil {
+			return nil, err
+		}
+		if i%2 == 1 {
+			n = -n
+		}
+		return lua.LNumber(n), nil
+	case lsFloat:
+		b, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return lua.LNumber(math.Float64frombits(binary.BigEndian.Uint64(b))), nil
+	case lsFloatStrPos, lsFloatStrNeg:
+		n, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(int(n))
+		if err != nil {
+			return nil, err
+		}
+		f, err := strconv.ParseFloat(string(b), 64)
+		if err != nil {
+			return nil, err
+		}
+		if typ == lsFloatStrNeg {
+			f = -f
+		}
+		return lua.LNumber(f), nil
+	case lsTrue:
+		return lua.LTrue, nil
+	case lsFalse:
+		return lua.LFalse, nil
+	case lsStr8, lsStr16, lsStr24:
+		n, err := d.int(int(typ-lsStr8) + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.string(n)
+	case lsTable8, lsTable16, lsTable24:
+		n, err := d.int(int(typ-lsTable8) + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.table(0, n)
+	case lsArray8, lsArray16, lsArray24:
+		n, err := d.int(int(typ-lsArray8) + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.table(n, 0)
+	case lsMixed8, lsMixed16, lsMixed24:
+		size := int(typ-lsMixed8) + 1
+		arrayCount, err := d.int(size)
+		if err != nil {
+			return nil, err
+		}
+		mapCount, err := d.int(size)
+		if err != nil {
+			return nil, err
+		}
+		return d.table(arrayCount, mapCount)
+	case lsStrRef8, lsStrRef16, lsStrRef24:
+		i, err := d.int(int(typ-lsStrRef8) + 1)
+		if err != nil {
+			return nil, err
+		}
+		if i < 1 || i > len(d.strRefs) {
+			return nil, fmt.Errorf("string reference %d out of range", i)
+		}
+		return d.strRefs[i-1], nil
+	default: // lsTableRef8, lsTableRef16, lsTableRef24
+		i, err := d.int(int(typ-lsTableRef8) + 1)
+		if err != nil {
+			return nil, err
+		}
+		if i < 1 || i > len(d.tableRefs) {
+			return nil, fmt.Errorf("table reference %d out of range", i)
+		}
+		return d.tableRefs[i-1], nil
+	}
+}
+
+// string reads a string of n bytes. Strings longer than two bytes can be
+// referred to again later.
+func (d *deserializer) string(n int) (lua.LValue, error) {
+	b, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	s := lua.LString(b)
+	if n > 2 {
+		d.strRefs = append(d.strRefs, s)
+	}
+	return s, nil
+}
+
+// table reads a table of arrayCount values followed by mapCount key/value
+// pairs. Once read, it can be referred to again later.
+func (d *deserializer) table(arrayCount, mapCount int) (lua.LValue, error) {
+	t := d.L.CreateTable(arrayCount, mapCount)
+	for i := 1; i <= arrayCount; i++ {
+		v, err := d.object()
+		if err != nil {
+			return nil, err
+		}
+		t.RawSetInt(i, v)
+	}
+	for range mapCount {
+		k, err := d.object()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.object()
+		if err != nil {
+			return nil, err
+		}
+		if k == lua.LNil {
+			return nil, errors.New("table key is nil")
+		}
+		t.RawSet(k, v)
+	}
+	d.tableRefs = append(d.tableRefs, t)
+	return t, nil
+}
@@ -0,0 +1,251 @@
+package savedvars
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// serialize writes v in LibSerialize's format, without string or table
+// references.
+func serialize(b *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteByte(lsNil << 3)
+	case bool:
+		if v {
+			b.WriteByte(lsTrue << 3)
+		} else {
+			b.WriteByte(lsFalse << 3)
+		}
+	case int:
+		n := max(v, -v)
+		switch {
+		case v >= 0 && v < 128:
+			b.WriteByte(byte(v<<1 | 1))
+		case n < 4096:
+			sign := byte(0)
+			if v < 0 {
+				sign = 8
+			}
+			b.Write([]byte{byte(n&15)<<4 | sign | 4, byte(n >> 4)})
+		default:
+			typ := byte(lsNum32Pos)
+			if v < 0 {
+				typ = lsNum32Neg
+			}
+			b.WriteByte(typ << 3)
+			_ = binary.Write(b, binary.BigEndian, uint32(n))
+		}
+	case float64:
+		b.WriteByte(lsFloat << 3)
+		_ = binary.Write(b, binary.BigEndian, math.Float64bits(v))
+	case string:
+		if len(v) < 16 {
+			b.WriteByte(byte(len(v)<<4 | lsEmbeddedString<<2 | 2))
+		} else {
+			b.Write([]byte{lsStr8 << 3, byte(len(v))})
+		}
+		b.WriteString(v)
+	case []any:
+		b.WriteByte(byte(len(v)<<4 | lsEmbeddedArray<<2 | 2))
+		for _, e := range v {
+			serialize(b, e)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		b.WriteByte(byte(len(v)<<4 | lsEmbeddedTable<<2 | 2))
+		for _, k := range keys {
+			serialize(b, k)
+			serialize(b, v[k])
+		}
+	}
+}
+
+// encodeForPrint is LibDeflate:EncodeForPrint.
+func encodeForPrint(data []byte) string {
+	var b strings.Builder
+	var cache uint32
+	var bits uint
+	for _, c := range data {
+		cache |= uint32(c) << bits
+		bits += 8
+		for bits >= 6 {
+			b.WriteByte(printAlphabet[cache&63])
+			cache >>= 6
+			bits -= 6
+		}
+	}
+	if bits > 0 {
+		b.WriteByte(printAlphabet[cache&63])
+	}
+	return b.String()
+}
+
+// weakAurasExport returns the export string WeakAuras makes for aura and its
+// children.
+func weakAurasExport(t *testing.T, aura map[string]any, children ...any) string {
+	t.Helper()
+	var data bytes.Buffer
+	data.WriteByte(libSerializeVersion)
+	serialize(&data, map[string]any{"m": "d", "d": aura, "c": children, "v": 2000, "s": "5.19.0"})
+	var compressed bytes.Buffer
+	w, _ := flate.NewWriter(&compressed, flate.BestCompression)
+	_, _ = w.Write(data.Bytes())
+	_ = w.Close()
+	return weakAurasPrefix + encodeForPrint(compressed.Bytes())
+}
+
+func TestDecodeForPrint(t *testing.T) {
+	for _, data := range [][]byte{{}, {1}, {1, 2}, {1, 2, 3}, []byte("hello, world")} {
+		got, err := decodeForPrint(encodeForPrint(data))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("decodeForPrint(encodeForPrint(%v)) = %v, %v", data, got, err)
+		}
+	}
+	if _, err := decodeForPrint("ab!c"); err == nil {
+		t.Error("decodeForPrint accepted '!'")
+	}
+}
+
+func TestDeserialize(t *testing.T) {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	data := []byte{
+		libSerializeVersion,
+		0x3<<4 | lsEmbeddedString<<2 | 2, 'a', 'b', 'c', // "abc", becomes string reference 1
+		lsStrRef8 << 3, 1, // "abc" again
+		0x5<<4 | 8 | 4, 0x12, // -0x125 as a 12-bit integer
+		lsNum16Pos << 3, 0x12, 0x34,
+		lsNum24Neg << 3, 0x01, 0x00, 0x00,
+		4<<4 | lsEmbeddedMixed<<2 | 2, // one array value, two map pairs
+		lsTrue << 3,
+		1<<4 | lsEmbeddedString<<2 | 2, 'k', 7<<1 | 1,
+		lsStrRef8 << 3, 1, lsFalse << 3,
+	}
+	values, err := deserialize(L, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"abc", "abc", "-293", "4660", "-65536"}
+	for i, w := range want {
+		if values[i].String() != w {
+			t.Errorf("value %d = %s, want %s", i, values[i], w)
+		}
+	}
+	tab, ok := values[5].(*lua.LTable)
+	if !ok || tab.RawGetInt(1) != lua.LTrue || tab.RawGetString("k") != lua.LNumber(7) || tab.RawGetString("abc") != lua.LFalse {
+		t.Errorf("mixed table = %v", values[5])
+	}
+
+	if _, err := deserialize(L, data[:len(data)-1]); err == nil {
+		t.Error("deserialize accepted truncated data")
+	}
+}
+
+func TestInjectWeakAuras(t *testing.T) {
+	path := filepath.Join(t.TempDir(), WeakAurasFile)
+	old := "\nWeakAurasSaved = {\n[\"displays\"] = {\n[\"Old\"] = {\n[\"id\"] = \"Old\",\n},\n},\n[\"login\"] = \"done\",\n}\nOtherSaved = 3\n"
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	group := map[string]any{"id": "My Group", "regionType": "dynamicgroup", "controlledChildren": []any{"My Aura"}}
+	child := map[string]any{"id": "My Aura", "parent": "My Group", "width": 64.5, "desc": "line one\nsays \"hi\"", "load": map[string]any{"level": -1000}}
+	ids, err := InjectWeakAuras(path, []string{weakAurasExport(t, group, child)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids, []string{"My Group", "My Aura"}) {
+		t.Errorf("ids = %v", ids)
+	}
+	if backup, _ := os.ReadFile(path + BackupSuffix); string(backup) != old {
+		t.Errorf("backup = %q", backup)
+	}
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	displays := f.Table(weakAurasVar, "displays")
+	for _, id := range []string{"Old", "My Group", "My Aura"} {
+		if _, ok := displays.RawGetString(id).(*lua.LTable); !ok {
+			t.Errorf("displays lack %q", id)
+		}
+	}
+	aura := displays.RawGetString("My Aura").(*lua.LTable)
+	if aura.RawGetString("desc").String() != "line one\nsays \"hi\"" || aura.RawGetString("width") != lua.LNumber(64.5) {
+		t.Errorf("My Aura = desc %q, width %v", aura.RawGetString("desc"), aura.RawGetString("width"))
+	}
+	if level := aura.RawGetString("load").(*lua.LTable).RawGetString("level"); level != lua.LNumber(-1000) {
+		t.Errorf("load.level = %v", level)
+	}
+	if f.Table(weakAurasVar).RawGetString("login").String() != "done" || f.Get("OtherSaved") != lua.LNumber(3) {
+		t.Error("other saved values were lost")
+	}
+}
+
+func TestDecodeWeakAuras_OldFormat(t *testing.T) {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	if _, err := DecodeWeakAuras(L, "!abcdef"); err == nil || !strings.Contains(err.Error(), "!WA:2!") {
+		t.Errorf("DecodeWeakAuras(old format) = %v", err)
+	}
+}
+
+// testdata/group.wa.txt is an export of a dynamic group holding one icon,
+// made outside blink: its LibSerialize data uses string references, mixed
+// tables and floats written as strings, and it was compressed by zlib rather
+// than compress/flate.
+func TestDecodeWeakAuras_Export(t *testing.T) {
+	export, err := os.ReadFile(filepath.Join("testdata", "group.wa.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	auras, err := DecodeWeakAuras(L, string(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(auras) != 2 || auraID(auras[0]) != "Raid Cooldowns" || auraID(auras[1]) != "Raid Cooldowns - Bloodlust" {
+		t.Fatalf("decoded %d aura(s), want the group and its icon", len(auras))
+	}
+
+	group, icon := auras[0], auras[1]
+	if group.RawGetString("xOffset") != lua.LNumber(-312.5) || group.RawGetString("tocversion") != lua.LNumber(110002) {
+		t.Errorf("group xOffset = %v, tocversion = %v", group.RawGetString("xOffset"), group.RawGetString("tocversion"))
+	}
+	if children := group.RawGetString("controlledChildren").(*lua.LTable); children.RawGetInt(1).String() != auraID(icon) {
+		t.Errorf("group children = %v", children.RawGetInt(1))
+	}
+	// The icon's parent and id repeat strings of the group, which are sent
+	// as references.
+	if icon.RawGetString("parent").String() != "Raid Cooldowns" || icon.RawGetString("desaturate") != lua.LFalse || icon.RawGetString("yOffset") != lua.LNumber(0.25) {
+		t.Errorf("icon parent = %v, desaturate = %v, yOffset = %v", icon.RawGetString("parent"), icon.RawGetString("desaturate"), icon.RawGetString("yOffset"))
+	}
+	triggers := icon.RawGetString("triggers").(*lua.LTable)
+	if triggers.RawGetString("activeTriggerMode") != lua.LNumber(-10) {
+		t.Errorf("activeTriggerMode = %v", triggers.RawGetString("activeTriggerMode"))
+	}
+	trigger := triggers.RawGetInt(1).(*lua.LTable).RawGetString("trigger").(*lua.LTable)
+	if names := trigger.RawGetString("auranames").(*lua.LTable); names.Len() != 3 || names.RawGetInt(3).String() != "80353" {
+		t.Errorf("auranames = %v", names)
+	}
+	if icon.RawGetString("desc").String() != `Shows "Bloodlust" and its variants.` {
+		t.Errorf("desc = %q", icon.RawGetString("desc"))
+	}
+}
@@ -0,0 +1,98 @@
+package savedvars
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// WeakAurasFile is the name of WeakAuras' SavedVariables file, and
+// weakAurasVar the global it saves auras in.
+const (
+	WeakAurasFile = "WeakAuras.lua"
+	weakAurasVar  = "WeakAurasSaved"
+)
+
+// weakAurasPrefix starts the export strings of WeakAuras 3 and later, which
+// hold LibSerialize data compressed with LibDeflate.
+const weakAurasPrefix = "!WA:2!"
+
+// DecodeWeakAuras returns the auras in a WeakAuras export string: the aura,
+// then the auras of its group if it is one.
+func DecodeWeakAuras(L *lua.LState, export string) ([]*lua.LTable, error) {
+	export = strings.TrimSpace(export)
+	encoded, ok := strings.CutPrefix(export, weakAurasPrefix)
+	if !ok {
+		if strings.HasPrefix(export, "!") {
+			return nil, errors.New("not a WeakAuras export string of the current format (!WA:2!) — export it again from an up to date WeakAuras")
+		}
+		return nil, errors.New("not a WeakAuras export string")
+	}
+	compressed, err := decodeForPrint(encoded)
+	if err != nil {
+		return nil, err
+	}
+	data, err := inflate(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing: %w", err)
+	}
+	values, err := deserialize(L, data)
+	if err != nil {
+		return nil, fmt.Errorf("reading: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, errors.New("export string is empty")
+	}
+	transmit, ok := values[0].(*lua.LTable)
+	if !ok {
+		return nil, errors.New("export string holds no aura")
+	}
+	aura, ok := transmit.RawGetString("d").(*lua.LTable)
+	if !ok || auraID(aura) == "" {
+		return nil, errors.New("export string holds no aura")
+	}
+	auras := []*lua.LTable{aura}
+	if children, ok := transmit.RawGetString("c").(*lua.LTable); ok {
+		for i := 1; i <= children.Len(); i++ {
+			if child, ok := children.RawGetInt(i).(*lua.LTable); ok && auraID(child) != "" {
+				auras = append(auras, child)
+			}
+		}
+	}
+	return auras, nil
+}
+
+func auraID(aura *lua.LTable) string {
+	id, _ := aura.RawGetString("id").(lua.LString)
+	return string(id)
+}
+
+// InjectWeakAuras adds the auras of the export strings to the WeakAuras
+// SavedVariables file at path, replacing auras with the same id, and returns
+// their ids. The client must not be running: it would write its own copy over
+// the file at logout.
+func InjectWeakAuras(path string, exports []string) ([]string, error) {
+	f, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	displays := f.Table(weakAurasVar, "displays")
+	var ids []string
+	for i, export := range exports {
+		auras, err := DecodeWeakAuras(f.L, export)
+		if err != nil {
+			return nil, fmt.Errorf("export %d: %w", i+1, err)
+		}
+		for _, aura := range auras {
+			displays.RawSetString(auraID(aura), aura)
+			ids = append(ids, auraID(aura))
+		}
+	}
+	if err := f.Save(path); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
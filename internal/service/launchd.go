@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// labelPrefix namespaces blink's launchd jobs.
+const labelPrefix = "com.github.byteorem."
+
+// Launchd manages launchd agents for the logged-in user on macOS.
+type Launchd struct {
+	AgentDir string // where plists are written, usually ~/Library/LaunchAgents
+	LogDir   string // where agent output goes, usually ~/Library/Logs/blink
+}
+
+// NewLaunchd returns a Launchd using the user's LaunchAgents folder.
+func NewLaunchd() (*Launchd, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Launchd{
+		AgentDir: filepath.Join(home, "Library", "LaunchAgents"),
+		LogDir:   filepath.Join(home, "Library", "Logs", "blink"),
+	}, nil
+}
+
+// Label returns the launchd label of the named service.
+func (l *Launchd) Label(name string) string {
+	return labelPrefix + name
+}
+
+func (l *Launchd) plistPath(name string) string {
+	return filepath.Join(l.AgentDir, l.Label(name)+".plist")
+}
+
+// LogPath returns the file the named service's output is written to.
+func (l *Launchd) LogPath(name string) string {
+	return filepath.Join(l.LogDir, name+".log")
+}
+
+// Plist renders the agent definition for s. The agent starts at login and is
+// restarted if blink exits with an error.
+func (l *Launchd) Plist(s Service) string {
+	var b bytes.Buffer
+	esc := func(v string) string {
+		var e bytes.Buffer
+		_ = xml.EscapeText(&e, []byte(v))
+		return e.String()
+	}
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t<string>%s</string>\n", esc(l.Label(s.Name)))
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, a := range append([]string{s.Exec}, s.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", esc(a))
+	}
+	b.WriteString("\t</array>\n")
+	fmt.Fprintf(&b, "\t<key>WorkingDirectory</key>\n\t<string>%s</string>\n", esc(s.Dir))
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	fmt.Fprintf(&b, "\t<key>StandardOutPath</key>\n\t<string>%s</string>\n", esc(l.LogPath(s.Name)))
+	fmt.Fprintf(&b, "\t<key>StandardErrorPath</key>\n\t<string>%s</string>\n", esc(l.LogPath(s.Name)))
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+func (l *Launchd) domain() string {
+	return "gui/" + strconv.Itoa(os.Getuid())
+}
+
+// Install writes the plist and loads the agent.
+func (l *Launchd) Install(s Service) (string, error) {
+	path := l.plistPath(s.Name)
+	if err := os.MkdirAll(l.AgentDir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(l.LogDir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(l.Plist(s)), 0o644); err != nil {
+		return "", err
+	}
+	// Reinstalling replaces a loaded agent.
+	_, _ = run("launchctl", "bootout", l.domain()+"/"+l.Label(s.Name))
+	if _, err := run("launchctl", "bootstrap", l.domain(), path); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// Status returns launchctl's report for the agent.
+func (l *Launchd) Status(name string) (string, error) {
+	if _, err := os.Stat(l.plistPath(name)); os.IsNotExist(err) {
+		return "", fmt.Errorf("%s is not installed", name)
+	}
+	out, err := run("launchctl", "print", l.domain()+"/"+l.Label(name))
+	if err != nil {
+		return "", fmt.Errorf("%s is installed but not loaded", name)
+	}
+	return out + "\n\nlog: " + l.LogPath(name), nil
+}
+
+// Uninstall unloads the agent and removes its plist.
+func (l *Launchd) Uninstall(name string) error {
+	path := l.plistPath(name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("%s is not installed", name)
+	}
+	_, _ = run("launchctl", "bootout", l.domain()+"/"+l.Label(name))
+	return os.Remove(path)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// taskFolder is the Task Scheduler folder blink's tasks are kept in.
+const taskFolder = `blink\`
+
+// Schtasks manages per-user scheduled tasks on Windows that start blink at logon.
+type Schtasks struct {
+	LogDir string // where task output goes, usually %LOCALAPPDATA%\blink\logs
+	User   string // DOMAIN\user the logon trigger is limited to, if known
+}
+
+// NewSchtasks returns a Schtasks for the current user.
+func NewSchtasks() (*Schtasks, error) {
+	dir, err := os.UserCacheDir() // %LOCALAPPDATA% on Windows
+	if err != nil {
+		return nil, err
+	}
+	st := &Schtasks{LogDir: filepath.Join(dir, "blink", "logs")}
+	if user := os.Getenv("USERNAME"); user != "" {
+		st.User = user
+		if domain := os.Getenv("USERDOMAIN"); domain != "" {
+			st.User = domain + `\` + user
+		}
+	}
+	return st, nil
+}
+
+// LogPath returns the file the named task's output is written to.
+func (st *Schtasks) LogPath(name string) string {
+	return filepath.Join(st.LogDir, name+".log")
+}
+
+// TaskXML renders the task definition for s. blink runs in a headless
+// console so no window opens, writes its output to LogPath, and is
+// restarted a few times if it fails.
+func (st *Schtasks) TaskXML(s Service) string {
+	esc := func(v string) string {
+		var e bytes.Buffer
+		_ = xml.EscapeText(&e, []byte(v))
+		return e.String()
+	}
+	args := append([]string{"--headless", s.Exec, "--log-file", st.LogPath(s.Name)}, s.Args...)
+
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-16\"?>\n")
+	b.WriteString("<Task version=\"1.2\" xmlns=\"http://schemas.microsoft.com/windows/2004/02/mit/task\">\n")
+	fmt.Fprintf(&b, "  <RegistrationInfo>\n    <Description>blink hot-reload for %s</Description>\n  </RegistrationInfo>\n", esc(filepath.Base(s.Dir)))
+	b.WriteString("  <Triggers>\n    <LogonTrigger>\n      <Enabled>true</Enabled>\n")
+	if st.User != "" {
+		fmt.Fprintf(&b, "      <UserId>%s</UserId>\n", esc(st.User))
+	}
+	b.WriteString("    </LogonTrigger>\n  </Triggers>\n")
+	b.WriteString("  <Principals>\n    <Principal id=\"Author\">\n      <LogonType>InteractiveToken</LogonType>\n      <RunLevel>LeastPrivilege</RunLevel>\n    </Principal>\n  </Principals>\n")
+	b.WriteString("  <Settings>\n")
+	b.WriteString("    <MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>\n")
+	b.WriteString("    <DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>\n")
+	b.WriteString("    <StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>\n")
+	b.WriteString("    <ExecutionTimeLimit>PT0S</ExecutionTimeLimit>\n")
+	b.WriteString("    <RestartOnFailure>\n      <Interval>PT1M</Interval>\n      <Count>3</Count>\n    </RestartOnFailure>\n")
+	b.WriteString("  </Settings>\n")
+	b.WriteString("  <Actions Context=\"Author\">\n    <Exec>\n")
+	b.WriteString("      <Command>conhost.exe</Command>\n")
+	fmt.Fprintf(&b, "      <Arguments>%s</Arguments>\n", esc(windowsCommandLine(args)))
+	fmt.Fprintf(&b, "      <WorkingDirectory>%s</WorkingDirectory>\n", esc(s.Dir))
+	b.WriteString("    </Exec>\n  </Actions>\n</Task>\n")
+	return b.String()
+}
+
+// Install registers the task and starts it right away.
+func (st *Schtasks) Install(s Service) (string, error) {
+	if err := os.MkdirAll(st.LogDir, 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp("", "blink-task-*.xml")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	// Task Scheduler expects the definition in UTF-16.
+	_, err = tmp.Write(encodeUTF16(st.TaskXML(s)))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	task := taskFolder + s.Name
+	if _, err := run("schtasks", "/Create", "/TN", task, "/XML", tmp.Name(), "/F"); err != nil {
+		return "", err
+	}
+	if _, err := run("schtasks", "/Run", "/TN", task); err != nil {
+		return "", err
+	}
+	return `Task Scheduler\` + task + ", log: " + st.LogPath(s.Name), nil
+}
+
+// Status returns Task Scheduler's report for the task.
+func (st *Schtasks) Status(name string) (string, error) {
+	out, err := run("schtasks", "/Query", "/TN", taskFolder+name, "/V", "/FO", "LIST")
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed", name)
+	}
+	return out + "\n\nlog: " + st.LogPath(name), nil
+}
+
+// Uninstall stops the task and removes it.
+func (st *Schtasks) Uninstall(name string) error {
+	_, _ = run("schtasks", "/End", "/TN", taskFolder+name)
+	if _, err := run("schtasks", "/Delete", "/TN", taskFolder+name, "/F"); err != nil {
+		return fmt.Errorf("%s is not installed", name)
+	}
+	return nil
+}
+
+// encodeUTF16 encodes s as little-endian UTF-16 with a byte order mark.
+func encodeUTF16(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	buf := make([]byte, 2+2*len(units))
+	buf[0], buf[1] = 0xFF, 0xFE
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(buf[2+2*i:], u)
+	}
+	return buf
+}
+
+// windowsCommandLine quotes args the way CommandLineToArgvW splits them.
+func windowsCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a != "" && !strings.ContainsAny(a, " \t\"") {
+			quoted[i] = a
+			continue
+		}
+		var b strings.Builder
+		b.WriteByte('"')
+		slashes := 0
+		for _, r := range a {
+			switch r {
+			case '\\':
+				slashes++
+			case '"':
+				// The backslashes before a quote are doubled, and one more
+				// escapes the quote; those read are already written.
+				b.WriteString(strings.Repeat(`\`, slashes+1))
+				slashes = 0
+			default:
+				slashes = 0
+			}
+			b.WriteRune(r)
+		}
+		// Backslashes before the closing quote are doubled.
+		b.WriteString(strings.Repeat(`\`, slashes))
+		b.WriteByte('"')
+		quoted[i] = b.String()
+	}
+	return strings.Join(quoted, " ")
+}
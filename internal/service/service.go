@@ -0,0 +1,56 @@
+// Package service installs blink as a background service that watches an
+// addon (or workspace) whenever the user is logged in.
+package service
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Service describes one blink instance run in the background.
+type Service struct {
+	Name string   // service name, e.g. "blink-MyAddon"
+	Dir  string   // working directory: the addon or workspace root
+	Exec string   // absolute path of the blink executable
+	Args []string // arguments passed to blink
+}
+
+// NameFor returns the service name used for the addon or workspace at dir.
+func NameFor(dir string) string {
+	var b strings.Builder
+	for _, r := range filepath.Base(dir) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return "blink-" + b.String()
+}
+
+// Manager installs and controls services with one service manager.
+type Manager interface {
+	// Install writes the service definition, enables it and starts it. It
+	// returns the path of the definition written.
+	Install(s Service) (string, error)
+	// Status returns the service manager's report on the named service.
+	Status(name string) (string, error)
+	// Uninstall stops the named service and removes its definition.
+	Uninstall(name string) error
+}
+
+// run executes a service manager command, returning its combined output.
+func run(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	text := strings.TrimSpace(string(out))
+	if err != nil {
+		if text == "" {
+			return "", fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+		}
+		return text, fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), text)
+	}
+	return text, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameFor(t *testing.T) {
+	tests := map[string]string{
+		"/home/me/src/MyAddon":   "blink-MyAddon",
+		"/home/me/src/Guild UI!": "blink-Guild-UI-",
+		"/src/my_addon.v2":       "blink-my_addon.v2",
+	}
+	for dir, want := range tests {
+		if got := NameFor(dir); got != want {
+			t.Errorf("NameFor(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	sd := &Systemd{UnitDir: t.TempDir()}
+	unit := sd.Unit(Service{
+		Name: "blink-MyAddon",
+		Dir:  "/home/me/src/MyAddon",
+		Exec: "/usr/local/bin/blink",
+		Args: []string{"--wow-path", "/games/World of Warcraft/_retail_"},
+	})
+
+	for _, want := range []string{
+		"Description=blink hot-reload for MyAddon\n",
+		"Type=notify\n",
+		"WorkingDirectory=/home/me/src/MyAddon\n",
+		`ExecStart=/usr/local/bin/blink --wow-path "/games/World of Warcraft/_retail_"` + "\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit missing %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestSystemdCommandLine(t *testing.T) {
+	got := systemdCommandLine([]string{"blink", "100%", `a"b`, "$HOME", ""})
+	want := `blink "100%%" "a\"b" "$$HOME" ""`
+	if got != want {
+		t.Errorf("systemdCommandLine() = %q, want %q", got, want)
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	l := &Launchd{AgentDir: "/Users/me/Library/LaunchAgents", LogDir: "/Users/me/Library/Logs/blink"}
+	plist := l.Plist(Service{
+		Name: "blink-MyAddon",
+		Dir:  "/Users/me/src/My & Addon",
+		Exec: "/opt/homebrew/bin/blink",
+		Args: []string{"--wow-path", "/Applications/World of Warcraft/_retail_"},
+	})
+
+	for _, want := range []string{
+		"<string>com.github.byteorem.blink-MyAddon</string>",
+		"<string>/opt/homebrew/bin/blink</string>\n\t\t<string>--wow-path</string>",
+		"<string>/Users/me/src/My &amp; Addon</string>",
+		"<key>RunAtLoad</key>\n\t<true/>",
+		"<string>/Users/me/Library/Logs/blink/blink-MyAddon.log</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestSchtasksXML(t *testing.T) {
+	st := &Schtasks{LogDir: `C:\Users\me\AppData\Local\blink\logs`, User: `PC\me`}
+	task := st.TaskXML(Service{
+		Name: "blink-MyAddon",
+		Dir:  `C:\src\MyAddon`,
+		Exec: `C:\tools\blink.exe`,
+		Args: []string{"--wow-path", `C:\Program Files\World of Warcraft\_retail_`},
+	})
+
+	for _, want := range []string{
+		"<UserId>PC\\me</UserId>",
+		"<Command>conhost.exe</Command>",
+		`<Arguments>--headless C:\tools\blink.exe --log-file ` + st.LogPath("blink-MyAddon") + ` --wow-path &#34;C:\Program Files\World of Warcraft\_retail_&#34;</Arguments>`,
+		`<WorkingDirectory>C:\src\MyAddon</WorkingDirectory>`,
+	} {
+		if !strings.Contains(task, want) {
+			t.Errorf("task missing %q:\n%s", want, task)
+		}
+	}
+}
+
+func TestWindowsCommandLine(t *testing.T) {
+	got := windowsCommandLine([]string{`C:\a b\blink.exe`, `say "hi"`, `C:\dir\`, ""})
+	want := `"C:\a b\blink.exe" "say \"hi\"" C:\dir\ ""`
+	if got != want {
+		t.Errorf("windowsCommandLine() = %s, want %s", got, want)
+	}
+
+	tests := map[string]string{
+		`a\"b`:                `"a\\\"b"`,
+		`a\\"b`:               `"a\\\\\"b"`,
+		`dir\`:                `dir\`,
+		`C:\path with space\`: `"C:\path with space\\"`,
+		`C:\two\\ slashes\\`:  `"C:\two\\ slashes\\\\"`,
+	}
+	for arg, want := range tests {
+		if got := windowsCommandLine([]string{arg}); got != want {
+			t.Errorf("windowsCommandLine(%s) = %s, want %s", arg, got, want)
+		}
+	}
+}
+
+func TestEncodeUTF16(t *testing.T) {
+	got := encodeUTF16("<é")
+	want := []byte{0xFF, 0xFE, '<', 0, 0xE9, 0}
+	if string(got) != string(want) {
+		t.Errorf("encodeUTF16() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Systemd manages systemd user units.
+type Systemd struct {
+	UnitDir string // where unit files are written, usually ~/.config/systemd/user
+}
+
+// NewSystemd returns a Systemd writing units to the user's unit directory.
+func NewSystemd() (*Systemd, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Systemd{UnitDir: filepath.Join(dir, "systemd", "user")}, nil
+}
+
+// Unit renders the unit file for s. blink runs headless under systemd and
+// reports readiness and watchdog pings itself.
+func (sd *Systemd) Unit(s Service) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=blink hot-reload for %s\n", filepath.Base(s.Dir))
+	b.WriteString("\n[Service]\n")
+	b.WriteString("Type=notify\n")
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", s.Dir)
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdCommandLine(append([]string{s.Exec}, s.Args...)))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("WatchdogSec=60\n")
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+func (sd *Systemd) unitPath(name string) string {
+	return filepath.Join(sd.UnitDir, name+".service")
+}
+
+// Install writes the unit, then enables and starts it.
+func (sd *Systemd) Install(s Service) (string, error) {
+	path := sd.unitPath(s.Name)
+	if err := os.MkdirAll(sd.UnitDir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(sd.Unit(s)), 0o644); err != nil {
+		return "", err
+	}
+	if _, err := run("systemctl", "--user", "daemon-reload"); err != nil {
+		return path, err
+	}
+	if _, err := run("systemctl", "--user", "enable", "--now", s.Name+".service"); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// Status returns systemctl's status report for the unit.
+func (sd *Systemd) Status(name string) (string, error) {
+	if _, err := os.Stat(sd.unitPath(name)); os.IsNotExist(err) {
+		return "", fmt.Errorf("%s is not installed", name)
+	}
+	out, err := run("systemctl", "--user", "status", "--no-pager", name+".service")
+	if out != "" {
+		// systemctl status exits non-zero for stopped units but still reports.
+		return out, nil
+	}
+	return out, err
+}
+
+// Uninstall disables and stops the unit and removes its file.
+func (sd *Systemd) Uninstall(name string) error {
+	path := sd.unitPath(name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("%s is not installed", name)
+	}
+	if _, err := run("systemctl", "--user", "disable", "--now", name+".service"); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	_, err := run("systemctl", "--user", "daemon-reload")
+	return err
+}
+
+// systemdCommandLine quotes args for an ExecStart= line.
+func systemdCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a != "" && !strings.ContainsAny(a, " \t\"'\\$%;") {
+			quoted[i] = a
+			continue
+		}
+		r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", "$$", "%", "%%")
+		quoted[i] = `"` + r.Replace(a) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,216 @@
+// Package snapshot archives addon folders so they can be put back later,
+// e.g. to flip between a release install and a dev build.
+package snapshot
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/byteorem/blink/internal/packager"
+)
+
+const manifestName = "snapshot.json"
+
+// Folder is one addon folder captured in a snapshot.
+type Folder struct {
+	Path    string `json:"path"`
+	Archive string `json:"archive,omitempty"` // zip inside the snapshot; empty when the folder didn't exist
+}
+
+// Snapshot is a set of addon folders captured together.
+type Snapshot struct {
+	Name    string    `json:"-"`
+	Created time.Time `json:"created"`
+	Folders []Folder  `json:"folders"`
+}
+
+// Create captures the folders into a new snapshot stored at root/name. A
+// folder that doesn't exist is recorded as absent, and removed on restore.
+func Create(root, name string, folders []string) (*Snapshot, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(root, name)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("snapshot %q already exists", name)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{Name: name, Created: time.Now()}
+	for i, path := range folders {
+		f := Folder{Path: path}
+		if _, err := os.Stat(path); err == nil {
+			f.Archive = fmt.Sprintf("%d-%s.zip", i, filepath.Base(path))
+			if err := packager.Zip(path, filepath.Join(dir, f.Archive), filepath.Base(path)); err != nil {
+				_ = os.RemoveAll(dir)
+				return nil, err
+			}
+		}
+		snap.Folders = append(snap.Folders, f)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, manifestName), append(data, '\n'), 0o644); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return snap, nil
+}
+
+// checkName refuses a snapshot name that isn't a plain folder name, so a
+// snapshot is never read from, or written to, outside root.
+func checkName(name string) error {
+	if name == "." || strings.ContainsAny(name, `/\`) || !filepath.IsLocal(name) {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return nil
+}
+
+// Load reads the snapshot stored at root/name.
+func Load(root, name string) (*Snapshot, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(root, name, manifestName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no snapshot named %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{Name: name}
+	if err := json.Unmarshal(data, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// List returns the snapshots stored under root, oldest first.
+func List(root string) ([]*Snapshot, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snaps []*Snapshot
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if snap, err := Load(root, e.Name()); err == nil {
+			snaps = append(snaps, snap)
+		}
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Created.Before(snaps[j].Created) })
+	return snaps, nil
+}
+
+// Restore replaces each captured folder with its archived contents.
+func Restore(root string, snap *Snapshot) error {
+	for _, f := range snap.Folders {
+		if f.Archive == "" {
+			if err := os.RemoveAll(f.Path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := restoreFolder(filepath.Join(root, snap.Name, f.Archive), f.Path); err != nil {
+			return fmt.Errorf("restoring %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// restoreFolder replaces dst with the contents of the archive at zipPath.
+// The archive is extracted next to dst first, and dst only swapped out once
+// that worked, so a broken archive leaves dst as it was.
+func restoreFolder(zipPath, dst string) error {
+	parent := filepath.Dir(dst)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(parent, "."+filepath.Base(dst)+".restore-*")
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0o755); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	if err := extract(zipPath, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+
+	old := tmp + ".old"
+	if err := os.Rename(dst, old); err != nil && !os.IsNotExist(err) {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Rename(old, dst)
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+// extract unpacks an archive written by packager.Zip into dst, dropping the
+// top-level folder it was written under.
+func extract(zipPath, dst string) error {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zr.Close() }()
+
+	for _, zf := range zr.File {
+		_, rel, ok := strings.Cut(zf.Name, "/")
+		if !ok || rel == "" || strings.HasSuffix(rel, "/") {
+			continue
+		}
+		path := filepath.Join(dst, filepath.FromSlash(rel))
+		if !strings.HasPrefix(path, filepath.Clean(dst)+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %q escapes the folder", zf.Name)
+		}
+		if err := extractFile(zf, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFile(zf *zip.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	r, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,101 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateRestore(t *testing.T) {
+	root := t.TempDir()
+	addOns := t.TempDir()
+	present := filepath.Join(addOns, "MyAddon")
+	absent := filepath.Join(addOns, "MyAddon_Options")
+
+	_ = os.MkdirAll(filepath.Join(present, "Libs"), 0o755)
+	_ = os.WriteFile(filepath.Join(present, "Core.lua"), []byte("release"), 0o644)
+	_ = os.WriteFile(filepath.Join(present, "Libs", "Lib.lua"), []byte("lib"), 0o644)
+
+	if _, err := Create(root, "release", []string{present, absent}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := Create(root, "release", []string{present}); err == nil {
+		t.Error("expected error creating a snapshot twice")
+	}
+
+	// Switch to a dev build.
+	_ = os.WriteFile(filepath.Join(present, "Core.lua"), []byte("dev"), 0o644)
+	_ = os.WriteFile(filepath.Join(present, "New.lua"), []byte("dev"), 0o644)
+	_ = os.MkdirAll(absent, 0o755)
+
+	snap, err := Load(root, "release")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := Restore(root, snap); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(present, "Core.lua")); string(data) != "release" {
+		t.Errorf("Core.lua = %q, want release", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(present, "Libs", "Lib.lua")); string(data) != "lib" {
+		t.Errorf("Libs/Lib.lua = %q, want lib", data)
+	}
+	if _, err := os.Stat(filepath.Join(present, "New.lua")); !os.IsNotExist(err) {
+		t.Error("New.lua should be gone after restore")
+	}
+	if _, err := os.Stat(absent); !os.IsNotExist(err) {
+		t.Error("folder absent at snapshot time should be removed")
+	}
+
+	snaps, err := List(root)
+	if err != nil || len(snaps) != 1 || snaps[0].Name != "release" {
+		t.Errorf("List() = %v, %v", snaps, err)
+	}
+}
+
+func TestRestore_BrokenArchive(t *testing.T) {
+	root := t.TempDir()
+	addon := filepath.Join(t.TempDir(), "MyAddon")
+	_ = os.MkdirAll(addon, 0o755)
+	_ = os.WriteFile(filepath.Join(addon, "Core.lua"), []byte("release"), 0o644)
+
+	snap, err := Create(root, "release", []string{addon})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_ = os.WriteFile(filepath.Join(addon, "Core.lua"), []byte("dev"), 0o644)
+	_ = os.WriteFile(filepath.Join(root, "release", snap.Folders[0].Archive), []byte("not a zip"), 0o644)
+
+	if err := Restore(root, snap); err == nil {
+		t.Fatal("Restore() of a broken archive: want error")
+	}
+	if data, _ := os.ReadFile(filepath.Join(addon, "Core.lua")); string(data) != "dev" {
+		t.Errorf("Core.lua = %q after a failed restore, want the folder left as it was", data)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(addon)); len(entries) != 1 {
+		t.Errorf("left behind %v, want only MyAddon", entries)
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	if _, err := Load(t.TempDir(), "nope"); err == nil {
+		t.Error("expected error for missing snapshot")
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"", ".", "..", "../x", "a/b", `a\b`} {
+		if _, err := Create(root, name, nil); err == nil {
+			t.Errorf("Create(%q): want error", name)
+		}
+		if _, err := Load(root, name); err == nil {
+			t.Errorf("Load(%q): want error", name)
+		}
+	}
+	if err := checkName("20260101-120000"); err != nil {
+		t.Errorf("checkName() = %v", err)
+	}
+}
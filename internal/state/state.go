@@ -0,0 +1,161 @@
+// Package state keeps blink's record of the addon folders it manages, so they
+// can be found again after the source that produced them is gone.
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+)
+
+const fileName = "targets.json"
+
+// Target is an addon folder blink has synced to.
+type Target struct {
+	Path    string    `json:"path"`
+	Sources []string  `json:"sources"`
+	Synced  time.Time `json:"synced"`
+}
+
+// Store is the set of managed targets recorded in the state directory.
+type Store struct {
+	path    string
+	Targets []Target `json:"targets"`
+	// Packs are the UI pack folders synced outside AddOns, which hold
+	// copies of the client files blink replaced there.
+	Packs []Target `json:"packs,omitempty"`
+}
+
+// Dir returns blink's state directory: $XDG_STATE_HOME/blink or
+// ~/.local/state/blink on Linux, and the user config directory elsewhere.
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "blink"), nil
+	}
+	if runtime.GOOS == "linux" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "state", "blink"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blink"), nil
+}
+
+// Open reads the store in dir. A missing store is empty.
+func Open(dir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dir, fileName)}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Record notes that target was synced from sources just now.
+func (s *Store) Record(target string, sources []string) {
+	s.Forget(target)
+	s.Targets = append(s.Targets, Target{Path: target, Sources: sources, Synced: time.Now()})
+	sort.Slice(s.Targets, func(i, j int) bool { return s.Targets[i].Path < s.Targets[j].Path })
+}
+
+// Forget removes target from the store.
+func (s *Store) Forget(target string) {
+	kept := s.Targets[:0]
+	for _, t := range s.Targets {
+		if t.Path != target {
+			kept = append(kept, t)
+		}
+	}
+	s.Targets = kept
+}
+
+// RecordPack notes that the UI pack folder target was synced from sources
+// just now.
+func (s *Store) RecordPack(target string, sources []string) {
+	s.ForgetPack(target)
+	s.Packs = append(s.Packs, Target{Path: target, Sources: sources, Synced: time.Now()})
+	sort.Slice(s.Packs, func(i, j int) bool { return s.Packs[i].Path < s.Packs[j].Path })
+}
+
+// ForgetPack removes the UI pack folder target from the store.
+func (s *Store) ForgetPack(target string) {
+	kept := s.Packs[:0]
+	for _, t := range s.Packs {
+		if t.Path != target {
+			kept = append(kept, t)
+		}
+	}
+	s.Packs = kept
+}
+
+// Orphans returns the targets whose addon is gone from the source: the
+// addon's own source folder (the first) no longer exists, or a later sync of
+// it went to another folder next to the target, as when the addon was
+// renamed.
+func (s *Store) Orphans() []Target {
+	latest := make(map[[2]string]time.Time) // by AddOns folder and source
+	for _, t := range s.Targets {
+		if len(t.Sources) == 0 {
+			continue
+		}
+		key := [2]string{filepath.Dir(t.Path), t.Sources[0]}
+		if t.Synced.After(latest[key]) {
+			latest[key] = t.Synced
+		}
+	}
+
+	var orphans []Target
+	for _, t := range s.Targets {
+		if len(t.Sources) == 0 {
+			continue
+		}
+		if _, err := os.Stat(t.Sources[0]); os.IsNotExist(err) {
+			orphans = append(orphans, t)
+			continue
+		}
+		if t.Synced.Before(latest[[2]string{filepath.Dir(t.Path), t.Sources[0]}]) {
+			orphans = append(orphans, t)
+		}
+	}
+	return orphans
+}
+
+// Save writes the store back to its directory.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, append(data, '\n'), 0o644)
+}
+
+// Update opens the store in blink's state directory, applies fn and saves it.
+func Update(fn func(*Store)) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	st, err := Open(dir)
+	if err != nil {
+		return err
+	}
+	fn(st)
+	return st.Save()
+}
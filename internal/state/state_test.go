@@ -0,0 +1,78 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(s.Targets) != 0 {
+		t.Fatalf("new store has %d targets", len(s.Targets))
+	}
+
+	s.Record("/wow/AddOns/B", []string{"/src/b"})
+	s.Record("/wow/AddOns/A", []string{"/src/a"})
+	s.Record("/wow/AddOns/B", []string{"/src/b2"})
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(s.Targets) != 2 || s.Targets[0].Path != "/wow/AddOns/A" || s.Targets[1].Sources[0] != "/src/b2" {
+		t.Errorf("Targets = %+v", s.Targets)
+	}
+
+	s.Forget("/wow/AddOns/A")
+	if len(s.Targets) != 1 || s.Targets[0].Path != "/wow/AddOns/B" {
+		t.Errorf("after Forget, Targets = %+v", s.Targets)
+	}
+
+	s.RecordPack("/wow/Fonts", []string{"/src/fonts"})
+	if len(s.Packs) != 1 || len(s.Targets) != 1 {
+		t.Errorf("after RecordPack, Packs = %+v, Targets = %+v", s.Packs, s.Targets)
+	}
+	s.ForgetPack("/wow/Fonts")
+	if len(s.Packs) != 0 {
+		t.Errorf("after ForgetPack, Packs = %+v", s.Packs)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	src := t.TempDir()
+	kept, gone := filepath.Join(src, "Kept"), filepath.Join(src, "Gone")
+	_ = os.Mkdir(kept, 0o755)
+	now := time.Now()
+
+	s := &Store{Targets: []Target{
+		{Path: "/wow/AddOns/Gone", Sources: []string{gone}, Synced: now},
+		{Path: "/wow/AddOns/Kept", Sources: []string{kept}, Synced: now},
+		{Path: "/wow/AddOns/KeptOld", Sources: []string{kept}, Synced: now.Add(-time.Hour)},
+		{Path: "/classic/AddOns/Kept", Sources: []string{kept}, Synced: now.Add(-time.Hour)},
+	}}
+	var got []string
+	for _, o := range s.Orphans() {
+		got = append(got, o.Path)
+	}
+	if len(got) != 2 || got[0] != "/wow/AddOns/Gone" || got[1] != "/wow/AddOns/KeptOld" {
+		t.Errorf("Orphans() = %v", got)
+	}
+}
+
+func TestDir_XDG(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	dir, err := Dir()
+	if err != nil || dir != "/tmp/state/blink" {
+		t.Errorf("Dir() = %q, %v", dir, err)
+	}
+}
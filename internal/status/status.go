@@ -0,0 +1,116 @@
+// Package status writes blink's current state to files that shell prompts
+// and status bars can read.
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State is what blink is currently doing.
+type State string
+
+// States reported in the status file.
+const (
+	StateWatching State = "watching"
+	StatePaused   State = "paused"
+	StateError    State = "error"
+)
+
+// Status is the content of the status file.
+type Status struct {
+	State    State     `json:"state"`
+	Addons   []string  `json:"addons"`
+	LastSync time.Time `json:"lastSync,omitzero"`
+	Pending  int       `json:"pending"` // changes seen but not yet synced
+	Error    string    `json:"error,omitempty"`
+	PID      int       `json:"pid"`
+}
+
+// Line renders the status as a single line, e.g. "✓ MyAddon 14:02:11".
+func (s Status) Line() string {
+	var b strings.Builder
+	switch s.State {
+	case StateError:
+		b.WriteString("✗ ")
+	case StatePaused:
+		b.WriteString("⏸ ")
+	default:
+		b.WriteString("✓ ")
+	}
+	b.WriteString(strings.Join(s.Addons, ","))
+	if !s.LastSync.IsZero() {
+		b.WriteString(" " + s.LastSync.Format("15:04:05"))
+	}
+	if s.Pending > 0 {
+		fmt.Fprintf(&b, " (%d pending)", s.Pending)
+	}
+	if s.State == StateError && s.Error != "" {
+		b.WriteString(": " + s.Error)
+	}
+	return b.String()
+}
+
+// Writer keeps a JSON status file and a one-line text variant next to it
+// (same name, .txt extension) up to date.
+type Writer struct {
+	path string
+	mu   sync.Mutex
+	cur  Status
+}
+
+// NewWriter returns a Writer for the JSON file at path.
+func NewWriter(path string, addons []string) *Writer {
+	return &Writer{path: path, cur: Status{State: StateWatching, Addons: addons, PID: os.Getpid()}}
+}
+
+// TextPath returns the path of the one-line text variant.
+func (w *Writer) TextPath() string {
+	return strings.TrimSuffix(w.path, filepath.Ext(w.path)) + ".txt"
+}
+
+// Update applies fn to the current status and rewrites both files. A nil
+// Writer does nothing, so callers need not check whether status output is on.
+func (w *Writer) Update(fn func(*Status)) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fn(&w.cur)
+	data, err := json.MarshalIndent(w.cur, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return err
+	}
+	if err := writeAtomic(w.path, append(data, '\n')); err != nil {
+		return err
+	}
+	return writeAtomic(w.TextPath(), []byte(w.cur.Line()+"\n"))
+}
+
+// Remove deletes both files, so a stale status isn't shown after blink exits.
+func (w *Writer) Remove() {
+	if w == nil {
+		return
+	}
+	_ = os.Remove(w.path)
+	_ = os.Remove(w.TextPath())
+}
+
+// writeAtomic replaces path so readers never see a partially written file.
+func writeAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
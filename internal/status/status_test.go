@@ -0,0 +1,69 @@
+package status
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLine(t *testing.T) {
+	synced := time.Date(2025, 1, 2, 14, 2, 11, 0, time.Local)
+	tests := []struct {
+		s    Status
+		want string
+	}{
+		{Status{State: StateWatching, Addons: []string{"MyAddon"}}, "✓ MyAddon"},
+		{Status{State: StateWatching, Addons: []string{"A", "B"}, LastSync: synced, Pending: 2}, "✓ A,B 14:02:11 (2 pending)"},
+		{Status{State: StatePaused, Addons: []string{"MyAddon"}, LastSync: synced}, "⏸ MyAddon 14:02:11"},
+		{Status{State: StateError, Addons: []string{"MyAddon"}, Error: "Core.lua: syntax error"}, "✗ MyAddon: Core.lua: syntax error"},
+	}
+	for _, tt := range tests {
+		if got := tt.s.Line(); got != tt.want {
+			t.Errorf("Line() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blink", "status.json")
+	w := NewWriter(path, []string{"MyAddon"})
+
+	if err := w.Update(func(s *Status) { s.Pending = 3 }); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Status
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("status file is not valid JSON: %v", err)
+	}
+	if got.State != StateWatching || got.Pending != 3 || got.PID != os.Getpid() {
+		t.Errorf("status = %+v", got)
+	}
+
+	text, err := os.ReadFile(w.TextPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != "✓ MyAddon (3 pending)\n" {
+		t.Errorf("text = %q", text)
+	}
+
+	w.Remove()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("status file should be removed")
+	}
+}
+
+func TestWriter_Nil(t *testing.T) {
+	var w *Writer
+	if err := w.Update(func(s *Status) { s.Pending = 1 }); err != nil {
+		t.Errorf("nil Update() error = %v", err)
+	}
+	w.Remove()
+}
@@ -0,0 +1,46 @@
+package sync
+
+import (
+	"cmp"
+	"slices"
+)
+
+// FileChurn is how often a file was synced this session, and how many bytes
+// were written for it in all.
+type FileChurn struct {
+	Label   string
+	Changes int
+	Bytes   int64
+}
+
+// recordChurn counts a change synced to the file with the given label.
+func (e *Engine) recordChurn(label string, bytes int64) {
+	e.churnMu.Lock()
+	defer e.churnMu.Unlock()
+	if e.churn == nil {
+		e.churn = make(map[string]*FileChurn)
+	}
+	c, ok := e.churn[label]
+	if !ok {
+		c = &FileChurn{Label: label}
+		e.churn[label] = c
+	}
+	c.Changes++
+	c.Bytes += bytes
+}
+
+// Churn returns the n files synced most often this session, most bytes first
+// among files changed as often. Files that top it are often generated ones
+// worth ignoring, or saved in bursts a longer delay would merge.
+func (e *Engine) Churn(n int) []FileChurn {
+	e.churnMu.Lock()
+	files := make([]FileChurn, 0, len(e.churn))
+	for _, c := range e.churn {
+		files = append(files, *c)
+	}
+	e.churnMu.Unlock()
+	slices.SortFunc(files, func(a, b FileChurn) int {
+		return cmp.Or(cmp.Compare(b.Changes, a.Changes), cmp.Compare(b.Bytes, a.Bytes), cmp.Compare(a.Label, b.Label))
+	})
+	return files[:min(n, len(files))]
+}
@@ -0,0 +1,598 @@
+// Package sync applies watched changes to the synced addon folders. The TUI
+// and the plain text mode both drive an Engine and only differ in how they
+// show its results.
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/byteorem/blink/internal/budget"
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/crash"
+	"github.com/byteorem/blink/internal/gitinfo"
+	"github.com/byteorem/blink/internal/i18n"
+	"github.com/byteorem/blink/internal/lint"
+	"github.com/byteorem/blink/internal/state"
+	"github.com/byteorem/blink/internal/transform"
+	"github.com/byteorem/blink/internal/watcher"
+	"github.com/byteorem/blink/internal/workspace"
+)
+
+// Kind is the outcome of a change.
+type Kind int
+
+const (
+	Synced   Kind = iota // the change was applied
+	Warning              // applied with a warning, or skipped on purpose
+	Failed               // the change could not be applied; worth retrying
+	Held                 // the destination was edited in place; the user picks a side
+	Renamed              // the addon took a new name from its renamed .toc file
+	Renaming             // the addon's renamed .toc gives it a new name; apply it with Rename
+)
+
+// Result is what came of a change to one file.
+type Result struct {
+	Kind  Kind
+	Addon *workspace.Addon
+	Label string         // the file, prefixed with its addon when several are synced
+	Event *watcher.Event // the change, set for watched changes so failures can be retried
+	Held  *Change        // set for Held: the change waiting for the user
+	Both  bool           // Held: the source changed too, seen from an edit in AddOns
+
+	format string
+	args   []any
+}
+
+// Action describes the outcome in English, e.g. "copied", for the status
+// file and changelog styling.
+func (r Result) Action() string {
+	if len(r.args) == 0 {
+		return r.format
+	}
+	return fmt.Sprintf(r.format, r.args...)
+}
+
+// Text describes the outcome in the current language.
+func (r Result) Text() string {
+	if len(r.args) == 0 {
+		return i18n.T(r.format)
+	}
+	return i18n.Tf(r.format, r.args...)
+}
+
+// Change is a file change between a source and a destination.
+type Change struct {
+	RelPath string
+	SrcPath string
+	DstPath string
+}
+
+// Mirror passes on what the engine writes into a target, e.g. to a container.
+// path is a file or a whole addon folder.
+type Mirror interface {
+	Copy(path string) error
+	Remove(path string) error
+}
+
+// Engine syncs watched changes into the addons' targets. It is safe to handle
+// events of different files concurrently. The addons' names, targets and
+// ignorers only change through Rename and Modify, which wait for the changes
+// being handled; call them where the addons are read, e.g. in the TUI's
+// Update.
+type Engine struct {
+	addons    []*workspace.Addon
+	cfg       config.Config
+	transform transform.Func
+	writes    *copier.Tracker
+	mirror    Mirror
+	buildInfo func() gitinfo.Info
+	name      string    // prefixes labels, see Named
+	others    []*Engine // see WithTargets
+
+	buildInfoMu gosync.Mutex
+	transformMu gosync.Mutex
+
+	churnMu gosync.Mutex
+	churn   map[string]*FileChurn // keyed by label
+
+	limits     budget.Limits // see WithBudget
+	budgetMu   gosync.Mutex
+	overBudget map[*workspace.Addon][]budget.Overrun // as last checked
+
+	// addonsMu guards the addons' names, targets and ignorers: handling a
+	// change reads them, Rename and Modify change them.
+	addonsMu gosync.RWMutex
+}
+
+// NewEngine returns an engine syncing changes to addons. tf tailors files to
+// the target's flavor and may be nil; writes tracks the files synced so far,
+// to notice destinations edited in place.
+func NewEngine(addons []*workspace.Addon, cfg config.Config, tf transform.Func, writes *copier.Tracker) *Engine {
+	return &Engine{addons: addons, cfg: cfg, transform: tf, writes: writes}
+}
+
+// WithMirror makes the engine pass every write and removal on to m.
+func (e *Engine) WithMirror(m Mirror) *Engine {
+	e.mirror = m
+	return e
+}
+
+// WithBudget makes the engine check the addons against l after every change
+// synced to them, and warn when one goes over a limit it was within. The
+// limits the addons are over already were warned about at the start.
+func (e *Engine) WithBudget(l budget.Limits) *Engine {
+	e.limits = l
+	e.overBudget = make(map[*workspace.Addon][]budget.Overrun)
+	for _, a := range e.addons {
+		if over, err := budget.Over(a.Sources, l); err == nil {
+			e.overBudget[a] = over
+		}
+	}
+	return e
+}
+
+// SetTransform makes the engine tailor files with tf from now on, e.g. after
+// the session switched to a client of another flavor.
+func (e *Engine) SetTransform(tf transform.Func) {
+	e.transformMu.Lock()
+	defer e.transformMu.Unlock()
+	e.transform = tf
+}
+
+// tf returns the current transform.
+func (e *Engine) tf() transform.Func {
+	e.transformMu.Lock()
+	defer e.transformMu.Unlock()
+	return e.transform
+}
+
+// tfFor returns the transform of the file c syncs: the current transform,
+// then that of its source.
+func (e *Engine) tfFor(a *workspace.Addon, c Change) transform.Func {
+	src, _ := copier.SourceOf(a.Sources, c.SrcPath, c.RelPath)
+	return transform.Chain(e.tf(), src.Transform)
+}
+
+// WithBuildInfo makes the engine write workspace.BuildInfoFile into an
+// addon's target after every change synced to it, describing the checkout
+// head returns.
+func (e *Engine) WithBuildInfo(head func() gitinfo.Info) *Engine {
+	e.buildInfo = head
+	return e
+}
+
+// WriteBuildInfo writes the build info into the targets of addons, if the
+// engine was asked to.
+func (e *Engine) WriteBuildInfo(addons ...*workspace.Addon) error {
+	if e.buildInfo == nil {
+		return nil
+	}
+	e.buildInfoMu.Lock()
+	defer e.buildInfoMu.Unlock()
+	info, now := e.buildInfo(), time.Now()
+	for _, a := range addons {
+		if a.Pack {
+			continue
+		}
+		if err := a.WriteBuildInfo(info, now); err != nil {
+			return err
+		}
+		e.writes.Record(filepath.Join(a.Target, workspace.BuildInfoFile))
+		if err := e.mirrorCopy(filepath.Join(a.Target, workspace.BuildInfoFile)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mirrorCopy passes a written file or folder on to the mirror, if any.
+func (e *Engine) mirrorCopy(path string) error {
+	if e.mirror == nil {
+		return nil
+	}
+	return e.mirror.Copy(path)
+}
+
+// mirrorRemove passes a removal on to the mirror, if any.
+func (e *Engine) mirrorRemove(path string) error {
+	if e.mirror == nil {
+		return nil
+	}
+	return e.mirror.Remove(path)
+}
+
+// Label names a file of addon a in messages, prefixed with the addon's name
+// when several addons are synced.
+func (e *Engine) Label(a *workspace.Addon, relPath string) string {
+	if len(e.addons) > 1 {
+		relPath = filepath.Join(a.Name, relPath)
+	}
+	if e.name != "" {
+		relPath = filepath.Join(e.name, relPath)
+	}
+	return relPath
+}
+
+// Handle applies a watched change and returns what came of it: nothing for
+// changes that don't need syncing, and only a Renaming result when a renamed
+// .toc gives the addon a new name. The results of the engines set with
+// WithTargets follow. ev must not carry an error.
+func (e *Engine) Handle(ev watcher.Event) []Result {
+	results := e.handle(ev)
+	for _, o := range e.others {
+		results = append(results, o.Handle(ev)...)
+	}
+	return results
+}
+
+// handle applies a watched change to the engine's own targets.
+func (e *Engine) handle(ev watcher.Event) []Result {
+	e.addonsMu.RLock()
+	defer e.addonsMu.RUnlock()
+	crash.Note("%s %s", ev.Op, filepath.Join(ev.Root, ev.RelPath))
+	if a, ok := workspace.RouteTarget(e.addons, ev.Root); ok {
+		return only(e.pullBack(a, ev))
+	}
+	a, src, ok := workspace.Route(e.addons, ev.Root)
+	if !ok {
+		return nil
+	}
+	if !a.Pack && a.IsToc(ev.Root, ev.RelPath) {
+		if name, ok := a.TocName(); ok && name != a.Name {
+			// Renaming moves the target, which other changes in flight
+			// may be writing to; Rename waits for them.
+			return []Result{{Kind: Renaming, Addon: a, Label: a.Name, Event: &ev}}
+		}
+	}
+	return e.apply(a, src, ev, nil)
+}
+
+// Rename applies a Renaming result: it renames the addon, moving its target
+// folder along, then syncs the change to the .toc. The Renamed result comes
+// first.
+func (e *Engine) Rename(r Result) []Result {
+	o := e.owner(r.Addon)
+	o.addonsMu.Lock()
+	defer o.addonsMu.Unlock()
+	a, ev := r.Addon, *r.Event
+	var results []Result
+	if r, ok := o.followToc(a); ok {
+		results = append(results, r)
+	}
+	_, src, ok := workspace.Route(o.addons, ev.Root)
+	if !ok {
+		return results
+	}
+	return o.apply(a, src, ev, results)
+}
+
+// Modify calls f, which may change the addons' targets and ignorers, once
+// the changes being handled are applied, and before any other is.
+func (e *Engine) Modify(f func() error) error {
+	e.addonsMu.Lock()
+	defer e.addonsMu.Unlock()
+	return f()
+}
+
+// apply syncs a change in source src of a, appending what came of it to
+// results.
+func (e *Engine) apply(a *workspace.Addon, src copier.Source, ev watcher.Event, results []Result) []Result {
+	if r, ok := e.sync(a, src, ev); ok {
+		r.Event = &ev
+		results = append(results, r)
+	}
+	for _, r := range results {
+		if r.Kind == Synced || r.Kind == Warning || r.Kind == Renamed {
+			if err := e.WriteBuildInfo(a); err != nil {
+				results = append(results, result(a, e.Label(a, workspace.BuildInfoFile), Failed, "error: %v", err))
+			}
+			return append(results, e.checkBudget(a)...)
+		}
+	}
+	return results
+}
+
+// checkBudget warns about the limits a went over since it was last checked.
+func (e *Engine) checkBudget(a *workspace.Addon) []Result {
+	if e.limits.IsZero() || a.Pack {
+		return nil
+	}
+	over, err := budget.Over(a.Sources, e.limits)
+	if err != nil {
+		return []Result{result(a, a.Name, Failed, "checking budget failed: %v", err)}
+	}
+	e.budgetMu.Lock()
+	defer e.budgetMu.Unlock()
+	var results []Result
+	for _, o := range over {
+		if !slices.ContainsFunc(e.overBudget[a], func(p budget.Overrun) bool { return p.Limit == o.Limit && p.File == o.File }) {
+			label := a.Name
+			if o.File != "" {
+				label = e.Label(a, o.File)
+			}
+			results = append(results, result(a, label, Warning, "%s", o.Text))
+		}
+	}
+	e.overBudget[a] = over
+	return results
+}
+
+func only(r Result, ok bool) []Result {
+	if !ok {
+		return nil
+	}
+	return []Result{r}
+}
+
+// Resolve settles a held change, either copying the edited destination back
+// into the source (pull) or overwriting it with the source.
+func (e *Engine) Resolve(a *workspace.Addon, label string, c Change, pull bool) Result {
+	o := e.owner(a)
+	o.addonsMu.RLock()
+	defer o.addonsMu.RUnlock()
+	return o.resolve(a, label, c, pull)
+}
+
+// resolve settles a held change of one of the engine's own addons.
+func (e *Engine) resolve(a *workspace.Addon, label string, c Change, pull bool) Result {
+	if !pull {
+		return e.copyChanged(a, label, c)
+	}
+	if err := e.writes.PullBack(c.DstPath, c.SrcPath, c.RelPath, e.tfFor(a, c)); err != nil {
+		return result(a, label, Failed, "not pulled back: %v", err)
+	}
+	return result(a, label, Synced, "pulled back into source")
+}
+
+// Resync copies the addons' sources over their targets again, regenerating
+// .toc files, and returns the number of files synced. The engines set with
+// WithTargets re-sync their copies of the addons too.
+func (e *Engine) Resync(addons ...*workspace.Addon) (int, error) {
+	e.addonsMu.RLock()
+	total, err := e.resync(addons...)
+	e.addonsMu.RUnlock()
+	if err != nil {
+		return total, err
+	}
+	for _, o := range e.others {
+		if _, err := o.Resync(e.counterparts(o, addons)...); err != nil {
+			return total, fmt.Errorf("%s: %w", o.name, err)
+		}
+	}
+	return total, nil
+}
+
+// resync re-syncs the engine's own targets of addons.
+func (e *Engine) resync(addons ...*workspace.Addon) (int, error) {
+	total := 0
+	for _, a := range addons {
+		crash.Note("re-sync %s", a.Name)
+		count, err := copier.InitialSyncSources(a.Sources, a.Target, e.tf(), nil)
+		total += count
+		if err == nil {
+			_, err = a.GenerateTocs(e.cfg.Toc.Variants())
+		}
+		if err == nil {
+			err = e.mirrorCopy(a.Target)
+		}
+		if err == nil {
+			err = e.WriteBuildInfo(a)
+		}
+		if err != nil {
+			return total, err
+		}
+		_ = e.writes.Scan(a.Target)
+	}
+	return total, nil
+}
+
+func result(a *workspace.Addon, label string, kind Kind, format string, args ...any) Result {
+	return Result{Kind: kind, Addon: a, Label: label, format: format, args: args}
+}
+
+// followToc renames an addon whose .toc file was renamed, moving its target
+// folder along. It reports false when the addon keeps its name.
+func (e *Engine) followToc(a *workspace.Addon) (Result, bool) {
+	oldTarget := a.Target
+	from, err := a.FollowToc(e.cfg.Toc.Variants())
+	if from == "" {
+		return Result{}, false
+	}
+	if err == nil {
+		if err = e.mirrorRemove(oldTarget); err == nil {
+			err = e.mirrorCopy(a.Target)
+		}
+	}
+	if err != nil {
+		return failed(a, from, err), true
+	}
+	dirs := make([]string, len(a.Sources))
+	for i, src := range a.Sources {
+		dirs[i] = src.Dir
+	}
+	_ = state.Update(func(st *state.Store) {
+		st.Forget(oldTarget)
+		st.Record(a.Target, dirs)
+	})
+	return result(a, from, Renamed, "renamed to %s", a.Name), true
+}
+
+// sync applies a change in one of a's sources.
+func (e *Engine) sync(a *workspace.Addon, src copier.Source, ev watcher.Event) (Result, bool) {
+	label := e.Label(a, ev.RelPath)
+	if a.IsTemplate(ev.Root, ev.RelPath) {
+		names, err := a.GenerateTocs(e.cfg.Toc.Variants())
+		if err == nil {
+			err = e.mirrorCopy(a.Target)
+		}
+		if err != nil {
+			return failed(a, label, err), true
+		}
+		return result(a, label, Synced, "generated %d .toc file(s)", len(names)), true
+	}
+	if src.Ignorer.ShouldIgnore(ev.RelPath) {
+		return Result{}, false
+	}
+
+	c := Change{RelPath: ev.RelPath, SrcPath: filepath.Join(ev.Root, ev.RelPath), DstPath: filepath.Join(a.Target, ev.RelPath)}
+	others := copier.OtherProviders(a.Sources, ev.Root, ev.RelPath)
+
+	switch ev.Op {
+	case watcher.OpRemove, watcher.OpRename:
+		if ev.Op == watcher.OpRename {
+			if _, err := os.Stat(c.SrcPath); err == nil {
+				return e.syncChanged(a, label, c)
+			}
+		}
+		return e.removeChanged(a, label, c, others), true
+	default:
+		if vanished(c.SrcPath) {
+			// Already gone again, e.g. during a git checkout.
+			return e.removeChanged(a, label, c, others), true
+		}
+		if len(others) > 0 {
+			return result(a, label, Failed, "conflict, also provided by %s", strings.Join(others, ", ")), true
+		}
+		if copier.BrokenLink(c.SrcPath) {
+			return result(a, label, Warning, "skipped, broken symlink"), true
+		}
+		return e.syncChanged(a, label, c)
+	}
+}
+
+// removeChanged applies the removal of a source file: the copy from another
+// source that still provides it is restored, otherwise the file is deleted
+// from the target.
+func (e *Engine) removeChanged(a *workspace.Addon, label string, c Change, others []string) Result {
+	if len(others) > 0 {
+		// Another source still provides the file; restore its copy.
+		c.SrcPath = filepath.Join(others[0], c.RelPath)
+		r := e.copyChanged(a, label, c)
+		if r.Kind == Synced {
+			r.format, r.args = "copied from %s", []any{others[0]}
+		}
+		return r
+	}
+	if a.Pack {
+		// Outside AddOns, the file blink replaced comes back.
+		_, err := os.Lstat(c.DstPath + copier.OriginalSuffix)
+		restored := err == nil
+		if err := copier.RestoreOriginal(c.DstPath); err != nil {
+			return failed(a, label, err)
+		}
+		e.recordChurn(label, 0)
+		if restored {
+			return result(a, label, Synced, "restored the original")
+		}
+		return result(a, label, Synced, "removed")
+	}
+	err := copier.DeleteFile(c.DstPath)
+	if err == nil {
+		err = e.mirrorRemove(c.DstPath)
+	}
+	if err != nil {
+		return failed(a, label, err)
+	}
+	e.recordChurn(label, 0)
+	return result(a, label, Synced, "removed")
+}
+
+// pullBack copies a file edited in an addon's target back into its source
+// (two-way mode). Changes blink made itself are ignored, as are removals;
+// when the source changed as well, the change is held for the user.
+func (e *Engine) pullBack(a *workspace.Addon, ev watcher.Event) (Result, bool) {
+	if ev.Op == watcher.OpRemove || ev.Op == watcher.OpRename {
+		return Result{}, false
+	}
+	dstPath := filepath.Join(a.Target, ev.RelPath)
+	if e.writes.Written(dstPath) {
+		return Result{}, false
+	}
+	label := e.Label(a, ev.RelPath)
+	c := Change{RelPath: ev.RelPath, SrcPath: a.SourceFile(ev.RelPath), DstPath: dstPath}
+	if e.writes.Changed(c.SrcPath) {
+		r := result(a, label, Held, "changed in both the source and AddOns, not synced")
+		r.Held, r.Both = &c, true
+		return r, true
+	}
+	r := e.resolve(a, label, c, true)
+	r.Event = &ev
+	return r, true
+}
+
+// syncChanged copies a changed source file unless its destination was edited
+// since blink last wrote it, in which case the change is held.
+func (e *Engine) syncChanged(a *workspace.Addon, label string, c Change) (Result, bool) {
+	if e.cfg.TwoWay && e.writes.Written(c.SrcPath) {
+		// The source was just written by a pull-back.
+		return Result{}, false
+	}
+	if e.writes.Changed(c.DstPath) {
+		r := result(a, label, Held, "edited in AddOns since the last sync, not overwritten")
+		r.Held = &c
+		return r, true
+	}
+	return e.copyChanged(a, label, c), true
+}
+
+// copyChanged copies a changed file, checking Lua syntax first when enabled.
+func (e *Engine) copyChanged(a *workspace.Addon, label string, c Change) Result {
+	var syntaxErr error
+	if e.cfg.SyntaxCheck && lint.IsLua(c.SrcPath) {
+		syntaxErr = lint.CheckSyntax(c.SrcPath)
+		if syntaxErr != nil && vanished(c.SrcPath) {
+			return e.removeChanged(a, label, c, nil)
+		}
+		if syntaxErr != nil && e.cfg.SkipInvalidLua {
+			return result(a, label, Warning, "skipped, %v", syntaxErr)
+		}
+	}
+	if a.Pack {
+		if err := copier.KeepOriginal(c.DstPath); err != nil {
+			return failed(a, label, err)
+		}
+	}
+	if err := copier.CopyFileWith(c.SrcPath, c.DstPath, c.RelPath, e.tfFor(a, c)); err != nil {
+		if vanished(c.SrcPath) {
+			// Deleted between the event and the copy.
+			return e.removeChanged(a, label, c, nil)
+		}
+		return failed(a, label, err)
+	}
+	e.writes.Record(c.DstPath)
+	e.writes.Record(c.SrcPath)
+	if err := e.mirrorCopy(c.DstPath); err != nil {
+		return failed(a, label, err)
+	}
+	if info, err := os.Stat(c.DstPath); err == nil {
+		e.recordChurn(label, info.Size())
+	}
+	if syntaxErr != nil {
+		return result(a, label, Warning, "copied, %v", syntaxErr)
+	}
+	return result(a, label, Synced, "copied")
+}
+
+// failed is the result of a change that couldn't be applied. A denied write
+// gets a hint instead of the OS error, which says the same for every file.
+func failed(a *workspace.Addon, label string, err error) Result {
+	if errors.Is(err, fs.ErrPermission) {
+		return result(a, label, Failed, "access denied: run blink as administrator or make %s writable", filepath.Dir(a.Target))
+	}
+	return result(a, label, Failed, "error: %v", err)
+}
+
+// vanished reports whether path no longer exists.
+func vanished(path string) bool {
+	_, err := os.Lstat(path)
+	return os.IsNotExist(err)
+}
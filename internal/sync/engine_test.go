@@ -0,0 +1,426 @@
+package sync
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	"github.com/byteorem/blink/internal/budget"
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/gitinfo"
+	"github.com/byteorem/blink/internal/watcher"
+	"github.com/byteorem/blink/internal/workspace"
+)
+
+// newEngine returns an engine syncing an addon with the given sources (the
+// first being its own) to an empty target.
+func newEngine(t *testing.T, sources ...string) (*Engine, *workspace.Addon) {
+	t.Helper()
+	a := &workspace.Addon{Name: "MyAddon", Target: filepath.Join(t.TempDir(), "MyAddon")}
+	for _, dir := range sources {
+		a.Sources = append(a.Sources, copier.Source{Dir: dir, Ignorer: copier.NewIgnorer(dir, nil, false, false, false)})
+	}
+	return NewEngine([]*workspace.Addon{a}, config.Defaults(), nil, copier.NewTracker()), a
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	_ = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func handleOne(t *testing.T, e *Engine, ev watcher.Event) Result {
+	t.Helper()
+	results := e.Handle(ev)
+	if len(results) != 1 {
+		t.Fatalf("Handle(%v) = %d results, want 1", ev, len(results))
+	}
+	return results[0]
+}
+
+func TestHandle_CopyAndRemove(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+
+	r := handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+	if r.Kind != Synced || r.Action() != "copied" || r.Event == nil {
+		t.Errorf("write: %v %q, event %v", r.Kind, r.Action(), r.Event)
+	}
+	if _, err := os.Stat(filepath.Join(a.Target, "Core.lua")); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.Remove(filepath.Join(src, "Core.lua"))
+	r = handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpRemove})
+	if r.Kind != Synced || r.Action() != "removed" {
+		t.Errorf("remove: %v %q", r.Kind, r.Action())
+	}
+	if _, err := os.Stat(filepath.Join(a.Target, "Core.lua")); !os.IsNotExist(err) {
+		t.Errorf("Core.lua still in the target: %v", err)
+	}
+}
+
+func TestHandle_VanishedFileIsRemoved(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+	handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+
+	// The file is deleted before its write event is handled.
+	_ = os.Remove(filepath.Join(src, "Core.lua"))
+	r := handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+	if r.Kind != Synced || r.Action() != "removed" {
+		t.Errorf("write of a vanished file: %v %q", r.Kind, r.Action())
+	}
+	if _, err := os.Stat(filepath.Join(a.Target, "Core.lua")); !os.IsNotExist(err) {
+		t.Errorf("Core.lua still in the target: %v", err)
+	}
+}
+
+func TestHandle_PackKeepsOriginals(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	a.Pack = true
+	a.Sources[0].KeepOriginals = true
+	dst := filepath.Join(a.Target, "FRIZQT__.TTF")
+	write(t, dst, "client font")
+	write(t, filepath.Join(src, "FRIZQT__.TTF"), "pack font")
+
+	r := handleOne(t, e, watcher.Event{Root: src, RelPath: "FRIZQT__.TTF", Op: watcher.OpWrite})
+	if r.Kind != Synced || r.Action() != "copied" {
+		t.Fatalf("write: %v %q", r.Kind, r.Action())
+	}
+	if data, _ := os.ReadFile(dst + copier.OriginalSuffix); string(data) != "client font" {
+		t.Errorf("original = %q", data)
+	}
+
+	_ = os.Remove(filepath.Join(src, "FRIZQT__.TTF"))
+	r = handleOne(t, e, watcher.Event{Root: src, RelPath: "FRIZQT__.TTF", Op: watcher.OpRemove})
+	if r.Kind != Synced || r.Action() != "restored the original" {
+		t.Errorf("remove: %v %q", r.Kind, r.Action())
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "client font" {
+		t.Errorf("FRIZQT__.TTF = %q after removal", data)
+	}
+	if _, err := os.Stat(dst + copier.OriginalSuffix); !os.IsNotExist(err) {
+		t.Errorf("original still kept: %v", err)
+	}
+}
+
+func TestFailed_AccessDenied(t *testing.T) {
+	a := &workspace.Addon{Name: "MyAddon", Target: filepath.Join("AddOns", "MyAddon")}
+	err := &fs.PathError{Op: "open", Path: filepath.Join(a.Target, "Core.lua"), Err: fs.ErrPermission}
+	r := failed(a, "Core.lua", err)
+	if r.Kind != Failed || !strings.HasPrefix(r.Action(), "access denied") || !strings.Contains(r.Action(), "AddOns") {
+		t.Errorf("failed(denied) = %v %q", r.Kind, r.Action())
+	}
+	if r := failed(a, "Core.lua", errors.New("disk full")); r.Action() != "error: disk full" {
+		t.Errorf("failed(other) = %q", r.Action())
+	}
+}
+
+func TestChurn(t *testing.T) {
+	src := t.TempDir()
+	e, _ := newEngine(t, src)
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+	write(t, filepath.Join(src, "Util.lua"), "local x = 1")
+	for range 3 {
+		handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+	}
+	handleOne(t, e, watcher.Event{Root: src, RelPath: "Util.lua", Op: watcher.OpWrite})
+
+	got := e.Churn(10)
+	want := []FileChurn{{"Core.lua", 3, 24}, {"Util.lua", 1, 11}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Churn() = %v, want %v", got, want)
+	}
+	if got := e.Churn(1); len(got) != 1 || got[0].Label != "Core.lua" {
+		t.Errorf("Churn(1) = %v", got)
+	}
+}
+
+func TestHandle_RenameOfExistingFileCopies(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+
+	r := handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpRename})
+	if r.Kind != Synced || r.Action() != "copied" {
+		t.Errorf("rename: %v %q", r.Kind, r.Action())
+	}
+	if _, err := os.Stat(filepath.Join(a.Target, "Core.lua")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandle_RemoveRestoresOtherSource(t *testing.T) {
+	common, own := t.TempDir(), t.TempDir()
+	e, a := newEngine(t, own, common)
+	write(t, filepath.Join(common, "Util.lua"), "-- common")
+
+	r := handleOne(t, e, watcher.Event{Root: own, RelPath: "Util.lua", Op: watcher.OpRemove})
+	if r.Kind != Synced || r.Action() != "copied from "+common {
+		t.Errorf("remove: %v %q", r.Kind, r.Action())
+	}
+	if data, _ := os.ReadFile(filepath.Join(a.Target, "Util.lua")); string(data) != "-- common" {
+		t.Errorf("Util.lua = %q", data)
+	}
+}
+
+func TestHandle_HeldWhenDestinationEdited(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+	handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+
+	dst := filepath.Join(a.Target, "Core.lua")
+	write(t, dst, "print('edited in game')")
+	write(t, filepath.Join(src, "Core.lua"), "print(2)")
+	r := handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+	if r.Kind != Held || r.Held == nil {
+		t.Fatalf("write over edit: %v %q", r.Kind, r.Action())
+	}
+
+	r = e.Resolve(r.Addon, r.Label, *r.Held, false)
+	if r.Kind != Synced {
+		t.Errorf("Resolve: %v %q", r.Kind, r.Action())
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "print(2)" {
+		t.Errorf("Core.lua = %q after overwriting", data)
+	}
+}
+
+func TestHandle_SkipInvalidLua(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	e.cfg.SkipInvalidLua = true
+	write(t, filepath.Join(src, "Core.lua"), "local = 1")
+
+	// A skip on purpose is a warning, not a failure to retry.
+	r := handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+	if r.Kind != Warning {
+		t.Errorf("invalid Lua: %v %q", r.Kind, r.Action())
+	}
+	if _, err := os.Stat(filepath.Join(a.Target, "Core.lua")); !os.IsNotExist(err) {
+		t.Errorf("invalid Core.lua was copied: %v", err)
+	}
+}
+
+func TestHandle_IgnoredAndUnknownRoots(t *testing.T) {
+	src := t.TempDir()
+	e, _ := newEngine(t, src)
+	write(t, filepath.Join(src, ".git", "HEAD"), "ref")
+
+	if r := e.Handle(watcher.Event{Root: src, RelPath: filepath.Join(".git", "HEAD"), Op: watcher.OpWrite}); r != nil {
+		t.Errorf("ignored file: %v", r)
+	}
+	if r := e.Handle(watcher.Event{Root: t.TempDir(), RelPath: "x.lua", Op: watcher.OpWrite}); r != nil {
+		t.Errorf("unknown root: %v", r)
+	}
+}
+
+// recordingMirror notes the paths passed on to it.
+type recordingMirror struct{ copied, removed []string }
+
+func (m *recordingMirror) Copy(path string) error   { m.copied = append(m.copied, path); return nil }
+func (m *recordingMirror) Remove(path string) error { m.removed = append(m.removed, path); return nil }
+
+func TestHandle_Mirror(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	m := &recordingMirror{}
+	e.WithMirror(m)
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+	dst := filepath.Join(a.Target, "Core.lua")
+
+	handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+	_ = os.Remove(filepath.Join(src, "Core.lua"))
+	handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpRemove})
+
+	if len(m.copied) != 1 || m.copied[0] != dst || len(m.removed) != 1 || m.removed[0] != dst {
+		t.Errorf("copied %v, removed %v, want %s each", m.copied, m.removed, dst)
+	}
+}
+
+func TestHandle_Targets(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	other, b := newEngine(t, src)
+	e.WithTargets(other.Named("_classic_era_"))
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+
+	results := e.Handle(watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+	var labels []string
+	for _, r := range results {
+		labels = append(labels, r.Label)
+	}
+	want := []string{"Core.lua", filepath.Join("_classic_era_", "Core.lua")}
+	if !slices.Equal(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+	for _, dir := range []string{a.Target, b.Target} {
+		if _, err := os.Stat(filepath.Join(dir, "Core.lua")); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Re-syncing an addon re-syncs its copy in the other target as well.
+	_ = os.RemoveAll(b.Target)
+	if _, err := e.Resync(a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(b.Target, "Core.lua")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandle_BuildInfo(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	e.WithBuildInfo(func() gitinfo.Info { return gitinfo.Info{Commit: "1a2b3c4", Branch: "main"} })
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+
+	handleOne(t, e, watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+	data, err := os.ReadFile(filepath.Join(a.Target, workspace.BuildInfoFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `commit = "1a2b3c4"`) {
+		t.Errorf("%s = %s", workspace.BuildInfoFile, data)
+	}
+}
+
+func TestHandle_SourceTransform(t *testing.T) {
+	own, lib := t.TempDir(), t.TempDir()
+	e, a := newEngine(t, own, lib)
+	for i, note := range []string{"-- own\n", "-- lib\n"} {
+		a.Sources[i].Transform = func(_ string, data []byte) ([]byte, error) {
+			return append([]byte(note), data...), nil
+		}
+	}
+	write(t, filepath.Join(own, "Core.lua"), "print(1)")
+	write(t, filepath.Join(lib, "Lib.lua"), "print(2)")
+
+	handleOne(t, e, watcher.Event{Root: own, RelPath: "Core.lua", Op: watcher.OpWrite})
+	handleOne(t, e, watcher.Event{Root: lib, RelPath: "Lib.lua", Op: watcher.OpWrite})
+	for name, want := range map[string]string{"Core.lua": "-- own\nprint(1)", "Lib.lua": "-- lib\nprint(2)"} {
+		if data, _ := os.ReadFile(filepath.Join(a.Target, name)); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+}
+
+func TestHandle_Budget(t *testing.T) {
+	src := t.TempDir()
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+	e, _ := newEngine(t, src)
+	e.WithBudget(budget.Limits{Files: 1, FileBytes: 16})
+
+	// Going over a limit is warned about once, when the change doing it is
+	// synced.
+	write(t, filepath.Join(src, "Art.tga"), strings.Repeat("x", 32))
+	results := e.Handle(watcher.Event{Root: src, RelPath: "Art.tga", Op: watcher.OpCreate})
+	var warnings []string
+	for _, r := range results[1:] {
+		if r.Kind == Warning {
+			warnings = append(warnings, r.Label+": "+r.Action())
+		}
+	}
+	want := []string{"MyAddon: 2 files synced, over the budget of 1", "Art.tga: Art.tga is 32 B, over the file budget of 16 B"}
+	if len(results) == 0 || results[0].Action() != "copied" || !slices.Equal(warnings, want) {
+		t.Errorf("Handle() = %v, want a copy and the warnings %q", results, want)
+	}
+
+	write(t, filepath.Join(src, "Art.tga"), strings.Repeat("x", 48))
+	if r := handleOne(t, e, watcher.Event{Root: src, RelPath: "Art.tga", Op: watcher.OpWrite}); r.Action() != "copied" {
+		t.Errorf("Handle() = %v %q, want no warning again", r.Kind, r.Action())
+	}
+}
+
+func TestHandle_TocRename(t *testing.T) {
+	src := t.TempDir()
+	e, a := newEngine(t, src)
+	write(t, filepath.Join(src, "MyAddon.toc"), "## Title: MyAddon\n")
+	write(t, filepath.Join(src, "Core.lua"), "print(1)")
+	if _, err := e.Resync(a); err != nil {
+		t.Fatal(err)
+	}
+	oldTarget := a.Target
+
+	if err := os.Rename(filepath.Join(src, "MyAddon.toc"), filepath.Join(src, "NewName.toc")); err != nil {
+		t.Fatal(err)
+	}
+	ev := watcher.Event{Root: src, RelPath: "NewName.toc", Op: watcher.OpCreate}
+	r := handleOne(t, e, ev)
+	if r.Kind != Renaming || a.Name != "MyAddon" || a.Target != oldTarget {
+		t.Fatalf("Handle() = %v, addon %s at %s; want Renaming and nothing changed yet", r.Kind, a.Name, a.Target)
+	}
+
+	// Changes of other files may be in flight while the rename is applied;
+	// run with -race.
+	var wg gosync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				e.Handle(watcher.Event{Root: src, RelPath: "Core.lua", Op: watcher.OpWrite})
+			}
+		}()
+	}
+	results := e.Rename(r)
+	wg.Wait()
+
+	if len(results) != 2 || results[0].Kind != Renamed || results[1].Kind != Synced {
+		t.Fatalf("Rename() = %+v, want Renamed then Synced", results)
+	}
+	if a.Name != "NewName" || a.Target != filepath.Join(filepath.Dir(oldTarget), "NewName") {
+		t.Errorf("addon now %s at %s", a.Name, a.Target)
+	}
+	for _, name := range []string{"NewName.toc", "Core.lua"} {
+		if _, err := os.Stat(filepath.Join(a.Target, name)); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := os.Stat(oldTarget); !os.IsNotExist(err) {
+		t.Errorf("old target still there: %v", err)
+	}
+}
+
+func TestResync_TargetsInAnotherOrder(t *testing.T) {
+	srcA, srcB := t.TempDir(), t.TempDir()
+	write(t, filepath.Join(srcA, "A.lua"), "print(1)")
+	write(t, filepath.Join(srcB, "B.lua"), "print(2)")
+	addon := func(name, src string) *workspace.Addon {
+		return &workspace.Addon{
+			Name:    name,
+			Sources: []copier.Source{{Dir: src, Ignorer: copier.NewIgnorer(src, nil, false, false, false)}},
+			Target:  filepath.Join(t.TempDir(), name),
+		}
+	}
+	a, b := addon("A", srcA), addon("B", srcB)
+	otherB, otherA := addon("B", srcB), addon("A", srcA)
+	e := NewEngine([]*workspace.Addon{a, b}, config.Defaults(), nil, copier.NewTracker())
+	// The other target's addons come in another order, e.g. filtered
+	// apart from the first's.
+	e.WithTargets(NewEngine([]*workspace.Addon{otherB, otherA}, config.Defaults(), nil, copier.NewTracker()).Named("_classic_era_"))
+
+	if _, err := e.Resync(a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(otherA.Target, "A.lua")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(otherB.Target); !os.IsNotExist(err) {
+		t.Errorf("re-synced B in the other target: %v", err)
+	}
+}
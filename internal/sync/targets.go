@@ -0,0 +1,50 @@
+package sync
+
+import (
+	"slices"
+
+	"github.com/byteorem/blink/internal/workspace"
+)
+
+// WithTargets makes the engine pass every change on to others after
+// applying it: engines syncing the same addons into other targets, e.g. the
+// AddOns folders of other WoW clients. Name them with Named to tell their
+// results apart.
+func (e *Engine) WithTargets(others ...*Engine) *Engine {
+	e.others = append(e.others, others...)
+	return e
+}
+
+// Named makes the engine prefix the labels of its results with name, e.g.
+// the client folder "_classic_era_" it syncs to.
+func (e *Engine) Named(name string) *Engine {
+	e.name = name
+	return e
+}
+
+// owner returns the engine, of e and the engines it passes changes on to,
+// that syncs a.
+func (e *Engine) owner(a *workspace.Addon) *Engine {
+	for _, o := range e.others {
+		if slices.Contains(o.addons, a) {
+			return o
+		}
+	}
+	return e
+}
+
+// counterparts returns the addons of o that sync the same sources as addons,
+// found by their own source folder, or else by name.
+func (e *Engine) counterparts(o *Engine, addons []*workspace.Addon) []*workspace.Addon {
+	var found []*workspace.Addon
+	for _, a := range addons {
+		i := slices.IndexFunc(o.addons, func(c *workspace.Addon) bool { return c.Dir() == a.Dir() })
+		if i < 0 {
+			i = slices.IndexFunc(o.addons, func(c *workspace.Addon) bool { return c.Name == a.Name })
+		}
+		if i >= 0 {
+			found = append(found, o.addons[i])
+		}
+	}
+	return found
+}
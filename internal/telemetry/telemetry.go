@@ -0,0 +1,155 @@
+// Package telemetry sends an anonymous usage report at the end of a watch
+// session, for users who opted in. The report holds no paths, names or
+// error messages: only the platform, a rough addon size and how many errors
+// of each broad kind happened.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fileName = "telemetry.json"
+
+// Report is everything sent.
+type Report struct {
+	Version    string         `json:"version"`
+	OS         string         `json:"os"`
+	Arch       string         `json:"arch"`
+	Watcher    string         `json:"watcher"`    // file notification backend
+	AddonSize  string         `json:"addonSize"`  // bucket of the synced file count, see SizeBucket
+	Errors     map[string]int `json:"errors"`     // count per category, see Category
+	SessionMin int            `json:"sessionMin"` // length of the watch session in minutes
+}
+
+type settings struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Enabled reports whether the user opted in, in blink's state directory dir.
+// DO_NOT_TRACK=1 turns telemetry off regardless.
+func Enabled(dir string) bool {
+	if v := os.Getenv("DO_NOT_TRACK"); v != "" && v != "0" {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		return false
+	}
+	var s settings
+	return json.Unmarshal(data, &s) == nil && s.Enabled
+}
+
+// SetEnabled records the user's choice in dir.
+func SetEnabled(dir string, enabled bool) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(settings{Enabled: enabled})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, fileName), append(data, '\n'), 0o644)
+}
+
+// SizeBucket places a synced file count in a coarse bucket.
+func SizeBucket(files int) string {
+	switch {
+	case files < 50:
+		return "<50"
+	case files < 200:
+		return "50-199"
+	case files < 1000:
+		return "200-999"
+	}
+	return "1000+"
+}
+
+// Category sorts an error message into a broad kind, so no message text (or
+// path in it) is reported.
+func Category(msg string) string {
+	msg = strings.ToLower(msg)
+	switch {
+	case strings.Contains(msg, "permission denied"), strings.Contains(msg, "access is denied"):
+		return "permission"
+	case strings.Contains(msg, "no such file"), strings.Contains(msg, "cannot find"):
+		return "not_found"
+	case strings.Contains(msg, "no space left"), strings.Contains(msg, "disk full"):
+		return "disk_full"
+	case strings.Contains(msg, "being used by another process"), strings.Contains(msg, "resource busy"):
+		return "locked"
+	case strings.Contains(msg, "syntax"), strings.Contains(msg, "parse"):
+		return "lua_syntax"
+	case strings.Contains(msg, "conflict"):
+		return "conflict"
+	}
+	return "other"
+}
+
+var (
+	mu        sync.Mutex
+	errCounts = map[string]int{}
+)
+
+// RecordError counts a failed sync for the session's report.
+func RecordError(msg string) {
+	mu.Lock()
+	defer mu.Unlock()
+	errCounts[Category(msg)]++
+}
+
+// NewReport returns the report for a session that started at start and
+// synced files files, with the errors recorded so far.
+func NewReport(version, watcher string, files int, start time.Time) Report {
+	mu.Lock()
+	defer mu.Unlock()
+	counts := make(map[string]int, len(errCounts))
+	for k, v := range errCounts {
+		counts[k] = v
+	}
+	return Report{
+		Version:    version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Watcher:    watcher,
+		AddonSize:  SizeBucket(files),
+		Errors:     counts,
+		SessionMin: int(time.Since(start).Minutes()),
+	}
+}
+
+// Send posts r to endpoint. Builds without an endpoint send nothing.
+func Send(ctx context.Context, endpoint string, r Report) error {
+	if endpoint == "" {
+		return nil
+	}
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry: %s", resp.Status)
+	}
+	return nil
+}
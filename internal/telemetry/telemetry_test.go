@@ -0,0 +1,72 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEnabled(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DO_NOT_TRACK", "")
+	if Enabled(dir) {
+		t.Error("Enabled() = true before opting in")
+	}
+	if err := SetEnabled(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if !Enabled(dir) {
+		t.Error("Enabled() = false after opting in")
+	}
+	t.Setenv("DO_NOT_TRACK", "1")
+	if Enabled(dir) {
+		t.Error("Enabled() = true with DO_NOT_TRACK=1")
+	}
+	t.Setenv("DO_NOT_TRACK", "")
+	_ = SetEnabled(dir, false)
+	if Enabled(dir) {
+		t.Error("Enabled() = true after opting out")
+	}
+}
+
+func TestCategory(t *testing.T) {
+	tests := map[string]string{
+		"Core.lua: error: open /x/Core.lua: permission denied":                                               "permission",
+		"error: open C:\\x: The process cannot access the file because it is being used by another process.": "locked",
+		"error: write /x: no space left on device":                                                           "disk_full",
+		"Core.lua: skipped, Core.lua:3: syntax error near 'end'":                                             "lua_syntax",
+		"Util.lua: conflict, also provided by ../Common":                                                     "conflict",
+		"something odd": "other",
+	}
+	for msg, want := range tests {
+		if got := Category(msg); got != want {
+			t.Errorf("Category(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	var got Report
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	RecordError("open x: permission denied")
+	RecordError("open y: permission denied")
+	r := NewReport("v1.2.0", "inotify", 120, time.Now().Add(-5*time.Minute))
+	if err := Send(context.Background(), srv.URL, r); err != nil {
+		t.Fatal(err)
+	}
+	if got.AddonSize != "50-199" || got.Errors["permission"] != 2 || got.SessionMin != 5 || got.Watcher != "inotify" {
+		t.Errorf("sent %+v", got)
+	}
+
+	// Without an endpoint nothing is sent.
+	if err := Send(context.Background(), "", r); err != nil {
+		t.Errorf("Send without endpoint = %v", err)
+	}
+}
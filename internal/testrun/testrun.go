@@ -0,0 +1,184 @@
+// Package testrun runs an addon's Lua test suite (busted or luaunit).
+package testrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// specDirs are the directories searched for test files, in order.
+var specDirs = []string{"spec", "specs", "test", "tests"}
+
+// Runner runs the test suite of an addon.
+type Runner struct {
+	Command string // e.g. "busted" or "lua tests/run.lua"
+	Mocks   string // WoW API mocks loaded before the tests, relative to Dir
+	Dir     string // addon source directory
+}
+
+// Result summarises one test run.
+type Result struct {
+	Passed   int
+	Failed   int
+	OK       bool
+	Duration time.Duration
+	Output   string // combined output, kept for failed runs
+}
+
+func (r Result) String() string {
+	if r.Passed == 0 && r.Failed == 0 {
+		if r.OK {
+			return "passed"
+		}
+		return "failed"
+	}
+	if r.Failed > 0 {
+		return fmt.Sprintf("%d failed, %d passed", r.Failed, r.Passed)
+	}
+	return fmt.Sprintf("%d passed", r.Passed)
+}
+
+// IsBusted reports whether the runner invokes busted.
+func (r *Runner) IsBusted() bool {
+	fields := strings.Fields(r.Command)
+	return len(fields) > 0 && strings.TrimSuffix(filepath.Base(fields[0]), ".bat") == "busted"
+}
+
+func (r *Runner) command(ctx context.Context, jsonOutput bool, specs []string) (*exec.Cmd, error) {
+	fields := strings.Fields(r.Command)
+	if len(fields) == 0 {
+		return nil, errors.New("no test command configured — set test.command in blink.toml")
+	}
+
+	args := fields[1:]
+	env := os.Environ()
+	if r.IsBusted() {
+		if jsonOutput {
+			args = append(args, "--output=json")
+		}
+		if r.Mocks != "" {
+			args = append(args, "--helper="+r.Mocks)
+		}
+	} else if r.Mocks != "" {
+		// Plain Lua runners pick the mocks up through the interpreter's init hook.
+		env = append(env, "LUA_INIT=@"+filepath.Join(r.Dir, r.Mocks))
+	}
+	args = append(args, specs...)
+
+	cmd := exec.CommandContext(ctx, fields[0], args...)
+	cmd.Dir = r.Dir
+	cmd.Env = env
+	return cmd, nil
+}
+
+// Stream runs the given specs (or the whole suite when none are given),
+// writing the runner's own output to w.
+func (r *Runner) Stream(ctx context.Context, w io.Writer, specs ...string) error {
+	cmd, err := r.command(ctx, false, specs)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	return cmd.Run()
+}
+
+// Run runs the given specs (or the whole suite when none are given) and
+// captures the result. A failing suite is reported in the Result, not as an error.
+func (r *Runner) Run(ctx context.Context, specs ...string) (Result, error) {
+	cmd, err := r.command(ctx, true, specs)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+	res := Result{Duration: time.Since(start), OK: runErr == nil}
+
+	var execErr *exec.Error
+	if errors.As(runErr, &execErr) {
+		return res, fmt.Errorf("test command not found: %w", runErr)
+	}
+
+	if r.IsBusted() {
+		parseBusted(stdout.Bytes(), &res)
+	}
+	if !res.OK {
+		res.Output = strings.TrimSpace(stdout.String() + "\n" + stderr.String())
+	}
+	return res, nil
+}
+
+// bustedJSON is the subset of busted's json output handler that blink reads.
+type bustedJSON struct {
+	Successes []json.RawMessage `json:"successes"`
+	Failures  []json.RawMessage `json:"failures"`
+	Errors    []json.RawMessage `json:"errors"`
+}
+
+func parseBusted(out []byte, res *Result) {
+	// busted may print stray lines (e.g. from print calls in tests) before the report.
+	start := bytes.LastIndex(out, []byte("\n{"))
+	if start < 0 {
+		start = 0
+	}
+	var bj bustedJSON
+	if err := json.Unmarshal(bytes.TrimSpace(out[start:]), &bj); err != nil {
+		return
+	}
+	res.Passed = len(bj.Successes)
+	res.Failed = len(bj.Failures) + len(bj.Errors)
+	res.OK = res.Failed == 0
+}
+
+// IsSpec reports whether relPath looks like a test file.
+func IsSpec(relPath string) bool {
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath)))
+	return strings.HasSuffix(name, "_spec") || strings.HasSuffix(name, "_test") || strings.HasPrefix(name, "test_")
+}
+
+// Affected returns the test files related to a changed source file, relative
+// to dir. A changed test file is its own affected set. It returns nil when no
+// related test is found, meaning the whole suite should run.
+func Affected(dir, relPath string) []string {
+	if IsSpec(relPath) {
+		return []string{relPath}
+	}
+
+	base := strings.ToLower(strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath)))
+	candidates := map[string]bool{
+		base + "_spec.lua":      true,
+		base + "_test.lua":      true,
+		"test_" + base + ".lua": true,
+	}
+
+	var found []string
+	for _, sd := range specDirs {
+		root := filepath.Join(dir, sd)
+		_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if candidates[strings.ToLower(d.Name())] {
+				if rel, err := filepath.Rel(dir, path); err == nil {
+					found = append(found, rel)
+				}
+			}
+			return nil
+		})
+	}
+	return found
+}
@@ -0,0 +1,73 @@
+package testrun
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSpec(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"spec/Core_spec.lua", true},
+		{"tests/util_test.lua", true},
+		{"tests/test_util.lua", true},
+		{"Core.lua", false},
+		{"Testing.lua", false},
+	}
+	for _, tt := range tests {
+		if got := IsSpec(tt.path); got != tt.want {
+			t.Errorf("IsSpec(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAffected(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "spec", "modules"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "spec", "modules", "Util_spec.lua"), []byte(""), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "spec", "Core_spec.lua"), []byte(""), 0o644)
+
+	got := Affected(dir, filepath.Join("Modules", "Util.lua"))
+	want := filepath.Join("spec", "modules", "Util_spec.lua")
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("Affected(Util.lua) = %v, want [%s]", got, want)
+	}
+
+	if got := Affected(dir, "Options.lua"); got != nil {
+		t.Errorf("Affected(Options.lua) = %v, want nil", got)
+	}
+
+	spec := filepath.Join("spec", "Core_spec.lua")
+	if got := Affected(dir, spec); len(got) != 1 || got[0] != spec {
+		t.Errorf("Affected(%s) = %v, want itself", spec, got)
+	}
+}
+
+func TestParseBusted(t *testing.T) {
+	out := []byte("debug print from a test\n" +
+		`{"successes":[{"name":"a"},{"name":"b"}],"failures":[{"name":"c"}],"errors":[],"pendings":[],"duration":0.01}`)
+
+	res := Result{OK: false}
+	parseBusted(out, &res)
+	if res.Passed != 2 || res.Failed != 1 {
+		t.Errorf("Passed/Failed = %d/%d, want 2/1", res.Passed, res.Failed)
+	}
+	if res.OK {
+		t.Error("OK = true, want false with failures")
+	}
+	if res.String() != "1 failed, 2 passed" {
+		t.Errorf("String() = %q", res.String())
+	}
+}
+
+func TestIsBusted(t *testing.T) {
+	if !(&Runner{Command: "/usr/local/bin/busted --verbose"}).IsBusted() {
+		t.Error("IsBusted() = false for busted command")
+	}
+	if (&Runner{Command: "lua tests/run.lua"}).IsBusted() {
+		t.Error("IsBusted() = true for luaunit command")
+	}
+}
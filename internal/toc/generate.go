@@ -0,0 +1,67 @@
+package toc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/byteorem/blink/internal/flavor"
+	"github.com/byteorem/blink/internal/transform"
+)
+
+// Variant describes a flavor-specific .toc generated from a template.
+type Variant struct {
+	Suffix    string   // .toc suffix, e.g. "Mainline" or "Vanilla"
+	Interface string   // value of the "## Interface" field
+	Files     []string // file lines only this flavor loads
+}
+
+// FileName returns the .toc file name for addonName and this variant.
+func (v Variant) FileName(addonName string) string {
+	return addonName + "_" + v.Suffix + ".toc"
+}
+
+// Generate renders a variant from template data: the Interface field is set
+// and the variant's files are appended after the template's own file list.
+func Generate(tmpl []byte, v Variant) []byte {
+	f := Parse(tmpl)
+	if v.Interface != "" {
+		f.Set("Interface", v.Interface)
+	}
+	f.lines = append(f.lines, v.Files...)
+	if len(f.lines) > 0 {
+		f.trailing = true
+	}
+	return f.Bytes()
+}
+
+// GenerateAll renders every variant from the template at tmplPath into
+// dstDir and returns the names of the files written.
+func GenerateAll(tmplPath, dstDir, addonName string, variants []Variant) ([]string, error) {
+	tmpl, err := os.ReadFile(tmplPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading toc template: %w", err)
+	}
+	if err := os.MkdirAll(dstDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, v := range variants {
+		fl, ok := flavor.FromTocSuffix(v.Suffix)
+		if !ok {
+			return written, fmt.Errorf("unknown .toc flavor suffix %q", v.Suffix)
+		}
+		name := v.FileName(addonName)
+		// #@retail@-style blocks in the template resolve for the variant's own flavor.
+		data, err := transform.Directives(fl)(name, Generate(tmpl, v))
+		if err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(filepath.Join(dstDir, name), data, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, name)
+	}
+	return written, nil
+}
@@ -0,0 +1,59 @@
+package toc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tmpl := []byte("## Interface: 0\n## Title: My Addon\n\nCore.lua\n")
+	got := string(Generate(tmpl, Variant{Suffix: "Vanilla", Interface: "11507", Files: []string{"Classic\\Init.lua"}}))
+
+	want := "## Interface: 11507\n## Title: My Addon\n\nCore.lua\nClassic\\Init.lua\n"
+	if got != want {
+		t.Errorf("Generate() = %q, want %q", got, want)
+	}
+
+	// The template's spacing is kept.
+	got = string(Generate([]byte("## Interface:0\n## Title: My Addon\n"), Variant{Suffix: "Vanilla", Interface: "11507"}))
+	if want := "## Interface:11507\n## Title: My Addon\n"; got != want {
+		t.Errorf("Generate() = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateAll(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	tmplPath := filepath.Join(src, "MyAddon.toc.tmpl")
+	_ = os.WriteFile(tmplPath, []byte("## Title: My Addon\nCore.lua\n"), 0o644)
+
+	written, err := GenerateAll(tmplPath, dst, "MyAddon", []Variant{
+		{Suffix: "Mainline", Interface: "110200"},
+		{Suffix: "Cata", Interface: "40402"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateAll() error = %v", err)
+	}
+	if len(written) != 2 || written[0] != "MyAddon_Mainline.toc" || written[1] != "MyAddon_Cata.toc" {
+		t.Errorf("written = %v", written)
+	}
+
+	f, err := Read(filepath.Join(dst, "MyAddon_Cata.toc"))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if v, _ := f.Get("Interface"); v != "40402" {
+		t.Errorf("Interface = %q, want 40402", v)
+	}
+}
+
+func TestGenerateAll_UnknownSuffix(t *testing.T) {
+	src := t.TempDir()
+	tmplPath := filepath.Join(src, "MyAddon.toc.tmpl")
+	_ = os.WriteFile(tmplPath, []byte("## Title: My Addon\n"), 0o644)
+
+	if _, err := GenerateAll(tmplPath, t.TempDir(), "MyAddon", []Variant{{Suffix: "Retail"}}); err == nil {
+		t.Fatal("GenerateAll() expected error for unknown suffix")
+	}
+}
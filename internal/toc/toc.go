@@ -0,0 +1,140 @@
+// Package toc reads and edits WoW addon .toc files without disturbing their layout.
+package toc
+
+import (
+	"bytes"
+	"os"
+	"strings"
+)
+
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// Field is a single "## Key: Value" metadata line.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// File is a parsed .toc file. Lines that are not touched by Set are written
+// back exactly as they were read, including line endings and a leading BOM.
+type File struct {
+	lines    []string
+	eol      string
+	bom      bool
+	trailing bool // whether the file ended with a line ending
+}
+
+// Parse parses the contents of a .toc file.
+func Parse(data []byte) *File {
+	f := &File{eol: "\n"}
+	if bytes.HasPrefix(data, bom) {
+		f.bom = true
+		data = data[len(bom):]
+	}
+	text := string(data)
+	if strings.Contains(text, "\r\n") {
+		f.eol = "\r\n"
+	}
+	if text == "" {
+		return f
+	}
+	f.trailing = strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+	f.lines = strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	return f
+}
+
+// Read parses the .toc file at path.
+func Read(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data), nil
+}
+
+// WriteFile writes f to path.
+func (f *File) WriteFile(path string) error {
+	return os.WriteFile(path, f.Bytes(), 0o644)
+}
+
+// Bytes renders the file.
+func (f *File) Bytes() []byte {
+	var b bytes.Buffer
+	if f.bom {
+		b.Write(bom)
+	}
+	b.WriteString(strings.Join(f.lines, f.eol))
+	if f.trailing && len(f.lines) > 0 {
+		b.WriteString(f.eol)
+	}
+	return b.Bytes()
+}
+
+// parseField splits a metadata line into its key and value. The returned
+// prefix is everything up to and including the whitespace after the colon.
+func parseField(line string) (key, value, prefix string, ok bool) {
+	if !strings.HasPrefix(line, "##") {
+		return "", "", "", false
+	}
+	rest := line[2:]
+	colon := strings.Index(rest, ":")
+	if colon < 0 {
+		return "", "", "", false
+	}
+	key = strings.TrimSpace(rest[:colon])
+	if key == "" {
+		return "", "", "", false
+	}
+	after := rest[colon+1:]
+	value = strings.TrimSpace(after)
+	pad := len(after) - len(strings.TrimLeft(after, " \t"))
+	return key, value, line[:2+colon+1+pad], true
+}
+
+// Fields returns all metadata fields in file order.
+func (f *File) Fields() []Field {
+	var fields []Field
+	for _, line := range f.lines {
+		if key, value, _, ok := parseField(line); ok {
+			fields = append(fields, Field{Key: key, Value: value})
+		}
+	}
+	return fields
+}
+
+// Get returns the value of the metadata field key (case-insensitive).
+func (f *File) Get(key string) (string, bool) {
+	for _, line := range f.lines {
+		if k, v, _, ok := parseField(line); ok && strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Set updates the metadata field key, keeping its position, spelling and the
+// spacing after its colon. A new field is added after the last existing
+// metadata line.
+func (f *File) Set(key, value string) {
+	lastMeta := -1
+	for i, line := range f.lines {
+		k, _, prefix, ok := parseField(line)
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(k, key) {
+			f.lines[i] = prefix + value
+			return
+		}
+		lastMeta = i
+	}
+
+	line := "## " + key + ": " + value
+	f.lines = append(f.lines, "")
+	copy(f.lines[lastMeta+2:], f.lines[lastMeta+1:])
+	f.lines[lastMeta+1] = line
+	if len(f.lines) == 1 {
+		f.trailing = true
+	}
+}
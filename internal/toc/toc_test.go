@@ -0,0 +1,59 @@
+package toc
+
+import (
+	"testing"
+)
+
+const sample = "## Interface: 110002\r\n## Title: My Addon\r\n## Version:1.0.0\r\n\r\nCore.lua\r\nOptions.lua\r\n"
+
+func TestGet(t *testing.T) {
+	f := Parse([]byte(sample))
+
+	if v, ok := f.Get("Interface"); !ok || v != "110002" {
+		t.Errorf("Get(Interface) = %q, %v, want 110002", v, ok)
+	}
+	if v, ok := f.Get("version"); !ok || v != "1.0.0" {
+		t.Errorf("Get(version) = %q, %v, want 1.0.0", v, ok)
+	}
+	if _, ok := f.Get("Author"); ok {
+		t.Error("Get(Author) found a field that does not exist")
+	}
+}
+
+func TestSet_PreservesLayout(t *testing.T) {
+	f := Parse([]byte(sample))
+	f.Set("Interface", "110005")
+	f.Set("Version", "2.4.0")
+
+	want := "## Interface: 110005\r\n## Title: My Addon\r\n## Version:2.4.0\r\n\r\nCore.lua\r\nOptions.lua\r\n"
+	if got := string(f.Bytes()); got != want {
+		t.Errorf("Bytes() = %q, want %q", got, want)
+	}
+}
+
+func TestSet_AddsAfterLastField(t *testing.T) {
+	f := Parse([]byte("## Interface: 110002\n## Title: My Addon\n\nCore.lua\n"))
+	f.Set("Author", "me")
+
+	want := "## Interface: 110002\n## Title: My Addon\n## Author: me\n\nCore.lua\n"
+	if got := string(f.Bytes()); got != want {
+		t.Errorf("Bytes() = %q, want %q", got, want)
+	}
+}
+
+func TestParse_RoundTripWithBOM(t *testing.T) {
+	data := "\xEF\xBB\xBF## Title: BOM\nCore.lua"
+	if got := string(Parse([]byte(data)).Bytes()); got != data {
+		t.Errorf("Bytes() = %q, want %q", got, data)
+	}
+}
+
+func TestFields(t *testing.T) {
+	fields := Parse([]byte(sample)).Fields()
+	if len(fields) != 3 {
+		t.Fatalf("len(Fields()) = %d, want 3", len(fields))
+	}
+	if fields[1].Key != "Title" || fields[1].Value != "My Addon" {
+		t.Errorf("fields[1] = %+v", fields[1])
+	}
+}
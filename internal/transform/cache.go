@@ -0,0 +1,39 @@
+package transform
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// Cache returns fn remembering its results by file and contents, so builds
+// that rewrite the same files, like the zips of several flavors, share the
+// work. The returned data must not be modified. It is safe for concurrent
+// use; a nil fn gives nil.
+func Cache(fn Func) Func {
+	if fn == nil {
+		return nil
+	}
+	type key struct {
+		relPath string
+		sum     [sha256.Size]byte
+	}
+	var mu sync.Mutex
+	done := make(map[key][]byte)
+	return func(relPath string, data []byte) ([]byte, error) {
+		k := key{relPath, sha256.Sum256(data)}
+		mu.Lock()
+		out, ok := done[k]
+		mu.Unlock()
+		if ok {
+			return out, nil
+		}
+		out, err := fn(relPath, data)
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		done[k] = out
+		mu.Unlock()
+		return out, nil
+	}
+}
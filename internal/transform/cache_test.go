@@ -0,0 +1,26 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	calls := 0
+	tf := Cache(func(relPath string, data []byte) ([]byte, error) {
+		calls++
+		return []byte(strings.ToUpper(string(data))), nil
+	})
+	for _, in := range []string{"a", "a", "b", "a"} {
+		if got, err := tf("Core.lua", []byte(in)); err != nil || string(got) != strings.ToUpper(in) {
+			t.Errorf("Core.lua %q: got %q, %v", in, got, err)
+		}
+	}
+	_, _ = tf("Util.lua", []byte("a"))
+	if calls != 3 {
+		t.Errorf("rewrote %d times, want 3", calls)
+	}
+	if Cache(nil) != nil {
+		t.Error("Cache(nil) is not nil")
+	}
+}
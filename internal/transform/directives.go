@@ -0,0 +1,179 @@
+package transform
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/byteorem/blink/internal/flavor"
+)
+
+// Directive keywords follow the BigWigs packager: @retail@, @non-retail@,
+// @version-<version>@, @non-version-<version>@ and @no-lib-strip@.
+const keywordPattern = `([a-z]+(?:-[a-z]+)*)`
+
+var (
+	luaOpen  = regexp.MustCompile(`--(\[=*\[)?@` + keywordPattern + `@`)
+	luaClose = regexp.MustCompile(`--@end-` + keywordPattern + `@(\]=*\])?`)
+	xmlOpen  = regexp.MustCompile(`<!--@` + keywordPattern + `@(-->)?`)
+	xmlClose = regexp.MustCompile(`(<!--)?@end-` + keywordPattern + `@-->`)
+	tocOpen  = regexp.MustCompile(`^#@` + keywordPattern + `@\s*$`)
+	tocClose = regexp.MustCompile(`^#@end-` + keywordPattern + `@\s*$`)
+)
+
+// active reports whether a directive keyword applies to f. ok is false for
+// keywords unrelated to flavors (e.g. @debug@), which are left untouched.
+func active(keyword string, f flavor.Flavor) (on, ok bool) {
+	negate := strings.HasPrefix(keyword, "non-")
+	kw := strings.TrimPrefix(keyword, "non-")
+
+	var version string
+	switch {
+	case kw == "retail":
+		version = "retail"
+	case strings.HasPrefix(kw, "version-"):
+		version = strings.TrimPrefix(kw, "version-")
+	default:
+		return false, false
+	}
+	return (version == f.Version) != negate, true
+}
+
+// Directives returns a Func that activates packager-style flavor blocks for f
+// and comments out blocks meant for other flavors, in .lua, .xml and .toc files.
+func Directives(f flavor.Flavor) Func {
+	return directives(func(keyword string) (on, ok bool) { return active(keyword, f) })
+}
+
+// NoLib returns a Func that comments out @no-lib-strip@ blocks, for a
+// release that leaves out the libraries such blocks load.
+func NoLib() Func {
+	return directives(func(keyword string) (on, ok bool) { return false, keyword == "no-lib-strip" })
+}
+
+// directives returns a Func that comments out the blocks of .lua, .xml and
+// .toc files whose keyword isn't on, leaving keywords that aren't ok alone.
+func directives(active func(keyword string) (on, ok bool)) Func {
+	return func(relPath string, data []byte) ([]byte, error) {
+		switch strings.ToLower(filepath.Ext(relPath)) {
+		case ".lua":
+			return directivesLua(data, active), nil
+		case ".xml":
+			return directivesXML(data, active), nil
+		case ".toc":
+			return directivesToc(data, active), nil
+		}
+		return data, nil
+	}
+}
+
+func directivesLua(data []byte, active func(keyword string) (on, ok bool)) []byte {
+	s := string(data)
+	if !strings.Contains(s, "@") {
+		return data
+	}
+	s = luaOpen.ReplaceAllStringFunc(s, func(m string) string {
+		kw := luaOpen.FindStringSubmatch(m)[2]
+		on, ok := active(kw)
+		switch {
+		case !ok:
+			return m
+		case on:
+			return "--@" + kw + "@"
+		default:
+			return "--[===[@" + kw + "@"
+		}
+	})
+	s = luaClose.ReplaceAllStringFunc(s, func(m string) string {
+		kw := luaClose.FindStringSubmatch(m)[1]
+		on, ok := active(kw)
+		switch {
+		case !ok:
+			return m
+		case on:
+			return "--@end-" + kw + "@"
+		default:
+			return "--@end-" + kw + "@]===]"
+		}
+	})
+	return []byte(s)
+}
+
+func directivesXML(data []byte, active func(keyword string) (on, ok bool)) []byte {
+	s := string(data)
+	if !strings.Contains(s, "@") {
+		return data
+	}
+	s = xmlOpen.ReplaceAllStringFunc(s, func(m string) string {
+		kw := xmlOpen.FindStringSubmatch(m)[1]
+		on, ok := active(kw)
+		switch {
+		case !ok:
+			return m
+		case on:
+			return "<!--@" + kw + "@-->"
+		default:
+			return "<!--@" + kw + "@"
+		}
+	})
+	s = xmlClose.ReplaceAllStringFunc(s, func(m string) string {
+		kw := xmlClose.FindStringSubmatch(m)[2]
+		on, ok := active(kw)
+		switch {
+		case !ok:
+			return m
+		case on:
+			return "<!--@end-" + kw + "@-->"
+		default:
+			return "@end-" + kw + "@-->"
+		}
+	})
+	return []byte(s)
+}
+
+// directivesToc comments out file lines inside inactive #@keyword@ blocks and
+// uncomments "# "-prefixed lines inside active ones.
+func directivesToc(data []byte, active func(keyword string) (on, ok bool)) []byte {
+	s := string(data)
+	if !strings.Contains(s, "#@") {
+		return data
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	var stack []bool // whether each open block is active
+	for i, line := range lines {
+		body := strings.TrimRight(line, "\r\n")
+		eol := line[len(body):]
+
+		// Closing lines first, as #@end-retail@ looks like a keyword too.
+		if m := tocClose.FindStringSubmatch(body); m != nil {
+			if _, ok := active(m[1]); ok && len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+		if m := tocOpen.FindStringSubmatch(body); m != nil {
+			if on, ok := active(m[1]); ok {
+				stack = append(stack, on)
+			}
+			continue
+		}
+		if len(stack) == 0 || body == "" {
+			continue
+		}
+
+		inactive := false
+		for _, on := range stack {
+			if !on {
+				inactive = true
+			}
+		}
+		switch {
+		case inactive && !strings.HasPrefix(body, "#"):
+			lines[i] = "# " + body + eol
+		case !inactive && strings.HasPrefix(body, "# "):
+			lines[i] = strings.TrimPrefix(body, "# ") + eol
+		}
+	}
+	return []byte(strings.Join(lines, ""))
+}
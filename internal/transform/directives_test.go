@@ -0,0 +1,137 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/byteorem/blink/internal/flavor"
+)
+
+func mustFlavor(t *testing.T, name string) flavor.Flavor {
+	t.Helper()
+	f, ok := flavor.Lookup(name)
+	if !ok {
+		t.Fatalf("unknown flavor %q", name)
+	}
+	return f
+}
+
+const luaSource = `local x = 1
+--@retail@
+x = C_Retail()
+--@end-retail@
+--[===[@non-retail@
+x = Classic()
+--@end-non-retail@]===]
+--@version-classic@
+x = Era()
+--@end-version-classic@
+--@debug@
+print(x)
+--@end-debug@
+`
+
+func TestDirectives_LuaRetail(t *testing.T) {
+	got, err := Directives(mustFlavor(t, "retail"))("Core.lua", []byte(luaSource))
+	if err != nil {
+		t.Fatalf("Directives() error = %v", err)
+	}
+	want := `local x = 1
+--@retail@
+x = C_Retail()
+--@end-retail@
+--[===[@non-retail@
+x = Classic()
+--@end-non-retail@]===]
+--[===[@version-classic@
+x = Era()
+--@end-version-classic@]===]
+--@debug@
+print(x)
+--@end-debug@
+`
+	if string(got) != want {
+		t.Errorf("Directives(retail) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDirectives_LuaClassicEra(t *testing.T) {
+	got, _ := Directives(mustFlavor(t, "classic_era"))("Core.lua", []byte(luaSource))
+	want := `local x = 1
+--[===[@retail@
+x = C_Retail()
+--@end-retail@]===]
+--@non-retail@
+x = Classic()
+--@end-non-retail@
+--@version-classic@
+x = Era()
+--@end-version-classic@
+--@debug@
+print(x)
+--@end-debug@
+`
+	if string(got) != want {
+		t.Errorf("Directives(classic_era) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDirectives_Idempotent(t *testing.T) {
+	fn := Directives(mustFlavor(t, "classic"))
+	once, _ := fn("Core.lua", []byte(luaSource))
+	twice, _ := fn("Core.lua", once)
+	if string(once) != string(twice) {
+		t.Errorf("applying Directives twice changed the output:\n%s\nvs\n%s", once, twice)
+	}
+}
+
+func TestDirectives_Toc(t *testing.T) {
+	src := "## Title: X\r\nCore.lua\r\n#@retail@\r\nRetail.lua\r\n#@end-retail@\r\n#@non-retail@\r\n# Classic.lua\r\n#@end-non-retail@\r\n"
+
+	got, _ := Directives(mustFlavor(t, "classic"))("X.toc", []byte(src))
+	want := "## Title: X\r\nCore.lua\r\n#@retail@\r\n# Retail.lua\r\n#@end-retail@\r\n#@non-retail@\r\nClassic.lua\r\n#@end-non-retail@\r\n"
+	if string(got) != want {
+		t.Errorf("Directives(toc) = %q, want %q", got, want)
+	}
+}
+
+func TestDirectives_XML(t *testing.T) {
+	src := `<Ui><!--@retail@--><Frame name="R"/><!--@end-retail@--></Ui>`
+
+	got, _ := Directives(mustFlavor(t, "classic_era"))("Frames.xml", []byte(src))
+	want := `<Ui><!--@retail@<Frame name="R"/>@end-retail@--></Ui>`
+	if string(got) != want {
+		t.Errorf("Directives(xml) = %q, want %q", got, want)
+	}
+}
+
+func TestNoLib(t *testing.T) {
+	for _, tt := range []struct{ file, src, want string }{
+		{"X.toc", "Core.lua\n#@no-lib-strip@\nLibs\\LibStub.lua\n#@end-no-lib-strip@\n", "Core.lua\n#@no-lib-strip@\n# Libs\\LibStub.lua\n#@end-no-lib-strip@\n"},
+		{"Core.lua", "--@no-lib-strip@\nLibStub()\n--@end-no-lib-strip@\n--@retail@\n", "--[===[@no-lib-strip@\nLibStub()\n--@end-no-lib-strip@]===]\n--@retail@\n"},
+		{"embeds.xml", `<Ui><!--@no-lib-strip@--><Script file="Libs\LibStub.lua"/><!--@end-no-lib-strip@--></Ui>`, `<Ui><!--@no-lib-strip@<Script file="Libs\LibStub.lua"/>@end-no-lib-strip@--></Ui>`},
+	} {
+		if got, _ := NoLib()(tt.file, []byte(tt.src)); string(got) != tt.want {
+			t.Errorf("NoLib(%s) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestDirectives_OtherFilesUntouched(t *testing.T) {
+	src := []byte("--@retail@ not code")
+	got, _ := Directives(mustFlavor(t, "classic"))("README.md", src)
+	if string(got) != string(src) {
+		t.Errorf("Directives(README.md) = %q, want unchanged", got)
+	}
+}
+
+func TestChain(t *testing.T) {
+	if Chain(nil, nil) != nil {
+		t.Error("Chain(nil, nil) should be nil")
+	}
+	appendA := func(_ string, d []byte) ([]byte, error) { return append(d, 'a'), nil }
+	appendB := func(_ string, d []byte) ([]byte, error) { return append(d, 'b'), nil }
+	got, _ := Chain(appendA, nil, appendB)("x", []byte(">"))
+	if string(got) != ">ab" {
+		t.Errorf("Chain() = %q, want %q", got, ">ab")
+	}
+}
@@ -0,0 +1,56 @@
+package transform
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// Header returns a Func that puts banner, e.g. a license notice, at the top of
+// .lua files as line comments. Lines of banner that already start with "--"
+// are kept as they are. Files that start with the banner already are left
+// alone, and Header returns nil for an empty banner.
+func Header(banner string) Func {
+	banner = strings.TrimRight(banner, "\r\n\t ")
+	if banner == "" {
+		return nil
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(banner, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		switch {
+		case strings.HasPrefix(line, "--"):
+			b.WriteString(line)
+		case line == "":
+			b.WriteString("--")
+		default:
+			b.WriteString("-- " + line)
+		}
+		b.WriteString("\n")
+	}
+	comment := b.String()
+	crlf := strings.ReplaceAll(comment, "\n", "\r\n")
+
+	return func(relPath string, data []byte) ([]byte, error) {
+		if !strings.EqualFold(filepath.Ext(relPath), ".lua") {
+			return data, nil
+		}
+		bom := bytes.HasPrefix(data, utf8BOM)
+		body := bytes.TrimPrefix(data, utf8BOM)
+		header := comment
+		if bytes.Contains(body, []byte("\r\n")) {
+			header = crlf
+		}
+		if bytes.HasPrefix(body, []byte(header)) {
+			return data, nil
+		}
+		out := make([]byte, 0, len(data)+len(header))
+		if bom {
+			out = append(out, utf8BOM...)
+		}
+		out = append(out, header...)
+		return append(out, body...), nil
+	}
+}
@@ -0,0 +1,32 @@
+package transform
+
+import "testing"
+
+func TestHeader(t *testing.T) {
+	tf := Header("Copyright (c) 2026 Jane Doe\n\nAll rights reserved.\n")
+	tests := []struct {
+		name, path, in, want string
+	}{
+		{"lua", "Core.lua", "print(1)\n", "-- Copyright (c) 2026 Jane Doe\n--\n-- All rights reserved.\n" + "print(1)\n"},
+		{"crlf", "Core.lua", "print(1)\r\n", "-- Copyright (c) 2026 Jane Doe\r\n--\r\n-- All rights reserved.\r\n" + "print(1)\r\n"},
+		{"bom", "Core.lua", "\xef\xbb\xbfprint(1)\n", "\xef\xbb\xbf-- Copyright (c) 2026 Jane Doe\n--\n-- All rights reserved.\n" + "print(1)\n"},
+		{"already there", "Core.lua", "-- Copyright (c) 2026 Jane Doe\n--\n-- All rights reserved.\nprint(1)\n", "-- Copyright (c) 2026 Jane Doe\n--\n-- All rights reserved.\nprint(1)\n"},
+		{"other file", "Layout.xml", "<Ui/>\n", "<Ui/>\n"},
+	}
+	for _, tt := range tests {
+		got, err := tf(tt.path, []byte(tt.in))
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestHeader_CommentLinesKept(t *testing.T) {
+	got, _ := Header("--[[ MIT License ]]")("Core.lua", []byte("x = 1\n"))
+	if want := "--[[ MIT License ]]\nx = 1\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if Header(" \n") != nil {
+		t.Error("Header of an empty banner is not nil")
+	}
+}
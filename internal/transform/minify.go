@@ -0,0 +1,145 @@
+package transform
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// Minify returns a Func that strips comments, indentation and blank lines
+// from the .lua files matching patterns (gitignore syntax). Lines are kept
+// apart, so statements never run together, and packager directive comments
+// (--@retail@ and the like) stay. Files that don't parse are left as they
+// are. It returns nil without patterns.
+func Minify(patterns []string) Func {
+	if len(patterns) == 0 {
+		return nil
+	}
+	match := ignore.CompileIgnoreLines(patterns...)
+	return func(relPath string, data []byte) ([]byte, error) {
+		if !strings.EqualFold(filepath.Ext(relPath), ".lua") || !match.MatchesPath(filepath.ToSlash(relPath)) {
+			return data, nil
+		}
+		if _, err := parse.Parse(bytes.NewReader(data), relPath); err != nil {
+			return data, nil
+		}
+		return minifyLua(data), nil
+	}
+}
+
+// minifyLua does the work of Minify on a file that parses.
+func minifyLua(src []byte) []byte {
+	var out, line []byte
+	space := false // whitespace or a comment since the last token
+	flush := func() {
+		if len(line) > 0 {
+			out = append(out, line...)
+			out = append(out, '\n')
+			line = line[:0]
+		}
+		space = false
+	}
+	emit := func(b []byte) {
+		if space && len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, b...)
+		space = false
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			flush()
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			space = true
+			i++
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			end := i + 2
+			if n := longBracket(src[end:]); n > 0 {
+				end += closeLongBracket(src[end:], n)
+			} else {
+				for end < len(src) && src[end] != '\n' {
+					end++
+				}
+			}
+			body := bytes.TrimLeft(src[i+2:end], "[=")
+			if bytes.HasPrefix(body, []byte("@")) {
+				emit(src[i:end]) // a packager directive
+			} else {
+				space = true
+				// Keep statements on either side of a long comment apart.
+				if bytes.IndexByte(src[i:end], '\n') >= 0 {
+					flush()
+				}
+			}
+			i = end
+		case c == '[' && longBracket(src[i:]) > 0:
+			end := i + closeLongBracket(src[i:], longBracket(src[i:]))
+			emit(src[i:end])
+			i = end
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != c && src[end] != '\n' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(src))
+			emit(src[i:end])
+			i = end
+		default:
+			end := i + 1
+			for end < len(src) && !isSpecial(src[end]) && !isSpecial(src[end-1]) {
+				end++
+			}
+			emit(src[i:end])
+			i = end
+		}
+	}
+	flush()
+	return out
+}
+
+// isSpecial reports whether c starts something minifyLua handles on its own:
+// whitespace, a comment, a string or a long bracket.
+func isSpecial(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', '\v', '-', '[', '"', '\'':
+		return true
+	}
+	return false
+}
+
+// longBracket returns the length of the opening long bracket ([[, [==[, …) at
+// the start of b, or 0 when there is none.
+func longBracket(b []byte) int {
+	if len(b) == 0 || b[0] != '[' {
+		return 0
+	}
+	n := 1
+	for n < len(b) && b[n] == '=' {
+		n++
+	}
+	if n < len(b) && b[n] == '[' {
+		return n + 1
+	}
+	return 0
+}
+
+// closeLongBracket returns the length of the long string or comment at the
+// start of b, whose opening bracket is open bytes long, up to and including
+// its closing bracket.
+func closeLongBracket(b []byte, open int) int {
+	closing := "]" + strings.Repeat("=", open-2) + "]"
+	if i := bytes.Index(b[open:], []byte(closing)); i >= 0 {
+		return open + i + len(closing)
+	}
+	return len(b)
+}
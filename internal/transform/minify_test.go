@@ -0,0 +1,70 @@
+package transform
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yuin/gopher-lua/parse"
+)
+
+func TestMinify(t *testing.T) {
+	src := `-- MyAddon core
+local addon = {}  -- the addon table
+
+--[[ A long
+comment ]]
+function addon:Greet(name)
+	local s = "Hello, " .. name -- not a comment: "--"
+	local t = [==[
+  keep   this  ]==]
+	return s .. ' -- ' .. t, 1 - -1
+end
+
+--@retail@
+addon.retail = true
+--@end-retail@
+--[===[@non-retail@
+addon.retail = false
+--@end-non-retail@]===]
+return addon
+`
+	want := `local addon = {}
+function addon:Greet(name)
+local s = "Hello, " .. name
+local t = [==[
+  keep   this  ]==]
+return s .. ' -- ' .. t, 1 - -1
+end
+--@retail@
+addon.retail = true
+--@end-retail@
+--[===[@non-retail@
+addon.retail = false
+--@end-non-retail@]===]
+return addon
+`
+	tf := Minify([]string{"*.lua", "!Libs/"})
+	got, err := tf("Core.lua", []byte(src))
+	if err != nil || string(got) != want {
+		t.Fatalf("got %q, %v, want %q", got, err, want)
+	}
+	if _, err := parse.Parse(bytes.NewReader(got), "Core.lua"); err != nil {
+		t.Errorf("minified code doesn't parse: %v", err)
+	}
+}
+
+func TestMinify_Skipped(t *testing.T) {
+	tf := Minify([]string{"*.lua", "!Libs/"})
+	for path, in := range map[string]string{
+		"Libs/LibStub.lua": "-- LibStub\nlocal x = 1\n",
+		"Layout.xml":       "<!-- layout -->\n<Ui/>\n",
+		"Broken.lua":       "-- broken\nlocal = 1\n",
+	} {
+		if got, _ := tf(path, []byte(in)); string(got) != in {
+			t.Errorf("%s: got %q, want it unchanged", path, got)
+		}
+	}
+	if Minify(nil) != nil {
+		t.Error("Minify(nil) is not nil")
+	}
+}
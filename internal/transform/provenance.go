@@ -0,0 +1,38 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Provenance returns a Func that notes at the start of .lua files where they
+// were synced from, e.g.
+//
+//	--[[ synced by blink from Core.lua@1a2b3c4 at 2026-10-16 12:00:00 ]] local addon = ...
+//
+// commit returns the source's commit ("" leaves it out) and now the time of
+// the sync. The note shares the first line with the code, so line numbers in
+// errors still match the source.
+func Provenance(commit func() string, now func() time.Time) Func {
+	return func(relPath string, data []byte) ([]byte, error) {
+		if !strings.EqualFold(filepath.Ext(relPath), ".lua") {
+			return data, nil
+		}
+		from := filepath.ToSlash(relPath)
+		if c := commit(); c != "" {
+			from += "@" + c
+		}
+		note := fmt.Sprintf("--[==[ synced by blink from %s at %s ]==] ", from, now().Format(time.DateTime))
+
+		out := make([]byte, 0, len(data)+len(note))
+		if bytes.HasPrefix(data, utf8BOM) {
+			out = append(out, utf8BOM...)
+			data = data[len(utf8BOM):]
+		}
+		out = append(out, note...)
+		return append(out, data...), nil
+	}
+}
@@ -0,0 +1,27 @@
+package transform
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProvenance(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	commit := "1a2b3c4"
+	tf := Provenance(func() string { return commit }, func() time.Time { return at })
+
+	got, _ := tf("Modules/Core.lua", []byte("local x = 1\n"))
+	if want := "--[==[ synced by blink from Modules/Core.lua@1a2b3c4 at 2026-10-16 12:00:00 ]==] local x = 1\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	commit = ""
+	got, _ = tf("Core.lua", []byte("\xef\xbb\xbflocal x = 1\n"))
+	if want := "\xef\xbb\xbf--[==[ synced by blink from Core.lua at 2026-10-16 12:00:00 ]==] local x = 1\n"; string(got) != want {
+		t.Errorf("without a commit: got %q, want %q", got, want)
+	}
+
+	if got, _ := tf("Layout.xml", []byte("<Ui/>")); string(got) != "<Ui/>" {
+		t.Errorf("Layout.xml = %q, want it unchanged", got)
+	}
+}
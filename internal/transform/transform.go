@@ -0,0 +1,32 @@
+// Package transform rewrites file contents as they are copied to a destination.
+package transform
+
+// Func rewrites the contents of the file at relPath (relative to the addon
+// root). Returning data unchanged is always valid.
+type Func func(relPath string, data []byte) ([]byte, error)
+
+// Chain returns a Func applying fns in order. Nil entries are skipped, and
+// Chain returns nil when nothing is left to apply.
+func Chain(fns ...Func) Func {
+	var active []Func
+	for _, fn := range fns {
+		if fn != nil {
+			active = append(active, fn)
+		}
+	}
+	switch len(active) {
+	case 0:
+		return nil
+	case 1:
+		return active[0]
+	}
+	return func(relPath string, data []byte) ([]byte, error) {
+		var err error
+		for _, fn := range active {
+			if data, err = fn(relPath, data); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}
+}
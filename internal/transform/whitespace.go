@@ -0,0 +1,41 @@
+package transform
+
+import (
+	"bytes"
+	"path/filepath"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// TrimWhitespace returns a Func that strips trailing spaces and tabs from
+// every line of the files matching patterns (gitignore syntax) and ends them
+// with a newline. Line endings are kept. It returns nil without patterns.
+func TrimWhitespace(patterns []string) Func {
+	if len(patterns) == 0 {
+		return nil
+	}
+	match := ignore.CompileIgnoreLines(patterns...)
+	return func(relPath string, data []byte) ([]byte, error) {
+		if len(data) == 0 || !match.MatchesPath(filepath.ToSlash(relPath)) {
+			return data, nil
+		}
+		out := make([]byte, 0, len(data)+1)
+		for len(data) > 0 {
+			line, rest, found := bytes.Cut(data, []byte("\n"))
+			eol := ""
+			if found {
+				eol = "\n"
+				if bytes.HasSuffix(line, []byte("\r")) {
+					line, eol = line[:len(line)-1], "\r\n"
+				}
+			}
+			out = append(out, bytes.TrimRight(line, " \t")...)
+			out = append(out, eol...)
+			data = rest
+		}
+		if !bytes.HasSuffix(out, []byte("\n")) {
+			out = append(out, '\n')
+		}
+		return out, nil
+	}
+}
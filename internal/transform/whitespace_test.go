@@ -0,0 +1,25 @@
+package transform
+
+import "testing"
+
+func TestTrimWhitespace(t *testing.T) {
+	tf := TrimWhitespace([]string{"*.lua", "/Locales/*.xml"})
+	tests := []struct {
+		path, in, want string
+	}{
+		{"Core.lua", "local x = 1  \nprint(x)\t", "local x = 1\nprint(x)\n"},
+		{"Libs/Util.lua", "a = 1 \r\nb = 2 \r\n", "a = 1\r\nb = 2\r\n"},
+		{"Locales/enUS.xml", "<Ui> \n</Ui>", "<Ui>\n</Ui>\n"},
+		{"Layout.xml", "<Ui> \n</Ui>", "<Ui> \n</Ui>"},
+		{"Empty.lua", "", ""},
+	}
+	for _, tt := range tests {
+		got, err := tf(tt.path, []byte(tt.in))
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+	if TrimWhitespace(nil) != nil {
+		t.Error("TrimWhitespace(nil) is not nil")
+	}
+}
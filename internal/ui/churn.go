@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/byteorem/blink/internal/budget"
+	"github.com/byteorem/blink/internal/i18n"
+)
+
+// churnPanelHeight is how many files the churn panel ranks.
+const churnPanelHeight = 10
+
+// viewChurn renders the files synced most often this session.
+func (m Model) viewChurn() string {
+	s := labelStyle.Render("  "+i18n.T("Most synced files")) + "\n"
+	files := m.engine.Churn(churnPanelHeight)
+	if len(files) == 0 {
+		s += dimStyle.Render("  "+i18n.T("(empty)")) + "\n"
+	}
+	for _, f := range files {
+		s += dimStyle.Render(fmt.Sprintf("  %5d×  %9s  ", f.Changes, budget.FormatSize(f.Bytes))) + pathStyle.Render(f.Label) + "\n"
+	}
+	return s
+}
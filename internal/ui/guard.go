@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"reflect"
+
+	"github.com/byteorem/blink/internal/crash"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Guard wraps a model so that a panic in its Update or View, or in any
+// command it returns, ends in a crash report (see crash.Recover) rather than
+// Bubble Tea's own panic dump. Run it with tea.WithoutCatchPanics.
+func Guard(m tea.Model) tea.Model {
+	return guarded{m}
+}
+
+type guarded struct{ tea.Model }
+
+func (g guarded) Init() tea.Cmd {
+	defer crash.Recover()
+	return guard(g.Model.Init())
+}
+
+func (g guarded) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer crash.Recover()
+	m, cmd := g.Model.Update(msg)
+	return guarded{m}, guard(cmd)
+}
+
+func (g guarded) View() string {
+	defer crash.Recover()
+	return g.Model.View()
+}
+
+var cmdType = reflect.TypeFor[tea.Cmd]()
+
+// guard wraps cmd, and the commands of a batch or sequence it returns, with
+// crash.Recover.
+func guard(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		defer crash.Recover()
+		msg := cmd()
+		// tea.Batch and tea.Sequence return their commands as a slice,
+		// which Bubble Tea runs in goroutines of its own.
+		if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice && v.Type().Elem() == cmdType {
+			for i := range v.Len() {
+				c, _ := v.Index(i).Interface().(tea.Cmd)
+				v.Index(i).Set(reflect.ValueOf(guard(c)))
+			}
+		}
+		return msg
+	}
+}
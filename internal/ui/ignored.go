@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/i18n"
+)
+
+// ignorePanelHeight is how many patterns the ignore panel lists.
+const ignorePanelHeight = 10
+
+// viewIgnored renders how many paths the ignore patterns of the watched
+// addons kept out of the sync, patterns that match nothing first: they are
+// usually typos.
+func (m Model) viewIgnored() string {
+	s := labelStyle.Render("  "+i18n.T("Ignore patterns")) + "\n"
+
+	// The same pattern in several sources is counted once, over all of them.
+	var stats []copier.PatternStat
+	index := make(map[[2]string]int)
+	for _, a := range m.addons {
+		for _, src := range a.Sources {
+			for _, st := range src.Ignorer.Stats() {
+				key := [2]string{st.Origin, st.Pattern}
+				if i, ok := index[key]; ok {
+					stats[i].Excluded += st.Excluded
+					continue
+				}
+				index[key] = len(stats)
+				stats = append(stats, st)
+			}
+		}
+	}
+	// blink's own patterns often match nothing, which is no mistake.
+	unused := func(st copier.PatternStat) bool { return st.Excluded == 0 && st.Origin != copier.OriginBuiltin }
+	sort.SliceStable(stats, func(i, j int) bool {
+		if unused(stats[i]) != unused(stats[j]) {
+			return unused(stats[i])
+		}
+		return stats[i].Excluded > stats[j].Excluded
+	})
+
+	for i, st := range stats {
+		if i == ignorePanelHeight {
+			s += dimStyle.Render("  "+i18n.Tf("… %d more", len(stats)-ignorePanelHeight)) + "\n"
+			break
+		}
+		line := dimStyle.Render(fmt.Sprintf("  %5d  %-14s ", st.Excluded, st.Origin)) + pathStyle.Render(st.Pattern)
+		if unused(st) {
+			line += " " + warnStyle.Render(i18n.T("matches nothing"))
+		}
+		s += line + "\n"
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	maxLogLines    = 200
+	logPanelHeight = 10
+)
+
+// LogWriter collects log output for the TUI's log panel, so log lines don't
+// scribble over the screen. Writes never block; lines the panel hasn't picked
+// up yet are dropped once its buffer is full.
+type LogWriter struct {
+	mu      sync.Mutex
+	partial string
+	ch      chan string
+}
+
+// NewLogWriter returns a LogWriter to pass to log.SetOutput and Model.WithLog.
+func NewLogWriter() *LogWriter {
+	return &LogWriter{ch: make(chan string, 256)}
+}
+
+// Write implements io.Writer, splitting the output into lines.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	lines := strings.Split(w.partial+string(p), "\n")
+	w.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		select {
+		case w.ch <- line:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// LogLineMsg carries a line of log output.
+type LogLineMsg string
+
+func listenToLog(ch <-chan string) tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return LogLineMsg(line)
+	}
+}
@@ -0,0 +1,288 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/byteorem/blink/internal/i18n"
+	"github.com/byteorem/blink/internal/status"
+	"github.com/byteorem/blink/internal/workspace"
+)
+
+// Controls change settings of the running session from the command palette.
+// Commands whose control is nil aren't available.
+type Controls struct {
+	SetDelay      func(ms int)
+	ToggleVerbose func() bool                         // returns whether debug logging is now on
+	Retarget      func(flavor string) (string, error) // returns the new target to show
+}
+
+// WithControls returns the model letting the command palette change the
+// session through c.
+func (m Model) WithControls(c Controls) Model {
+	m.controls = c
+	return m
+}
+
+// paletteCommand is a command the palette runs.
+type paletteCommand struct {
+	name string
+	args string
+}
+
+var paletteCommands = []paletteCommand{
+	{"resync", "[addon]"},
+	{"pause", "<addon>"},
+	{"resume", "<addon>"},
+	{"target", "<flavor>"},
+	{"open", "[source|target] [addon]"},
+	{"delay", "<ms>"},
+	{"verbose", ""},
+	{"help", ""},
+	{"quit", ""},
+}
+
+// lookupCommand returns the command named name, or the only one it is a
+// prefix of.
+func lookupCommand(name string) (paletteCommand, bool) {
+	var found []paletteCommand
+	for _, c := range paletteCommands {
+		if c.name == name {
+			return c, true
+		}
+		if strings.HasPrefix(c.name, name) {
+			found = append(found, c)
+		}
+	}
+	if len(found) != 1 {
+		return paletteCommand{}, false
+	}
+	return found[0], true
+}
+
+// updatePalette edits the command line while the palette is open.
+func (m Model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.paletteOpen, m.palette = false, ""
+	case tea.KeyEnter:
+		line := m.palette
+		m.paletteOpen, m.palette = false, ""
+		return m.runCommand(line)
+	case tea.KeyBackspace:
+		if r := []rune(m.palette); len(r) > 0 {
+			m.palette = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.palette += " "
+	case tea.KeyRunes:
+		m.palette += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// runCommand runs a command line typed into the palette and notes what came
+// of it in the changelog.
+func (m Model) runCommand(line string) (tea.Model, tea.Cmd) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return m, nil
+	}
+	c, ok := lookupCommand(fields[0])
+	if !ok {
+		m.paletteEntry(fields[0], true, i18n.T("unknown command — type help for the list"))
+		return m, nil
+	}
+	args := fields[1:]
+	usage := func() {
+		m.paletteEntry(c.name, true, i18n.Tf("usage: %s %s", c.name, c.args))
+	}
+
+	switch c.name {
+	case "resync":
+		addons := m.active()
+		if len(args) > 0 {
+			a, ok := m.lookupAddon(c.name, args[0])
+			if !ok {
+				return m, nil
+			}
+			addons = []*workspace.Addon{a}
+		} else if m.syncing {
+			return m, nil
+		} else {
+			m.syncing = true
+		}
+		return m, m.doResync(addons...)
+
+	case "pause", "resume":
+		if len(args) != 1 {
+			usage()
+			return m, nil
+		}
+		a, ok := m.lookupAddon(c.name, args[0])
+		if !ok {
+			return m, nil
+		}
+		pause := c.name == "pause"
+		if m.paused[a.Name] == pause {
+			return m, nil
+		}
+		if pause {
+			m.paletteEntry(c.name, false, i18n.Tf("paused %s", a.Name))
+		} else {
+			m.paletteEntry(c.name, false, i18n.Tf("resumed %s", a.Name))
+		}
+		return m.setPaused(a, pause)
+
+	case "target":
+		if len(args) != 1 {
+			usage()
+			return m, nil
+		}
+		if m.controls.Retarget == nil {
+			m.paletteEntry(c.name, true, i18n.T("not available in this session"))
+			return m, nil
+		}
+		// The addons' targets change, which the view reads, so this
+		// doesn't run in a Cmd.
+		target, err := m.controls.Retarget(args[0])
+		if err != nil {
+			m.paletteEntry(c.name, true, i18n.Tf("error: %v", err))
+			return m, nil
+		}
+		m.targetPath = target
+		m.paletteEntry(c.name, false, i18n.Tf("switched to %s", args[0]))
+		m.syncing = true
+		return m, m.doResync(m.active()...)
+
+	case "open":
+		which := "target"
+		if len(args) > 0 {
+			which, args = args[0], args[1:]
+		}
+		if which != "source" && which != "target" || len(args) > 1 {
+			usage()
+			return m, nil
+		}
+		a := m.addons[0]
+		if len(args) == 1 {
+			if a, ok = m.lookupAddon(c.name, args[0]); !ok {
+				return m, nil
+			}
+		} else if len(m.addons) > 1 && which == "source" {
+			usage()
+			return m, nil
+		}
+		path := m.targetPath
+		switch {
+		case which == "source":
+			path = a.Dir()
+		case len(args) == 1:
+			path = a.Target
+		}
+		if err := openPath(path); err != nil {
+			m.paletteEntry(c.name, true, i18n.Tf("error: %v", err))
+			return m, nil
+		}
+		m.paletteEntry(c.name, false, i18n.Tf("opened %s", path))
+
+	case "delay":
+		if len(args) != 1 {
+			usage()
+			return m, nil
+		}
+		ms, err := strconv.Atoi(args[0])
+		if err != nil || ms < 0 {
+			usage()
+			return m, nil
+		}
+		if m.controls.SetDelay == nil {
+			m.paletteEntry(c.name, true, i18n.T("not available in this session"))
+			return m, nil
+		}
+		m.controls.SetDelay(ms)
+		m.paletteEntry(c.name, false, i18n.Tf("changes now wait %d ms before syncing", ms))
+
+	case "verbose":
+		if m.controls.ToggleVerbose == nil {
+			m.paletteEntry(c.name, true, i18n.T("not available in this session"))
+			return m, nil
+		}
+		if m.controls.ToggleVerbose() {
+			m.paletteEntry(c.name, false, i18n.T("debug logging on"))
+		} else {
+			m.paletteEntry(c.name, false, i18n.T("debug logging off"))
+		}
+
+	case "help":
+		names := make([]string, len(paletteCommands))
+		for i, c := range paletteCommands {
+			names[i] = strings.TrimSpace(c.name + " " + c.args)
+		}
+		m.paletteEntry(c.name, false, strings.Join(names, ", "))
+
+	case "quit":
+		return m.quit()
+	}
+	return m, nil
+}
+
+// lookupAddon returns the watched addon named name, noting in the changelog
+// when there is none.
+func (m *Model) lookupAddon(command, name string) (*workspace.Addon, bool) {
+	for _, a := range m.addons {
+		if strings.EqualFold(a.Name, name) {
+			return a, true
+		}
+	}
+	m.paletteEntry(command, true, i18n.Tf("no addon named %s", name))
+	return nil, false
+}
+
+// paletteEntry adds the outcome of a palette command to the changelog.
+func (m *Model) paletteEntry(command string, isError bool, text string) {
+	m.addEntry(changeEntry{time: time.Now(), relPath: ":" + command, text: text, isError: isError})
+}
+
+// setPaused toggles syncing of addon a off or back on for the session.
+func (m Model) setPaused(a *workspace.Addon, pause bool) (tea.Model, tea.Cmd) {
+	if !pause {
+		// Catch up on changes made while the addon was off.
+		delete(m.paused, a.Name)
+		_ = m.status.Update(func(st *status.Status) { st.State = m.watchState() })
+		return m, tea.Batch(m.setTitle(), m.doResync(a))
+	}
+	m.paused[a.Name] = true
+	_ = m.status.Update(func(st *status.Status) { st.State = m.watchState() })
+	return m, m.setTitle()
+}
+
+// viewPalette renders the command line being typed.
+func (m Model) viewPalette() string {
+	return "  " + pathStyle.Render(":"+m.palette+"█") + "  " + dimStyle.Render(i18n.T("enter to run, esc to cancel")) + "\n"
+}
+
+// openPath shows path in the system's file manager.
+func openPath(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("explorer", filepath.Clean(path))
+	case "darwin":
+		cmd = exec.Command("open", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("can't open %s: %w", path, err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
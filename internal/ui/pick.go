@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"github.com/byteorem/blink/internal/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Picked is the answer of a PickModel.
+type Picked struct {
+	Addon     string // the addon picked, or "" for all of them
+	Save      bool   // remember the choice in blink.toml
+	Cancelled bool
+}
+
+// PickModel is the Bubbletea model asking which of several addons found
+// side by side to sync: all of them, or one. The answer is written to the
+// Picked it was made with when the program ends.
+type PickModel struct {
+	names  []string
+	cursor int // 0 is every addon, i+1 is names[i]
+	save   bool
+	picked *Picked
+}
+
+// NewPickModel creates a picker for the addons names, answering in picked.
+func NewPickModel(names []string, picked *Picked) PickModel {
+	return PickModel{names: names, picked: picked}
+}
+
+// Init returns nil; the picker only waits for keys.
+func (m PickModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update moves the cursor and ends the program on a choice.
+func (m PickModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "up", "k":
+		m.cursor = (m.cursor + len(m.names)) % (len(m.names) + 1)
+	case "down", "j":
+		m.cursor = (m.cursor + 1) % (len(m.names) + 1)
+	case "s":
+		m.save = !m.save
+	case "enter":
+		*m.picked = Picked{Save: m.save}
+		if m.cursor > 0 {
+			m.picked.Addon = m.names[m.cursor-1]
+		}
+		return m, tea.Quit
+	case "esc", "q", "ctrl+c":
+		*m.picked = Picked{Cancelled: true}
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// View renders the list of choices.
+func (m PickModel) View() string {
+	s := "\n " + headerStyle.Render(title) + "\n\n"
+	s += "  " + i18n.T("Several addons were found. Which should be synced?") + "\n\n"
+	choices := append([]string{i18n.T("All of them")}, m.names...)
+	for i, c := range choices {
+		if i == m.cursor {
+			s += "  " + pathStyle.Render("> "+c) + "\n"
+		} else {
+			s += "    " + c + "\n"
+		}
+	}
+	box := "[ ]"
+	if m.save {
+		box = "[x]"
+	}
+	s += "\n  " + labelStyle.Render(box+" "+i18n.T("s to remember the choice in blink.toml")) + "\n"
+	s += dimStyle.Render("  "+i18n.T("↑/↓ to choose, enter to sync, esc to quit")) + "\n"
+	return s
+}
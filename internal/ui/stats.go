@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/byteorem/blink/internal/budget"
+	"github.com/byteorem/blink/internal/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// statsInterval is how often the runtime stats line is refreshed.
+const statsInterval = 2 * time.Second
+
+// RuntimeStatsMsg carries a fresh runtime stats line.
+type RuntimeStatsMsg string
+
+// WithRuntimeStats returns the model showing blink's own memory use and
+// goroutine count under the header, e.g. while profiling with --pprof.
+func (m Model) WithRuntimeStats() Model {
+	m.stats = runtimeStats()
+	return m
+}
+
+// tickStats schedules the next refresh of the runtime stats.
+func tickStats() tea.Cmd {
+	return tea.Tick(statsInterval, func(time.Time) tea.Msg { return RuntimeStatsMsg(runtimeStats()) })
+}
+
+// runtimeStats describes the heap in use, the memory taken from the OS and
+// the number of goroutines.
+func runtimeStats() string {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return i18n.Tf("%s heap, %s from the OS, %d goroutines",
+		budget.FormatSize(int64(ms.HeapAlloc)), budget.FormatSize(int64(ms.Sys)), runtime.NumGoroutine())
+}
@@ -1,8 +1,9 @@
 package ui
 
 import (
-	"fmt"
+	"math"
 
+	"github.com/byteorem/blink/internal/i18n"
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -20,17 +21,33 @@ type SyncModel struct {
 	progress progress.Model
 	done     bool
 	count    int
+	step     float64 // the bar only moves in steps of this fraction; 0 moves it per file
 }
 
 // NewSyncModel creates a new sync progress model.
 func NewSyncModel(total int) SyncModel {
 	p := progress.New(progress.WithDefaultGradient())
+	if barColor != "" {
+		p = progress.New(progress.WithSolidFill(barColor))
+	}
 	return SyncModel{
 		total:    total,
 		progress: p,
 	}
 }
 
+// WithoutAnimation returns the model with a plain bar that moves in 10%
+// steps, so the screen is redrawn a few times rather than for every file.
+func (m SyncModel) WithoutAnimation() SyncModel {
+	fill := barColor
+	if fill == "" {
+		fill = "12"
+	}
+	m.progress = progress.New(progress.WithSolidFill(fill))
+	m.step = 0.1
+	return m
+}
+
 // Init returns nil; sync progress is driven by external messages.
 func (m SyncModel) Init() tea.Cmd {
 	return nil
@@ -78,10 +95,16 @@ func (m SyncModel) View() string {
 	if m.total > 0 {
 		pct = float64(m.copied) / float64(m.total)
 	}
+	copied := m.copied
+	if m.step > 0 {
+		// Unchanged output is not redrawn, so the count moves with the bar.
+		pct = math.Floor(pct/m.step) * m.step
+		copied = int(pct * float64(m.total))
+	}
 
 	s := "\n"
-	s += " " + headerStyle.Render("✨ blink") + "\n\n"
+	s += " " + headerStyle.Render(title) + "\n\n"
 	s += " " + m.progress.ViewAs(pct) + "\n\n"
-	s += fmt.Sprintf("  Syncing files... %d/%d\n", m.copied, m.total)
+	s += "  " + i18n.Tf("Syncing files... %d/%d", copied, m.total) + "\n"
 	return s
 }
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"github.com/byteorem/blink/internal/config"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// spinners maps config.SpinnerStyles to spinners.
+var spinners = map[string]spinner.Spinner{
+	"dot":       spinner.Dot,
+	"minidot":   spinner.MiniDot,
+	"line":      spinner.Line,
+	"jump":      spinner.Jump,
+	"pulse":     spinner.Pulse,
+	"points":    spinner.Points,
+	"meter":     spinner.Meter,
+	"ellipsis":  spinner.Ellipsis,
+	"hamburger": spinner.Hamburger,
+	"globe":     spinner.Globe,
+	"moon":      spinner.Moon,
+}
+
+var (
+	spinnerKind  = spinner.Dot
+	spinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("12")) // blue
+	barColor     = ""                                                   // solid progress bar fill; empty keeps the gradient
+	title        = "✨ blink"
+)
+
+// SetTheme changes the look of the TUIs started from now on.
+func SetTheme(t config.ThemeConfig) {
+	if s, ok := spinners[t.Spinner]; ok {
+		spinnerKind = s
+	}
+	if !t.Emoji {
+		title = "blink"
+	}
+	if t.Accent != "" {
+		accent := lipgloss.Color(t.Accent)
+		headerStyle = headerStyle.Foreground(accent)
+		spinnerStyle = spinnerStyle.Foreground(accent)
+		barColor = t.Accent
+	}
+	if t.Success != "" {
+		dotStyle = dotStyle.Foreground(lipgloss.Color(t.Success))
+		copiedStyle = copiedStyle.Foreground(lipgloss.Color(t.Success))
+	}
+	if t.Warning != "" {
+		warnStyle = warnStyle.Foreground(lipgloss.Color(t.Warning))
+	}
+	if t.Error != "" {
+		errorStyle = errorStyle.Foreground(lipgloss.Color(t.Error))
+		removedStyle = removedStyle.Foreground(lipgloss.Color(t.Error))
+	}
+	if t.Path != "" {
+		pathStyle = pathStyle.Foreground(lipgloss.Color(t.Path))
+	}
+}
@@ -2,19 +2,32 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/config"
+	"github.com/byteorem/blink/internal/i18n"
+	"github.com/byteorem/blink/internal/lint"
+	"github.com/byteorem/blink/internal/status"
+	"github.com/byteorem/blink/internal/sync"
+	"github.com/byteorem/blink/internal/telemetry"
+	"github.com/byteorem/blink/internal/testrun"
 	"github.com/byteorem/blink/internal/watcher"
+	"github.com/byteorem/blink/internal/workspace"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-const maxChangelog = 5
+const (
+	maxChangelog   = 5
+	maxDiagnostics = 8
+)
 
 var (
 	headerStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("220")) // yellow/gold
@@ -25,68 +38,179 @@ var (
 	copiedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))             // green
 	removedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))              // red
 	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)   // bold red
+	warnStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))             // yellow
 	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
 )
 
 type changeEntry struct {
-	time    time.Time
-	relPath string
-	action  string
-	isError bool
+	time      time.Time
+	relPath   string
+	action    string // in English; see text
+	text      string // action in the current language, when translated up front
+	isError   bool
+	isWarning bool
+	count     int       // times the same error repeated in a row, 0 for once
+	first     time.Time // when a repeated error was first seen
+}
+
+// addEntry appends e to the changelog, folding it into the last entry when
+// both are the same error, so a file failing in a loop takes a single line.
+func (m *Model) addEntry(e changeEntry) {
+	if n := len(m.changelog); n > 0 && e.isError {
+		last := &m.changelog[n-1]
+		if last.isError && last.relPath == e.relPath && last.action == e.action {
+			if last.count == 0 {
+				last.first = last.time
+				last.count = 1
+			}
+			last.count++
+			last.time = e.time
+			return
+		}
+	}
+	m.changelog = append(m.changelog, e)
+	if len(m.changelog) > maxChangelog {
+		m.changelog = m.changelog[len(m.changelog)-maxChangelog:]
+	}
 }
 
-// ResyncCompleteMsg signals that a manual re-sync finished.
+// ResyncCompleteMsg signals that a manual re-sync finished. addon is set when
+// only one addon was re-synced.
 type ResyncCompleteMsg struct {
+	addon string
 	count int
 	err   error
 }
 
 // Model is the Bubbletea model for the main watcher TUI.
 type Model struct {
-	addonName  string
-	targetPath string
-	fileCount  int
-	spinner    spinner.Model
-	changelog  []changeEntry
-	srcDir     string
-	dstDir     string
-	eventCh    <-chan watcher.Event
-	ignorer    *copier.Ignorer
-	quitting   bool
-	syncing    bool
+	addons      []*workspace.Addon
+	targetPath  string
+	fileCount   int
+	spinner     spinner.Model
+	changelog   []changeEntry
+	eventCh     <-chan watcher.Event
+	engine      *sync.Engine
+	cfg         config.Config
+	diags       map[string][]lint.Diagnostic
+	testRes     *testrun.Result
+	testing     bool
+	paused      map[string]bool // addons toggled off for this session, by name
+	status      *status.Writer
+	lastSync    time.Time
+	lastErr     string                  // last failed sync, cleared by the next successful one
+	held        map[string]sync.Result  // changes not synced because the destination was edited, by label
+	failed      map[string]failedChange // changes whose sync failed, by label
+	warnings    []string                // shown under the header for the whole session
+	logCh       <-chan string
+	checkUpdate func() string // returns a notice when a newer blink is out
+	notice      string
+	stats       string // runtime stats line, set by WithRuntimeStats
+	logs        []string
+	showLog     bool
+	showChurn   bool
+	showIgnored bool
+	controls    Controls
+	palette     string // command line typed into the palette
+	paletteOpen bool
+	quitting    bool
+	syncing     bool
 }
 
 // WatcherEventMsg wraps a watcher event for the Bubbletea update loop.
 type WatcherEventMsg watcher.Event
 
-// FileChangedMsg signals that a file was synced or removed.
-type FileChangedMsg struct {
+// SyncResultMsg carries what came of a change.
+type SyncResultMsg []sync.Result
+
+// failedChange is a watched change whose sync failed, queued for a retry.
+type failedChange struct {
+	addon string
+	ev    watcher.Event
+}
+
+// LintResultMsg carries selene diagnostics for a changed file.
+type LintResultMsg struct {
 	relPath string
-	action  string
-	isError bool
+	diags   []lint.Diagnostic
+	err     error
+}
+
+// TestResultMsg carries the result of a test run triggered by a change.
+type TestResultMsg struct {
+	res testrun.Result
+	err error
 }
 
 // NewModel creates a new watcher TUI model.
-func NewModel(addonName, targetPath, srcDir, dstDir string, fileCount int, eventCh <-chan watcher.Event, ig *copier.Ignorer) Model {
+// targetPath is the folder shown as the target: the addon's own folder, or
+// the AddOns folder when several addons are watched.
+// engine applies the changes from eventCh. st may be nil when no status file
+// is written.
+func NewModel(addons []*workspace.Addon, targetPath string, fileCount int, eventCh <-chan watcher.Event, engine *sync.Engine, cfg config.Config, st *status.Writer) Model {
 	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+	s.Spinner = spinnerKind
+	if cfg.LowPower {
+		s.Spinner.FPS = time.Second / 2
+	}
+	s.Style = spinnerStyle
 
 	return Model{
-		addonName:  addonName,
+		addons:     addons,
 		targetPath: targetPath,
 		fileCount:  fileCount,
 		spinner:    s,
-		srcDir:     srcDir,
-		dstDir:     dstDir,
 		eventCh:    eventCh,
-		ignorer:    ig,
+		engine:     engine,
+		cfg:        cfg,
+		diags:      make(map[string][]lint.Diagnostic),
+		paused:     make(map[string]bool),
+		status:     st,
+		lastSync:   time.Now(), // the initial sync has just finished
+		held:       make(map[string]sync.Result),
+		failed:     make(map[string]failedChange),
 	}
 }
 
+// WithWarnings returns the model showing warnings found before the session
+// started, e.g. .toc files the client won't load.
+func (m Model) WithWarnings(warnings []string) Model {
+	m.warnings = warnings
+	return m
+}
+
+// WithLog returns the model showing the output of w in a log panel.
+func (m Model) WithLog(w *LogWriter) Model {
+	m.logCh = w.ch
+	return m
+}
+
+// WithUpdateCheck returns the model running check in the background at
+// startup, and showing the notice it returns, if any.
+func (m Model) WithUpdateCheck(check func() string) Model {
+	m.checkUpdate = check
+	return m
+}
+
+// UpdateNoticeMsg carries the result of the update check.
+type UpdateNoticeMsg string
+
 // Init starts the spinner and watcher listener.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(tea.ClearScreen, m.spinner.Tick, listenToWatcher(m.eventCh))
+	cmds := []tea.Cmd{tea.ClearScreen, m.setTitle(), listenToWatcher(m.eventCh)}
+	if m.cfg.Animations {
+		cmds = append(cmds, m.spinner.Tick)
+	}
+	if m.logCh != nil {
+		cmds = append(cmds, listenToLog(m.logCh))
+	}
+	if m.checkUpdate != nil {
+		cmds = append(cmds, func() tea.Msg { return UpdateNoticeMsg(m.checkUpdate()) })
+	}
+	if m.stats != "" {
+		cmds = append(cmds, tickStats())
+	}
+	return tea.Batch(cmds...)
 }
 
 func listenToWatcher(ch <-chan watcher.Event) tea.Cmd {
@@ -103,16 +227,62 @@ func listenToWatcher(ch <-chan watcher.Event) tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.paletteOpen && msg.String() != "ctrl+c" {
+			return m.updatePalette(msg)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
-			m.quitting = true
-			return m, tea.Quit
+			return m.quit()
+		case ":":
+			m.paletteOpen = true
+		case "l":
+			if m.logCh != nil {
+				m.showLog = !m.showLog
+			}
+		case "c":
+			m.showChurn = !m.showChurn
+		case "i":
+			m.showIgnored = !m.showIgnored
+		case "p":
+			if len(m.held) > 0 {
+				return m, m.resolveHeld(true)
+			}
+		case "o":
+			if len(m.held) > 0 {
+				return m, m.resolveHeld(false)
+			}
+		case "t":
+			if len(m.failed) > 0 {
+				return m, m.retryFailed("")
+			}
 		case "r":
 			if !m.syncing {
 				m.syncing = true
-				return m, m.doResync()
+				return m, m.doResync(m.active()...)
+			}
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			i := int(msg.String()[0] - '1')
+			if len(m.addons) < 2 || i >= len(m.addons) {
+				break
 			}
+			a := m.addons[i]
+			return m.setPaused(a, !m.paused[a.Name])
+		}
+
+	case UpdateNoticeMsg:
+		m.notice = string(msg)
+		return m, nil
+
+	case RuntimeStatsMsg:
+		m.stats = string(msg)
+		return m, tickStats()
+
+	case LogLineMsg:
+		m.logs = append(m.logs, string(msg))
+		if len(m.logs) > maxLogLines {
+			m.logs = m.logs[len(m.logs)-maxLogLines:]
 		}
+		return m, listenToLog(m.logCh)
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -121,104 +291,348 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case WatcherEventMsg:
 		ev := watcher.Event(msg)
+		if ev.Restarted {
+			m.addEntry(changeEntry{time: time.Now(), relPath: "watcher", action: "restarted, re-syncing", isWarning: true})
+			// Catch up on changes made while the watch was down.
+			if a, _, ok := workspace.Route(m.addons, ev.Root); ok {
+				return m, tea.Batch(m.doResync(a), listenToWatcher(m.eventCh))
+			}
+			return m, listenToWatcher(m.eventCh)
+		}
 		if ev.Err != nil {
 			entry := changeEntry{
 				time:    time.Now(),
 				relPath: "watcher",
-				action:  fmt.Sprintf("error: %v", ev.Err),
+				action:  i18n.Tf("error: %v", ev.Err),
 				isError: true,
 			}
-			m.changelog = append(m.changelog, entry)
-			if len(m.changelog) > maxChangelog {
-				m.changelog = m.changelog[len(m.changelog)-maxChangelog:]
+			m.addEntry(entry)
+			return m, listenToWatcher(m.eventCh)
+		}
+		if a, ok := workspace.RouteTarget(m.addons, ev.Root); ok {
+			if m.paused[a.Name] {
+				return m, listenToWatcher(m.eventCh)
 			}
+			return m, tea.Batch(m.handle(ev), listenToWatcher(m.eventCh))
+		}
+		a, _, ok := workspace.Route(m.addons, ev.Root)
+		if !ok || m.paused[a.Name] {
 			return m, listenToWatcher(m.eventCh)
 		}
+		if ev.Op == watcher.OpRemove {
+			delete(m.diags, m.engine.Label(a, ev.RelPath))
+		}
+		_ = m.status.Update(func(st *status.Status) { st.Pending = len(m.eventCh) + 1 })
+		handle := m.handle(ev)
+		if tests := m.runTests(ev); tests != nil {
+			m.testing = true
+			handle = tea.Sequence(tests, handle)
+		}
 		return m, tea.Batch(
-			m.handleEvent(ev),
+			handle,
+			m.runSelene(ev),
 			listenToWatcher(m.eventCh),
 		)
 
+	case TestResultMsg:
+		m.testing = false
+		if msg.err != nil {
+			m.testRes = nil
+			m.addEntry(changeEntry{
+				time:    time.Now(),
+				relPath: "tests",
+				action:  i18n.Tf("error: %v", msg.err),
+				isError: true,
+			})
+			return m, nil
+		}
+		m.testRes = &msg.res
+		return m, nil
+
+	case LintResultMsg:
+		if msg.err != nil {
+			m.addEntry(changeEntry{
+				time:    time.Now(),
+				relPath: "selene",
+				action:  i18n.Tf("error: %v", msg.err),
+				isError: true,
+			})
+			return m, nil
+		}
+		if len(msg.diags) == 0 {
+			delete(m.diags, msg.relPath)
+		} else {
+			m.diags[msg.relPath] = msg.diags
+		}
+		return m, nil
+
 	case ResyncCompleteMsg:
-		m.syncing = false
+		var cmd tea.Cmd
+		label := i18n.T("re-sync")
+		if msg.addon != "" {
+			label += " " + msg.addon
+		} else {
+			m.syncing = false
+		}
 		if msg.err != nil {
 			entry := changeEntry{
 				time:    time.Now(),
-				relPath: "re-sync",
-				action:  fmt.Sprintf("error: %v", msg.err),
+				relPath: label,
+				action:  i18n.Tf("error: %v", msg.err),
 				isError: true,
 			}
-			m.changelog = append(m.changelog, entry)
+			m.addEntry(entry)
+			cmd = m.recordSync(fmt.Sprintf("%s: %v", label, msg.err))
 		} else {
-			m.fileCount = msg.count
+			if msg.addon == "" {
+				m.fileCount = msg.count
+			}
+			cmd = m.recordSync("")
+			for label, f := range m.failed {
+				if f.addon == msg.addon || msg.addon == "" && !m.paused[f.addon] {
+					delete(m.failed, label)
+				}
+			}
 			entry := changeEntry{
 				time:    time.Now(),
-				relPath: "re-sync",
-				action:  fmt.Sprintf("synced %d files", msg.count),
+				relPath: label,
+				action:  i18n.Tf("synced %d files", msg.count),
 			}
-			m.changelog = append(m.changelog, entry)
-		}
-		if len(m.changelog) > maxChangelog {
-			m.changelog = m.changelog[len(m.changelog)-maxChangelog:]
+			m.addEntry(entry)
 		}
-		return m, nil
+		return m, cmd
 
-	case FileChangedMsg:
-		if !msg.isError {
-			m.fileCount++
+	case SyncResultMsg:
+		var cmds []tea.Cmd
+		for _, r := range msg {
+			if r.Kind == sync.Renaming {
+				// The rename changes the addon's name and target, which
+				// the view reads, so it happens here rather than in a Cmd.
+				for _, r := range m.engine.Rename(r) {
+					cmds = append(cmds, m.applyResult(r))
+				}
+				continue
+			}
+			cmds = append(cmds, m.applyResult(r))
 		}
-		entry := changeEntry{
-			time:    time.Now(),
-			relPath: msg.relPath,
-			action:  msg.action,
-			isError: msg.isError,
+		return m, tea.Batch(cmds...)
+	}
+
+	return m, nil
+}
+
+// quit ends the session.
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	m.quitting = true
+	if m.cfg.TerminalTitle {
+		return m, tea.Sequence(tea.SetWindowTitle(""), tea.Quit)
+	}
+	return m, tea.Quit
+}
+
+// applyResult records what came of a change.
+func (m *Model) applyResult(r sync.Result) tea.Cmd {
+	entry := changeEntry{time: time.Now(), relPath: r.Label, action: r.Action(), text: r.Text()}
+	switch r.Kind {
+	case sync.Held:
+		m.held[r.Label] = r
+		entry.isWarning = true
+		m.addEntry(entry)
+		return nil
+	case sync.Renamed:
+		entry.isWarning = true
+		if len(m.addons) == 1 {
+			m.targetPath = r.Addon.Target
 		}
-		m.changelog = append(m.changelog, entry)
-		if len(m.changelog) > maxChangelog {
-			m.changelog = m.changelog[len(m.changelog)-maxChangelog:]
+		names := make([]string, len(m.addons))
+		for i, a := range m.addons {
+			names[i] = a.Name
 		}
-		return m, nil
+		_ = m.status.Update(func(st *status.Status) { st.Addons = names })
+		m.addEntry(entry)
+		return m.setTitle()
 	}
 
-	return m, nil
+	var cmd tea.Cmd
+	if r.Kind == sync.Failed {
+		cmd = m.recordSync(r.Label + ": " + r.Action())
+	} else {
+		m.fileCount++
+		cmd = m.recordSync("")
+	}
+	if r.Event != nil {
+		if r.Kind == sync.Failed {
+			m.failed[r.Label] = failedChange{addon: r.Addon.Name, ev: *r.Event}
+		} else {
+			// The addon's folder is writable again; try what failed before.
+			delete(m.failed, r.Label)
+			cmd = tea.Batch(cmd, m.retryFailed(r.Addon.Name))
+		}
+	}
+	entry.isError = r.Kind == sync.Failed
+	entry.isWarning = r.Kind == sync.Warning
+	m.addEntry(entry)
+	return cmd
 }
 
-func (m Model) doResync() tea.Cmd {
+// doResync re-syncs the given addons. Re-syncing a single addon of several
+// is reported as a partial re-sync.
+func (m Model) doResync(addons ...*workspace.Addon) tea.Cmd {
+	var name string
+	if len(addons) == 1 && len(m.addons) > 1 {
+		name = addons[0].Name
+	}
 	return func() tea.Msg {
-		count, err := copier.InitialSync(m.srcDir, m.dstDir, m.ignorer)
-		return ResyncCompleteMsg{count: count, err: err}
+		count, err := m.engine.Resync(addons...)
+		return ResyncCompleteMsg{addon: name, count: count, err: err}
 	}
 }
 
-func (m Model) handleEvent(ev watcher.Event) tea.Cmd {
+// handle applies a watched change in the background.
+func (m Model) handle(ev watcher.Event) tea.Cmd {
 	return func() tea.Msg {
-		dstPath := filepath.Join(m.dstDir, ev.RelPath)
-		srcPath := filepath.Join(m.srcDir, ev.RelPath)
+		results := m.engine.Handle(ev)
+		if len(results) == 0 {
+			return nil
+		}
+		return SyncResultMsg(results)
+	}
+}
 
-		switch ev.Op {
-		case watcher.OpRemove:
-			if err := copier.DeleteFile(dstPath); err != nil {
-				return FileChangedMsg{relPath: ev.RelPath, action: fmt.Sprintf("error: %v", err), isError: true}
-			}
-			return FileChangedMsg{relPath: ev.RelPath, action: "removed"}
-		case watcher.OpRename:
-			if _, err := os.Stat(srcPath); err == nil {
-				if err := copier.CopyFile(srcPath, dstPath); err != nil {
-					return FileChangedMsg{relPath: ev.RelPath, action: fmt.Sprintf("error: %v", err), isError: true}
-				}
-				return FileChangedMsg{relPath: ev.RelPath, action: "copied"}
-			}
-			if err := copier.DeleteFile(dstPath); err != nil {
-				return FileChangedMsg{relPath: ev.RelPath, action: fmt.Sprintf("error: %v", err), isError: true}
-			}
-			return FileChangedMsg{relPath: ev.RelPath, action: "removed"}
-		default:
-			if err := copier.CopyFile(srcPath, dstPath); err != nil {
-				return FileChangedMsg{relPath: ev.RelPath, action: fmt.Sprintf("error: %v", err), isError: true}
-			}
-			return FileChangedMsg{relPath: ev.RelPath, action: "copied"}
+// retryFailed syncs the queued failed changes of addon again, or all of them
+// when addon is empty. Changes that fail again are queued anew.
+func (m *Model) retryFailed(addon string) tea.Cmd {
+	var cmds []tea.Cmd
+	for label, f := range m.failed {
+		if addon != "" && f.addon != addon {
+			continue
+		}
+		delete(m.failed, label)
+		cmds = append(cmds, m.handle(f.ev))
+	}
+	return tea.Batch(cmds...)
+}
+
+// resolveHeld settles the held changes, either copying each edited
+// destination back into the source (pull) or overwriting it with the source.
+func (m Model) resolveHeld(pull bool) tea.Cmd {
+	var cmds []tea.Cmd
+	for label, r := range m.held {
+		cmds = append(cmds, func() tea.Msg {
+			return SyncResultMsg{m.engine.Resolve(r.Addon, label, *r.Held, pull)}
+		})
+	}
+	clear(m.held)
+	return tea.Batch(cmds...)
+}
+
+// recordSync notes the outcome of a sync in the status file and terminal
+// title. errMsg is empty when the sync succeeded.
+func (m *Model) recordSync(errMsg string) tea.Cmd {
+	m.lastErr = errMsg
+	if errMsg == "" {
+		m.lastSync = time.Now()
+	} else {
+		telemetry.RecordError(errMsg)
+	}
+	_ = m.status.Update(func(st *status.Status) {
+		st.Pending = len(m.eventCh)
+		if errMsg != "" {
+			st.State, st.Error = status.StateError, errMsg
+			return
+		}
+		st.State, st.Error = m.watchState(), ""
+		st.LastSync = m.lastSync
+	})
+	return m.setTitle()
+}
+
+// setTitle shows the sync status in the terminal or tab title, e.g.
+// "blink: MyAddon ✓ 14:02:11".
+func (m Model) setTitle() tea.Cmd {
+	if !m.cfg.TerminalTitle {
+		return nil
+	}
+	name := m.addons[0].Name
+	if len(m.addons) > 1 {
+		name = i18n.Tf("%d addons", len(m.addons))
+	}
+	mark := "✓"
+	switch {
+	case m.lastErr != "":
+		mark = "✗"
+	case len(m.active()) == 0:
+		mark = "⏸"
+	}
+	title := "blink: " + name + " " + mark
+	if !m.lastSync.IsZero() {
+		title += " " + m.cfg.FormatTime(m.lastSync)
+	}
+	return tea.SetWindowTitle(title)
+}
+
+// watchState is the status state when nothing has failed.
+func (m Model) watchState() status.State {
+	if len(m.active()) == 0 {
+		return status.StatePaused
+	}
+	return status.StateWatching
+}
+
+// active returns the addons that are not toggled off.
+func (m Model) active() []*workspace.Addon {
+	var active []*workspace.Addon
+	for _, a := range m.addons {
+		if !m.paused[a.Name] {
+			active = append(active, a)
 		}
 	}
+	return active
+}
+
+// runSelene lints a changed Lua file when selene is enabled.
+func (m Model) runSelene(ev watcher.Event) tea.Cmd {
+	if !m.cfg.Selene.Enabled || ev.Op == watcher.OpRemove || !lint.IsLua(ev.RelPath) {
+		return nil
+	}
+	a, _, ok := workspace.Route(m.addons, ev.Root)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		label := m.engine.Label(a, ev.RelPath)
+		if _, err := os.Stat(filepath.Join(ev.Root, ev.RelPath)); err != nil {
+			return LintResultMsg{relPath: label}
+		}
+		s := &lint.Selene{Command: m.cfg.Selene.Command, Std: m.cfg.Selene.Std, Dir: ev.Root}
+		diags, err := s.Run(context.Background(), ev.RelPath)
+		for i := range diags {
+			diags[i].File = m.engine.Label(a, diags[i].File)
+		}
+		return LintResultMsg{relPath: label, diags: diags, err: err}
+	}
+}
+
+// runTests runs the tests affected by a changed Lua file when test.onChange is set.
+func (m Model) runTests(ev watcher.Event) tea.Cmd {
+	if !m.cfg.Test.OnChange || ev.Op == watcher.OpRemove || !lint.IsLua(ev.RelPath) {
+		return nil
+	}
+	a, _, ok := workspace.Route(m.addons, ev.Root)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		r := &testrun.Runner{Command: m.cfg.Test.Command, Mocks: m.cfg.Test.Mocks, Dir: a.Dir()}
+		res, err := r.Run(context.Background(), testrun.Affected(a.Dir(), ev.RelPath)...)
+		return TestResultMsg{res: res, err: err}
+	}
+}
+
+// headerLabel renders a header label, padded so the values line up.
+func headerLabel(name string) string {
+	s := " " + i18n.T(name)
+	return labelStyle.Render(s + strings.Repeat(" ", max(1, 12-lipgloss.Width(s))))
 }
 
 // View renders the TUI.
@@ -227,28 +641,69 @@ func (m Model) View() string {
 		return ""
 	}
 
+	names := make([]string, len(m.addons))
+	for i, a := range m.addons {
+		switch {
+		case len(m.addons) == 1:
+			names[i] = a.Name
+		case m.paused[a.Name]:
+			names[i] = dimStyle.Render(fmt.Sprintf("%d %s %s", i+1, a.Name, i18n.T("(off)")))
+		default:
+			names[i] = fmt.Sprintf("%s %s", dimStyle.Render(fmt.Sprint(i+1)), a.Name)
+		}
+	}
+
 	s := "\n"
-	s += " " + headerStyle.Render("✨ blink") + "\n\n"
-	s += dotStyle.Render(" ●") + labelStyle.Render(" Watching   ") + m.addonName + "\n"
-	s += dotStyle.Render(" ●") + labelStyle.Render(" Target     ") + m.targetPath + "\n"
-	s += dotStyle.Render(" ●") + labelStyle.Render(" Files      ") + fmt.Sprintf("%d synced", m.fileCount) + "\n"
+	s += " " + headerStyle.Render(title) + "\n"
+	if m.notice != "" {
+		s += dimStyle.Render(" "+m.notice) + "\n"
+	}
 	s += "\n"
-	s += " " + m.spinner.View() + " Watching for changes...\n"
+	s += dotStyle.Render(" ●") + headerLabel("Watching") + strings.Join(names, ", ") + "\n"
+	s += dotStyle.Render(" ●") + headerLabel("Target") + m.targetPath + "\n"
+	s += dotStyle.Render(" ●") + headerLabel("Files") + i18n.Tf("%d synced", m.fileCount) + "\n"
+	if m.cfg.Test.OnChange {
+		s += dotStyle.Render(" ●") + headerLabel("Tests") + m.viewTests() + "\n"
+	}
+	if m.stats != "" {
+		s += dotStyle.Render(" ●") + headerLabel("Runtime") + dimStyle.Render(m.stats) + "\n"
+	}
+	s += "\n"
+	for _, w := range m.warnings {
+		s += warnStyle.Render(" ⚠ "+w) + "\n"
+	}
+	if len(m.warnings) > 0 {
+		s += "\n"
+	}
+	indicator := m.spinner.View()
+	if !m.cfg.Animations {
+		indicator = dotStyle.Render("●")
+	}
+	s += " " + indicator + " " + i18n.T("Watching for changes...") + "\n"
 	s += "\n"
 
 	for _, entry := range m.changelog {
-		ts := entry.time.Format("15:04:05")
-		actionStyled := entry.action
+		ts := m.cfg.FormatTime(entry.time)
+		action := entry.text
+		if action == "" {
+			action = i18n.T(entry.action)
+		}
+		actionStyled := action
 		if entry.isError {
-			actionStyled = errorStyle.Render(entry.action)
+			actionStyled = errorStyle.Render(action)
+		} else if entry.isWarning {
+			actionStyled = warnStyle.Render(action)
 		} else {
 			switch entry.action {
 			case "copied":
-				actionStyled = copiedStyle.Render(entry.action)
+				actionStyled = copiedStyle.Render(action)
 			case "removed":
-				actionStyled = removedStyle.Render(entry.action)
+				actionStyled = removedStyle.Render(action)
 			}
 		}
+		if entry.count > 1 {
+			actionStyled += dimStyle.Render(" " + i18n.Tf("(×%d since %s)", entry.count, m.cfg.FormatTime(entry.first)))
+		}
 		s += dimStyle.Render("  "+ts) + "  " + pathStyle.Render(entry.relPath) + " " + arrowStyle.Render("→") + " " + actionStyled + "\n"
 	}
 
@@ -256,6 +711,113 @@ func (m Model) View() string {
 		s += "\n"
 	}
 
-	s += dimStyle.Render("  Press r to re-sync, q to quit") + "\n"
+	if len(m.diags) > 0 {
+		s += m.viewDiagnostics() + "\n"
+	}
+
+	if m.showChurn {
+		s += m.viewChurn() + "\n"
+	}
+	if m.showIgnored {
+		s += m.viewIgnored() + "\n"
+	}
+	if m.showLog {
+		s += m.viewLog() + "\n"
+	}
+
+	if len(m.held) > 0 {
+		s += warnStyle.Render("  "+i18n.Tf("%d file(s) edited in AddOns: p to pull back into source, o to overwrite", len(m.held))) + "\n"
+	}
+	if len(m.failed) > 0 {
+		s += errorStyle.Render("  "+i18n.Tf("%d change(s) failed to sync: t to retry", len(m.failed))) + "\n"
+	}
+	if m.paletteOpen {
+		return s + m.viewPalette()
+	}
+	var keys []string
+	if len(m.addons) > 1 {
+		keys = append(keys, i18n.T("1-9 to toggle an addon"))
+	}
+	if m.logCh != nil {
+		if m.showLog {
+			keys = append(keys, i18n.Tf("l to hide the log (%d)", len(m.logs)))
+		} else {
+			keys = append(keys, i18n.Tf("l to show the log (%d)", len(m.logs)))
+		}
+	}
+	if m.showChurn {
+		keys = append(keys, i18n.T("c to hide the most synced files"))
+	} else {
+		keys = append(keys, i18n.T("c to show the most synced files"))
+	}
+	if m.showIgnored {
+		keys = append(keys, i18n.T("i to hide the ignore patterns"))
+	} else {
+		keys = append(keys, i18n.T("i to show the ignore patterns"))
+	}
+	keys = append(keys, i18n.T("r to re-sync"), i18n.T(": for commands"), i18n.T("q to quit"))
+	s += dimStyle.Render("  "+i18n.Tf("Press %s", strings.Join(keys, ", "))) + "\n"
+	return s
+}
+
+// viewLog renders the most recent log lines.
+func (m Model) viewLog() string {
+	s := labelStyle.Render("  "+i18n.T("Log")) + "\n"
+	lines := m.logs
+	if len(lines) > logPanelHeight {
+		lines = lines[len(lines)-logPanelHeight:]
+	}
+	if len(lines) == 0 {
+		s += dimStyle.Render("  "+i18n.T("(empty)")) + "\n"
+	}
+	for _, line := range lines {
+		s += dimStyle.Render("  "+line) + "\n"
+	}
+	return s
+}
+
+// viewDiagnostics renders outstanding linter diagnostics, errors first.
+func (m Model) viewDiagnostics() string {
+	var all []lint.Diagnostic
+	for _, d := range m.diags {
+		all = append(all, d...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Severity != all[j].Severity {
+			return all[i].Severity > all[j].Severity
+		}
+		if all[i].File != all[j].File {
+			return all[i].File < all[j].File
+		}
+		return all[i].Line < all[j].Line
+	})
+
+	s := ""
+	for i, d := range all {
+		if i == maxDiagnostics {
+			s += dimStyle.Render("  "+i18n.Tf("… %d more", len(all)-maxDiagnostics)) + "\n"
+			break
+		}
+		style := warnStyle
+		if d.Severity == lint.SeverityError {
+			style = errorStyle
+		}
+		loc := fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+		s += "  " + style.Render(d.Severity.String()) + " " + pathStyle.Render(loc) + " " + dimStyle.Render(d.Code) + " " + d.Message + "\n"
+	}
 	return s
 }
+
+// viewTests renders the status of the most recent test run.
+func (m Model) viewTests() string {
+	switch {
+	case m.testing:
+		return dimStyle.Render(i18n.T("running…"))
+	case m.testRes == nil:
+		return dimStyle.Render(i18n.T("waiting for changes"))
+	case m.testRes.OK:
+		return copiedStyle.Render("✓ "+m.testRes.String()) + dimStyle.Render(fmt.Sprintf(" (%s)", m.testRes.Duration.Round(time.Millisecond)))
+	default:
+		return errorStyle.Render("✗ " + m.testRes.String())
+	}
+}
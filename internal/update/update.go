@@ -0,0 +1,136 @@
+// Package update checks whether a newer blink release is out.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReleasesURL is the GitHub API endpoint for the latest blink release.
+const ReleasesURL = "https://api.github.com/repos/byteorem/blink/releases/latest"
+
+// Release is a published blink release.
+type Release struct {
+	Version string `json:"tag_name"`
+	URL     string `json:"html_url"` // release page, with the changelog
+}
+
+// Checker looks up the latest release, asking at most once per Interval and
+// remembering the answer in CacheFile.
+type Checker struct {
+	URL       string
+	CacheFile string
+	Interval  time.Duration
+	Client    *http.Client
+}
+
+type cache struct {
+	Checked time.Time `json:"checked"`
+	Latest  Release   `json:"latest"`
+}
+
+// NewChecker returns a Checker for blink's GitHub releases that checks once a
+// day, caching in dir.
+func NewChecker(dir string) *Checker {
+	return &Checker{
+		URL:       ReleasesURL,
+		CacheFile: filepath.Join(dir, "update.json"),
+		Interval:  24 * time.Hour,
+		Client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Latest returns the latest release, from the cache if it was checked within
+// Interval. A failed check is cached too, so an offline machine isn't asked
+// to retry on every start.
+func (c *Checker) Latest(ctx context.Context) (Release, error) {
+	var cached cache
+	if data, err := os.ReadFile(c.CacheFile); err == nil && json.Unmarshal(data, &cached) == nil {
+		if time.Since(cached.Checked) < c.Interval {
+			return cached.Latest, nil
+		}
+	}
+
+	rel, err := c.fetch(ctx)
+	c.save(cache{Checked: time.Now(), Latest: rel})
+	return rel, err
+}
+
+func (c *Checker) fetch(ctx context.Context) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("checking for updates: %s", resp.Status)
+	}
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return Release{}, fmt.Errorf("checking for updates: %w", err)
+	}
+	return rel, nil
+}
+
+func (c *Checker) save(entry cache) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.CacheFile), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.CacheFile, data, 0o644)
+}
+
+// Newer reports whether version latest is newer than current. Versions look
+// like v1.2.3; a pre-release (v1.3.0-rc1) is older than its release, and a
+// current version that isn't one, like a dev build, is never outdated.
+func Newer(latest, current string) bool {
+	l, lok := parse(latest)
+	c, cok := parse(current)
+	if !lok || !cok {
+		return false
+	}
+	for i := range 3 {
+		if l.nums[i] != c.nums[i] {
+			return l.nums[i] > c.nums[i]
+		}
+	}
+	return c.pre != "" && (l.pre == "" || l.pre > c.pre)
+}
+
+type semver struct {
+	nums [3]int
+	pre  string
+}
+
+func parse(v string) (semver, bool) {
+	var s semver
+	v = strings.TrimPrefix(v, "v")
+	v, s.pre, _ = strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return s, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return s, false
+		}
+		s.nums[i] = n
+	}
+	return s, true
+}
@@ -0,0 +1,83 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.3.0", "v1.2.9", true},
+		{"v1.2.10", "v1.2.9", true},
+		{"v2.0.0", "1.9.9", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.1.0", "v1.2.0", false},
+		{"v1.3.0", "v1.3.0-rc1", true},
+		{"v1.3.0-rc1", "v1.2.0", true},
+		{"v1.3.0-rc1", "v1.3.0", false},
+		{"v1.3.0", "dev", false},
+		{"", "v1.2.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestLatest_Cached(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"tag_name": "v1.4.0", "html_url": "https://github.com/byteorem/blink/releases/tag/v1.4.0"}`)
+	}))
+	defer srv.Close()
+
+	c := NewChecker(t.TempDir())
+	c.URL = srv.URL
+	for range 2 {
+		rel, err := c.Latest(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rel.Version != "v1.4.0" || rel.URL == "" {
+			t.Errorf("Latest() = %+v", rel)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("asked GitHub %d times, want 1", calls)
+	}
+
+	c.Interval = 0
+	_, _ = c.Latest(context.Background())
+	if calls != 2 {
+		t.Errorf("asked GitHub %d times after the interval, want 2", calls)
+	}
+}
+
+func TestLatest_FailureCached(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "rate limited", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := NewChecker(t.TempDir())
+	c.URL = srv.URL
+	c.CacheFile = filepath.Join(t.TempDir(), "nested", "update.json")
+	if _, err := c.Latest(context.Background()); err == nil {
+		t.Error("Latest() succeeded on a 403")
+	}
+	rel, err := c.Latest(context.Background())
+	if err != nil || rel.Version != "" || calls != 1 {
+		t.Errorf("second Latest() = %+v, %v after %d calls", rel, err, calls)
+	}
+}
@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// debouncer gathers the changes to the files below root until none came for
+// a debounce window, then passes them on, one event per file, until done is
+// closed. It belongs to the goroutine of a watch, which flushes it when due
+// fires.
+type debouncer struct {
+	root  string
+	delay *Delay
+	out   chan<- Event
+	done  <-chan struct{}
+
+	pending map[string]Event
+	// Files moved away that get one more window, in case an editor saving
+	// atomically is about to put them back.
+	deferred map[string]bool
+	// Files created within the current window, which didn't exist before.
+	fresh map[string]bool
+
+	timer *time.Timer
+	due   <-chan time.Time // nil while nothing is pending
+}
+
+func newDebouncer(root string, delay *Delay, out chan<- Event, done <-chan struct{}) *debouncer {
+	return &debouncer{
+		root:     root,
+		delay:    delay,
+		out:      out,
+		done:     done,
+		pending:  make(map[string]Event),
+		deferred: make(map[string]bool),
+		fresh:    make(map[string]bool),
+	}
+}
+
+// add notes a change to rel and starts the window over.
+func (d *debouncer) add(rel string, op Op) {
+	_, had := d.pending[rel]
+	if (op == OpRemove || op == OpRename) && had && d.fresh[rel] {
+		// A temp file renamed away before it was ever synced.
+		delete(d.pending, rel)
+		delete(d.fresh, rel)
+		return
+	}
+	if op == OpCreate && !had {
+		d.fresh[rel] = true
+	}
+	d.pending[rel] = Event{Root: d.root, RelPath: rel, Op: op}
+	d.wait()
+}
+
+// wait starts the window over.
+func (d *debouncer) wait() {
+	if d.timer == nil {
+		d.timer = time.NewTimer(d.delay.duration())
+		d.due = d.timer.C
+	} else {
+		d.timer.Reset(d.delay.duration())
+	}
+}
+
+// flush passes the pending changes on. A file moved away is held for one
+// more window, unless it is already back.
+func (d *debouncer) flush() {
+	held := make(map[string]Event)
+	for rel, ev := range d.pending {
+		if ev.Op == OpRemove || ev.Op == OpRename {
+			if _, err := os.Stat(filepath.Join(d.root, rel)); err == nil {
+				// Replaced by a temp file renamed over it: a plain save.
+				ev.Op = OpWrite
+			} else if ev.Op == OpRename && !d.deferred[rel] {
+				d.deferred[rel] = true
+				held[rel] = ev
+				continue
+			}
+		}
+		delete(d.deferred, rel)
+		delete(d.fresh, rel)
+		select {
+		case d.out <- ev:
+		case <-d.done:
+			return
+		}
+	}
+	d.pending = held
+	d.timer, d.due = nil, nil
+	if len(held) > 0 {
+		d.wait()
+	}
+}
@@ -0,0 +1,270 @@
+package watcher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/byteorem/blink/internal/crash"
+	"github.com/fsnotify/fsnotify"
+)
+
+// recorded is a line of a recording: an event, or with Raw a change as the
+// OS reported it, At milliseconds after the recording started. Roots are
+// relative to the working directory when they are below it, so a recording
+// replays in another checkout of the same addon.
+type recorded struct {
+	At        int64  `json:"at"`
+	Raw       bool   `json:"raw,omitempty"`
+	Root      string `json:"root"`
+	Path      string `json:"path,omitempty"`
+	Op        string `json:"op,omitempty"`
+	Err       string `json:"err,omitempty"`
+	Restarted bool   `json:"restarted,omitempty"`
+}
+
+// Recorder writes the events of a watch session, and the changes reported
+// by the OS they were made from, to a file for Replay, e.g. to reproduce a
+// debouncing bug. It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	w     *bufio.Writer
+	start time.Time
+	err   error
+}
+
+// NewRecorder returns a Recorder writing to w, timed from now.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: bufio.NewWriter(w), start: time.Now()}
+}
+
+// Trace records a change as the OS reported it; it fits Options.Trace.
+func (r *Recorder) Trace(root, rel, op string) {
+	r.write(recorded{Raw: true, Root: root, Path: filepath.ToSlash(rel), Op: op})
+}
+
+// Tee returns a channel passing on the events of ch, recording each, until
+// ch is closed or ctx is done.
+func (r *Recorder) Tee(ctx context.Context, ch <-chan Event) <-chan Event {
+	out := make(chan Event, cap(ch))
+	go func() {
+		defer crash.Recover()
+		defer close(out)
+		for {
+			var ev Event
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					return
+				}
+				ev = e
+			case <-ctx.Done():
+				return
+			}
+			rec := recorded{Root: ev.Root, Path: filepath.ToSlash(ev.RelPath), Restarted: ev.Restarted}
+			switch {
+			case ev.Err != nil:
+				rec.Err = ev.Err.Error()
+			case !ev.Restarted:
+				rec.Op = ev.Op.String()
+			}
+			r.write(rec)
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Close writes out what is buffered and returns the first error writing
+// the recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.w.Flush(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+func (r *Recorder) write(rec recorded) {
+	rec.At = time.Since(r.start).Milliseconds()
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, rec.Root); err == nil && filepath.IsLocal(rel) {
+			rec.Root = filepath.ToSlash(rel)
+		}
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.w.Write(append(data, '\n')); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// Replay reports the events of a recording made by a Recorder, at their
+// recorded times divided by speed. The changes recorded as the OS reported
+// them are debounced again, with delay divided by speed, so debouncing bugs
+// show up as they did; recordings without them, and replays with a speed of
+// 0, which report the events as fast as they are taken, replay the events
+// blink acted on instead. The channel is closed at the end of the recording
+// or when ctx is done.
+func Replay(ctx context.Context, r io.Reader, speed float64, delay *Delay) (<-chan Event, error) {
+	var records []recorded
+	raw := false
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		var rec recorded
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+		raw = raw || rec.Raw
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	raw = raw && speed > 0
+	if raw {
+		delay = NewDelay(int(float64(delay.duration().Milliseconds()) / speed))
+	}
+
+	ch := make(chan Event)
+	go func() {
+		defer crash.Recover()
+		defer close(ch)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		changes := make(map[string]chan rawChange) // by root
+		defer func() {
+			for _, c := range changes {
+				close(c)
+			}
+		}()
+
+		start := time.Now()
+		for _, rec := range records {
+			switch {
+			case rec.Raw && !raw:
+				continue
+			case !rec.Raw && raw && rec.Err == "" && !rec.Restarted:
+				// Made again from the changes the OS reported.
+				continue
+			}
+			if speed > 0 {
+				at := start.Add(time.Duration(float64(rec.At) / speed * float64(time.Millisecond)))
+				select {
+				case <-time.After(time.Until(at)):
+				case <-ctx.Done():
+					return
+				}
+			}
+			root, err := filepath.Abs(filepath.FromSlash(rec.Root))
+			if err != nil {
+				continue
+			}
+
+			if rec.Raw {
+				op, ok := opOf(parseRawOp(rec.Op))
+				rel := filepath.FromSlash(rec.Path)
+				if !ok || isEditorTemp(rel) {
+					continue
+				}
+				c, ok := changes[root]
+				if !ok {
+					c = make(chan rawChange)
+					changes[root] = c
+					wg.Add(1)
+					go func() {
+						defer crash.Recover()
+						defer wg.Done()
+						debounceReplay(ctx, newDebouncer(root, delay, ch, ctx.Done()), c)
+					}()
+				}
+				select {
+				case c <- rawChange{rel, op}:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			ev := Event{Root: root, RelPath: filepath.FromSlash(rec.Path), Restarted: rec.Restarted}
+			switch {
+			case rec.Err != "":
+				ev.Err = errors.New(rec.Err)
+			case !rec.Restarted:
+				op, ok := parseOp(rec.Op)
+				if !ok {
+					continue
+				}
+				ev.Op = op
+			}
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// rawChange is a replayed change as the OS reported it.
+type rawChange struct {
+	rel string
+	op  Op
+}
+
+// debounceReplay debounces the replayed changes like a watch does, until
+// changes is closed and nothing is pending any more, or ctx is done.
+func debounceReplay(ctx context.Context, d *debouncer, changes <-chan rawChange) {
+	for changes != nil || d.due != nil {
+		select {
+		case c, ok := <-changes:
+			if !ok {
+				changes = nil
+				continue
+			}
+			d.add(c.rel, c.op)
+		case <-d.due:
+			d.flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// parseRawOp reads an fsnotify op as Trace records it, e.g. "CREATE|WRITE".
+func parseRawOp(s string) fsnotify.Op {
+	var op fsnotify.Op
+	for _, name := range strings.Split(s, "|") {
+		switch name {
+		case "CREATE":
+			op |= fsnotify.Create
+		case "WRITE":
+			op |= fsnotify.Write
+		case "REMOVE":
+			op |= fsnotify.Remove
+		case "RENAME":
+			op |= fsnotify.Rename
+		case "CHMOD":
+			op |= fsnotify.Chmod
+		}
+	}
+	return op
+}
@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// record returns a recording of the changes an editor saving Core.lua
+// atomically makes, as the OS reported them, with the events blink acted on
+// and a watch error.
+func record(t *testing.T, root string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	for _, c := range []struct{ rel, op string }{
+		{"Core.lua.tmp", "CREATE"},
+		{"Core.lua.tmp", "WRITE"},
+		{"Core.lua.tmp", "RENAME"},
+		{"Core.lua", "CREATE"},
+		{".Core.lua.swp", "CREATE"},
+		{"Core.lua", "CHMOD"},
+	} {
+		r.Trace(root, c.rel, c.op)
+	}
+	events := make(chan Event, 2)
+	events <- Event{Root: root, RelPath: "Core.lua", Op: OpCreate}
+	events <- Event{Root: root, Err: errors.New("queue overflow")}
+	close(events)
+	for range r.Tee(context.Background(), events) {
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func replayAll(t *testing.T, buf *bytes.Buffer, speed float64) []Event {
+	t.Helper()
+	ch, err := Replay(context.Background(), buf, speed, NewDelay(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []Event
+	for ev := range ch {
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestReplay_DebouncesRawChanges(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "Core.lua"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes, errs int
+	for _, ev := range replayAll(t, record(t, root), 1) {
+		switch {
+		case ev.Err != nil:
+			errs++
+		case ev.RelPath == "Core.lua" && ev.Op == OpCreate && ev.Root == root:
+			changes++
+		default:
+			t.Errorf("unexpected event %+v", ev)
+		}
+	}
+	if changes != 1 || errs != 1 {
+		t.Errorf("replayed %d change(s) of Core.lua and %d error(s), want one each", changes, errs)
+	}
+}
+
+func TestReplay_WithoutWaiting(t *testing.T) {
+	root := t.TempDir()
+	events := replayAll(t, record(t, root), 0)
+	if len(events) != 2 || events[0].RelPath != "Core.lua" || events[0].Op != OpCreate || events[1].Err == nil {
+		t.Errorf("replayed %+v, want the recorded events", events)
+	}
+}
+
+func TestTee_StopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan Event)
+	out := NewRecorder(&bytes.Buffer{}).Tee(ctx, events)
+	// Nobody reads the event, and events is never closed; Tee must still
+	// close out once ctx is done rather than block.
+	events <- Event{RelPath: "Core.lua", Op: OpWrite}
+	cancel()
+	for range out {
+	}
+}
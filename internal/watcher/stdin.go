@@ -0,0 +1,146 @@
+package watcher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/crash"
+)
+
+// Root is a directory whose changes Read reports, with the ignorer of its
+// files.
+type Root struct {
+	Dir     string
+	Ignorer *copier.Ignorer
+}
+
+// notice is a change written to Read's input as JSON.
+type notice struct {
+	Path string `json:"path"`
+	Op   string `json:"op"` // create, write, remove or rename; empty tells from the file
+}
+
+// Read reports the changes written to r instead of watching for them, for
+// editors, build tools and watchman wrappers that know what they changed.
+// Each line is a path, absolute or relative to dir, or a JSON object like
+// {"path": "Core.lua", "op": "remove"}. Without an op, a path that exists was
+// written and one that doesn't was removed; a folder stands for every file
+// in it. Paths outside the roots, or ignored there,
+// are skipped. The channel is closed when r ends or ctx is done.
+func Read(ctx context.Context, r io.Reader, dir string, roots []Root) <-chan Event {
+	ch := make(chan Event, 64)
+	go func() {
+		defer crash.Recover()
+		defer close(ch)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			events, err := parseNotice(line, dir, roots)
+			if err != nil {
+				slog.Warn("skipped change notification", "line", line, "err", err)
+				continue
+			}
+			for _, ev := range events {
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			select {
+			case ch <- Event{Err: fmt.Errorf("reading change notifications: %w", err)}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return ch
+}
+
+// parseNotice returns the events a line of Read's input stands for, its path
+// relative to dir unless absolute.
+func parseNotice(line, dir string, roots []Root) ([]Event, error) {
+	n := notice{Path: line}
+	if strings.HasPrefix(line, "{") {
+		n = notice{}
+		if err := json.Unmarshal([]byte(line), &n); err != nil {
+			return nil, err
+		}
+		if n.Path == "" {
+			return nil, fmt.Errorf("no path")
+		}
+	}
+
+	path := n.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	root, dir, rel, ok := findRoot(path, roots)
+	if !ok {
+		return nil, fmt.Errorf("not in a synced folder")
+	}
+	if rel == "." || root.Ignorer.ShouldIgnore(rel) {
+		return nil, nil
+	}
+
+	var op Op
+	switch n.Op {
+	case "":
+		if _, err := os.Lstat(path); err != nil {
+			op = OpRemove
+		} else if isDirectory(path, root.Ignorer.FollowSymlinks) {
+			_, files, err := walkTree(dir, path, root.Ignorer)
+			if err != nil {
+				return nil, err
+			}
+			events := make([]Event, len(files))
+			for i, f := range files {
+				events[i] = Event{Root: root.Dir, RelPath: f, Op: OpWrite}
+			}
+			return events, nil
+		} else {
+			op = OpWrite
+		}
+	default:
+		var ok bool
+		if op, ok = parseOp(n.Op); !ok {
+			return nil, fmt.Errorf("unknown op %q", n.Op)
+		}
+	}
+	return []Event{{Root: root.Dir, RelPath: rel, Op: op}}, nil
+}
+
+// findRoot returns the innermost root holding path, its absolute directory,
+// and path relative to it.
+func findRoot(path string, roots []Root) (root Root, dir, rel string, ok bool) {
+	for _, r := range roots {
+		abs, err := filepath.Abs(r.Dir)
+		if err != nil {
+			continue
+		}
+		p, err := filepath.Rel(abs, path)
+		if err != nil || p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if !ok || len(abs) > len(dir) {
+			root, dir, rel, ok = r, abs, p, true
+		}
+	}
+	return root, dir, rel, ok
+}
@@ -0,0 +1,102 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/byteorem/blink/internal/copier"
+)
+
+func TestFindRoot(t *testing.T) {
+	base := t.TempDir()
+	outer := Root{Dir: filepath.Join(base, "MyAddon")}
+	inner := Root{Dir: filepath.Join(base, "MyAddon", "Libs")}
+	roots := []Root{inner, outer}
+
+	for path, want := range map[string]struct {
+		dir, rel string
+		ok       bool
+	}{
+		filepath.Join(base, "MyAddon", "Core.lua"):        {outer.Dir, "Core.lua", true},
+		filepath.Join(base, "MyAddon", "Libs", "Lib.lua"): {inner.Dir, "Lib.lua", true},
+		filepath.Join(base, "MyAddon"):                    {outer.Dir, ".", true},
+		filepath.Join(base, "MyAddonExtra", "Core.lua"):   {"", "", false},
+		filepath.Join(base, "Other", "..", "Core.lua"):    {"", "", false},
+	} {
+		_, dir, rel, ok := findRoot(filepath.Clean(path), roots)
+		if dir != want.dir || rel != want.rel || ok != want.ok {
+			t.Errorf("findRoot(%s) = %s, %s, %v, want %s, %s, %v", path, dir, rel, ok, want.dir, want.rel, want.ok)
+		}
+	}
+}
+
+func TestParseNotice(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "MyAddon")
+	for _, rel := range []string{"Core.lua", filepath.Join("UI", "Frame.lua"), filepath.Join("UI", "notes.txt")} {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(root, rel)), 0o755)
+		write(t, filepath.Join(root, rel))
+	}
+	roots := []Root{{Dir: root, Ignorer: copier.NewIgnorer(root, []string{"*.txt"}, false, false, false)}}
+
+	for _, tt := range []struct {
+		line string
+		want []Event
+	}{
+		// Relative paths are taken from dir.
+		{filepath.Join("MyAddon", "Core.lua"), []Event{{Root: root, RelPath: "Core.lua", Op: OpWrite}}},
+		{filepath.Join(root, "Gone.lua"), []Event{{Root: root, RelPath: "Gone.lua", Op: OpRemove}}},
+		{`{"path": "MyAddon/Core.lua", "op": "create"}`, []Event{{Root: root, RelPath: "Core.lua", Op: OpCreate}}},
+		// A folder stands for the files in it that aren't ignored.
+		{filepath.Join(root, "UI"), []Event{{Root: root, RelPath: filepath.Join("UI", "Frame.lua"), Op: OpWrite}}},
+		{filepath.Join(root, "UI", "notes.txt"), nil},
+		{root, nil},
+	} {
+		got, err := parseNotice(tt.line, base, roots)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("parseNotice(%s) = %v, %v, want %v", tt.line, got, err, tt.want)
+		}
+	}
+
+	for _, line := range []string{
+		filepath.Join(base, "Other", "Core.lua"),
+		`{"op": "write"}`,
+		`{"path": "MyAddon/Core.lua", "op": "touch"}`,
+		`{"path":`,
+	} {
+		if got, err := parseNotice(line, base, roots); err == nil {
+			t.Errorf("parseNotice(%s) = %v, want an error", line, got)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "MyAddon")
+	_ = os.MkdirAll(root, 0o755)
+	write(t, filepath.Join(root, "Core.lua"))
+	roots := []Root{{Dir: root, Ignorer: copier.NewIgnorer(root, nil, false, false, false)}}
+
+	// Lines that can't be read are skipped, not fatal.
+	input := strings.Join([]string{
+		"",
+		filepath.Join("MyAddon", "Core.lua"),
+		"../elsewhere.lua",
+		`{"path": "MyAddon/Old.lua", "op": "remove"}`,
+	}, "\n")
+	var got []Event
+	for ev := range Read(context.Background(), strings.NewReader(input), base, roots) {
+		got = append(got, ev)
+	}
+	want := []Event{
+		{Root: root, RelPath: "Core.lua", Op: OpWrite},
+		{Root: root, RelPath: "Old.lua", Op: OpRemove},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+}
@@ -3,12 +3,19 @@ package watcher
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/crash"
 	"github.com/fsnotify/fsnotify"
 )
 
@@ -23,121 +30,530 @@ const (
 	OpRename
 )
 
+func (o Op) String() string {
+	switch o {
+	case OpCreate:
+		return "create"
+	case OpWrite:
+		return "write"
+	case OpRemove:
+		return "remove"
+	case OpRename:
+		return "rename"
+	}
+	return fmt.Sprintf("Op(%d)", int(o))
+}
+
+// parseOp returns the Op whose String is s.
+func parseOp(s string) (Op, bool) {
+	for _, op := range []Op{OpCreate, OpWrite, OpRemove, OpRename} {
+		if op.String() == s {
+			return op, true
+		}
+	}
+	return 0, false
+}
+
 // Event represents a debounced filesystem change.
 // If Err is set, the event represents a watcher error rather than a file change.
+// If Restarted is set, the watch on Root failed and was set up again; changes
+// made in between may have been missed.
 type Event struct {
-	RelPath string
-	Op      Op
-	Err     error
+	Root      string // the watched source directory RelPath is relative to
+	RelPath   string
+	Op        Op
+	Err       error
+	Restarted bool
 }
 
-// Watch starts watching srcDir for changes, returning debounced events on a channel.
-// The delay parameter specifies the debounce window in milliseconds.
-func Watch(ctx context.Context, srcDir string, ig *copier.Ignorer, delay int, verbose bool) (<-chan Event, error) {
-	w, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
-	}
+// heartbeatInterval is how often an idle watch loop records that it is alive.
+const heartbeatInterval = time.Second
 
-	// Add all existing subdirectories
-	err = filepath.WalkDir(srcDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if !d.IsDir() {
-			return nil
-		}
-		rel, _ := filepath.Rel(srcDir, path)
-		if rel != "." && ig.ShouldIgnore(rel) {
-			return filepath.SkipDir
+// AuditInterval is how often the registered watches are checked against the
+// directory tree by default, since fsnotify can drop watches without telling.
+const AuditInterval = 30 * time.Second
+
+var (
+	beatsMu sync.Mutex
+	beats   = make(map[*atomic.Int64]struct{})
+)
+
+// Healthy reports whether every running watch loop has gone round within
+// maxAge. A loop stuck in fsnotify handling, or blocked on a consumer that
+// stopped reading events, stops beating.
+func Healthy(maxAge time.Duration) bool {
+	beatsMu.Lock()
+	defer beatsMu.Unlock()
+	now := time.Now().UnixNano()
+	for b := range beats {
+		if time.Duration(now-b.Load()) > maxAge {
+			return false
 		}
-		return w.Add(path)
-	})
+	}
+	return true
+}
+
+// Backend names the OS file notification API fsnotify uses here.
+func Backend() string {
+	switch runtime.GOOS {
+	case "linux":
+		return "inotify"
+	case "windows":
+		return "ReadDirectoryChangesW"
+	case "darwin", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return "kqueue"
+	}
+	return runtime.GOOS
+}
+
+// A suspended watch looks for changes after idlePollInterval, then less
+// often while none come, up to every maxIdlePollInterval.
+const (
+	idlePollInterval    = 5 * time.Second
+	maxIdlePollInterval = 30 * time.Second
+)
+
+// nextPollInterval returns how long a suspended watch waits after a check
+// that found nothing, checking every d so far.
+func nextPollInterval(d time.Duration) time.Duration {
+	return min(2*d, maxIdlePollInterval)
+}
+
+// A failed watch is set up again after restartBackoff, doubling on every
+// failed attempt up to maxRestartBackoff.
+const (
+	restartBackoff    = time.Second
+	maxRestartBackoff = time.Minute
+)
+
+// Delay is a debounce window in milliseconds that can be changed while the
+// watches sharing it run.
+type Delay struct{ ms atomic.Int64 }
+
+// NewDelay returns a debounce window of ms milliseconds.
+func NewDelay(ms int) *Delay {
+	d := new(Delay)
+	d.Set(ms)
+	return d
+}
+
+// Set changes the window; changes seen from now on wait for the new one.
+func (d *Delay) Set(ms int) {
+	d.ms.Store(int64(ms))
+}
+
+// Millis returns the window in milliseconds.
+func (d *Delay) Millis() int {
+	return int(d.ms.Load())
+}
+
+// duration returns the window, none for a nil Delay.
+func (d *Delay) duration() time.Duration {
+	if d == nil {
+		return 0
+	}
+	return time.Duration(d.ms.Load()) * time.Millisecond
+}
+
+// Options tunes a watch.
+type Options struct {
+	Delay *Delay // debounce window
+
+	// Idle is how long without changes before the OS watches are dropped
+	// and the tree is polled every few seconds instead, until the next
+	// change resumes them. 0 never suspends.
+	Idle time.Duration
+
+	// Audit is how often lost watches are looked for; 0 means AuditInterval.
+	Audit time.Duration
+
+	// Trace, if set, is called with every change the OS reports, before
+	// ignoring and debouncing, e.g. "CREATE|WRITE" for Core.lua.
+	Trace func(root, rel, op string)
+}
+
+// Watch starts watching srcDir for changes, returning debounced events on a channel.
+func Watch(ctx context.Context, srcDir string, ig *copier.Ignorer, opts Options) (<-chan Event, error) {
+	w, err := newWatcher(srcDir, ig)
 	if err != nil {
-		_ = w.Close()
 		return nil, err
 	}
 
 	ch := make(chan Event, 64)
 
+	beat := new(atomic.Int64)
+	beat.Store(time.Now().UnixNano())
+	beatsMu.Lock()
+	beats[beat] = struct{}{}
+	beatsMu.Unlock()
+
 	go func() {
-		defer func() { _ = w.Close() }()
+		defer crash.Recover()
+		defer func() {
+			if w != nil {
+				_ = w.Close()
+			}
+		}()
 		defer close(ch)
+		defer func() {
+			beatsMu.Lock()
+			delete(beats, beat)
+			beatsMu.Unlock()
+		}()
+
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		auditEvery := opts.Audit
+		if auditEvery <= 0 {
+			auditEvery = AuditInterval
+		}
+		audit := time.NewTicker(auditEvery)
+		defer audit.Stop()
 
-		debounce := time.Duration(delay) * time.Millisecond
-		pending := make(map[string]Event)
-		var timer *time.Timer
-		var timerC <-chan time.Time
+		idle := opts.Idle
+		d := newDebouncer(srcDir, opts.Delay, ch, ctx.Done())
 
-		flush := func() {
-			for _, ev := range pending {
-				ch <- ev
+		// While suspended, w is nil and the tree is polled against snapshot.
+		events, errs := w.Events, w.Errors
+		var idleC, pollC <-chan time.Time
+		var idleTimer *time.Timer
+		var poll *time.Timer
+		var pollEvery time.Duration
+		var snapshot map[string]fileState
+		if idle > 0 {
+			idleTimer = time.NewTimer(idle)
+			defer idleTimer.Stop()
+			idleC = idleTimer.C
+		}
+		defer func() {
+			if poll != nil {
+				poll.Stop()
 			}
-			pending = make(map[string]Event)
-			timer = nil
-			timerC = nil
+		}()
+		resetIdle := func() {
+			if idleTimer != nil {
+				idleTimer.Reset(idle)
+			}
+		}
+
+		// While restarting after the watch failed, w is nil until the next
+		// attempt at restartC succeeds.
+		var restartC <-chan time.Time
+		backoff := restartBackoff
+		restart := func(reason any) {
+			slog.Warn("file watching failed, restarting", "dir", srcDir, "reason", reason)
+			_ = w.Close()
+			w, events, errs = nil, nil, nil
+			restartC = time.After(backoff)
 		}
 
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-timerC:
-				flush()
-			case ev, ok := <-w.Events:
-				if !ok {
+			case now := <-ticker.C:
+				beat.Store(now.UnixNano())
+			case <-audit.C:
+				if w != nil {
+					repairWatches(w, srcDir, ig)
+				}
+			case <-d.due:
+				d.flush()
+			case <-restartC:
+				nw, err := newWatcher(srcDir, ig)
+				if err != nil {
+					backoff = min(2*backoff, maxRestartBackoff)
+					restartC = time.After(backoff)
+					slog.Debug("can't restart watching", "dir", srcDir, "err", err, "retry", backoff)
+					continue
+				}
+				w, events, errs = nw, nw.Events, nw.Errors
+				restartC, backoff = nil, restartBackoff
+				resetIdle()
+				select {
+				case ch <- Event{Root: srcDir, Restarted: true}:
+				case <-ctx.Done():
 					return
 				}
+			case <-idleC:
+				if w == nil {
+					// Restarting; suspend once watching again.
+					resetIdle()
+					continue
+				}
+				if len(d.pending) > 0 {
+					resetIdle()
+					continue
+				}
+				snapshot = scanTree(srcDir, ig)
+				_ = w.Close()
+				w, events, errs = nil, nil, nil
+				pollEvery = idlePollInterval
+				poll = time.NewTimer(pollEvery)
+				pollC = poll.C
+				slog.Debug("suspended watching while idle", "dir", srcDir)
+			case <-pollC:
+				current := scanTree(srcDir, ig)
+				changed := diffTrees(srcDir, snapshot, current)
+				if len(changed) == 0 {
+					pollEvery = nextPollInterval(pollEvery)
+					poll.Reset(pollEvery)
+					continue
+				}
+				nw, err := newWatcher(srcDir, ig)
+				if err != nil {
+					// Keep polling; the next round tries again.
+					slog.Debug("can't resume watching", "dir", srcDir, "err", err)
+					poll.Reset(idlePollInterval)
+					continue
+				}
+				poll.Stop()
+				poll, pollC = nil, nil
+				w, events, errs, snapshot = nw, nw.Events, nw.Errors, nil
+				for _, ev := range changed {
+					d.pending[ev.RelPath] = ev
+				}
+				d.flush()
+				resetIdle()
+				slog.Debug("resumed watching", "dir", srcDir)
+			case ev, ok := <-events:
+				if !ok {
+					restart("events closed")
+					continue
+				}
+				resetIdle()
 
 				rel, err := filepath.Rel(srcDir, ev.Name)
 				if err != nil || rel == "." {
 					continue
 				}
+				if opts.Trace != nil {
+					opts.Trace(srcDir, rel, ev.Op.String())
+				}
 
 				if ig.ShouldIgnore(rel) {
-					if verbose {
-						log.Printf("[verbose] ignored: %s", rel)
-					}
+					slog.Debug("ignored change", "path", rel)
 					continue
 				}
-
-				var op Op
-				switch {
-				case ev.Has(fsnotify.Create):
-					op = OpCreate
-					// If new directory, add to watcher
-					if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
-						_ = w.Add(ev.Name)
-					}
-				case ev.Has(fsnotify.Write):
-					op = OpWrite
-				case ev.Has(fsnotify.Remove):
-					op = OpRemove
-					_ = w.Remove(ev.Name)
-				case ev.Has(fsnotify.Rename):
-					op = OpRename
-					_ = w.Remove(ev.Name)
-				default:
+				if isEditorTemp(rel) {
+					slog.Debug("ignored editor temp file", "path", rel)
 					continue
 				}
 
-				pending[rel] = Event{RelPath: rel, Op: op}
-
-				if timer == nil {
-					timer = time.NewTimer(debounce)
-					timerC = timer.C
-				} else {
-					timer.Reset(debounce)
+				op, ok := opOf(ev.Op)
+				if !ok {
+					continue
 				}
+				switch op {
+				case OpCreate:
+					// A new (or recreated) directory may already hold files and
+					// subdirectories by the time we see it, e.g. after a git
+					// checkout. Watch the whole subtree and report its files.
+					if isDirectory(ev.Name, ig.FollowSymlinks) {
+						dirs, files, _ := walkTree(srcDir, ev.Name, ig)
+						for _, dir := range dirs {
+							_ = w.Add(dir)
+						}
+						for _, f := range files {
+							d.pending[f] = Event{Root: srcDir, RelPath: f, Op: OpCreate}
+						}
+					}
+				case OpRemove, OpRename:
+					_ = w.Remove(ev.Name)
+				}
+				d.add(rel, op)
 
-			case watchErr, ok := <-w.Errors:
+			case watchErr, ok := <-errs:
 				if !ok {
+					restart("errors closed")
+					continue
+				}
+				select {
+				case ch <- Event{Root: srcDir, Err: watchErr}:
+				case <-ctx.Done():
 					return
 				}
-				ch <- Event{Err: watchErr}
+				if errors.Is(watchErr, fsnotify.ErrEventOverflow) {
+					// Events were dropped; a restart brings a resync.
+					restart(watchErr)
+				}
 			}
 		}
 	}()
 
 	return ch, nil
 }
+
+// newFSWatcher sets up OS file watching; tests wrap it to get at the
+// watchers.
+var newFSWatcher = fsnotify.NewWatcher
+
+// newWatcher returns an fsnotify watcher on srcDir and every directory below
+// it that ig lets through.
+func newWatcher(srcDir string, ig *copier.Ignorer) (*fsnotify.Watcher, error) {
+	w, err := newFSWatcher()
+	if err != nil {
+		return nil, err
+	}
+	dirs, _, err := walkTree(srcDir, srcDir, ig)
+	if err == nil {
+		for _, dir := range dirs {
+			if err = w.Add(dir); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// fileState is what a suspended watch compares to notice a changed file.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// scanTree returns the state of every file below srcDir that ig lets through,
+// keyed by relative path.
+func scanTree(srcDir string, ig *copier.Ignorer) map[string]fileState {
+	files := make(map[string]fileState)
+	_ = copier.Walk(srcDir, srcDir, ig, func(path, rel string, isDir bool) error {
+		if isDir {
+			return nil
+		}
+		if info, err := os.Stat(path); err == nil {
+			files[rel] = fileState{info.Size(), info.ModTime()}
+		}
+		return nil
+	})
+	return files
+}
+
+// diffTrees returns the events that turn tree before into after.
+func diffTrees(srcDir string, before, after map[string]fileState) []Event {
+	var events []Event
+	for rel, st := range after {
+		old, ok := before[rel]
+		switch {
+		case !ok:
+			events = append(events, Event{Root: srcDir, RelPath: rel, Op: OpCreate})
+		case old != st:
+			events = append(events, Event{Root: srcDir, RelPath: rel, Op: OpWrite})
+		}
+	}
+	for rel := range before {
+		if _, ok := after[rel]; !ok {
+			events = append(events, Event{Root: srcDir, RelPath: rel, Op: OpRemove})
+		}
+	}
+	return events
+}
+
+// opOf returns the change an fsnotify op stands for, reporting false for
+// those that aren't one, like a change of permissions.
+func opOf(op fsnotify.Op) (Op, bool) {
+	switch {
+	case op.Has(fsnotify.Create):
+		return OpCreate, true
+	case op.Has(fsnotify.Write):
+		return OpWrite, true
+	case op.Has(fsnotify.Remove):
+		return OpRemove, true
+	case op.Has(fsnotify.Rename):
+		return OpRename, true
+	}
+	return 0, false
+}
+
+// isEditorTemp reports whether rel names a scratch file an editor writes
+// while saving: vim's 4913 probe, swap and ~ backup files, Emacs lock files
+// and JetBrains' atomic-save temp files.
+func isEditorTemp(rel string) bool {
+	base := filepath.Base(rel)
+	switch {
+	case base == "4913",
+		strings.HasSuffix(base, "~"),
+		strings.HasPrefix(base, ".") && (strings.HasSuffix(base, ".swp") || strings.HasSuffix(base, ".swx") || strings.HasSuffix(base, ".swo")),
+		strings.HasPrefix(base, ".#"),
+		strings.HasSuffix(base, "___jb_tmp___"),
+		strings.HasSuffix(base, "___jb_old___"):
+		return true
+	}
+	return false
+}
+
+// isDirectory reports whether path is a directory, or a symlink to one when follow
+// is set.
+func isDirectory(path string, follow bool) bool {
+	stat := os.Lstat
+	if follow {
+		stat = os.Stat
+	}
+	info, err := stat(path)
+	return err == nil && info.IsDir()
+}
+
+// walkTree returns dir and every directory below it that ig lets through,
+// and the files in them relative to srcDir.
+func walkTree(srcDir, dir string, ig *copier.Ignorer) (dirs, files []string, err error) {
+	err = copier.Walk(srcDir, dir, ig, func(path, rel string, isDir bool) error {
+		if isDir {
+			dirs = append(dirs, path)
+		} else {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return dirs, files, err
+}
+
+// repairWatches re-adds the watches w lost for directories still in the tree.
+func repairWatches(w *fsnotify.Watcher, srcDir string, ig *copier.Ignorer) {
+	dirs, _, err := walkTree(srcDir, srcDir, ig)
+	if err != nil {
+		// The tree is changing under us; the next audit will catch up.
+		slog.Debug("watch audit skipped", "dir", srcDir, "err", err)
+		return
+	}
+	watched := make(map[string]bool)
+	for _, dir := range w.WatchList() {
+		watched[dir] = true
+	}
+	for _, dir := range dirs {
+		if watched[dir] {
+			continue
+		}
+		if err := w.Add(dir); err != nil {
+			slog.Warn("could not re-add dropped watch", "dir", dir, "err", err)
+			continue
+		}
+		slog.Info("re-added dropped watch", "dir", dir)
+	}
+}
+
+// Merge combines the events of several watchers into one channel, which is
+// closed once all of them are.
+func Merge(chs ...<-chan Event) <-chan Event {
+	if len(chs) == 1 {
+		return chs[0]
+	}
+	out := make(chan Event, 64)
+	var wg sync.WaitGroup
+	for _, ch := range chs {
+		wg.Add(1)
+		go func(ch <-chan Event) {
+			defer wg.Done()
+			for ev := range ch {
+				out <- ev
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,237 @@
+package watcher
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/fsnotify/fsnotify"
+)
+
+// newTestDebouncer returns a debouncer for root whose window never ends on
+// its own: the tests flush it.
+func newTestDebouncer(root string) (*debouncer, chan Event) {
+	out := make(chan Event, 16)
+	return newDebouncer(root, NewDelay(60_000), out, nil), out
+}
+
+// drain returns the events flushed so far.
+func drain(out chan Event) []Event {
+	var events []Event
+	for {
+		select {
+		case ev := <-out:
+			events = append(events, ev)
+		default:
+			return events
+		}
+	}
+}
+
+func write(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("print(1)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDebounce_TempWriteAndRename(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, "Core.lua"))
+	d, out := newTestDebouncer(root)
+
+	// An editor saving atomically: write a temp file, rename it over the
+	// real one.
+	d.add("Core.lua.tmp", OpCreate)
+	d.add("Core.lua.tmp", OpWrite)
+	d.add("Core.lua.tmp", OpRename)
+	d.add("Core.lua", OpCreate)
+	d.flush()
+
+	events := drain(out)
+	if len(events) != 1 || events[0].RelPath != "Core.lua" || events[0].Op != OpCreate {
+		t.Errorf("flushed %v, want one create of Core.lua", events)
+	}
+	if len(d.pending) != 0 {
+		t.Errorf("still pending: %v", d.pending)
+	}
+}
+
+func TestDebounce_RenameAwayAndBack(t *testing.T) {
+	root := t.TempDir()
+	d, out := newTestDebouncer(root)
+
+	// The original moved aside at the end of one window...
+	d.add("Core.lua", OpRename)
+	d.flush()
+	if events := drain(out); len(events) != 0 {
+		t.Fatalf("flushed %v, want the move held for another window", events)
+	}
+
+	// ...and the new file in place by the next: a save, not a removal.
+	write(t, filepath.Join(root, "Core.lua"))
+	d.flush()
+	events := drain(out)
+	if len(events) != 1 || events[0].Op != OpWrite {
+		t.Errorf("flushed %v, want one write of Core.lua", events)
+	}
+}
+
+func TestDebounce_DeleteAndCreate(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, "Core.lua"))
+	d, out := newTestDebouncer(root)
+
+	d.add("Core.lua", OpRemove)
+	d.add("Core.lua", OpCreate)
+	d.flush()
+
+	events := drain(out)
+	if len(events) != 1 || events[0].Op != OpCreate {
+		t.Errorf("flushed %v, want one create of Core.lua", events)
+	}
+}
+
+func TestDebounce_PlainRemoval(t *testing.T) {
+	root := t.TempDir()
+	d, out := newTestDebouncer(root)
+
+	d.add("Core.lua", OpRemove)
+	d.flush()
+
+	events := drain(out)
+	if len(events) != 1 || events[0].Op != OpRemove {
+		t.Errorf("flushed %v, want the removal without waiting another window", events)
+	}
+	if d.due != nil {
+		t.Error("window started again with nothing held")
+	}
+}
+
+func TestIsEditorTemp(t *testing.T) {
+	for rel, want := range map[string]bool{
+		"4913":                               true,
+		"Core.lua~":                          true,
+		filepath.Join("UI", ".Core.lua.swp"): true,
+		".Core.lua.swx":                      true,
+		".#Core.lua":                         true,
+		"Core.lua___jb_tmp___":               true,
+		"Core.lua___jb_old___":               true,
+		"Core.lua":                           false,
+		"Core.swp":                           false,
+		"Locales.lua":                        false,
+	} {
+		if got := isEditorTemp(rel); got != want {
+			t.Errorf("isEditorTemp(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestScanAndDiffTrees(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{"Core.lua", "Old.lua", "Same.lua", filepath.Join("Libs", "Lib.lua"), "notes.txt"} {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(root, rel)), 0o755)
+		write(t, filepath.Join(root, rel))
+	}
+	ig := copier.NewIgnorer(root, []string{"*.txt"}, false, false, false)
+	before := scanTree(root, ig)
+	if len(before) != 4 {
+		t.Fatalf("scanTree() = %v, want the 4 files not ignored", before)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "Core.lua"), []byte("print(1, 2)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Remove(filepath.Join(root, "Old.lua"))
+	write(t, filepath.Join(root, "New.lua"))
+	write(t, filepath.Join(root, "more.txt"))
+
+	got := make(map[string]Op)
+	for _, ev := range diffTrees(root, before, scanTree(root, ig)) {
+		if ev.Root != root {
+			t.Errorf("event root %s, want %s", ev.Root, root)
+		}
+		got[ev.RelPath] = ev.Op
+	}
+	want := map[string]Op{"Core.lua": OpWrite, "Old.lua": OpRemove, "New.lua": OpCreate}
+	if !maps.Equal(got, want) {
+		t.Errorf("diffTrees() = %v, want %v", got, want)
+	}
+
+	if events := diffTrees(root, before, before); len(events) != 0 {
+		t.Errorf("diffTrees() of the same tree = %v", events)
+	}
+}
+
+func TestNextPollInterval(t *testing.T) {
+	d := idlePollInterval
+	for range 10 {
+		next := nextPollInterval(d)
+		if next < d || next > maxIdlePollInterval {
+			t.Fatalf("nextPollInterval(%v) = %v", d, next)
+		}
+		d = next
+	}
+	if d != maxIdlePollInterval {
+		t.Errorf("polls every %v after a long break, want %v", d, maxIdlePollInterval)
+	}
+}
+
+func TestWatch_RestartsAfterFailure(t *testing.T) {
+	created := make(chan *fsnotify.Watcher, 2)
+	orig := newFSWatcher
+	newFSWatcher = func() (*fsnotify.Watcher, error) {
+		w, err := orig()
+		if err == nil {
+			created <- w
+		}
+		return w, err
+	}
+	defer func() { newFSWatcher = orig }()
+
+	root := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := Watch(ctx, root, copier.NewIgnorer(root, nil, false, false, false), Options{Delay: NewDelay(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() Event {
+		t.Helper()
+		select {
+		case ev := <-ch:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event")
+		}
+		return Event{}
+	}
+
+	// The OS watching dies; it is set up again and the restart reported.
+	_ = (<-created).Close()
+	if ev := next(); !ev.Restarted || ev.Root != root {
+		t.Fatalf("got %+v, want a restart of %s", ev, root)
+	}
+	write(t, filepath.Join(root, "Core.lua"))
+	if ev := next(); ev.RelPath != "Core.lua" {
+		t.Errorf("got %+v after the restart, want a change of Core.lua", ev)
+	}
+
+	// Once ctx is done the watch ends and closes ch.
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch still running after ctx is done")
+	}
+}
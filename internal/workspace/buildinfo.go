@@ -0,0 +1,34 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/byteorem/blink/internal/gitinfo"
+)
+
+// BuildInfoFile is the Lua file blink writes into a target with the state of
+// the checkout it was synced from, when buildInfo is enabled.
+const BuildInfoFile = "BlinkBuildInfo.lua"
+
+// WriteBuildInfo writes BuildInfoFile into the addon's target, describing
+// the checkout info and the time of the sync. Listed in the .toc, it sets
+// ns.BlinkBuildInfo in the addon's namespace.
+func (a *Addon) WriteBuildInfo(info gitinfo.Info, at time.Time) error {
+	lua := fmt.Sprintf(`-- Generated by blink on every sync; changes are overwritten.
+local _, ns = ...
+ns.BlinkBuildInfo = {
+	commit = %q,
+	branch = %q,
+	dirty = %t,
+	syncedAt = %q,
+	syncTime = %d,
+}
+`, info.Commit, info.Branch, info.Dirty, at.UTC().Format(time.RFC3339), at.Unix())
+	if err := os.MkdirAll(a.Target, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(a.Target, BuildInfoFile), []byte(lua), 0o644)
+}
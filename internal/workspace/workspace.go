@@ -0,0 +1,266 @@
+// Package workspace describes the addons a blink process syncs and discovers
+// the addons kept together in one repository.
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/detect"
+	"github.com/byteorem/blink/internal/toc"
+)
+
+// Addon is one addon being synced: where its files come from and where they go.
+type Addon struct {
+	Name     string
+	Sources  []copier.Source // the addon's own source first, then any overlaid ones
+	Target   string          // destination folder, e.g. .../Interface/AddOns/MyAddon
+	Template string          // flavor .toc template relative to Dir, if the addon uses one
+	FixName  bool            // named so every .toc file loads, rather than after the first (--fix)
+
+	// Pack marks a folder of a UI pack synced outside AddOns, e.g. into
+	// Interface/Fonts: the files it replaces are kept (see
+	// copier.KeepOriginal), and no .toc, marker or build info is written.
+	Pack bool
+}
+
+// Dir returns the addon's own source directory.
+func (a *Addon) Dir() string {
+	return a.Sources[0].Dir
+}
+
+// GenerateTocs renders the addon's flavor .toc files into its target. It does
+// nothing when the addon has no template.
+func (a *Addon) GenerateTocs(variants []toc.Variant) ([]string, error) {
+	if a.Template == "" {
+		return nil, nil
+	}
+	return toc.GenerateAll(filepath.Join(a.Dir(), a.Template), a.Target, a.Name, variants)
+}
+
+// IsTemplate reports whether the watched file root/relPath is the addon's .toc template.
+func (a *Addon) IsTemplate(root, relPath string) bool {
+	return a.Template != "" && root == a.Dir() && relPath == filepath.Clean(a.Template)
+}
+
+// IsToc reports whether the watched file root/relPath is one of the .toc
+// files the addon is named after.
+func (a *Addon) IsToc(root, relPath string) bool {
+	return root == a.Dir() && filepath.Dir(relPath) == "." && strings.EqualFold(filepath.Ext(relPath), ".toc")
+}
+
+// Rename gives the addon a new name, e.g. after its .toc file was renamed:
+// the target folder moves to one named after it, and generated .toc files
+// are written again under the new name.
+func (a *Addon) Rename(name string, variants []toc.Variant) error {
+	target := filepath.Join(filepath.Dir(a.Target), name)
+	// A change of case only is the same folder on Windows and macOS.
+	if !strings.EqualFold(target, a.Target) {
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("can't move %s to %s: it already exists", a.Target, target)
+		}
+	}
+	if a.Template != "" {
+		for _, v := range variants {
+			_ = os.Remove(filepath.Join(a.Target, v.FileName(a.Name)))
+		}
+	}
+	if err := os.Rename(a.Target, target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	a.Name, a.Target = name, target
+	_, err := a.GenerateTocs(variants)
+	return err
+}
+
+// FollowToc renames the addon when the name given by its .toc files has
+// changed. It returns the previous name, or "" when the name is unchanged.
+func (a *Addon) FollowToc(variants []toc.Variant) (string, error) {
+	name, ok := a.TocName()
+	if !ok || name == a.Name {
+		return "", nil
+	}
+	old := a.Name
+	return old, a.Rename(name, variants)
+}
+
+// TocName returns the name the addon's .toc files give it, fixed if the
+// addon asks for it. It reports false when there is no .toc to go by.
+func (a *Addon) TocName() (string, bool) {
+	name, ok := detect.AddonName(a.Dir())
+	if nc := detect.CheckName(a.Dir(), name); a.FixName && nc.Fix != "" {
+		name = nc.Fix
+	}
+	return name, ok
+}
+
+// SourceFile returns where a file of the addon's target lives in the sources:
+// the source that provides it, or the addon's own source for a new file.
+func (a *Addon) SourceFile(relPath string) string {
+	if dirs := copier.OtherProviders(a.Sources, "", relPath); len(dirs) > 0 {
+		return filepath.Join(dirs[0], relPath)
+	}
+	return filepath.Join(a.Dir(), relPath)
+}
+
+// TargetIgnorer returns the ignore rules for watching the addon's target in
+// two-way mode: blink's marker, its build info and the .toc files generated
+// from the template are never copied back.
+func (a *Addon) TargetIgnorer(variants []toc.Variant) *copier.Ignorer {
+	patterns := []string{"/" + copier.MarkerFile, "/" + BuildInfoFile}
+	if a.Template != "" {
+		for _, v := range variants {
+			patterns = append(patterns, "/"+v.FileName(a.Name))
+		}
+	}
+	return copier.NewIgnorer(a.Target, patterns, false, false, false)
+}
+
+// RouteTarget returns the addon whose target is the watched directory root.
+func RouteTarget(addons []*Addon, root string) (*Addon, bool) {
+	for _, a := range addons {
+		if a.Target == root {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// Route returns the addon and source that a watched directory belongs to.
+func Route(addons []*Addon, root string) (*Addon, copier.Source, bool) {
+	for _, a := range addons {
+		if s, ok := copier.SourceFor(a.Sources, root); ok {
+			return a, s, true
+		}
+	}
+	return nil, copier.Source{}, false
+}
+
+// Filter returns the addons named in names (case-insensitive), keeping their
+// order. An empty names list keeps every addon.
+func Filter(addons []*Addon, names []string) ([]*Addon, error) {
+	if len(names) == 0 {
+		return addons, nil
+	}
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[strings.ToLower(strings.TrimSpace(n))] = true
+	}
+
+	var kept []*Addon
+	for _, a := range addons {
+		if want[strings.ToLower(a.Name)] {
+			kept = append(kept, a)
+			delete(want, strings.ToLower(a.Name))
+		}
+	}
+	if len(want) > 0 {
+		var unknown, known []string
+		for n := range want {
+			unknown = append(unknown, n)
+		}
+		for _, a := range addons {
+			known = append(known, a.Name)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown addon(s) %s — available: %s", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return kept, nil
+}
+
+// CheckTargets returns an error naming the first two addons that would sync
+// into the same destination folder. Folder names are compared without case,
+// since the AddOns folder on Windows and macOS is case-insensitive.
+func CheckTargets(addons []*Addon) error {
+	seen := make(map[string]*Addon, len(addons))
+	for _, a := range addons {
+		key := strings.ToLower(filepath.Clean(a.Target))
+		if prev, ok := seen[key]; ok {
+			return fmt.Errorf("addons in %s and %s both sync to %s — rename one of them", prev.Dir(), a.Dir(), a.Target)
+		}
+		seen[key] = a
+	}
+	return nil
+}
+
+// Member is an addon found in a workspace.
+type Member struct {
+	Name string
+	Dir  string
+}
+
+// Discover returns the addons below root, sorted by name. Every directory
+// holding a .toc file is an addon; directories inside an addon are not
+// searched, since embedded libraries carry .toc files of their own. Hidden
+// directories and directories excluded by ig are skipped.
+func Discover(root string, ig *copier.Ignorer) ([]Member, error) {
+	var members []Member
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") || (ig != nil && ig.ShouldIgnore(rel)) {
+			return filepath.SkipDir
+		}
+		tocs, err := detect.TocFiles(path)
+		if err != nil {
+			return err
+		}
+		if len(tocs) == 0 {
+			return nil
+		}
+		name, _ := detect.AddonName(path)
+		members = append(members, Member{Name: name, Dir: path})
+		return filepath.SkipDir
+	})
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+	return members, err
+}
+
+// Glob returns the addons in the folders matching patterns, relative to root,
+// sorted by name. Matching folders without a .toc file are skipped, but every
+// pattern must match at least one addon.
+func Glob(root string, patterns []string) ([]Member, error) {
+	var members []Member
+	seen := make(map[string]bool)
+	for _, p := range patterns {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(root, p)
+		}
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		found := false
+		for _, dir := range matches {
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+			name, ok := detect.AddonName(dir)
+			if !ok {
+				continue
+			}
+			found = true
+			if !seen[dir] {
+				seen[dir] = true
+				members = append(members, Member{Name: name, Dir: dir})
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no addon folders match %q", p)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+	return members, nil
+}
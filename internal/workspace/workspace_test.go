@@ -0,0 +1,206 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/byteorem/blink/internal/copier"
+	"github.com/byteorem/blink/internal/gitinfo"
+	"github.com/byteorem/blink/internal/toc"
+)
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("## Title: x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "Raid", "RaidTools.toc"))
+	writeFile(t, filepath.Join(root, "Raid", "Libs", "LibStub", "LibStub.toc"))
+	writeFile(t, filepath.Join(root, "addons", "Bags", "Bags.toc"))
+	writeFile(t, filepath.Join(root, "node_modules", "x", "X.toc"))
+	writeFile(t, filepath.Join(root, ".cache", "Y", "Y.toc"))
+	writeFile(t, filepath.Join(root, "docs", "index.md"))
+
+	ig := copier.NewIgnorer(root, []string{"node_modules/"}, false, false, false)
+	members, err := Discover(root, ig)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	want := []Member{
+		{Name: "Bags", Dir: filepath.Join(root, "addons", "Bags")},
+		{Name: "RaidTools", Dir: filepath.Join(root, "Raid")},
+	}
+	if len(members) != len(want) {
+		t.Fatalf("Discover() = %+v, want %+v", members, want)
+	}
+	for i := range want {
+		if members[i] != want[i] {
+			t.Errorf("members[%d] = %+v, want %+v", i, members[i], want[i])
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	a := &Addon{Name: "A", Sources: []copier.Source{{Dir: "/src/a"}, {Dir: "/src/common"}}}
+	b := &Addon{Name: "B", Sources: []copier.Source{{Dir: "/src/b"}}}
+
+	got, src, ok := Route([]*Addon{a, b}, "/src/common")
+	if !ok || got != a || src.Dir != "/src/common" {
+		t.Errorf("Route(common) = %v, %v, %v", got, src, ok)
+	}
+	if got, _, ok := Route([]*Addon{a, b}, "/src/b"); !ok || got != b {
+		t.Errorf("Route(b) = %v, %v", got, ok)
+	}
+	if _, _, ok := Route([]*Addon{a, b}, "/elsewhere"); ok {
+		t.Error("Route() matched an unknown root")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	addons := []*Addon{{Name: "MyAddon"}, {Name: "MyAddon_Options"}, {Name: "Other"}}
+
+	got, err := Filter(addons, []string{"myaddon_options", "MyAddon"})
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "MyAddon" || got[1].Name != "MyAddon_Options" {
+		t.Errorf("Filter() = %v, want MyAddon, MyAddon_Options", got)
+	}
+
+	if got, _ := Filter(addons, nil); len(got) != 3 {
+		t.Errorf("Filter(nil) kept %d addons, want 3", len(got))
+	}
+	if _, err := Filter(addons, []string{"Missing"}); err == nil {
+		t.Error("expected error for unknown addon")
+	}
+}
+
+func TestCheckTargets(t *testing.T) {
+	addOns := filepath.Join("wow", "Interface", "AddOns")
+	addon := func(dir, name string) *Addon {
+		return &Addon{Name: name, Sources: []copier.Source{{Dir: dir}}, Target: filepath.Join(addOns, name)}
+	}
+
+	if err := CheckTargets([]*Addon{addon("a", "MyAddon"), addon("b", "MyAddon_Options")}); err != nil {
+		t.Errorf("CheckTargets() error = %v", err)
+	}
+	if err := CheckTargets([]*Addon{addon("a", "MyAddon"), addon("b", "myaddon")}); err == nil {
+		t.Error("expected error for targets differing only in case")
+	}
+}
+
+func TestSourceFile(t *testing.T) {
+	own := t.TempDir()
+	common := t.TempDir()
+	writeFile(t, filepath.Join(common, "Shared.lua"))
+	a := &Addon{Name: "A", Sources: []copier.Source{
+		{Dir: own, Ignorer: copier.NewIgnorer(own, nil, false, false, false)},
+		{Dir: common, Ignorer: copier.NewIgnorer(common, nil, false, false, false)},
+	}}
+
+	if got := a.SourceFile("Shared.lua"); got != filepath.Join(common, "Shared.lua") {
+		t.Errorf("SourceFile(Shared.lua) = %s, want it in the common source", got)
+	}
+	if got := a.SourceFile("New.lua"); got != filepath.Join(own, "New.lua") {
+		t.Errorf("SourceFile(New.lua) = %s, want it in the addon's own source", got)
+	}
+}
+
+func TestTargetIgnorer(t *testing.T) {
+	a := &Addon{Name: "A", Target: t.TempDir(), Template: "A.toc.tmpl"}
+	ig := a.TargetIgnorer([]toc.Variant{{Suffix: "Mainline"}})
+	for _, rel := range []string{copier.MarkerFile, BuildInfoFile, "A_Mainline.toc"} {
+		if !ig.ShouldIgnore(rel) {
+			t.Errorf("%s not ignored", rel)
+		}
+	}
+	if ig.ShouldIgnore("Core.lua") {
+		t.Error("Core.lua ignored")
+	}
+
+	if got, ok := RouteTarget([]*Addon{a}, a.Target); !ok || got != a {
+		t.Errorf("RouteTarget() = %v, %v", got, ok)
+	}
+}
+
+func TestFollowToc(t *testing.T) {
+	src := t.TempDir()
+	addOns := t.TempDir()
+	writeFile(t, filepath.Join(src, "Old.toc"))
+	writeFile(t, filepath.Join(addOns, "Old", "Core.lua"))
+	a := &Addon{Name: "Old", Sources: []copier.Source{{Dir: src}}, Target: filepath.Join(addOns, "Old")}
+
+	if from, err := a.FollowToc(nil); from != "" || err != nil {
+		t.Errorf("FollowToc() = %q, %v before a rename", from, err)
+	}
+	if !a.IsToc(src, "Old.toc") || a.IsToc(src, filepath.Join("Libs", "Lib.toc")) {
+		t.Error("IsToc() wrong")
+	}
+
+	if err := os.Rename(filepath.Join(src, "Old.toc"), filepath.Join(src, "New.toc")); err != nil {
+		t.Fatal(err)
+	}
+	from, err := a.FollowToc(nil)
+	if err != nil {
+		t.Fatalf("FollowToc() error = %v", err)
+	}
+	if from != "Old" || a.Name != "New" || a.Target != filepath.Join(addOns, "New") {
+		t.Errorf("FollowToc() = %q, addon now %s at %s", from, a.Name, a.Target)
+	}
+	if _, err := os.Stat(filepath.Join(addOns, "New", "Core.lua")); err != nil {
+		t.Error("target folder not moved")
+	}
+
+	// A folder already using the new name is left alone.
+	writeFile(t, filepath.Join(addOns, "Taken", "Core.lua"))
+	if err := a.Rename("Taken", nil); err == nil {
+		t.Error("expected error renaming onto an existing folder")
+	}
+}
+
+func TestGlob(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "packages", "AddonCore", "AddonCore.toc"))
+	writeFile(t, filepath.Join(root, "packages", "AddonUI", "AddonUI.toc"))
+	writeFile(t, filepath.Join(root, "packages", "AddonDocs", "README.md"))
+	writeFile(t, filepath.Join(root, "packages", "Other", "Other.toc"))
+
+	members, err := Glob(root, []string{"packages/Addon*"})
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if len(members) != 2 || members[0].Name != "AddonCore" || members[1].Name != "AddonUI" {
+		t.Errorf("Glob() = %+v, want AddonCore and AddonUI", members)
+	}
+
+	if _, err := Glob(root, []string{"packages/Nothing*"}); err == nil {
+		t.Error("expected error for a pattern matching no addon")
+	}
+}
+
+func TestWriteBuildInfo(t *testing.T) {
+	a := &Addon{Name: "A", Target: filepath.Join(t.TempDir(), "A")}
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	if err := a.WriteBuildInfo(gitinfo.Info{Commit: "1a2b3c4", Branch: "main", Dirty: true}, at); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(a.Target, BuildInfoFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`commit = "1a2b3c4"`, `branch = "main"`, `dirty = true`, `syncedAt = "2026-10-16T12:00:00Z"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s lacks %s:\n%s", BuildInfoFile, want, data)
+		}
+	}
+}
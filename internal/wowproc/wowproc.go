@@ -0,0 +1,103 @@
+// Package wowproc tells whether a WoW client is running.
+package wowproc
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// clients are the executable names of the WoW clients (retail, classic, PTR,
+// beta) on Windows and under Wine, and the app binaries on macOS.
+var clients = []string{
+	"wow.exe", "wowclassic.exe", "wowt.exe", "wowb.exe", "wowclassict.exe", "wowclassicb.exe",
+	"world of warcraft", "world of warcraft classic",
+}
+
+// Running reports whether a WoW client process is running. Under WSL, the
+// client is a Windows process, which ps doesn't list; it asks Windows'
+// tasklist.exe instead.
+func Running(ctx context.Context) (bool, error) {
+	var names []string
+	if tasklist := tasklistCommand(); tasklist != "" {
+		out, err := exec.CommandContext(ctx, tasklist, "/FO", "CSV", "/NH").Output()
+		if err != nil {
+			return false, err
+		}
+		names = parseTasklist(string(out))
+	} else {
+		out, err := exec.CommandContext(ctx, "ps", "-A", "-o", "comm=").Output()
+		if err != nil {
+			return false, err
+		}
+		names = parsePS(string(out))
+	}
+	for _, name := range names {
+		if isClient(name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// tasklistCommand returns the command listing Windows processes, or "" where
+// ps lists them.
+func tasklistCommand() string {
+	switch {
+	case runtime.GOOS == "windows":
+		return "tasklist"
+	case runtime.GOOS == "linux" && isWSL(os.Getenv("WSL_DISTRO_NAME"), readFile("/proc/sys/kernel/osrelease")):
+		return "tasklist.exe"
+	}
+	return ""
+}
+
+// isWSL reports whether Linux runs under WSL, given the WSL_DISTRO_NAME
+// variable and the kernel release, e.g. "5.15.153.1-microsoft-standard-WSL2".
+func isWSL(distro, release string) bool {
+	return distro != "" || strings.Contains(strings.ToLower(release), "microsoft")
+}
+
+func readFile(path string) string {
+	data, _ := os.ReadFile(path)
+	return string(data)
+}
+
+// isClient reports whether a process name is a WoW client's.
+func isClient(name string) bool {
+	name = strings.ToLower(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	for _, c := range clients {
+		if name == c {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePS returns the process names in the output of ps -o comm=, one per
+// line, which may be full paths (macOS).
+func parsePS(out string) []string {
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			names = append(names, line)
+		}
+	}
+	return names
+}
+
+// parseTasklist returns the process names in the CSV output of tasklist.
+func parseTasklist(out string) []string {
+	records, _ := csv.NewReader(strings.NewReader(out)).ReadAll()
+	var names []string
+	for _, r := range records {
+		if len(r) > 0 {
+			names = append(names, r[0])
+		}
+	}
+	return names
+}
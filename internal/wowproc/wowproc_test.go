@@ -0,0 +1,42 @@
+package wowproc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTasklist(t *testing.T) {
+	out := "\"System Idle Process\",\"0\",\"Services\",\"0\",\"8 K\"\r\n\"Wow.exe\",\"1234\",\"Console\",\"1\",\"2,345,678 K\"\r\n"
+	if got := parseTasklist(out); !slices.Equal(got, []string{"System Idle Process", "Wow.exe"}) {
+		t.Errorf("parseTasklist() = %q", got)
+	}
+}
+
+func TestIsClient(t *testing.T) {
+	for _, name := range []string{"Wow.exe", "WowClassic.exe", `C:\Games\World of Warcraft\_retail_\Wow.exe`, "/Applications/World of Warcraft/_retail_/World of Warcraft.app/Contents/MacOS/World of Warcraft"} {
+		if !isClient(name) {
+			t.Errorf("isClient(%q) = false", name)
+		}
+	}
+	for _, name := range parsePS("  bash\n/usr/bin/blink\nBattle.net.exe\n") {
+		if isClient(name) {
+			t.Errorf("isClient(%q) = true", name)
+		}
+	}
+}
+
+func TestIsWSL(t *testing.T) {
+	for _, tt := range []struct {
+		distro, release string
+		want            bool
+	}{
+		{"", "5.15.153.1-microsoft-standard-WSL2\n", true},
+		{"", "4.4.0-19041-Microsoft\n", true},
+		{"Ubuntu", "", true},
+		{"", "6.8.0-45-generic\n", false},
+	} {
+		if got := isWSL(tt.distro, tt.release); got != tt.want {
+			t.Errorf("isWSL(%q, %q) = %v, want %v", tt.distro, tt.release, got, tt.want)
+		}
+	}
+}